@@ -166,6 +166,7 @@ func newReconciler(ctx context.Context, mgr manager.Manager, lookup *lookup, dri
 		mgr.GetAPIReader(),
 		lookup,
 		helmDeployer,
+		systemNamespace,
 	)
 
 	// Build the monitor to detect changes
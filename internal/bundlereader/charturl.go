@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/Masterminds/semver/v3"
 	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
@@ -67,6 +68,49 @@ func ChartVersion(ctx context.Context, location fleet.HelmOptions, a Auth) (stri
 	return chart.Version, nil
 }
 
+// AvailableChartVersion is a version of a chart, which is available in a
+// helm repository or OCI registry.
+type AvailableChartVersion struct {
+	Version string
+	// Created is the creation time of the version from the repository's
+	// index. It is zero for OCI registries, which do not record it.
+	Created time.Time
+}
+
+// ChartVersions returns all versions of the helm chart, which are available
+// in the helm repo server or OCI registry, regardless of the version
+// constraint.
+func ChartVersions(ctx context.Context, location fleet.HelmOptions, a Auth) ([]AvailableChartVersion, error) {
+	var versions []AvailableChartVersion
+	if repoURI, ok := strings.CutPrefix(location.Repo, ociURLPrefix); ok {
+		client, err := getOCIRepoClient(repoURI, a)
+		if err != nil {
+			return nil, err
+		}
+		tags, err := registry.Tags(ctx, client)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get available tags of registry %s: %w", location.Repo, err)
+		}
+		for _, tag := range tags {
+			versions = append(versions, AvailableChartVersion{Version: tag})
+		}
+		return versions, nil
+	}
+
+	if location.Repo == "" {
+		return nil, fmt.Errorf("no repository to get versions of chart %s from", location.Chart)
+	}
+
+	index, err := getHelmRepoIndex(ctx, location.Repo, a)
+	if err != nil {
+		return nil, err
+	}
+	for _, cv := range index.Entries[location.Chart] {
+		versions = append(versions, AvailableChartVersion{Version: cv.Version, Created: cv.Created})
+	}
+	return versions, nil
+}
+
 func getOCIRepoClient(repoURI string, a Auth) (*remote.Repository, error) {
 	r, err := remote.NewRepository(repoURI)
 	if err != nil {
@@ -131,7 +175,7 @@ type helmRepoIndex interface {
 }
 
 // getHelmRepoIndex retrieves and parses the index.yaml from a base URL which can be used to find a specific chart and version
-func getHelmRepoIndex(ctx context.Context, repoURL string, auth Auth) (helmRepoIndex, error) {
+func getHelmRepoIndex(ctx context.Context, repoURL string, auth Auth) (*repov1.IndexFile, error) {
 	indexURL, err := url.JoinPath(repoURL, "index.yaml")
 	if err != nil {
 		return nil, err
@@ -37,14 +37,20 @@ func GetManifestFromHelmChart(ctx context.Context, c client.Reader, bd *fleet.Bu
 		return nil, err
 	}
 
+	verifier, err := newVerifier(ctx, c, bd.Namespace, helm.Verification)
+	if err != nil {
+		return nil, err
+	}
+
 	resources, err := loadDirectory(ctx,
 		loadOpts{},
 		directory{
-			prefix:  checksum(helm),
-			base:    temp,
-			source:  chartURL,
-			version: helm.Version,
-			auth:    auth,
+			prefix:   checksum(helm),
+			base:     temp,
+			source:   chartURL,
+			version:  helm.Version,
+			auth:     auth,
+			verifier: verifier,
 		},
 	)
 	if err != nil {
@@ -27,6 +27,11 @@ import (
 )
 
 const (
+	cosignPublicKey = `-----BEGIN PUBLIC KEY-----
+MFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAEVJX0u2n13VIsdBcDORXu50tofeTC
+6xabEsjMLQoAE0o/M6Rtb8WsOCnODLaGmRDzef4ZUGQJmkzL1UkD9SEWsw==
+-----END PUBLIC KEY-----
+`
 	authUsername  = "holadonpepito"
 	authPassword  = "holadonjose"
 	chartName     = "sleeper-chart"
@@ -347,6 +352,61 @@ func TestGetManifestFromHelmChart(t *testing.T) {
 			expectedErrNotNil:   true,
 			expectedError:       "Get \"##URL##/index.yaml\": tls: failed to verify certificate: x509: certificate signed by unknown authority",
 		},
+		{
+			name: "missing verification secret",
+			bd: fleet.BundleDeployment{
+				Spec: fleet.BundleDeploymentSpec{
+					Options: fleet.BundleDeploymentOptions{
+						Helm: &fleet.HelmOptions{
+							Repo:         "##URL##", // will be replaced by the mock server url
+							Chart:        "sleeper",
+							Verification: &fleet.OCIVerification{SecretName: "keys"},
+						},
+					},
+					HelmChartOptions: &fleet.BundleHelmOptions{
+						InsecureSkipTLSverify: true,
+					},
+				},
+			},
+			readerCalls: func(c *mocks.MockReader) {
+				c.EXPECT().Get(gomock.Any(), types.NamespacedName{Name: "keys"}, gomock.Any()).Return(fmt.Errorf("secret not found"))
+			},
+			requiresAuth:        false,
+			expectedNilManifest: true,
+			expectedResources:   []fleet.BundleResource{},
+			expectedErrNotNil:   true,
+			expectedError:       "failed to read verification secret /keys: secret not found",
+		},
+		{
+			name: "verification of a chart from a helm repository",
+			bd: fleet.BundleDeployment{
+				Spec: fleet.BundleDeploymentSpec{
+					Options: fleet.BundleDeploymentOptions{
+						Helm: &fleet.HelmOptions{
+							Repo:         "##URL##", // will be replaced by the mock server url
+							Chart:        "sleeper",
+							Verification: &fleet.OCIVerification{SecretName: "keys"},
+						},
+					},
+					HelmChartOptions: &fleet.BundleHelmOptions{
+						InsecureSkipTLSverify: true,
+					},
+				},
+			},
+			readerCalls: func(c *mocks.MockReader) {
+				c.EXPECT().Get(gomock.Any(), types.NamespacedName{Name: "keys"}, gomock.Any()).DoAndReturn(
+					func(_ context.Context, _ types.NamespacedName, secret *corev1.Secret, _ ...interface{}) error {
+						secret.Data = map[string][]byte{"cosign.pub": []byte(cosignPublicKey)}
+						return nil
+					},
+				)
+			},
+			requiresAuth:        false,
+			expectedNilManifest: true,
+			expectedResources:   []fleet.BundleResource{},
+			expectedErrNotNil:   true,
+			expectedError:       "signature verification is only supported for OCI charts",
+		},
 		{
 			name: "load directory no version specified",
 			bd: fleet.BundleDeployment{
@@ -21,9 +21,13 @@ import (
 	"helm.sh/helm/v4/pkg/downloader"
 	helmgetter "helm.sh/helm/v4/pkg/getter"
 	"helm.sh/helm/v4/pkg/registry"
+	"oras.land/oras-go/v2/registry/remote"
+	orasauth "oras.land/oras-go/v2/registry/remote/auth"
 
 	"github.com/rancher/fleet/internal/content"
 	"github.com/rancher/fleet/internal/helmupdater"
+	"github.com/rancher/fleet/internal/ociverify"
+	"github.com/rancher/fleet/internal/sops"
 	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
 )
 
@@ -168,12 +172,15 @@ func readFleetIgnore(path string) ([]string, error) {
 func loadDirectory(ctx context.Context, opts loadOpts, dir directory) ([]fleet.BundleResource, error) {
 	var resources []fleet.BundleResource
 
-	files, err := GetContent(ctx, dir.base, dir.source, dir.version, dir.auth, opts.disableDepsUpdate, opts.ignoreApplyConfigs)
+	files, err := getContent(ctx, dir.base, dir.source, dir.version, dir.auth, dir.verifier, opts.disableDepsUpdate, opts.ignoreApplyConfigs, opts.root)
 	if err != nil {
 		return nil, err
 	}
 
 	for name, data := range files {
+		if err := checkEncrypted(name, data); err != nil {
+			return nil, err
+		}
 		r := fleet.BundleResource{Name: name}
 		if opts.compress || !utf8.Valid(data) {
 			content, err := content.Base64GZ(data)
@@ -194,8 +201,39 @@ func loadDirectory(ctx context.Context, opts loadOpts, dir directory) ([]fleet.B
 	return resources, nil
 }
 
+// checkEncrypted returns an error if the file is encrypted with SOPS, but
+// the agent cannot decrypt it. SOPS encrypted files are kept encrypted in the
+// bundle and only decrypted by the agent.
+func checkEncrypted(name string, data []byte) error {
+	ext := filepath.Ext(name)
+	if (ext != ".yaml" && ext != ".yml") || !sops.IsEncrypted(data) {
+		return nil
+	}
+	recipients, err := sops.Recipients(data)
+	if err != nil {
+		return fmt.Errorf("reading sops encrypted file %s: %w", name, err)
+	}
+	if len(recipients) == 0 {
+		return fmt.Errorf("sops encrypted file %s has no age or pgp recipients, other key types cannot be decrypted by the agent", name)
+	}
+	return nil
+}
+
 // GetContent uses go-getter (and Helm for OCI) to read the files from directories and servers.
 func GetContent(ctx context.Context, base, source, version string, auth Auth, disableDepsUpdate bool, ignoreApplyConfigs []string) (map[string][]byte, error) {
+	return getContent(ctx, base, source, version, auth, nil, disableDepsUpdate, ignoreApplyConfigs, "")
+}
+
+// getContent reads the files like GetContent. If verifier is not nil, the
+// source must be an OCI chart, which is only downloaded if its signature
+// can be verified. If root is set, files outside of root, e.g. linked by
+// symlinks, are rejected.
+func getContent(ctx context.Context, base, source, version string, auth Auth, verifier *ociverify.Verifier, disableDepsUpdate bool, ignoreApplyConfigs []string, root string) (map[string][]byte, error) {
+	isOCI := strings.HasPrefix(source, ociURLPrefix)
+	if verifier != nil && !isOCI {
+		return nil, fmt.Errorf("cannot verify the signature of %s, signature verification is only supported for OCI charts", source)
+	}
+
 	temp, err := os.MkdirTemp("", "fleet")
 	if err != nil {
 		return nil, err
@@ -207,8 +245,8 @@ func GetContent(ctx context.Context, base, source, version string, auth Auth, di
 	// go-getter does not support downloading OCI registry based files yet
 	// until this is implemented we use Helm to download charts from OCI based registries
 	// and provide the downloaded file to go-getter locally
-	if strings.HasPrefix(source, ociURLPrefix) {
-		source, err = downloadOCIChart(source, version, temp, auth)
+	if isOCI {
+		source, err = downloadOCIChart(ctx, source, version, temp, auth, verifier)
 		if err != nil {
 			return nil, err
 		}
@@ -253,6 +291,12 @@ func GetContent(ctx context.Context, base, source, version string, auth Auth, di
 		GetMode: getter.ModeDir,
 	}
 
+	// the environment is global to the process, local only loads run
+	// inside the controller and must not modify it
+	if root != "" && (auth.CABundle != nil || auth.InsecureSkipVerify) {
+		return nil, fmt.Errorf("TLS options are not supported for %s", orgSource)
+	}
+
 	if auth.CABundle != nil {
 		file, err := os.CreateTemp("", "cabundle-*")
 		if err != nil {
@@ -331,6 +375,12 @@ func GetContent(ctx context.Context, base, source, version string, auth Auth, di
 			return nil
 		}
 
+		if root != "" && info.Type()&fs.ModeSymlink != 0 {
+			if err := checkInRoot(root, path); err != nil {
+				return err
+			}
+		}
+
 		content, err := os.ReadFile(path)
 		if err != nil {
 			return err
@@ -347,7 +397,7 @@ func GetContent(ctx context.Context, base, source, version string, auth Auth, di
 }
 
 // downloadOCIChart uses Helm to download charts from OCI based registries
-func downloadOCIChart(name, version, path string, auth Auth) (string, error) {
+func downloadOCIChart(ctx context.Context, name, version, path string, auth Auth, verifier *ociverify.Verifier) (string, error) {
 	var requiresLogin = auth.Username != "" && auth.Password != ""
 
 	url, err := url.Parse(name)
@@ -393,6 +443,15 @@ func downloadOCIChart(name, version, path string, auth Auth) (string, error) {
 		}
 	}
 
+	if verifier != nil {
+		name, err = verifyOCIChart(ctx, registryClient, tmpGetter.Client, name, version, auth, verifier)
+		if err != nil {
+			return "", err
+		}
+		// the verified chart is pulled by its digest
+		version = ""
+	}
+
 	getterOptions := []helmgetter.Option{}
 	if auth.Username != "" && auth.Password != "" {
 		getterOptions = append(getterOptions, helmgetter.WithBasicAuth(auth.Username, auth.Password))
@@ -430,6 +489,47 @@ func downloadOCIChart(name, version, path string, auth Auth) (string, error) {
 	return saved, nil
 }
 
+// verifyOCIChart resolves the chart version to the digest of its manifest
+// and verifies the signature of the manifest. It returns a reference to the
+// chart, which is pinned to the verified digest.
+func verifyOCIChart(ctx context.Context, registryClient *registry.Client, httpClient *http.Client, name, version string, auth Auth, verifier *ociverify.Verifier) (string, error) {
+	u, err := url.Parse(name)
+	if err != nil {
+		return "", err
+	}
+	_, u, err = registryClient.ValidateReference(name, version, u)
+	if err != nil {
+		return "", err
+	}
+
+	repo, err := remote.NewRepository(strings.TrimPrefix(u.String(), ociURLPrefix))
+	if err != nil {
+		return "", err
+	}
+	repo.PlainHTTP = auth.BasicHTTP
+	client := &orasauth.Client{
+		Client: httpClient,
+		Cache:  orasauth.NewCache(),
+	}
+	if auth.Username != "" && auth.Password != "" {
+		client.Credential = orasauth.StaticCredential(repo.Reference.Registry, orasauth.Credential{
+			Username: auth.Username,
+			Password: auth.Password,
+		})
+	}
+	repo.Client = client
+
+	desc, err := repo.Resolve(ctx, repo.Reference.Reference)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", repo.Reference, err)
+	}
+	if err := verifier.Verify(ctx, repo, desc); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s%s/%s@%s", ociURLPrefix, repo.Reference.Registry, repo.Reference.Repository, desc.Digest), nil
+}
+
 func newHttpGetter(auth Auth) *getter.HttpGetter {
 	httpGetter := &getter.HttpGetter{
 		Client: &http.Client{},
@@ -0,0 +1,61 @@
+package bundlereader
+
+import (
+	"fmt"
+	"path/filepath"
+
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+)
+
+// checkInRoot returns an error, if path does not exist or if it is not below
+// root after resolving symlinks.
+func checkInRoot(root, path string) error {
+	resolvedRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return err
+	}
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return err
+	}
+	rel, err := filepath.Rel(resolvedRoot, resolved)
+	if err != nil || !filepath.IsLocal(rel) {
+		return fmt.Errorf("%s is outside of %s", path, root)
+	}
+	return nil
+}
+
+// checkLocalSources returns an error, if the charts or values files of the
+// bundle are not local files below root. Remote charts are rejected, as
+// their download would run with the network access of the caller.
+func checkLocalSources(spec *fleet.BundleSpec, base, root string) error {
+	check := func(helm *fleet.HelmOptions) error {
+		if helm == nil {
+			return nil
+		}
+		if helm.Chart != "" {
+			if helm.Repo != "" {
+				return fmt.Errorf("chart %s: charts from helm repositories are not supported", helm.Chart)
+			}
+			if err := checkInRoot(root, filepath.Join(base, helm.Chart)); err != nil {
+				return fmt.Errorf("chart %s must be a local path: %w", helm.Chart, err)
+			}
+		}
+		for _, vf := range helm.ValuesFiles {
+			if err := checkInRoot(root, filepath.Join(base, vf)); err != nil {
+				return fmt.Errorf("values file %s: %w", vf, err)
+			}
+		}
+		return nil
+	}
+
+	if err := check(spec.Helm); err != nil {
+		return err
+	}
+	for _, target := range spec.Targets {
+		if err := check(target.Helm); err != nil {
+			return err
+		}
+	}
+	return nil
+}
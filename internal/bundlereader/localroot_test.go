@@ -0,0 +1,115 @@
+package bundlereader
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewBundleLocalRoot(t *testing.T) {
+	const cm = "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\n"
+
+	outside := t.TempDir()
+	secret := filepath.Join(outside, "token")
+	if err := os.WriteFile(secret, []byte("secret"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	// escape is a relative path from any directory to outside
+	escape := strings.Repeat("../", 32) + strings.TrimPrefix(outside, "/")
+
+	tests := []struct {
+		name    string
+		files   map[string]string
+		link    string
+		wantErr string
+	}{
+		{
+			name:  "local resources",
+			files: map[string]string{"app/cm.yaml": cm},
+		},
+		{
+			name: "local chart below the root",
+			files: map[string]string{
+				"app/fleet.yaml":           "helm:\n  chart: ../chart\n",
+				"chart/Chart.yaml":         "apiVersion: v2\nname: chart\nversion: 0.1.0\n",
+				"chart/templates/cm.yaml":  cm,
+				"app/values/values-a.yaml": "a: 1\n",
+			},
+		},
+		{
+			name:    "absolute chart path",
+			files:   map[string]string{"app/fleet.yaml": "helm:\n  chart: " + outside + "\n"},
+			wantErr: "must be a local path",
+		},
+		{
+			name:    "chart outside of the root",
+			files:   map[string]string{"app/fleet.yaml": "helm:\n  chart: " + escape + "\n"},
+			wantErr: "must be a local path",
+		},
+		{
+			name:    "chart from a helm repository",
+			files:   map[string]string{"app/fleet.yaml": "helm:\n  repo: https://charts.example.com\n  chart: app\n"},
+			wantErr: "charts from helm repositories are not supported",
+		},
+		{
+			name:    "OCI chart",
+			files:   map[string]string{"app/fleet.yaml": "helm:\n  chart: oci://ghcr.io/example/app\n"},
+			wantErr: "must be a local path",
+		},
+		{
+			name: "values file outside of the root",
+			files: map[string]string{
+				"app/fleet.yaml":       "helm:\n  chart: ./chart\n  valuesFiles:\n  - " + escape + "/token\n",
+				"app/chart/Chart.yaml": "apiVersion: v2\nname: chart\nversion: 0.1.0\n",
+			},
+			wantErr: "is outside of",
+		},
+		{
+			name:    "symlink to a file outside of the root",
+			files:   map[string]string{"app/cm.yaml": cm},
+			link:    "app/token",
+			wantErr: "is outside of",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			for name, data := range tt.files {
+				path := filepath.Join(root, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.link != "" {
+				if err := os.Symlink(secret, filepath.Join(root, tt.link)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			_, _, err := NewBundle(context.TODO(), "test", filepath.Join(root, "app"), "", &Options{LocalRoot: root})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	t.Run("bundle directory outside of the root", func(t *testing.T) {
+		root := t.TempDir()
+		_, _, err := NewBundle(context.TODO(), "test", outside, "", &Options{LocalRoot: root})
+		if err == nil || !strings.Contains(err.Error(), "invalid bundle directory") {
+			t.Errorf("expected an invalid bundle directory, got %v", err)
+		}
+	})
+}
@@ -19,6 +19,7 @@ import (
 
 	"github.com/sirupsen/logrus"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/yaml"
 )
 
@@ -37,6 +38,16 @@ type Options struct {
 	KeepResources    bool
 	DeleteNamespace  bool
 	CorrectDrift     *fleet.CorrectDrift
+	// SecretReader reads the secrets referenced in fleet.yaml, like the
+	// keys to verify the signatures of OCI charts, from SecretNamespace.
+	SecretReader    client.Reader
+	SecretNamespace string
+	// LocalRoot restricts the bundle to local files below it, if set. The
+	// base dir, fleet.yaml, charts and values files must be below LocalRoot,
+	// symlinks must not point outside of it and charts are neither
+	// downloaded nor updated from helm repositories. Used to create bundles
+	// from untrusted content inside the controller.
+	LocalRoot string
 }
 
 // NewBundle reads the fleet.yaml, from stdin, or basedir, or a file in basedir.
@@ -47,6 +58,20 @@ func NewBundle(ctx context.Context, name, baseDir, file string, opts *Options) (
 		baseDir = "."
 	}
 
+	if opts != nil && opts.LocalRoot != "" {
+		if err := checkInRoot(opts.LocalRoot, baseDir); err != nil {
+			return nil, nil, fmt.Errorf("invalid bundle directory: %w", err)
+		}
+		if file != "" {
+			if file == "-" {
+				return nil, nil, errors.New("reading the bundle from STDIN is not supported")
+			}
+			if err := checkInRoot(opts.LocalRoot, filepath.Join(baseDir, file)); err != nil {
+				return nil, nil, fmt.Errorf("invalid bundle file: %w", err)
+			}
+		}
+	}
+
 	if file == "-" {
 		b, s, err := loadBundle(ctx, name, baseDir, os.Stdin, opts)
 		if err != nil {
@@ -190,7 +215,7 @@ func bundleFromDir(ctx context.Context, name, baseDir string, bundleData []byte,
 
 	propagateHelmChartProperties(&fy.BundleSpec)
 
-	resources, err := readResources(ctx, &fy.BundleSpec, opts.Compress, baseDir, opts.Auth, opts.HelmRepoURLRegex, opts.BundleFile)
+	resources, err := readResources(ctx, &fy.BundleSpec, opts, baseDir)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed reading resources for %q: %w", baseDir, err)
 	}
@@ -234,6 +259,7 @@ func bundleFromDir(ctx context.Context, name, baseDir string, bundleData []byte,
 				ClusterSelector:      target.ClusterSelector,
 				ClusterGroup:         target.ClusterGroup,
 				ClusterGroupSelector: target.ClusterGroupSelector,
+				ClusterFactSelector:  target.ClusterFactSelector,
 			})
 			bundle.Spec.TargetRestrictions = append(bundle.Spec.TargetRestrictions, fleet.BundleTargetRestriction(target))
 		}
@@ -274,6 +300,12 @@ func bundleFromDir(ctx context.Context, name, baseDir string, bundleData []byte,
 
 	if opts.CorrectDrift != nil && opts.CorrectDrift.Enabled {
 		bundle.Spec.CorrectDrift = opts.CorrectDrift
+	} else if opts.CorrectDrift != nil && opts.CorrectDrift.Report {
+		// keep the drift correction settings from fleet.yaml
+		if bundle.Spec.CorrectDrift == nil {
+			bundle.Spec.CorrectDrift = &fleet.CorrectDrift{}
+		}
+		bundle.Spec.CorrectDrift.Report = true
 	}
 
 	return bundle, scans, nil
@@ -300,6 +332,9 @@ func propagateHelmChartProperties(spec *fleet.BundleSpec) {
 		if target.Helm.Version == "" {
 			target.Helm.Version = spec.Helm.Version
 		}
+		if target.Helm.Verification == nil {
+			target.Helm.Verification = spec.Helm.Verification
+		}
 	}
 }
 
@@ -4,18 +4,25 @@ import (
 	"context"
 	"crypto/sha256"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"sort"
 	"sync"
 
+	"github.com/rancher/fleet/internal/ociverify"
+	"github.com/rancher/fleet/internal/sops"
 	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
 	"golang.org/x/sync/errgroup"
 	"golang.org/x/sync/semaphore"
 
 	"github.com/rancher/wrangler/v3/pkg/data"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/yaml"
 )
 
@@ -23,12 +30,18 @@ const ociURLPrefix = "oci://"
 
 // readResources reads and downloads all resources from the bundle. Resources
 // can be downloaded and are spread across multiple directories.
-func readResources(ctx context.Context, spec *fleet.BundleSpec, compress bool, base string, auth Auth, helmRepoURLRegex, bundleFile string) ([]fleet.BundleResource, error) {
+func readResources(ctx context.Context, spec *fleet.BundleSpec, opts *Options, base string) ([]fleet.BundleResource, error) {
 	directories, err := addDirectory(base, ".", ".")
 	if err != nil {
 		return nil, err
 	}
 
+	if opts.LocalRoot != "" {
+		if err := checkLocalSources(spec, base, opts.LocalRoot); err != nil {
+			return nil, err
+		}
+	}
+
 	var chartDirs []*fleet.HelmOptions
 
 	if spec.Helm != nil && spec.Helm.Chart != "" {
@@ -50,21 +63,23 @@ func readResources(ctx context.Context, spec *fleet.BundleSpec, compress bool, b
 		}
 	}
 
-	directories, err = addRemoteCharts(ctx, directories, base, chartDirs, auth, helmRepoURLRegex)
+	directories, err = addRemoteCharts(ctx, directories, base, chartDirs, opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to add directory for chart: %w", err)
 	}
 
-	// helm chart dependency update is enabled by default
-	disableDepsUpdate := false
+	// helm chart dependency update is enabled by default, it downloads
+	// the dependencies and is not supported for local only bundles
+	disableDepsUpdate := opts.LocalRoot != ""
 	if spec.Helm != nil {
-		disableDepsUpdate = spec.Helm.DisableDependencyUpdate
+		disableDepsUpdate = disableDepsUpdate || spec.Helm.DisableDependencyUpdate
 	}
 
 	loadOpts := loadOpts{
-		compress:           compress,
+		compress:           opts.Compress,
 		disableDepsUpdate:  disableDepsUpdate,
-		ignoreApplyConfigs: ignoreApplyConfigs(bundleFile, spec.Helm, spec.Targets...),
+		ignoreApplyConfigs: ignoreApplyConfigs(opts.BundleFile, spec.Helm, spec.Targets...),
+		root:               opts.LocalRoot,
 	}
 	resources, err := loadDirectories(ctx, loadOpts, directories...)
 	if err != nil {
@@ -88,6 +103,8 @@ type loadOpts struct {
 	compress           bool
 	disableDepsUpdate  bool
 	ignoreApplyConfigs []string
+	// root restricts the loaded files to files below root, if set
+	root string
 }
 
 // ignoreApplyConfigs returns a list of config files that should not be added to the
@@ -140,6 +157,8 @@ type directory struct {
 	version string
 	// auth is the auth to use for the chart URL
 	auth Auth
+	// verifier verifies the signature of an OCI chart, if set
+	verifier *ociverify.Verifier
 }
 
 func addDirectory(base, customDir, defaultDir string) ([]directory, error) {
@@ -185,6 +204,9 @@ func generateValues(base string, chart *fleet.HelmOptions) (valuesMap *fleet.Gen
 		if err != nil {
 			return nil, fmt.Errorf("reading values file: %s/%s: %w", base, value, err)
 		}
+		if sops.IsEncrypted(valuesByte) {
+			return nil, fmt.Errorf("reading values file: %s/%s: sops encrypted values files are not supported, use an encrypted secret with valuesFrom instead", base, value)
+		}
 		tmpDataOpt := &fleet.GenericMap{}
 		err = yaml.Unmarshal(valuesByte, tmpDataOpt)
 		if err != nil {
@@ -205,14 +227,14 @@ func mergeGenericMap(first, second *fleet.GenericMap) *fleet.GenericMap {
 // addRemoteCharts gets the chart url from a helm repo server and returns a `directory` struct.
 // For every chart that is not on disk, create a directory struct that contains the charts URL as path.
 // This adds one directory per HelmOption.
-func addRemoteCharts(ctx context.Context, directories []directory, base string, charts []*fleet.HelmOptions, auth Auth, helmRepoURLRegex string) ([]directory, error) {
+func addRemoteCharts(ctx context.Context, directories []directory, base string, charts []*fleet.HelmOptions, opts *Options) ([]directory, error) {
 	for _, chart := range charts {
 		if _, err := os.Stat(filepath.Join(base, chart.Chart)); os.IsNotExist(err) || chart.Repo != "" {
-			shouldAddAuthToRequest, err := shouldAddAuthToRequest(helmRepoURLRegex, chart.Repo, chart.Chart)
+			shouldAddAuthToRequest, err := shouldAddAuthToRequest(opts.HelmRepoURLRegex, chart.Repo, chart.Chart)
 			if err != nil {
 				return nil, fmt.Errorf("failed to add auth to request for %s: %w", downloadChartError(*chart), err)
 			}
-			auth := auth // loop-scoped variable
+			auth := opts.Auth // loop-scoped variable
 			if !shouldAddAuthToRequest {
 				auth = Auth{}
 			}
@@ -222,18 +244,54 @@ func addRemoteCharts(ctx context.Context, directories []directory, base string,
 				return nil, fmt.Errorf("failed to resolve URL of %s: %w", downloadChartError(*chart), err)
 			}
 
+			verifier, err := newVerifier(ctx, opts.SecretReader, opts.SecretNamespace, chart.Verification)
+			if err != nil {
+				return nil, fmt.Errorf("failed to verify %s: %w", downloadChartError(*chart), err)
+			}
+
 			directories = append(directories, directory{
-				prefix:  checksum(chart),
-				base:    base,
-				source:  chartURL,
-				auth:    auth,
-				version: chart.Version,
+				prefix:   checksum(chart),
+				base:     base,
+				source:   chartURL,
+				auth:     auth,
+				version:  chart.Version,
+				verifier: verifier,
 			})
 		}
 	}
 	return directories, nil
 }
 
+// newVerifier returns a verifier for the signatures of OCI charts, which
+// trusts the keys in the secret referenced by the verification options. It
+// returns nil, if verification is not enabled.
+func newVerifier(ctx context.Context, c client.Reader, namespace string, verification *fleet.OCIVerification) (*ociverify.Verifier, error) {
+	if verification == nil {
+		return nil, nil
+	}
+	if c == nil {
+		return nil, fmt.Errorf("cannot read verification secret %q", verification.SecretName)
+	}
+
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: verification.SecretName}, secret); err != nil {
+		return nil, fmt.Errorf("failed to read verification secret %s/%s: %w", namespace, verification.SecretName, err)
+	}
+
+	keys := make([][]byte, 0, len(secret.Data))
+	for _, k := range slices.Sorted(maps.Keys(secret.Data)) {
+		keys = append(keys, secret.Data[k])
+	}
+	verifier, err := ociverify.New(verification.Provider, keys...)
+	if err != nil {
+		return nil, fmt.Errorf("verification secret %s/%s: %w", namespace, verification.SecretName, err)
+	}
+	if err := verifier.TrustIdentities(verification.TrustedIdentities...); err != nil {
+		return nil, err
+	}
+	return verifier, nil
+}
+
 func downloadChartError(c fleet.HelmOptions) string {
 	return fmt.Sprintf(
 		"repo=%s chart=%s version=%s",
@@ -95,3 +95,41 @@ func TestValueMerge(t *testing.T) {
 		}
 	}
 }
+
+func TestCheckEncrypted(t *testing.T) {
+	const (
+		age = `stringData:
+  password: ENC[AES256_GCM,data:DR8DG9J9,iv:9sQdwONIQ1J08M3sRUj9+wGGzs4o8lRbNxEyVV2hspI=,tag:qCM1jj+pLPcmvnapw9igSg==,type:str]
+sops:
+  age:
+    - recipient: age1fyejjxc9nhysxzvanel3grgms0xhd2h57ugmqj2h7hjnjy35z5jq8g3ngf
+      enc: encrypted
+  lastmodified: "2026-10-17T02:14:54Z"
+  mac: ENC[AES256_GCM,data:D3r4,iv:06KU,tag:3ay7,type:str]
+  version: 3.9.4
+`
+		kms = `stringData:
+  password: ENC[AES256_GCM,data:DR8DG9J9,iv:9sQdwONIQ1J08M3sRUj9+wGGzs4o8lRbNxEyVV2hspI=,tag:qCM1jj+pLPcmvnapw9igSg==,type:str]
+sops:
+  kms:
+    - arn: arn:aws:kms:us-east-1:123456789012:key/1234
+      enc: encrypted
+  lastmodified: "2026-10-17T02:14:54Z"
+  mac: ENC[AES256_GCM,data:D3r4,iv:06KU,tag:3ay7,type:str]
+  version: 3.9.4
+`
+	)
+
+	if err := checkEncrypted("secret.yaml", []byte(age)); err != nil {
+		t.Errorf("expected age encrypted file to be accepted, got %v", err)
+	}
+	if err := checkEncrypted("secret.yaml", []byte(kms)); err == nil {
+		t.Errorf("expected error for file without age or pgp recipients")
+	}
+	if err := checkEncrypted("secret.json", []byte(kms)); err != nil {
+		t.Errorf("expected non yaml file to be ignored, got %v", err)
+	}
+	if err := checkEncrypted("values.yaml", []byte(valuesOneYaml)); err != nil {
+		t.Errorf("expected unencrypted file to be accepted, got %v", err)
+	}
+}
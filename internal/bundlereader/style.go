@@ -51,8 +51,18 @@ func (s Style) IsKustomize() bool {
 	return s.KustomizePath != ""
 }
 
+// IsJsonnet returns true if the manifests are generated by a Jsonnet file.
+func (s Style) IsJsonnet() bool {
+	return s.Options.Jsonnet != nil
+}
+
+// IsCUE returns true if the manifests are generated by a CUE package.
+func (s Style) IsCUE() bool {
+	return s.Options.CUE != nil
+}
+
 func (s Style) IsRawYAML() bool {
-	return !s.IsHelm() && !s.IsKustomize()
+	return !s.IsHelm() && !s.IsKustomize() && !s.IsJsonnet() && !s.IsCUE()
 }
 
 func matchesExternalChartYAML(externalChartPath string, path string) bool {
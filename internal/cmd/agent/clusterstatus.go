@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -16,6 +17,7 @@ import (
 
 type ClusterStatusRunnable struct {
 	config          *rest.Config
+	localConfig     *rest.Config
 	namespace       string
 	checkinInterval string
 	agentInfo       *register.AgentInfo
@@ -36,6 +38,20 @@ func (cs *ClusterStatusRunnable) Start(ctx context.Context) error {
 
 	setupLog.Info("Starting cluster status ticker", "checkin interval", checkinInterval.String(), "cluster namespace", cs.agentInfo.ClusterNamespace, "cluster name", cs.agentInfo.ClusterName)
 
+	// the facts are collected from the downstream cluster
+	localClient, err := client.New(cs.localConfig, client.Options{Scheme: localScheme})
+	if err != nil {
+		return err
+	}
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(cs.localConfig)
+	if err != nil {
+		return err
+	}
+	facts := &clusterstatus.FactCollector{
+		Client:    localClient,
+		Discovery: discoveryClient,
+	}
+
 	// use a separate client for the cluster status ticker, that does not use a cache
 	client, err := client.New(cs.config, client.Options{Scheme: scheme})
 	if err != nil {
@@ -46,6 +62,7 @@ func (cs *ClusterStatusRunnable) Start(ctx context.Context) error {
 		clusterstatus.Ticker(
 			ctx,
 			client,
+			facts,
 			cs.namespace,
 			cs.agentInfo.ClusterNamespace,
 			cs.agentInfo.ClusterName,
@@ -0,0 +1,122 @@
+package clusterstatus
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/rancher/fleet/internal/helmdeployer"
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/discovery"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// defaultStorageClassAnnotation marks the default storage class.
+const defaultStorageClassAnnotation = "storageclass.kubernetes.io/is-default-class"
+
+// Discovery is the part of the discovery client, which is needed to
+// collect the server version and the served API versions.
+type Discovery interface {
+	discovery.ServerVersionInterface
+	discovery.ServerResourcesInterface
+}
+
+// FactCollector collects facts about the downstream cluster.
+type FactCollector struct {
+	// Client for the downstream cluster, its scheme must contain the
+	// core, storage and apiextensions types.
+	Client    client.Reader
+	Discovery Discovery
+}
+
+// Collect returns the current facts of the downstream cluster.
+func (f *FactCollector) Collect(ctx context.Context) (*fleet.ClusterFacts, error) {
+	facts := &fleet.ClusterFacts{}
+
+	version, err := f.Discovery.ServerVersion()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get server version: %w", err)
+	}
+	facts.KubernetesVersion = version.GitVersion
+
+	// use the same version set as helm does when installing on the
+	// cluster, so charts render the same offline
+	apiVersions, err := helmdeployer.GetVersionSet(f.Discovery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get api versions: %w", err)
+	}
+	facts.APIVersions = append([]string{}, apiVersions...)
+	sort.Strings(facts.APIVersions)
+
+	nodes := &corev1.NodeList{}
+	if err := f.Client.List(ctx, nodes); err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	addNodeFacts(facts, nodes.Items)
+
+	crds := &apiextensionsv1.CustomResourceDefinitionList{}
+	if err := f.Client.List(ctx, crds); err != nil {
+		return nil, fmt.Errorf("failed to list custom resource definitions: %w", err)
+	}
+	groups := sets.New[string]()
+	for _, crd := range crds.Items {
+		groups.Insert(crd.Spec.Group)
+	}
+	facts.CRDGroups = sets.List(groups)
+
+	storageClasses := &storagev1.StorageClassList{}
+	if err := f.Client.List(ctx, storageClasses); err != nil {
+		return nil, fmt.Errorf("failed to list storage classes: %w", err)
+	}
+	for _, sc := range storageClasses.Items {
+		facts.StorageClasses = append(facts.StorageClasses, sc.Name)
+		if sc.Annotations[defaultStorageClassAnnotation] == "true" {
+			facts.DefaultStorageClass = sc.Name
+		}
+	}
+	sort.Strings(facts.StorageClasses)
+
+	return facts, nil
+}
+
+func addNodeFacts(facts *fleet.ClusterFacts, nodes []corev1.Node) {
+	var (
+		architectures    = sets.New[string]()
+		operatingSystems = sets.New[string]()
+		providers        = map[string]int{}
+		cpu              = resource.Quantity{}
+		memory           = resource.Quantity{}
+	)
+	for _, node := range nodes {
+		if arch := node.Status.NodeInfo.Architecture; arch != "" {
+			architectures.Insert(arch)
+		}
+		if os := node.Status.NodeInfo.OperatingSystem; os != "" {
+			operatingSystems.Insert(os)
+		}
+		if provider, _, ok := strings.Cut(node.Spec.ProviderID, "://"); ok && provider != "" {
+			providers[provider]++
+		}
+		cpu.Add(*node.Status.Allocatable.Cpu())
+		memory.Add(*node.Status.Allocatable.Memory())
+	}
+
+	facts.NodeCount = len(nodes)
+	facts.Architectures = sets.List(architectures)
+	facts.OperatingSystems = sets.List(operatingSystems)
+	facts.AllocatableCPU = cpu
+	facts.AllocatableMemory = memory
+	for provider, count := range providers {
+		if count > providers[facts.CloudProvider] ||
+			(count == providers[facts.CloudProvider] && provider < facts.CloudProvider) {
+			facts.CloudProvider = provider
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package clusterstatus
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	clienttesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func node(name, arch, providerID, cpu, memory string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       corev1.NodeSpec{ProviderID: providerID},
+		Status: corev1.NodeStatus{
+			NodeInfo: corev1.NodeSystemInfo{Architecture: arch, OperatingSystem: "linux"},
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(cpu),
+				corev1.ResourceMemory: resource.MustParse(memory),
+			},
+		},
+	}
+}
+
+var _ = Describe("ClusterStatus facts", func() {
+	var (
+		ctx       context.Context
+		collector *FactCollector
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		localScheme := runtime.NewScheme()
+		utilruntime.Must(clientgoscheme.AddToScheme(localScheme))
+		utilruntime.Must(apiextensionsv1.AddToScheme(localScheme))
+
+		local := fake.NewClientBuilder().WithScheme(localScheme).WithObjects(
+			node("a", "amd64", "aws:///eu-west-1a/i-1", "2", "4Gi"),
+			node("b", "arm64", "aws:///eu-west-1b/i-2", "1500m", "2Gi"),
+			node("c", "arm64", "", "500m", "2Gi"),
+			&apiextensionsv1.CustomResourceDefinition{
+				ObjectMeta: metav1.ObjectMeta{Name: "certificates.cert-manager.io"},
+				Spec:       apiextensionsv1.CustomResourceDefinitionSpec{Group: "cert-manager.io"},
+			},
+			&apiextensionsv1.CustomResourceDefinition{
+				ObjectMeta: metav1.ObjectMeta{Name: "issuers.cert-manager.io"},
+				Spec:       apiextensionsv1.CustomResourceDefinitionSpec{Group: "cert-manager.io"},
+			},
+			&storagev1.StorageClass{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "local-path",
+					Annotations: map[string]string{defaultStorageClassAnnotation: "true"},
+				},
+			},
+			&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "gp3"}},
+		).Build()
+
+		discovery := &fakediscovery.FakeDiscovery{
+			Fake: &clienttesting.Fake{Resources: []*metav1.APIResourceList{
+				{GroupVersion: "v1", APIResources: []metav1.APIResource{{Name: "configmaps", Kind: "ConfigMap"}}},
+				{GroupVersion: "cert-manager.io/v1", APIResources: []metav1.APIResource{{Name: "certificates", Kind: "Certificate"}}},
+			}},
+			FakedServerVersion: &version.Info{GitVersion: "v1.30.2+k3s1"},
+		}
+		collector = &FactCollector{Client: local, Discovery: discovery}
+	})
+
+	It("collects the facts of the downstream cluster", func() {
+		facts, err := collector.Collect(ctx)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(facts.KubernetesVersion).To(Equal("v1.30.2+k3s1"))
+		Expect(facts.APIVersions).To(Equal([]string{
+			"cert-manager.io/v1", "cert-manager.io/v1/Certificate", "v1", "v1/ConfigMap",
+		}))
+		Expect(facts.NodeCount).To(Equal(3))
+		Expect(facts.Architectures).To(Equal([]string{"amd64", "arm64"}))
+		Expect(facts.OperatingSystems).To(Equal([]string{"linux"}))
+		Expect(facts.AllocatableCPU.String()).To(Equal("4"))
+		Expect(facts.AllocatableMemory.String()).To(Equal("8Gi"))
+		Expect(facts.CloudProvider).To(Equal("aws"))
+		Expect(facts.CRDGroups).To(Equal([]string{"cert-manager.io"}))
+		Expect(facts.StorageClasses).To(Equal([]string{"gp3", "local-path"}))
+		Expect(facts.DefaultStorageClass).To(Equal("local-path"))
+	})
+
+	It("reports facts only when they changed", func() {
+		scheme := runtime.NewScheme()
+		utilruntime.Must(fleet.AddToScheme(scheme))
+
+		var patches [][]patchOp
+		cluster := &fleet.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster-name", Namespace: "cluster-namespace"}}
+		upstream := fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(cluster).
+			WithStatusSubresource(cluster).
+			WithInterceptorFuncs(interceptor.Funcs{
+				SubResourcePatch: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+					data, err := patch.Data(obj)
+					if err != nil {
+						return err
+					}
+					var ops []patchOp
+					if err := json.Unmarshal(data, &ops); err != nil {
+						return err
+					}
+					patches = append(patches, ops)
+					return c.SubResource(subResourceName).Patch(ctx, obj, patch, opts...)
+				},
+			}).
+			Build()
+
+		h := handler{
+			agentNamespace:   "cattle-fleet-system",
+			clusterName:      "cluster-name",
+			clusterNamespace: "cluster-namespace",
+			client:           upstream,
+			facts:            collector,
+		}
+		Expect(h.Update(ctx)).To(Succeed())
+		Expect(patches).To(HaveLen(1))
+		Expect(patches[0]).To(HaveLen(2))
+		Expect(patches[0][1].Path).To(Equal("/status/facts"))
+
+		updated := &fleet.Cluster{}
+		Expect(upstream.Get(ctx, client.ObjectKeyFromObject(cluster), updated)).To(Succeed())
+		Expect(updated.Status.Facts).ToNot(BeNil())
+		Expect(updated.Status.Facts.CRDGroups).To(Equal([]string{"cert-manager.io"}))
+
+		// force a new agent status, the facts did not change
+		h.reported = fleet.AgentStatus{}
+		Expect(h.Update(ctx)).To(Succeed())
+		Expect(patches).To(HaveLen(2))
+		Expect(patches[1]).To(HaveLen(1))
+		Expect(patches[1][0].Path).To(Equal("/status/agent"))
+	})
+})
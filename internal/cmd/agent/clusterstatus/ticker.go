@@ -3,6 +3,7 @@ package clusterstatus
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 
 	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
@@ -22,10 +23,14 @@ type handler struct {
 	clusterName      string
 	clusterNamespace string
 	client           client.Client
+	facts            *FactCollector
 	reported         fleet.AgentStatus
+	reportedFacts    *fleet.ClusterFacts
 }
 
-func Ticker(ctx context.Context, client client.Client, agentNamespace string, clusterNamespace string, clusterName string, checkinInterval time.Duration) {
+// Ticker reports the cluster status periodically. If facts is not nil, the
+// facts of the downstream cluster are collected and reported, too.
+func Ticker(ctx context.Context, client client.Client, facts *FactCollector, agentNamespace string, clusterNamespace string, clusterName string, checkinInterval time.Duration) {
 	logger := log.FromContext(ctx).WithName("clusterstatus").WithValues("cluster", clusterName, "interval", checkinInterval)
 
 	h := handler{
@@ -33,6 +38,7 @@ func Ticker(ctx context.Context, client client.Client, agentNamespace string, cl
 		clusterName:      clusterName,
 		clusterNamespace: clusterNamespace,
 		client:           client,
+		facts:            facts,
 	}
 
 	go func() {
@@ -66,6 +72,17 @@ func (h *handler) Update(ctx context.Context) error {
 		return nil
 	}
 
+	var facts *fleet.ClusterFacts
+	if h.facts != nil {
+		var err error
+		facts, err = h.facts.Collect(ctx)
+		if err != nil {
+			// still report the agent status, facts are updated on
+			// the next check-in
+			log.FromContext(ctx).Error(err, "failed to collect cluster facts")
+		}
+	}
+
 	cluster := &fleet.Cluster{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      h.clusterName,
@@ -75,16 +92,30 @@ func (h *handler) Update(ctx context.Context) error {
 
 	// Create a patch with the updated status, we avoid Get as that would
 	// need additional RBAC
-	patch := `[{"op":"add","path":"/status/agent","value":{"lastSeen":"` +
-		agentStatus.LastSeen.Format(time.RFC3339) +
-		`","namespace":"` + agentStatus.Namespace +
-		`"}}]`
+	ops := []patchOp{{Op: "add", Path: "/status/agent", Value: agentStatus}}
+	reportFacts := facts != nil && !equality.Semantic.DeepEqual(h.reportedFacts, facts)
+	if reportFacts {
+		ops = append(ops, patchOp{Op: "add", Path: "/status/facts", Value: facts})
+	}
+	patch, err := json.Marshal(ops)
+	if err != nil {
+		return err
+	}
 
-	err := h.client.Status().Patch(ctx, cluster, client.RawPatch(types.JSONPatchType, []byte(patch)))
+	err = h.client.Status().Patch(ctx, cluster, client.RawPatch(types.JSONPatchType, patch))
 	if err != nil {
 		return err
 	}
 
 	h.reported = agentStatus
+	if reportFacts {
+		h.reportedFacts = facts
+	}
 	return nil
 }
+
+type patchOp struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
@@ -132,9 +132,11 @@ func (r *BundleDeploymentReconciler) Reconcile(ctx context.Context, req ctrl.Req
 
 	if bd.Spec.Paused {
 		logger.V(1).Info("Bundle paused, clearing drift detection")
-		err := r.DriftDetect.Clear(req.String())
+		if err := r.DriftDetect.Clear(req.String()); err != nil {
+			return ctrl.Result{}, err
+		}
 
-		return ctrl.Result{}, err
+		return ctrl.Result{}, r.updatePlan(ctx, orig, bd)
 	}
 	if bd.Spec.OffSchedule {
 		logger.V(1).Info("Bundle not in schedule, clearing drift detection")
@@ -143,22 +145,18 @@ func (r *BundleDeploymentReconciler) Reconcile(ctx context.Context, req ctrl.Req
 		return ctrl.Result{}, err
 	}
 
-	// load the bundledeployment options from the secret, if present
-	if bd.Spec.ValuesHash != "" {
-		secret := &corev1.Secret{}
-		if err := r.Reader.Get(ctx, client.ObjectKey{Namespace: bd.Namespace, Name: bd.Name}, secret); err != nil {
-			return ctrl.Result{}, err
-		}
-
-		h := helmvalues.HashOptions(secret.Data[helmvalues.ValuesKey], secret.Data[helmvalues.StagedValuesKey])
-		if h != bd.Spec.ValuesHash {
-			return ctrl.Result{}, fmt.Errorf("retrying, hash mismatch between secret and bundledeployment: actual %s != expected %s", h, bd.Spec.ValuesHash)
-		}
+	// the controller sets the deployment ID, once the rollout reaches the
+	// cluster and the dependencies are ready
+	if bd.Spec.DeploymentID == "" {
+		logger.V(1).Info("Bundle not released for deployment yet", "stagedDeploymentID", bd.Spec.StagedDeploymentID)
+		return ctrl.Result{}, nil
+	}
 
-		if err := helmvalues.SetOptions(bd, secret.Data); err != nil {
-			return ctrl.Result{}, err
-		}
+	if err := r.loadOptions(ctx, bd); err != nil {
+		return ctrl.Result{}, err
 	}
+	// the plan is only kept while the bundle deployment is paused
+	bd.Status.Plan = nil
 
 	forceDeploy, err := r.copyResourcesFromUpstream(ctx, bd, logger)
 	if err != nil {
@@ -379,6 +377,63 @@ func (r *BundleDeploymentReconciler) copyResourcesFromUpstream(
 	return requiresBDUpdate, nil
 }
 
+// loadOptions loads the bundledeployment options from the secret, if present.
+func (r *BundleDeploymentReconciler) loadOptions(ctx context.Context, bd *fleetv1.BundleDeployment) error {
+	if bd.Spec.ValuesHash == "" {
+		return nil
+	}
+
+	secret := &corev1.Secret{}
+	if err := r.Reader.Get(ctx, client.ObjectKey{Namespace: bd.Namespace, Name: bd.Name}, secret); err != nil {
+		return err
+	}
+
+	h := helmvalues.HashOptions(secret.Data[helmvalues.ValuesKey], secret.Data[helmvalues.StagedValuesKey])
+	if h != bd.Spec.ValuesHash {
+		return fmt.Errorf("retrying, hash mismatch between secret and bundledeployment: actual %s != expected %s", h, bd.Spec.ValuesHash)
+	}
+
+	return helmvalues.SetOptions(bd, secret.Data)
+}
+
+// updatePlan runs a dry run of the staged deployment of a paused
+// bundledeployment and stores the changes it would make in the status, so
+// they can be reviewed before unpausing the bundle. Errors of the dry run
+// are reported in the plan, instead of retrying.
+func (r *BundleDeploymentReconciler) updatePlan(ctx context.Context, orig *fleetv1.BundleDeployment, bd *fleetv1.BundleDeployment) error {
+	logger := log.FromContext(ctx)
+
+	bd.Status.Plan = nil
+	if bd.Spec.StagedDeploymentID != "" && bd.Spec.StagedDeploymentID != bd.Spec.DeploymentID {
+		if err := r.loadOptions(ctx, bd); err != nil {
+			return err
+		}
+
+		plan, err := r.plan(ctx, bd)
+		if err != nil {
+			logger.V(1).Info("Failed to plan staged deployment", "stagedDeploymentID", bd.Spec.StagedDeploymentID, "error", err)
+			plan = &fleetv1.BundleDeploymentPlan{
+				StagedDeploymentID: bd.Spec.StagedDeploymentID,
+				Error:              err.Error(),
+			}
+		}
+		bd.Status.Plan = plan
+	}
+
+	if err := r.updateStatus(ctx, orig, bd); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to update the plan in the bundledeployment status: %w", err)
+	}
+	return nil
+}
+
+func (r *BundleDeploymentReconciler) plan(ctx context.Context, bd *fleetv1.BundleDeployment) (*fleetv1.BundleDeploymentPlan, error) {
+	resources, err := r.Deployer.Template(ctx, bd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render staged deployment: %w", err)
+	}
+	return r.Monitor.Plan(ctx, bd, resources)
+}
+
 func (r *BundleDeploymentReconciler) updateStatus(ctx context.Context, orig *fleetv1.BundleDeployment, obj *fleetv1.BundleDeployment) error {
 	statusPatch := client.MergeFrom(orig)
 	if patchData, err := statusPatch.Data(obj); err == nil && string(patchData) == "{}" {
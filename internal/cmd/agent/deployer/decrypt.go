@@ -0,0 +1,79 @@
+package deployer
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/rancher/fleet/internal/content"
+	"github.com/rancher/fleet/internal/manifest"
+	"github.com/rancher/fleet/internal/sops"
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// SOPSKeysSecretName is the name of the secret in the agent's namespace,
+// which holds the private keys to decrypt SOPS encrypted resources. Keys
+// ending in ".agekey" contain age identities, keys ending in ".asc" contain
+// armored PGP private keys.
+// The secret only exists on the downstream cluster, so the upstream cluster
+// never holds the cleartext of encrypted resources.
+const SOPSKeysSecretName = "fleet-agent-sops-keys"
+
+// decrypt returns a copy of the manifest, in which SOPS encrypted resources
+// are replaced by their cleartext. The manifest is returned as is, if it
+// does not contain encrypted resources.
+func (d *Deployer) decrypt(ctx context.Context, m *manifest.Manifest) (*manifest.Manifest, error) {
+	var (
+		keys      *sops.Keys
+		resources []fleet.BundleResource
+	)
+	for i, r := range m.Resources {
+		ext := filepath.Ext(r.Name)
+		if ext != ".yaml" && ext != ".yml" {
+			continue
+		}
+		data, err := content.Decode(r.Content, r.Encoding)
+		if err != nil {
+			return nil, err
+		}
+		if !sops.IsEncrypted(data) {
+			continue
+		}
+
+		if keys == nil {
+			keys, err = d.sopsKeys(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decrypt %s: %w", r.Name, err)
+			}
+			resources = make([]fleet.BundleResource, len(m.Resources))
+			copy(resources, m.Resources)
+		}
+		plain, err := sops.Decrypt(data, keys)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt %s: %w", r.Name, err)
+		}
+		resources[i] = fleet.BundleResource{Name: r.Name, Content: string(plain)}
+	}
+	if resources == nil {
+		return m, nil
+	}
+
+	decrypted := *m
+	decrypted.Resources = resources
+	return &decrypted, nil
+}
+
+func (d *Deployer) sopsKeys(ctx context.Context) (*sops.Keys, error) {
+	secret := &corev1.Secret{}
+	err := d.client.Get(ctx, types.NamespacedName{Namespace: d.systemNamespace, Name: SOPSKeysSecretName}, secret)
+	if apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("secret %s/%s with the sops keys does not exist", d.systemNamespace, SOPSKeysSecretName)
+	} else if err != nil {
+		return nil, err
+	}
+	return sops.ParseKeys(secret.Data)
+}
@@ -0,0 +1,87 @@
+package deployer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rancher/fleet/internal/content"
+	"github.com/rancher/fleet/internal/manifest"
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func readSOPSTestdata(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("..", "..", "..", "sops", "testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestDecrypt(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	encrypted := readSOPSTestdata(t, "age.yaml")
+	compressed, err := content.Base64GZ(encrypted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := manifest.New([]fleet.BundleResource{
+		{Name: "configmap.yaml", Content: "apiVersion: v1\nkind: ConfigMap\n"},
+		{Name: "secret.yaml", Content: compressed, Encoding: "base64+gz"},
+	})
+
+	d := &Deployer{
+		client:          fake.NewClientBuilder().WithScheme(scheme).Build(),
+		systemNamespace: "cattle-fleet-system",
+	}
+	if _, err := d.decrypt(context.TODO(), m); err == nil || !strings.Contains(err.Error(), SOPSKeysSecretName) {
+		t.Fatalf("expected error for missing secret, got %v", err)
+	}
+
+	d.client = fake.NewClientBuilder().WithScheme(scheme).WithObjects(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: SOPSKeysSecretName, Namespace: "cattle-fleet-system"},
+		Data:       map[string][]byte{"keys.agekey": readSOPSTestdata(t, "age.agekey")},
+	}).Build()
+	decrypted, err := d.decrypt(context.TODO(), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if decrypted.Resources[0] != m.Resources[0] {
+		t.Errorf("expected unencrypted resource to be unchanged, got %+v", decrypted.Resources[0])
+	}
+	secret := decrypted.Resources[1]
+	if secret.Encoding != "" || !strings.Contains(secret.Content, "password: s3cr3t") || strings.Contains(secret.Content, "sops:") {
+		t.Errorf("expected decrypted secret, got %+v", secret)
+	}
+	if m.Resources[1].Content != compressed {
+		t.Errorf("expected the original manifest to be unchanged")
+	}
+}
+
+func TestDecryptUnencrypted(t *testing.T) {
+	m := manifest.New([]fleet.BundleResource{{Name: "configmap.yaml", Content: "apiVersion: v1\nkind: ConfigMap\n"}})
+
+	// no secret is read, if no resource is encrypted
+	d := &Deployer{}
+	decrypted, err := d.decrypt(context.TODO(), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decrypted != m {
+		t.Errorf("expected manifest to be returned as is")
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/rancher/fleet/internal/helmdeployer"
 	"github.com/rancher/fleet/internal/manifest"
 	"github.com/rancher/fleet/internal/ocistorage"
+	"github.com/rancher/fleet/internal/ociverify"
 	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
 
 	"github.com/rancher/wrangler/v3/pkg/condition"
@@ -19,29 +20,33 @@ import (
 
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
 type Deployer struct {
-	client         client.Client
-	upstreamClient client.Reader
-	lookup         Lookup
-	helm           *helmdeployer.Helm
+	client          client.Client
+	upstreamClient  client.Reader
+	lookup          Lookup
+	helm            *helmdeployer.Helm
+	systemNamespace string
 }
 
 type Lookup interface {
 	Get(ctx context.Context, client client.Reader, id string) (*manifest.Manifest, error)
 }
 
-func New(localClient client.Client, upstreamClient client.Reader, lookup Lookup, deployer *helmdeployer.Helm) *Deployer {
+// New returns a deployer. The systemNamespace is the namespace the agent is
+// running in, it contains the secret with the keys to decrypt SOPS encrypted
+// resources.
+func New(localClient client.Client, upstreamClient client.Reader, lookup Lookup, deployer *helmdeployer.Helm, systemNamespace string) *Deployer {
 	return &Deployer{
-		client:         localClient,
-		upstreamClient: upstreamClient,
-		lookup:         lookup,
-		helm:           deployer,
+		client:          localClient,
+		upstreamClient:  upstreamClient,
+		lookup:          lookup,
+		helm:            deployer,
+		systemNamespace: systemNamespace,
 	}
 }
 
@@ -65,11 +70,6 @@ func (d *Deployer) DeployBundle(
 	status := bd.Status
 	logger := log.FromContext(ctx).WithName("deploy-bundle").WithValues("deploymentID", bd.Spec.DeploymentID, "appliedDeploymentID", status.AppliedDeploymentID)
 
-	if err := d.checkDependency(ctx, bd); err != nil {
-		logger.V(1).Info("Bundle has a dependency that is not ready", "error", err)
-		return status, err
-	}
-
 	releaseID, err := d.helmdeploy(ctx, logger, bd, force)
 
 	if err != nil {
@@ -101,6 +101,9 @@ func (d *Deployer) DeployBundle(
 
 	// Setting the error to nil clears any existing error
 	condition.Cond(fleet.BundleDeploymentConditionInstalled).SetError(&status, "", nil)
+	if condition.Cond(fleet.BundleDeploymentConditionVerified).GetStatus(&status) != "" {
+		condition.Cond(fleet.BundleDeploymentConditionVerified).SetError(&status, "", nil)
+	}
 	return status, nil
 }
 
@@ -116,56 +119,84 @@ func (d *Deployer) helmdeploy(ctx context.Context, logger logr.Logger, bd *fleet
 			return bd.Status.Release, nil
 		}
 	}
-	manifestID, _ := kv.Split(bd.Spec.DeploymentID, ":")
-	var (
-		m   *manifest.Manifest
-		err error
-	)
+	m, err := d.manifest(ctx, bd, bd.Spec.DeploymentID)
+	if err != nil {
+		return "", err
+	}
+
+	// SOPS encrypted resources are only decrypted on the downstream
+	// cluster, right before they are deployed.
+	m, err = d.decrypt(ctx, m)
+	if err != nil {
+		return "", err
+	}
+
+	m.Commit = bd.Labels[fleet.CommitLabel]
+	release, err := d.helm.Deploy(ctx, bd.Name, m, bd.Spec.Options)
+	if err != nil {
+		return "", err
+	}
+
+	resourceID := helmdeployer.ReleaseToResourceID(release)
+
+	logger.Info("Deployed bundle", "release", resourceID, "DeploymentID", bd.Spec.DeploymentID)
+
+	return resourceID, nil
+}
+
+// Template renders the staged deployment of bd, without installing it. It
+// is used to plan the changes of a paused bundle deployment.
+func (d *Deployer) Template(ctx context.Context, bd *fleet.BundleDeployment) (*helmdeployer.Resources, error) {
+	// charts of HelmOps are downloaded according to the helm options, use
+	// the staged ones
+	staged := bd.DeepCopy()
+	staged.Spec.Options = staged.Spec.StagedOptions
+	m, err := d.manifest(ctx, staged, bd.Spec.StagedDeploymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err = d.decrypt(ctx, m)
+	if err != nil {
+		return nil, err
+	}
+
+	m.Commit = bd.Labels[fleet.CommitLabel]
+	return d.helm.Template(ctx, bd.Name, m, bd.Spec.StagedOptions)
+}
+
+// manifest loads the manifest of the given deployment ID, either from OCI
+// storage, a helm chart or the content resource in the upstream cluster.
+func (d *Deployer) manifest(ctx context.Context, bd *fleet.BundleDeployment, deploymentID string) (*manifest.Manifest, error) {
+	manifestID, _ := kv.Split(deploymentID, ":")
 	switch {
 	case bd.Spec.OCIContents:
 		oci := ocistorage.NewOCIWrapper()
 		secretID := client.ObjectKey{Name: manifestID, Namespace: bd.Namespace}
 		opts, err := ocistorage.ReadOptsFromSecret(ctx, d.upstreamClient, secretID)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
-		m, err = oci.PullManifest(ctx, opts, manifestID)
+		m, err := oci.PullManifest(ctx, opts, manifestID)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 		// Verify that the calculated manifestID for the manifest
 		// we just downloaded matches the expected one.
 		// Otherwise, the manifest will be considered incorrect or corrupted.
 		actualID, err := m.ID()
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 		if actualID != manifestID {
-			return "", fmt.Errorf("invalid or corrupt manifest. Expecting id: %q, got %q", manifestID, actualID)
+			return nil, fmt.Errorf("invalid or corrupt manifest. Expecting id: %q, got %q", manifestID, actualID)
 		}
+		return m, nil
 	case bd.Spec.HelmChartOptions != nil:
-		m, err = bundlereader.GetManifestFromHelmChart(ctx, d.upstreamClient, bd)
-		if err != nil {
-			return "", err
-		}
+		return bundlereader.GetManifestFromHelmChart(ctx, d.upstreamClient, bd)
 	default:
-		m, err = d.lookup.Get(ctx, d.upstreamClient, manifestID)
-		if err != nil {
-			return "", err
-		}
+		return d.lookup.Get(ctx, d.upstreamClient, manifestID)
 	}
-
-	m.Commit = bd.Labels[fleet.CommitLabel]
-	release, err := d.helm.Deploy(ctx, bd.Name, m, bd.Spec.Options)
-	if err != nil {
-		return "", err
-	}
-
-	resourceID := helmdeployer.ReleaseToResourceID(release)
-
-	logger.Info("Deployed bundle", "release", resourceID, "DeploymentID", bd.Spec.DeploymentID)
-
-	return resourceID, nil
 }
 
 // setNamespaceLabelsAndAnnotations updates the namespace for the release, applying all labels and annotations to that namespace as configured in the bundle spec.
@@ -259,6 +290,18 @@ func deployErrToStatus(err error, status fleet.BundleDeploymentStatus) (bool, fl
 
 	msg := err.Error()
 
+	// An OCI artifact, which is not signed by a trusted key, is not deployed.
+	// Retrying does not help, until the artifact or the trusted keys change.
+	var verr *ociverify.VerificationError
+	if errors.As(err, &verr) {
+		status.Ready = false
+		status.NonModified = true
+		condition.Cond(fleet.BundleDeploymentConditionReady).SetError(&status, "", fmt.Errorf("not ready: %s", msg))
+		condition.Cond(fleet.BundleDeploymentConditionInstalled).SetError(&status, "", fmt.Errorf("not installed: %s", msg))
+		condition.Cond(fleet.BundleDeploymentConditionVerified).SetError(&status, "VerificationFailed", verr)
+		return true, status
+	}
+
 	// The following error conditions are turned into a status
 	// Note: these error strings are returned by the Helm SDK and its dependencies
 	re := regexp.MustCompile(
@@ -307,53 +350,3 @@ func deployErrToStatus(err error, status fleet.BundleDeploymentStatus) (bool, fl
 
 	return false, status
 }
-
-func (d *Deployer) checkDependency(ctx context.Context, bd *fleet.BundleDeployment) error {
-	var depBundleList []string
-	bundleNamespace := bd.Labels[fleet.BundleNamespaceLabel]
-	for _, depend := range bd.Spec.DependsOn {
-		// skip empty BundleRef definitions. Possible if there is a typo in the yaml
-		if depend.Name != "" || depend.Selector != nil {
-			ls := &metav1.LabelSelector{}
-			if depend.Selector != nil {
-				ls = depend.Selector
-			}
-
-			// depend.Name is just a shortcut for matchLabels: {bundle-name: name}
-			if depend.Name != "" {
-				ls = metav1.AddLabelToSelector(ls, fleet.BundleLabel, depend.Name)
-				ls = metav1.AddLabelToSelector(ls, fleet.BundleNamespaceLabel, bundleNamespace)
-			}
-
-			selector, err := metav1.LabelSelectorAsSelector(ls)
-			if err != nil {
-				return err
-			}
-
-			bds := fleet.BundleDeploymentList{}
-			err = d.upstreamClient.List(ctx, &bds, client.MatchingLabelsSelector{Selector: selector}, client.InNamespace(bd.Namespace))
-			if err != nil {
-				return err
-			}
-
-			if len(bds.Items) == 0 {
-				return fmt.Errorf("list bundledeployments: no bundles matching labels %s in namespace %s", selector.String(), bundleNamespace)
-			}
-
-			for _, depBundle := range bds.Items {
-				c := condition.Cond("Ready")
-				if c.IsTrue(depBundle) {
-					continue
-				} else {
-					depBundleList = append(depBundleList, depBundle.Name)
-				}
-			}
-		}
-	}
-
-	if len(depBundleList) != 0 {
-		return fmt.Errorf("dependent bundle(s) are not ready: %v", depBundleList)
-	}
-
-	return nil
-}
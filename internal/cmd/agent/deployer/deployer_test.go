@@ -2,9 +2,13 @@ package deployer
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"testing"
 
+	"github.com/rancher/fleet/internal/ociverify"
 	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+	"github.com/rancher/wrangler/v3/pkg/condition"
 
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -172,3 +176,29 @@ func TestSetNamespaceLabelsAndAnnotationsError(t *testing.T) {
 		t.Errorf("expected not found error: got %v", err)
 	}
 }
+
+func TestDeployErrToStatusVerification(t *testing.T) {
+	err := fmt.Errorf("loading directory: %w", &ociverify.VerificationError{
+		Digest: "sha256:0123",
+		Err:    errors.New("no cosign signature found"),
+	})
+
+	ok, status := deployErrToStatus(err, fleet.BundleDeploymentStatus{Ready: true})
+	if !ok {
+		t.Fatal("expected verification error to be turned into a status")
+	}
+	if status.Ready || !status.NonModified {
+		t.Errorf("expected status to be not ready, got %+v", status)
+	}
+
+	verified := condition.Cond(fleet.BundleDeploymentConditionVerified)
+	if !verified.IsFalse(&status) || verified.GetReason(&status) != "VerificationFailed" {
+		t.Errorf("expected verified condition to be false, got %+v", status.Conditions)
+	}
+	if msg := verified.GetMessage(&status); msg != "artifact sha256:0123 failed signature verification: no cosign signature found" {
+		t.Errorf("unexpected message %q", msg)
+	}
+	if condition.Cond(fleet.BundleDeploymentConditionInstalled).IsTrue(&status) {
+		t.Errorf("expected installed condition not to be true")
+	}
+}
@@ -0,0 +1,131 @@
+package monitor
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/rancher/fleet/internal/cmd/agent/deployer/desiredset"
+	"github.com/rancher/fleet/internal/cmd/agent/deployer/objectset"
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+)
+
+const (
+	// limit the number of reported field changes per resource
+	fieldChangesMaxLength = 20
+	// limit the length of reported live and desired values
+	fieldValueMaxLength = 256
+
+	redactedValue = `"<redacted>"`
+)
+
+// shouldReportDrift returns true if the changed fields of modified
+// resources should be added to the status.
+func shouldReportDrift(bd *fleet.BundleDeployment) bool {
+	return bd.Spec.CorrectDrift != nil && bd.Spec.CorrectDrift.Report
+}
+
+// addFieldChanges adds the changed fields to the modified resources, which
+// have a patch. The live values are taken from the plan's objects.
+func addFieldChanges(modified []fleet.ModifiedStatus, plan desiredset.Plan) {
+	live := objectset.NewObjectSet(plan.Objects...).ObjectsByGVK()
+	for i, m := range modified {
+		if m.Patch == "" {
+			continue
+		}
+		gvk := schema.FromAPIVersionAndKind(m.APIVersion, m.Kind)
+		obj := live[gvk][objectset.ObjectKey{Namespace: m.Namespace, Name: m.Name}]
+		modified[i].Changes = fieldChanges(obj, m.Kind, m.Patch)
+	}
+}
+
+// fieldChanges returns the fields changed by a JSON merge patch, which would
+// restore the desired state of the live object.
+func fieldChanges(obj runtime.Object, kind string, patch string) []fleet.FieldChange {
+	var p map[string]interface{}
+	if err := json.Unmarshal([]byte(patch), &p); err != nil {
+		return nil
+	}
+
+	var live map[string]interface{}
+	if obj != nil {
+		if data, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj); err == nil {
+			live = data
+		}
+	}
+	var changes []fleet.FieldChange
+	walkPatch(p, live, "", &changes)
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	if len(changes) > fieldChangesMaxLength {
+		changes = changes[:fieldChangesMaxLength]
+	}
+
+	// secret data must not be copied into the status
+	if kind == "Secret" {
+		for i, c := range changes {
+			if strings.HasPrefix(c.Path, "/data/") || strings.HasPrefix(c.Path, "/stringData/") ||
+				c.Path == "/data" || c.Path == "/stringData" {
+				if c.Live != "" {
+					changes[i].Live = redactedValue
+				}
+				if c.Desired != "" {
+					changes[i].Desired = redactedValue
+				}
+			}
+		}
+	}
+	return changes
+}
+
+// walkPatch recurses into the objects of a merge patch and records its
+// leaves. Null values in a merge patch remove the field.
+func walkPatch(patch map[string]interface{}, live map[string]interface{}, path string, changes *[]fleet.FieldChange) {
+	for k, v := range patch {
+		// skip strategic merge patch directives
+		if strings.HasPrefix(k, "$") {
+			continue
+		}
+		p := path + "/" + escapeJSONPointer(k)
+
+		var liveValue interface{}
+		if live != nil {
+			liveValue = live[k]
+		}
+		if nested, ok := v.(map[string]interface{}); ok {
+			if liveMap, ok := liveValue.(map[string]interface{}); ok || liveValue == nil {
+				walkPatch(nested, liveMap, p, changes)
+				continue
+			}
+		}
+
+		c := fleet.FieldChange{Path: p}
+		if liveValue != nil {
+			c.Live = encodeFieldValue(liveValue)
+		}
+		if v != nil {
+			c.Desired = encodeFieldValue(v)
+		}
+		*changes = append(*changes, c)
+	}
+}
+
+// escapeJSONPointer escapes a key for use in a JSON pointer, see RFC 6901.
+func escapeJSONPointer(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "~", "~0"), "/", "~1")
+}
+
+func encodeFieldValue(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	if len(data) > fieldValueMaxLength {
+		return string(data[:fieldValueMaxLength]) + "..."
+	}
+	return string(data)
+}
@@ -0,0 +1,92 @@
+package monitor
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/rancher/fleet/internal/cmd/agent/deployer/desiredset"
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+)
+
+func TestFieldChanges(t *testing.T) {
+	deployment := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":        "app",
+			"namespace":   "default",
+			"annotations": map[string]interface{}{"example.com/owner": "ops"},
+		},
+		"spec": map[string]interface{}{
+			"replicas": int64(5),
+			"paused":   true,
+		},
+	}}
+
+	changes := fieldChanges(deployment, "Deployment",
+		`{"metadata":{"annotations":{"example.com/owner":null}},"spec":{"paused":null,"replicas":2,"template":{"metadata":{"labels":{"app":"web"}}}},"$setElementOrder/ports":[]}`)
+	assert.Equal(t, []fleet.FieldChange{
+		{Path: "/metadata/annotations/example.com~1owner", Live: `"ops"`},
+		{Path: "/spec/paused", Live: "true"},
+		{Path: "/spec/replicas", Live: "5", Desired: "2"},
+		{Path: "/spec/template/metadata/labels/app", Desired: `"web"`},
+	}, changes)
+}
+
+func TestFieldChangesSecret(t *testing.T) {
+	secret := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata":   map[string]interface{}{"name": "creds", "namespace": "default"},
+		"type":       "Opaque",
+		"data":       map[string]interface{}{"password": "bGl2ZQ=="},
+	}}
+
+	changes := fieldChanges(secret, "Secret", `{"data":{"password":"ZGVzaXJlZA==","token":"dG9rZW4="},"type":"kubernetes.io/basic-auth"}`)
+	assert.Equal(t, []fleet.FieldChange{
+		{Path: "/data/password", Live: redactedValue, Desired: redactedValue},
+		{Path: "/data/token", Desired: redactedValue},
+		{Path: "/type", Live: `"Opaque"`, Desired: `"kubernetes.io/basic-auth"`},
+	}, changes)
+}
+
+func TestFieldChangesBounds(t *testing.T) {
+	var fields []string
+	for i := 0; i < 2*fieldChangesMaxLength; i++ {
+		fields = append(fields, fmt.Sprintf(`"key%02d":"value"`, i))
+	}
+	fields = append(fields, fmt.Sprintf(`"large":%q`, strings.Repeat("x", 2*fieldValueMaxLength)))
+
+	changes := fieldChanges(nil, "ConfigMap", `{"data":{`+strings.Join(fields, ",")+`}}`)
+	assert.Len(t, changes, fieldChangesMaxLength)
+
+	changes = fieldChanges(nil, "ConfigMap", `{"data":{`+fields[len(fields)-1]+`}}`)
+	assert.Len(t, changes, 1)
+	assert.Len(t, changes[0].Desired, fieldValueMaxLength+len("..."))
+
+	assert.Empty(t, fieldChanges(nil, "ConfigMap", "not json"))
+}
+
+func TestAddFieldChanges(t *testing.T) {
+	cm := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "cm", "namespace": "default"},
+		"data":       map[string]interface{}{"key": "live"},
+	}}
+	plan := desiredset.Plan{Objects: []runtime.Object{cm}}
+	modified := []fleet.ModifiedStatus{
+		{Kind: "ConfigMap", APIVersion: "v1", Namespace: "default", Name: "cm", Patch: `{"data":{"key":"desired"}}`},
+		{Kind: "ConfigMap", APIVersion: "v1", Namespace: "default", Name: "missing", Create: true},
+	}
+
+	addFieldChanges(modified, plan)
+	assert.Equal(t, []fleet.FieldChange{{Path: "/data/key", Live: `"live"`, Desired: `"desired"`}}, modified[0].Changes)
+	assert.Empty(t, modified[1].Changes)
+}
@@ -0,0 +1,92 @@
+package monitor
+
+import (
+	"context"
+	"sort"
+
+	"github.com/rancher/fleet/internal/cmd/agent/deployer/desiredset"
+	"github.com/rancher/fleet/internal/helmdeployer"
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	planActionCreate = "create"
+	planActionUpdate = "update"
+	planActionDelete = "delete"
+)
+
+// Plan compares the rendered resources of the staged deployment with the
+// live state and returns the changes deploying it would make. It is used
+// for paused bundle deployments, whose staged deployment is not deployed.
+func (m *Monitor) Plan(ctx context.Context, bd *fleet.BundleDeployment, resources *helmdeployer.Resources) (*fleet.BundleDeploymentPlan, error) {
+	ns := resources.DefaultNamespace
+	if ns == "" {
+		ns = m.defaultNamespace
+	}
+
+	plan, err := m.desiredset.Plan(ctx, ns, desiredset.GetSetID(bd.Name, m.labelPrefix, m.labelSuffix), resources.Objects...)
+	if err != nil {
+		return nil, err
+	}
+
+	// the diff options of the staged deployment decide which changes
+	// are ignored
+	staged := bd.DeepCopy()
+	staged.Spec.Options = staged.Spec.StagedOptions
+	plan, err = desiredset.Diff(plan, staged, resources.DefaultNamespace, resources.Objects...)
+	if err != nil {
+		return nil, err
+	}
+
+	result := summarizePlan(plan)
+	result.StagedDeploymentID = bd.Spec.StagedDeploymentID
+	return result, nil
+}
+
+// summarizePlan counts the planned changes and lists the changed
+// resources, sorted by action and key and truncated to keep the status
+// small.
+func summarizePlan(plan desiredset.Plan) *fleet.BundleDeploymentPlan {
+	result := &fleet.BundleDeploymentPlan{}
+	add := func(action string, gvk schema.GroupVersionKind, namespace, name string) {
+		apiVersion, kind := gvk.ToAPIVersionAndKind()
+		result.Resources = append(result.Resources, fleet.PlannedResource{
+			Action:     action,
+			Kind:       kind,
+			APIVersion: apiVersion,
+			Namespace:  namespace,
+			Name:       name,
+		})
+	}
+
+	for gvk, keys := range plan.Create {
+		for _, key := range keys {
+			result.Create++
+			add(planActionCreate, gvk, key.Namespace, key.Name)
+		}
+	}
+	for gvk, patches := range plan.Update {
+		for key := range patches {
+			result.Update++
+			add(planActionUpdate, gvk, key.Namespace, key.Name)
+		}
+	}
+	for gvk, keys := range plan.Delete {
+		for _, key := range keys {
+			result.Delete++
+			add(planActionDelete, gvk, key.Namespace, key.Name)
+		}
+	}
+
+	sort.Slice(result.Resources, func(i, j int) bool {
+		return result.Resources[i].String() < result.Resources[j].String()
+	})
+	if len(result.Resources) > resourcesDetailsMaxLength {
+		result.IncompleteState = true
+		result.Resources = result.Resources[:resourcesDetailsMaxLength]
+	}
+
+	return result
+}
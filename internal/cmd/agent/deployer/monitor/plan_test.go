@@ -0,0 +1,52 @@
+package monitor
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/rancher/fleet/internal/cmd/agent/deployer/desiredset"
+	"github.com/rancher/fleet/internal/cmd/agent/deployer/objectset"
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+)
+
+func TestSummarizePlan(t *testing.T) {
+	deployment := schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}
+	configMap := schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}
+
+	plan := desiredset.Plan{
+		Create: objectset.ObjectKeyByGVK{
+			configMap: {{Namespace: "default", Name: "new"}},
+		},
+		Update: desiredset.PatchByGVK{
+			deployment: {{Namespace: "default", Name: "web"}: `{"spec":{"replicas":2}}`},
+		},
+		Delete: objectset.ObjectKeyByGVK{
+			configMap: {{Namespace: "default", Name: "old"}},
+		},
+	}
+	assert.Equal(t, &fleet.BundleDeploymentPlan{
+		Create: 1,
+		Update: 1,
+		Delete: 1,
+		Resources: []fleet.PlannedResource{
+			{Action: "create", Kind: "ConfigMap", APIVersion: "v1", Namespace: "default", Name: "new"},
+			{Action: "delete", Kind: "ConfigMap", APIVersion: "v1", Namespace: "default", Name: "old"},
+			{Action: "update", Kind: "Deployment", APIVersion: "apps/v1", Namespace: "default", Name: "web"},
+		},
+	}, summarizePlan(plan))
+
+	// the list of resources is truncated, the counts are not
+	plan = desiredset.Plan{Create: objectset.ObjectKeyByGVK{}}
+	for i := 0; i < 15; i++ {
+		plan.Create[configMap] = append(plan.Create[configMap], objectset.ObjectKey{Namespace: "default", Name: fmt.Sprintf("cm-%02d", i)})
+	}
+	result := summarizePlan(plan)
+	assert.Equal(t, 15, result.Create)
+	assert.True(t, result.IncompleteState)
+	assert.Len(t, result.Resources, resourcesDetailsMaxLength)
+	assert.Equal(t, "cm-00", result.Resources[0].Name)
+}
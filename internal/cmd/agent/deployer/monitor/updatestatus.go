@@ -184,6 +184,9 @@ func (m *Monitor) updateFromPreviousDeployment(ctx context.Context, bd *fleet.Bu
 
 	nonReadyResources := nonReady(ctx, plan, bd.Spec.Options.IgnoreOptions)
 	modifiedResources := modified(ctx, m.client, plan, resourcesPreviousRelease)
+	if shouldReportDrift(bd) {
+		addFieldChanges(modifiedResources, plan)
+	}
 	allResources, err := toBundleDeploymentResources(m.client, plan.Objects, resources.DefaultNamespace)
 	if err != nil {
 		return err
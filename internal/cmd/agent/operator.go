@@ -22,6 +22,7 @@ import (
 	"helm.sh/helm/v4/pkg/cli"
 
 	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
@@ -52,6 +53,7 @@ func init() {
 	//+kubebuilder:scaffold:scheme
 
 	utilruntime.Must(clientgoscheme.AddToScheme(localScheme))
+	utilruntime.Must(apiextensionsv1.AddToScheme(localScheme))
 }
 
 // start the fleet agent
@@ -159,6 +161,7 @@ func start(
 	clusterStatus := &ClusterStatusRunnable{
 		agentInfo:       agentInfo,
 		config:          upstreamConfig,
+		localConfig:     localConfig,
 		checkinInterval: checkinInterval,
 		namespace:       systemNamespace,
 	}
@@ -235,6 +238,7 @@ func newReconciler(
 		mgr.GetAPIReader(),
 		manifest.NewLookup(),
 		helmDeployer,
+		systemNamespace,
 	)
 
 	// Build the monitor to update the bundle deployment's status, calculates modified/non-modified
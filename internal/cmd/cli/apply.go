@@ -63,6 +63,7 @@ type Apply struct {
 	CorrectDrift                 bool              `usage:"Rollback any change made from outside of Fleet" name:"correct-drift"`
 	CorrectDriftForce            bool              `usage:"Use --force when correcting drift. Resources can be deleted and recreated" name:"correct-drift-force"`
 	CorrectDriftKeepFailHistory  bool              `usage:"Keep helm history for failed rollbacks" name:"correct-drift-keep-fail-history"`
+	ReportDrift                  bool              `usage:"Report the changed fields of modified resources in the bundle deployment status" name:"report-drift"`
 	OCIRegistrySecret            string            `usage:"OCI storage registry secret name" name:"oci-registry-secret"`
 	DrivenScan                   bool              `usage:"Use driven scan. Bundles are defined by the user" name:"driven-scan"`
 	DrivenScanSeparator          string            `usage:"Separator to use for bundle folder and options file" name:"driven-scan-sep" default:":"`
@@ -125,6 +126,7 @@ func (a *Apply) run(cmd *cobra.Command, args []string) error {
 		CorrectDrift:                 a.CorrectDrift,
 		CorrectDriftForce:            a.CorrectDriftForce,
 		CorrectDriftKeepFailHistory:  a.CorrectDriftKeepFailHistory,
+		ReportDrift:                  a.ReportDrift,
 		DrivenScan:                   a.DrivenScan,
 		DrivenScanSeparator:          a.DrivenScanSeparator,
 		OCIRegistrySecret:            a.OCIRegistrySecret,
@@ -88,12 +88,19 @@ type Options struct {
 	CorrectDrift                 bool
 	CorrectDriftForce            bool
 	CorrectDriftKeepFailHistory  bool
+	ReportDrift                  bool
 	OCIRegistry                  OCIRegistrySpec
 	OCIRegistrySecret            string
 	DrivenScan                   bool
 	DrivenScanSeparator          string
 	JobNameEnvVar                string
 	BundleCreationMaxConcurrency int
+	// RootDir is the directory the base dirs are relative to. Defaults to
+	// the working directory.
+	RootDir string
+	// LocalOnly restricts the bundles to local files below RootDir, see
+	// bundlereader.Options.LocalRoot.
+	LocalOnly bool
 }
 
 type bundleWithOpts struct {
@@ -102,11 +109,11 @@ type bundleWithOpts struct {
 	opts   *Options
 }
 
-func globDirs(baseDir string) (result []string, err error) {
+func globDirs(root, baseDir string) (result []string, err error) {
 	for strings.HasPrefix(baseDir, "/") {
 		baseDir = baseDir[1:]
 	}
-	paths, err := filepath.Glob(baseDir)
+	paths, err := filepath.Glob(rootPath(root, baseDir))
 	if err != nil {
 		return nil, err
 	}
@@ -118,6 +125,36 @@ func globDirs(baseDir string) (result []string, err error) {
 	return
 }
 
+// rootPath returns the path of dir in root, or dir if root is not set.
+func rootPath(root, dir string) string {
+	if root == "" {
+		return dir
+	}
+	return filepath.Join(root, dir)
+}
+
+// localRoot returns the root bundles are restricted to, if any.
+func localRoot(opts Options) string {
+	if !opts.LocalOnly {
+		return ""
+	}
+	if opts.RootDir == "" {
+		return "."
+	}
+	return opts.RootDir
+}
+
+// relPath returns the path of dir relative to root, or dir if root is not set.
+func relPath(root, dir string) string {
+	if root == "" {
+		return dir
+	}
+	if rel, err := filepath.Rel(root, dir); err == nil {
+		return rel
+	}
+	return dir
+}
+
 func getEffectiveMaxConcurrency(configured int) int {
 	if configured <= 0 {
 		return defaultBundleCreationMaxConcurrency
@@ -145,7 +182,7 @@ func CreateBundles(pctx context.Context, client client.Client, r record.EventRec
 	eg.SetLimit(maxConcurrency + 1) // extra goroutine for WalkDir loop
 	eg.Go(func() error {
 		for _, baseDir := range baseDirs {
-			matches, err := globDirs(baseDir)
+			matches, err := globDirs(opts.RootDir, baseDir)
 			if err != nil {
 				return fmt.Errorf("invalid path glob %s: %w", baseDir, err)
 			}
@@ -168,11 +205,11 @@ func CreateBundles(pctx context.Context, client client.Client, r record.EventRec
 					// needed as opts are mutated in this loop
 					opts := opts
 					eg.Go(func() error {
-						if err := setAuthByPath(&opts, path); err != nil {
+						if err := setAuthByPath(&opts, relPath(opts.RootDir, path)); err != nil {
 							return err
 						}
 
-						bundle, scans, err := bundleFromDir(ctx, repoName, path, opts)
+						bundle, scans, err := bundleFromDir(ctx, client, repoName, path, opts)
 						if err != nil {
 							if errors.Is(err, ErrNoResources) {
 								logrus.Warnf("%s: %v", path, err)
@@ -271,7 +308,7 @@ func CreateBundlesDriven(pctx context.Context, client client.Client, r record.Ev
 					return err
 				}
 
-				bundle, scans, err := bundleFromDir(ctx, repoName, baseDir, opts)
+				bundle, scans, err := bundleFromDir(ctx, client, repoName, rootPath(opts.RootDir, baseDir), opts)
 				if err != nil {
 					if errors.Is(err, ErrNoResources) {
 						logrus.Warnf("%s: %v", baseDir, err)
@@ -414,7 +451,7 @@ func pruneBundlesNotFoundInRepo(
 
 // newBundle reads bundle data from a source and returns a bundle with the
 // given name, or the name from the raw source file
-func newBundle(ctx context.Context, name, baseDir string, opts Options) (*fleet.Bundle, []*fleet.ImageScan, error) {
+func newBundle(ctx context.Context, c client.Reader, name, baseDir string, opts Options) (*fleet.Bundle, []*fleet.ImageScan, error) {
 	var bundle *fleet.Bundle
 	var scans []*fleet.ImageScan
 	if opts.BundleReader != nil {
@@ -440,7 +477,11 @@ func newBundle(ctx context.Context, name, baseDir string, opts Options) (*fleet.
 				Enabled:         opts.CorrectDrift,
 				Force:           opts.CorrectDriftForce,
 				KeepFailHistory: opts.CorrectDriftKeepFailHistory,
+				Report:          opts.ReportDrift,
 			},
+			SecretReader:    c,
+			SecretNamespace: opts.Namespace,
+			LocalRoot:       localRoot(opts),
 		})
 		if err != nil {
 			return nil, nil, err
@@ -454,16 +495,16 @@ func newBundle(ctx context.Context, name, baseDir string, opts Options) (*fleet.
 //
 // name: the gitrepo name, passed to 'fleet apply' on the cli
 // basedir: a directory containing a Bundle, as observed by CreateBundles or CreateBundlesDriven
-func bundleFromDir(ctx context.Context, name, baseDir string, opts Options) (*fleet.Bundle, []*fleet.ImageScan, error) {
+func bundleFromDir(ctx context.Context, c client.Reader, name, baseDir string, opts Options) (*fleet.Bundle, []*fleet.ImageScan, error) {
 	// The bundleID is a valid helm release name, it's used as a default if a release name is not specified in helm options.
 	// It's also used to create the bundle name.
-	bundleID := filepath.Join(name, baseDir)
+	bundleID := filepath.Join(name, relPath(opts.RootDir, baseDir))
 	if opts.BundleFile != "" {
 		bundleID = filepath.Join(bundleID, strings.TrimSuffix(opts.BundleFile, filepath.Ext(opts.BundleFile)))
 	}
 	bundleID = names.HelmReleaseName(bundleID)
 
-	bundle, scans, err := newBundle(ctx, bundleID, baseDir, opts)
+	bundle, scans, err := newBundle(ctx, c, bundleID, baseDir, opts)
 	if err != nil {
 		return nil, nil, err
 	} else if len(bundle.Spec.Resources) == 0 {
@@ -573,6 +614,8 @@ func shouldStoreInOCIRegistry(ctx context.Context, c client.Reader, ociSecretKey
 	ociOpts.AgentPassword = opts.AgentPassword
 	ociOpts.BasicHTTP = opts.BasicHTTP
 	ociOpts.InsecureSkipTLS = opts.InsecureSkipTLS
+	ociOpts.VerificationProvider = opts.VerificationProvider
+	ociOpts.VerificationKeys = opts.VerificationKeys
 
 	return true, nil
 }
@@ -738,7 +781,7 @@ func deleteOCIManifest(ctx context.Context, c client.Client, bundle *fleet.Bundl
 // OCI registry reference and credentials so the fleet controller is
 // able to access.
 func newOCISecret(manifestID string, bundle *fleet.Bundle, opts ocistorage.OCIOpts) *corev1.Secret {
-	return &corev1.Secret{
+	secret := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      manifestID,
 			Namespace: bundle.Namespace,
@@ -765,6 +808,11 @@ func newOCISecret(manifestID string, bundle *fleet.Bundle, opts ocistorage.OCIOp
 		},
 		Type: fleet.SecretTypeOCIStorage,
 	}
+	if len(opts.VerificationKeys) > 0 {
+		secret.Data[ocistorage.OCISecretVerificationProvider] = []byte(opts.VerificationProvider)
+		secret.Data[ocistorage.OCISecretVerificationKeys] = opts.VerificationKeys
+	}
+	return secret
 }
 
 func newValuesSecret(bundle *fleet.Bundle, data map[string][]byte) *corev1.Secret {
@@ -1,9 +1,19 @@
 package apply
 
 import (
+	"context"
+	"os"
+	"path/filepath"
 	"testing"
 
 	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func Test_getKindNS(t *testing.T) {
@@ -40,3 +50,60 @@ data:
 	}
 
 }
+
+func TestCreateBundlesRootDir(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "app"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "app", "fleet.yaml"), []byte("namespace: app\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cm := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\n"
+	if err := os.WriteFile(filepath.Join(root, "app", "cm.yaml"), []byte(cm), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	scheme := runtime.NewScheme()
+	if err := fleet.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, create := range map[string]func(context.Context, client.Client, record.EventRecorder, string, []string, Options) error{
+		"scan":   CreateBundles,
+		"driven": CreateBundlesDriven,
+	} {
+		t.Run(name, func(t *testing.T) {
+			old := &fleet.Bundle{ObjectMeta: metav1.ObjectMeta{
+				Name:      "repo-old",
+				Namespace: "fleet-local",
+				Labels:    map[string]string{fleet.RepoLabel: "repo"},
+			}}
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(old).Build()
+
+			opts := Options{
+				Namespace:           "fleet-local",
+				Labels:              map[string]string{fleet.RepoLabel: "repo"},
+				DrivenScanSeparator: ":",
+				RootDir:             root,
+			}
+			if err := create(context.TODO(), c, record.NewFakeRecorder(10), "repo", []string{"app"}, opts); err != nil {
+				t.Fatal(err)
+			}
+
+			bundles := &fleet.BundleList{}
+			if err := c.List(context.TODO(), bundles); err != nil {
+				t.Fatal(err)
+			}
+			if len(bundles.Items) != 1 || bundles.Items[0].Name != "repo-app" {
+				t.Fatalf("expected only the bundle named after the path in the root dir, got %v", bundles.Items)
+			}
+			if r := bundles.Items[0].Spec.Resources; len(r) != 1 || r[0].Name != "cm.yaml" {
+				t.Errorf("expected resources of the bundle dir, got %v", r)
+			}
+		})
+	}
+}
@@ -46,10 +46,10 @@ func NewDeploy() *cobra.Command {
 }
 
 type Deploy struct {
-	InputFile   string `usage:"Location of the YAML file containing the content and the bundledeployment resource" short:"i"`
+	InputFile   string `usage:"Location of the YAML file containing the content and the bundledeployment resource. For dry runs, it may contain the target cluster resource, to render with the capabilities the cluster reported." short:"i"`
 	DryRun      bool   `usage:"Print the resources that would be deployed, but do not actually deploy them" short:"d"`
 	Namespace   string `usage:"Set the default namespace. Deploy helm chart into this namespace." short:"n"`
-	KubeVersion string `usage:"For dry runs, sets the Kubernetes version to assume when validating Chart Kubernetes version constraints."`
+	KubeVersion string `usage:"For dry runs, sets the Kubernetes version to assume when validating Chart Kubernetes version constraints. Overrides the version reported by the cluster."`
 
 	// AgentNamespace is set as an annotation on the chart.yaml in the helm release. Fleet-agent will manage charts with a matching label.
 	AgentNamespace string `usage:"Set the agent namespace, normally cattle-fleet-system. If set, fleet agent will garbage collect the helm release, i.e. delete it if the bundledeployment is missing." short:"a"`
@@ -70,12 +70,13 @@ func (d *Deploy) Run(cmd *cobra.Command, args []string) error {
 
 	c := &v1alpha1.Content{}
 	bd := &v1alpha1.BundleDeployment{}
+	cluster := &v1alpha1.Cluster{}
 	objs, err := wyaml.ToObjects(bytes.NewBuffer(b))
 	if err != nil {
 		return err
 	}
 
-	// position of the content and bundledeployment resources in the file is not guaranteed
+	// position of the content, bundledeployment and cluster resources in the file is not guaranteed
 	for _, obj := range objs {
 		switch obj.GetObjectKind().GroupVersionKind().Kind {
 		case "Content":
@@ -96,6 +97,15 @@ func (d *Deploy) Run(cmd *cobra.Command, args []string) error {
 			if err != nil {
 				return err
 			}
+		case "Cluster":
+			un, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+			if err != nil {
+				return err
+			}
+			err = runtime.DefaultUnstructuredConverter.FromUnstructured(un, cluster)
+			if err != nil {
+				return err
+			}
 		}
 	}
 
@@ -119,7 +129,11 @@ func (d *Deploy) Run(cmd *cobra.Command, args []string) error {
 	}
 
 	if d.DryRun {
-		rel, err := helmdeployer.Template(ctx, bd.Name, manifest, bd.Spec.Options, d.KubeVersion)
+		capabilities, err := helmdeployer.Capabilities(d.KubeVersion, cluster.Status.Facts)
+		if err != nil {
+			return err
+		}
+		rel, err := helmdeployer.Template(ctx, bd.Name, manifest, bd.Spec.Options, capabilities)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,164 @@
+package cli
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	command "github.com/rancher/fleet/internal/cmd"
+	"github.com/rancher/fleet/internal/cmd/cli/apply"
+	"github.com/rancher/fleet/internal/cmd/cli/diff"
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	wyaml "github.com/rancher/wrangler/v3/pkg/yaml"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+// NewDiff returns a subcommand to preview the changes of bundles on each targeted cluster.
+func NewDiff() *cobra.Command {
+	cmd := command.Command(&Diff{}, cobra.Command{
+		Use:   "diff [flags] BUNDLE_NAME PATH...",
+		Short: "Render bundles from directories for each cluster of an inventory and print a diff against the deployed manifests",
+		Long: `Render bundles from directories for each cluster of an inventory and print a diff against the deployed manifests.
+
+The inventory is a YAML file, which contains the clusters, cluster groups,
+bundle namespace mappings, bundledeployments and contents of the upstream
+cluster, e.g. as produced by:
+
+  kubectl get clusters,clustergroups,bundlenamespacemappings,bundledeployments -A -o yaml
+  kubectl get contents -o yaml
+
+Bundledeployments with Helm values stored in secrets, and bundles verifying
+the signatures of OCI charts, require these secrets to be part of the
+inventory, too. No connection to a cluster is made.`,
+	})
+	cmd.SetOut(os.Stdout)
+
+	// add command line flags from zap, which use goflags and convert them to pflags
+	fs := flag.NewFlagSet("", flag.ExitOnError)
+	zopts.BindFlags(fs)
+	cmd.Flags().AddGoFlagSet(fs)
+	return cmd
+}
+
+type Diff struct {
+	Namespace           string `usage:"Namespace of the bundles, targeting searches this namespace for clusters" default:"fleet-local" short:"n"`
+	File                string `usage:"Location of the fleet.yaml, relative to each path" short:"f"`
+	Inventory           string `usage:"Location of the YAML file containing the inventory of the upstream cluster" short:"i"`
+	TargetsFile         string `usage:"Addition source of targets and restrictions to be append"`
+	KubeVersion         string `usage:"Sets the Kubernetes version to assume when rendering charts, overrides the version reported by each cluster"`
+	Context             int    `usage:"Number of context lines in the diff" default:"3"`
+	DrivenScan          bool   `usage:"Use driven scan. Bundles are defined by the user" name:"driven-scan"`
+	DrivenScanSeparator string `usage:"Separator to use for bundle folder and options file" name:"driven-scan-sep" default:":"`
+}
+
+func (d *Diff) Run(cmd *cobra.Command, args []string) error {
+	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&zopts)))
+	ctx := log.IntoContext(cmd.Context(), ctrl.Log)
+
+	if len(args) < 1 {
+		return fmt.Errorf("the bundle name is required as the first argument")
+	}
+	if d.Inventory == "" {
+		return fmt.Errorf("the inventory is required, set --inventory")
+	}
+	name := args[0]
+	args = args[1:]
+
+	f, err := os.Open(d.Inventory)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	c, err := diff.NewInventoryClient(scheme, f)
+	if err != nil {
+		return err
+	}
+
+	// create the bundles the same way "fleet apply" does when writing to
+	// an output, so bundle names and options match the gitjob's. Secrets
+	// referenced by fleet.yaml, like the keys to verify OCI charts, are
+	// read from the inventory.
+	buf := &bytes.Buffer{}
+	opts := apply.Options{
+		Namespace:                    d.Namespace,
+		BundleFile:                   d.File,
+		TargetsFile:                  d.TargetsFile,
+		Output:                       &documentWriter{w: buf},
+		DrivenScan:                   d.DrivenScan,
+		DrivenScanSeparator:          d.DrivenScanSeparator,
+		BundleCreationMaxConcurrency: 1, // the output writer is not safe for concurrent use
+	}
+	if opts.DrivenScan {
+		err = apply.CreateBundlesDriven(ctx, c, nil, name, args, opts)
+	} else {
+		err = apply.CreateBundles(ctx, c, nil, name, args, opts)
+	}
+	if err != nil {
+		return err
+	}
+
+	bundles, err := bundlesFromOutput(buf)
+	if err != nil {
+		return err
+	}
+
+	diffs, err := diff.Diff(ctx, &diff.Options{
+		Bundles:     bundles,
+		Client:      c,
+		KubeVersion: d.KubeVersion,
+		Context:     d.Context,
+	})
+	if err != nil {
+		return err
+	}
+
+	return diff.Print(cmd.OutOrStdout(), diffs)
+}
+
+// documentWriter starts every write with a YAML document separator, as
+// bundles written to the output of apply are not separated.
+type documentWriter struct {
+	w io.Writer
+}
+
+func (d *documentWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(d.w, "\n---\n"); err != nil {
+		return 0, err
+	}
+	return d.w.Write(p)
+}
+
+func bundlesFromOutput(r io.Reader) ([]*fleet.Bundle, error) {
+	objs, err := wyaml.ToObjects(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var bundles []*fleet.Bundle
+	for _, obj := range objs {
+		if obj.GetObjectKind().GroupVersionKind().Kind != "Bundle" {
+			continue
+		}
+		un, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return nil, err
+		}
+		bundle := &fleet.Bundle{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(un, bundle); err != nil {
+			return nil, err
+		}
+		bundles = append(bundles, bundle)
+	}
+
+	return bundles, nil
+}
@@ -0,0 +1,318 @@
+// Package diff renders bundles for each cluster of an offline inventory and
+// compares the result with the manifests currently deployed to that cluster.
+//
+// It is used by the "diff" sub command of the fleet CLI, e.g. to post the
+// rendered delta of a pull request in CI.
+package diff
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+
+	"github.com/rancher/fleet/internal/cmd/controller/target"
+	"github.com/rancher/fleet/internal/helmdeployer"
+	"github.com/rancher/fleet/internal/manifest"
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+
+	"github.com/rancher/wrangler/v3/pkg/kv"
+	wyaml "github.com/rancher/wrangler/v3/pkg/yaml"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// Created is the operation for a resource which is only in the desired state.
+	Created = "create"
+	// Updated is the operation for a resource which differs between the current and the desired state.
+	Updated = "update"
+	// Deleted is the operation for a resource which is only in the current state.
+	Deleted = "delete"
+
+	defaultContextLines = 3
+)
+
+type Options struct {
+	// Bundles are the bundles to render, e.g. as read from a git repository.
+	Bundles []*fleet.Bundle
+	// Client reads the inventory of clusters, cluster groups, bundle
+	// deployments and contents.
+	Client client.Client
+	// KubeVersion is the Kubernetes version to assume when rendering charts.
+	// It overrides the version reported by each cluster.
+	KubeVersion string
+	// Context is the number of context lines in the unified diff.
+	Context int
+}
+
+// ResourceDiff is the diff of a single resource.
+type ResourceDiff struct {
+	// ID identifies the resource by api version, kind, namespace and name.
+	ID string
+	// Op is one of Created, Updated or Deleted.
+	Op string
+	// Diff is the unified diff between the current and the desired resource.
+	Diff string
+}
+
+// ClusterDiff contains the diff of a bundle for a single cluster.
+type ClusterDiff struct {
+	Bundle           string
+	ClusterNamespace string
+	ClusterName      string
+	// CurrentDeploymentID is empty if the bundle is not deployed to the cluster yet.
+	CurrentDeploymentID string
+	DesiredDeploymentID string
+	// Warning explains why the current state could not be rendered.
+	Warning   string
+	Resources []ResourceDiff
+}
+
+// NewInventoryClient returns a read only client, which serves the objects
+// contained in the YAML documents or lists read from r. Objects of a kind
+// unknown to the scheme are ignored.
+func NewInventoryClient(scheme *runtime.Scheme, r io.Reader) (client.Client, error) {
+	objs, err := wyaml.ToObjects(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read inventory: %w", err)
+	}
+
+	var typed []client.Object
+	for _, obj := range objs {
+		un, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			continue
+		}
+		o, err := scheme.New(un.GroupVersionKind())
+		if err != nil {
+			continue
+		}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(un.Object, o); err != nil {
+			return nil, fmt.Errorf("failed to convert %s %s/%s: %w", un.GetKind(), un.GetNamespace(), un.GetName(), err)
+		}
+		co, ok := o.(client.Object)
+		if !ok {
+			continue
+		}
+		co.SetResourceVersion("")
+		co.SetManagedFields(nil)
+		typed = append(typed, co)
+	}
+
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(typed...).Build(), nil
+}
+
+// Diff computes the targets of each bundle and returns a diff per cluster.
+// Clusters without changes are included with an empty list of resources.
+func Diff(ctx context.Context, opts *Options) ([]ClusterDiff, error) {
+	var result []ClusterDiff
+	mgr := target.New(opts.Client, opts.Client)
+	for _, bundle := range opts.Bundles {
+		m := manifest.New(bundle.Spec.Resources)
+		manifestID, err := m.ID()
+		if err != nil {
+			return nil, err
+		}
+
+		targets, err := mgr.Targets(ctx, bundle, manifestID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute targets for bundle %s: %w", bundle.Name, err)
+		}
+
+		for _, t := range targets {
+			d, err := diffTarget(ctx, opts, m, t)
+			if err != nil {
+				return nil, fmt.Errorf("bundle %s, cluster %s/%s: %w", bundle.Name, t.Cluster.Namespace, t.Cluster.Name, err)
+			}
+			result = append(result, d)
+		}
+	}
+
+	return result, nil
+}
+
+func diffTarget(ctx context.Context, opts *Options, m *manifest.Manifest, t *target.Target) (ClusterDiff, error) {
+	d := ClusterDiff{
+		Bundle:              t.Bundle.Name,
+		ClusterNamespace:    t.Cluster.Namespace,
+		ClusterName:         t.Cluster.Name,
+		DesiredDeploymentID: t.DeploymentID,
+	}
+
+	// render with the capabilities the agent reported, so charts render as
+	// they would on the cluster
+	capabilities, err := helmdeployer.Capabilities(opts.KubeVersion, t.Cluster.Status.Facts)
+	if err != nil {
+		return d, err
+	}
+
+	desired, err := render(ctx, t.Bundle.Name, m, t.Options, capabilities)
+	if err != nil {
+		return d, fmt.Errorf("failed to render desired state: %w", err)
+	}
+
+	current := map[string]string{}
+	if t.Deployment != nil && t.Deployment.Spec.DeploymentID != "" {
+		d.CurrentDeploymentID = t.Deployment.Spec.DeploymentID
+		manifestID, _ := kv.Split(t.Deployment.Spec.DeploymentID, ":")
+		cm, err := manifest.NewLookup().Get(ctx, opts.Client, manifestID)
+		switch {
+		case apierrors.IsNotFound(err):
+			d.Warning = fmt.Sprintf("content %s is missing from the inventory, comparing against an empty state", manifestID)
+		case err != nil:
+			return d, err
+		default:
+			current, err = render(ctx, t.Deployment.Name, cm, t.Deployment.Spec.Options, capabilities)
+			if err != nil {
+				return d, fmt.Errorf("failed to render current state: %w", err)
+			}
+		}
+	}
+
+	d.Resources = diffResources(current, desired, opts.Context)
+	return d, nil
+}
+
+// render templates the manifest and returns the resources as YAML, indexed by their ID.
+func render(ctx context.Context, bundleID string, m *manifest.Manifest, options fleet.BundleDeploymentOptions, capabilities *common.Capabilities) (map[string]string, error) {
+	rel, err := helmdeployer.Template(ctx, bundleID, m, options, capabilities)
+	if err != nil {
+		return nil, err
+	}
+
+	objs, err := wyaml.ToObjects(bytes.NewBufferString(rel.Manifest))
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range rel.Hooks {
+		hookObjs, err := wyaml.ToObjects(bytes.NewBufferString(h.Manifest))
+		if err != nil {
+			return nil, err
+		}
+		objs = append(objs, hookObjs...)
+	}
+
+	result := map[string]string{}
+	for _, obj := range objs {
+		un, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			continue
+		}
+		data, err := yaml.Marshal(un.Object)
+		if err != nil {
+			return nil, err
+		}
+		result[resourceID(un)] = string(data)
+	}
+
+	return result, nil
+}
+
+func resourceID(un *unstructured.Unstructured) string {
+	id := un.GetAPIVersion() + "/" + un.GetKind()
+	if un.GetNamespace() != "" {
+		id += "/" + un.GetNamespace()
+	}
+	return id + "/" + un.GetName()
+}
+
+func diffResources(current, desired map[string]string, context int) []ResourceDiff {
+	if context <= 0 {
+		context = defaultContextLines
+	}
+
+	ids := map[string]struct{}{}
+	for id := range current {
+		ids[id] = struct{}{}
+	}
+	for id := range desired {
+		ids[id] = struct{}{}
+	}
+	sorted := make([]string, 0, len(ids))
+	for id := range ids {
+		sorted = append(sorted, id)
+	}
+	sort.Strings(sorted)
+
+	var result []ResourceDiff
+	for _, id := range sorted {
+		c, inCurrent := current[id]
+		n, inDesired := desired[id]
+		if c == n {
+			continue
+		}
+
+		op := Updated
+		switch {
+		case !inCurrent:
+			op = Created
+		case !inDesired:
+			op = Deleted
+		}
+
+		// errors are only returned by the underlying writer, which is a strings.Builder
+		diff, _ := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        splitLines(c),
+			B:        splitLines(n),
+			FromFile: "current/" + id,
+			ToFile:   "desired/" + id,
+			Context:  context,
+		})
+		result = append(result, ResourceDiff{ID: id, Op: op, Diff: diff})
+	}
+
+	return result
+}
+
+// splitLines splits s into lines, an empty string has no lines.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return difflib.SplitLines(strings.TrimSuffix(s, "\n"))
+}
+
+// Print writes a summary line per cluster, followed by the unified diff of each changed resource.
+func Print(w io.Writer, diffs []ClusterDiff) error {
+	for _, d := range diffs {
+		counts := map[string]int{}
+		for _, r := range d.Resources {
+			counts[r.Op]++
+		}
+
+		current := d.CurrentDeploymentID
+		if current == "" {
+			current = "<none>"
+		}
+		if _, err := fmt.Fprintf(w, "# bundle %s, cluster %s/%s: %d to create, %d to update, %d to delete (%s -> %s)\n",
+			d.Bundle, d.ClusterNamespace, d.ClusterName,
+			counts[Created], counts[Updated], counts[Deleted],
+			current, d.DesiredDeploymentID); err != nil {
+			return err
+		}
+		if d.Warning != "" {
+			if _, err := fmt.Fprintf(w, "# warning: %s\n", d.Warning); err != nil {
+				return err
+			}
+		}
+		for _, r := range d.Resources {
+			if _, err := io.WriteString(w, r.Diff); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,208 @@
+package diff
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/rancher/fleet/internal/content"
+	"github.com/rancher/fleet/internal/manifest"
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	configMapV1 = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app
+data:
+  key: v1
+`
+	configMapV2 = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app
+data:
+  key: v2
+`
+	service = `apiVersion: v1
+kind: Service
+metadata:
+  name: app
+spec:
+  ports:
+  - port: 80
+`
+)
+
+func newScheme(t *testing.T) *runtime.Scheme {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(fleet.AddToScheme(scheme))
+	return scheme
+}
+
+func inventory(t *testing.T, deployed []fleet.BundleResource) string {
+	t.Helper()
+
+	m := manifest.New(deployed)
+	id, err := m.ID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := m.Content()
+	if err != nil {
+		t.Fatal(err)
+	}
+	compressed, err := content.Gzip(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	objs := []interface{}{
+		&fleet.Cluster{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "fleet.cattle.io/v1alpha1", Kind: "Cluster"},
+			ObjectMeta: metav1.ObjectMeta{Name: "deployed", Namespace: "fleet-default", ResourceVersion: "42"},
+			Status:     fleet.ClusterStatus{Namespace: "cluster-fleet-default-deployed"},
+		},
+		&fleet.Cluster{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "fleet.cattle.io/v1alpha1", Kind: "Cluster"},
+			ObjectMeta: metav1.ObjectMeta{Name: "new", Namespace: "fleet-default"},
+			Status:     fleet.ClusterStatus{Namespace: "cluster-fleet-default-new"},
+		},
+		&fleet.BundleDeployment{
+			TypeMeta: metav1.TypeMeta{APIVersion: "fleet.cattle.io/v1alpha1", Kind: "BundleDeployment"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "app",
+				Namespace: "cluster-fleet-default-deployed",
+				Labels: map[string]string{
+					fleet.BundleLabel:          "app",
+					fleet.BundleNamespaceLabel: "fleet-default",
+				},
+			},
+			Spec: fleet.BundleDeploymentSpec{DeploymentID: id + ":hash"},
+		},
+		&fleet.Content{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "fleet.cattle.io/v1alpha1", Kind: "Content"},
+			ObjectMeta: metav1.ObjectMeta{Name: id},
+			Content:    compressed,
+		},
+		// unknown kinds are ignored
+		map[string]interface{}{"apiVersion": "example.com/v1", "kind": "Unknown", "metadata": map[string]interface{}{"name": "x"}},
+	}
+
+	var docs []string
+	for _, obj := range objs {
+		b, err := yaml.Marshal(obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		docs = append(docs, string(b))
+	}
+	return strings.Join(docs, "---\n")
+}
+
+func TestDiff(t *testing.T) {
+	c, err := NewInventoryClient(newScheme(t), strings.NewReader(inventory(t, []fleet.BundleResource{
+		{Name: "cm.yaml", Content: configMapV1},
+		{Name: "svc.yaml", Content: service},
+	})))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bundle := &fleet.Bundle{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "fleet-default"},
+		Spec: fleet.BundleSpec{
+			BundleDeploymentOptions: fleet.BundleDeploymentOptions{DefaultNamespace: "default"},
+			Resources:               []fleet.BundleResource{{Name: "cm.yaml", Content: configMapV2}},
+			Targets:                 []fleet.BundleTarget{{ClusterSelector: &metav1.LabelSelector{}}},
+		},
+	}
+
+	diffs, err := Diff(context.TODO(), &Options{Bundles: []*fleet.Bundle{bundle}, Client: c})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diffs) != 2 {
+		t.Fatalf("expected a diff for each of the 2 clusters, got %d", len(diffs))
+	}
+
+	deployed := diffs[0]
+	if deployed.ClusterName != "deployed" || deployed.CurrentDeploymentID == "" {
+		t.Fatalf("expected first diff to be for the deployed cluster, got %+v", deployed)
+	}
+	if len(deployed.Resources) != 2 {
+		t.Fatalf("expected 2 changed resources, got %+v", deployed.Resources)
+	}
+	if r := deployed.Resources[0]; r.ID != "v1/ConfigMap/app" || r.Op != Updated ||
+		!strings.Contains(r.Diff, "-  key: v1") || !strings.Contains(r.Diff, "+  key: v2") {
+		t.Errorf("unexpected config map diff: %+v", r)
+	}
+	if r := deployed.Resources[1]; r.ID != "v1/Service/app" || r.Op != Deleted {
+		t.Errorf("unexpected service diff: %+v", r)
+	}
+
+	created := diffs[1]
+	if created.ClusterName != "new" || created.CurrentDeploymentID != "" {
+		t.Fatalf("expected second diff to be for the new cluster, got %+v", created)
+	}
+	if len(created.Resources) != 1 || created.Resources[0].Op != Created {
+		t.Errorf("expected the config map to be created, got %+v", created.Resources)
+	}
+
+	var out bytes.Buffer
+	if err := Print(&out, diffs); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		"# bundle app, cluster fleet-default/deployed: 0 to create, 1 to update, 1 to delete",
+		"# bundle app, cluster fleet-default/new: 1 to create, 0 to update, 0 to delete (<none> -> ",
+		"--- current/v1/ConfigMap/app",
+		"+++ desired/v1/ConfigMap/app",
+	} {
+		if !strings.Contains(out.String(), s) {
+			t.Errorf("expected output to contain %q, got:\n%s", s, out.String())
+		}
+	}
+}
+
+func TestDiffMissingContent(t *testing.T) {
+	inv := inventory(t, []fleet.BundleResource{{Name: "cm.yaml", Content: configMapV1}})
+	// drop the content document
+	inv = inv[:strings.LastIndex(inv, "---\napiVersion: fleet.cattle.io/v1alpha1\ncontent:")]
+	c, err := NewInventoryClient(newScheme(t), strings.NewReader(inv))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bundle := &fleet.Bundle{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "fleet-default"},
+		Spec: fleet.BundleSpec{
+			Resources: []fleet.BundleResource{{Name: "cm.yaml", Content: configMapV1}},
+			Targets:   []fleet.BundleTarget{{ClusterName: "deployed"}},
+		},
+	}
+
+	diffs, err := Diff(context.TODO(), &Options{Bundles: []*fleet.Bundle{bundle}, Client: c})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diffs) != 1 {
+		t.Fatalf("expected a single diff, got %d", len(diffs))
+	}
+	if !strings.Contains(diffs[0].Warning, "is missing from the inventory") {
+		t.Errorf("expected a warning about the missing content, got %q", diffs[0].Warning)
+	}
+	if len(diffs[0].Resources) != 1 || diffs[0].Resources[0].Op != Created {
+		t.Errorf("expected the config map to be created, got %+v", diffs[0].Resources)
+	}
+}
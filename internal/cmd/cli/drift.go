@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	command "github.com/rancher/fleet/internal/cmd"
+	"github.com/rancher/fleet/internal/cmd/cli/drift"
+)
+
+// NewDrift returns a subcommand to show the modified resources of bundle deployments.
+func NewDrift() *cobra.Command {
+	return command.Command(&Drift{}, cobra.Command{
+		Use:   "drift [flags]",
+		Short: "Show the modified resources of bundle deployments",
+		Long: `Show the modified resources of bundle deployments.
+
+If correctDrift.report is enabled, the agent records the changed fields of
+modified resources, with their live and desired values. For these, a
+comparePatch is printed, which would ignore the changes.`,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	})
+}
+
+type Drift struct {
+	FleetClient
+	AllNamespaces bool   `usage:"Show bundle deployments of bundles in all namespaces" short:"A" name:"all-namespaces"`
+	Bundle        string `usage:"Only show bundle deployments of this bundle" short:"b"`
+	Cluster       string `usage:"Only show bundle deployments of this cluster" short:"c"`
+}
+
+func (d *Drift) PersistentPre(_ *cobra.Command, _ []string) error {
+	if err := d.SetupDebug(); err != nil {
+		return fmt.Errorf("failed to set up debug logging: %w", err)
+	}
+	return nil
+}
+
+func (d *Drift) Run(cmd *cobra.Command, args []string) error {
+	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&zopts)))
+	ctx := log.IntoContext(cmd.Context(), ctrl.Log)
+
+	cfg := ctrl.GetConfigOrDie()
+	client, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		return err
+	}
+
+	opts := drift.Options{
+		Namespace: d.Namespace,
+		Bundle:    d.Bundle,
+		Cluster:   d.Cluster,
+	}
+	if d.AllNamespaces {
+		opts.Namespace = ""
+	}
+	drifts, err := drift.List(ctx, client, opts)
+	if err != nil {
+		return err
+	}
+
+	return drift.Print(cmd.OutOrStdout(), drifts)
+}
@@ -0,0 +1,168 @@
+// Package drift lists the modified resources of bundle deployments, with the
+// changed fields reported by the agent.
+//
+// It is used by the "drift" sub command of the fleet CLI, to decide whether
+// a drift should be ignored with a comparePatch or be corrected.
+package drift
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+type Options struct {
+	// Namespace of the bundles, all namespaces if empty.
+	Namespace string
+	// Bundle limits the result to bundle deployments of this bundle.
+	Bundle string
+	// Cluster limits the result to bundle deployments of this cluster.
+	Cluster string
+}
+
+// BundleDeploymentDrift contains the modified resources of a bundle deployment.
+type BundleDeploymentDrift struct {
+	Bundle           string
+	BundleNamespace  string
+	ClusterName      string
+	ClusterNamespace string
+	// Reported is true if the agent reports field changes for the bundle
+	// deployment.
+	Reported bool
+	// Incomplete is true if the agent truncated the list of resources.
+	Incomplete bool
+	Resources  []fleet.ModifiedStatus
+}
+
+// List returns the bundle deployments with modified resources.
+func List(ctx context.Context, c client.Reader, opts Options) ([]BundleDeploymentDrift, error) {
+	selector := labels.Set{}
+	if opts.Namespace != "" {
+		selector[fleet.BundleNamespaceLabel] = opts.Namespace
+	}
+	if opts.Bundle != "" {
+		selector[fleet.BundleLabel] = opts.Bundle
+	}
+	if opts.Cluster != "" {
+		selector[fleet.ClusterLabel] = opts.Cluster
+	}
+
+	bdList := &fleet.BundleDeploymentList{}
+	if err := c.List(ctx, bdList, client.MatchingLabels(selector)); err != nil {
+		return nil, err
+	}
+
+	var result []BundleDeploymentDrift
+	for _, bd := range bdList.Items {
+		if len(bd.Status.ModifiedStatus) == 0 {
+			continue
+		}
+		result = append(result, BundleDeploymentDrift{
+			Bundle:           bd.Labels[fleet.BundleLabel],
+			BundleNamespace:  bd.Labels[fleet.BundleNamespaceLabel],
+			ClusterName:      bd.Labels[fleet.ClusterLabel],
+			ClusterNamespace: bd.Labels[fleet.ClusterNamespaceLabel],
+			Reported:         bd.Spec.CorrectDrift != nil && bd.Spec.CorrectDrift.Report,
+			Incomplete:       bd.Status.IncompleteState,
+			Resources:        bd.Status.ModifiedStatus,
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		return a.BundleNamespace+"/"+a.Bundle+"/"+a.ClusterNamespace+"/"+a.ClusterName <
+			b.BundleNamespace+"/"+b.Bundle+"/"+b.ClusterNamespace+"/"+b.ClusterName
+	})
+	return result, nil
+}
+
+// Print writes the modified resources of each bundle deployment, followed by
+// the comparePatches, which would ignore the changed fields.
+func Print(w io.Writer, drifts []BundleDeploymentDrift) error {
+	p := &printer{w: w}
+	for _, d := range drifts {
+		p.printf("# bundle %s/%s, cluster %s/%s: %d modified resources\n",
+			d.BundleNamespace, d.Bundle, d.ClusterNamespace, d.ClusterName, len(d.Resources))
+		if d.Incomplete {
+			p.printf("# warning: the list of resources is incomplete\n")
+		}
+
+		var patches []fleet.ComparePatch
+		for _, r := range d.Resources {
+			id := strings.TrimSpace(fmt.Sprintf("%s %s %s", r.APIVersion, r.Kind, resourceName(r)))
+			switch {
+			case r.Create && r.Exist:
+				p.printf("%s: not owned by the bundle\n", id)
+			case r.Create:
+				p.printf("%s: missing\n", id)
+			case r.Delete:
+				p.printf("%s: orphaned\n", id)
+			case len(r.Changes) == 0:
+				p.printf("%s: modified\n  patch: %s\n", id, r.Patch)
+			default:
+				p.printf("%s: modified\n", id)
+				pointers := make([]string, 0, len(r.Changes))
+				for _, c := range r.Changes {
+					p.printf("  %s: %s -> %s\n", c.Path, valueOrMissing(c.Live), valueOrMissing(c.Desired))
+					pointers = append(pointers, c.Path)
+				}
+				patches = append(patches, fleet.ComparePatch{
+					APIVersion:   r.APIVersion,
+					Kind:         r.Kind,
+					Namespace:    r.Namespace,
+					Name:         r.Name,
+					JsonPointers: pointers,
+				})
+			}
+		}
+
+		if !d.Reported {
+			p.printf("# enable correctDrift.report to see the changed fields\n")
+		}
+		if len(patches) > 0 {
+			data, err := yaml.Marshal(map[string]fleet.DiffOptions{"diff": {ComparePatches: patches}})
+			if err != nil {
+				return err
+			}
+			p.printf("# to ignore these changes, add to fleet.yaml:\n%s", data)
+		}
+		p.printf("\n")
+	}
+
+	return p.err
+}
+
+// printer keeps the first write error, so it only has to be checked once.
+type printer struct {
+	w   io.Writer
+	err error
+}
+
+func (p *printer) printf(format string, args ...interface{}) {
+	if p.err != nil {
+		return
+	}
+	_, p.err = fmt.Fprintf(p.w, format, args...)
+}
+
+func resourceName(r fleet.ModifiedStatus) string {
+	if r.Namespace == "" {
+		return r.Name
+	}
+	return r.Namespace + "/" + r.Name
+}
+
+func valueOrMissing(v string) string {
+	if v == "" {
+		return "<missing>"
+	}
+	return v
+}
@@ -0,0 +1,119 @@
+package drift
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func bundleDeployment(name, bundle, cluster string, modified ...fleet.ModifiedStatus) *fleet.BundleDeployment {
+	return &fleet.BundleDeployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "cluster-fleet-default-" + cluster,
+			Labels: map[string]string{
+				fleet.BundleLabel:           bundle,
+				fleet.BundleNamespaceLabel:  "fleet-default",
+				fleet.ClusterLabel:          cluster,
+				fleet.ClusterNamespaceLabel: "fleet-default",
+			},
+		},
+		Spec: fleet.BundleDeploymentSpec{
+			CorrectDrift: &fleet.CorrectDrift{Report: true},
+		},
+		Status: fleet.BundleDeploymentStatus{
+			ModifiedStatus: modified,
+		},
+	}
+}
+
+func TestListAndPrint(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := fleet.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	deployment := fleet.ModifiedStatus{
+		APIVersion: "apps/v1",
+		Kind:       "Deployment",
+		Namespace:  "default",
+		Name:       "web",
+		Patch:      `{"spec":{"replicas":2}}`,
+		Changes: []fleet.FieldChange{
+			{Path: "/metadata/labels/team", Live: `"ops"`},
+			{Path: "/spec/replicas", Live: "5", Desired: "2"},
+		},
+	}
+	configMap := fleet.ModifiedStatus{APIVersion: "v1", Kind: "ConfigMap", Namespace: "default", Name: "config", Create: true}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		bundleDeployment("app-b", "app", "b", deployment, configMap),
+		bundleDeployment("app-a", "app", "a", configMap),
+		bundleDeployment("other-a", "other", "a", deployment),
+		bundleDeployment("unmodified", "app", "c"),
+	).Build()
+
+	drifts, err := List(context.TODO(), c, Options{Namespace: "fleet-default", Bundle: "app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(drifts) != 2 || drifts[0].ClusterName != "a" || drifts[1].ClusterName != "b" {
+		t.Fatalf("expected modified bundle deployments of app on clusters a and b, got %+v", drifts)
+	}
+
+	drifts, err = List(context.TODO(), c, Options{Cluster: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(drifts) != 2 || drifts[0].Bundle != "app" || drifts[1].Bundle != "other" {
+		t.Fatalf("expected modified bundle deployments of cluster a, got %+v", drifts)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := Print(buf, []BundleDeploymentDrift{{
+		Bundle:           "app",
+		BundleNamespace:  "fleet-default",
+		ClusterName:      "b",
+		ClusterNamespace: "fleet-default",
+		Reported:         true,
+		Resources:        []fleet.ModifiedStatus{deployment, configMap},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	expected := `# bundle fleet-default/app, cluster fleet-default/b: 2 modified resources
+apps/v1 Deployment default/web: modified
+  /metadata/labels/team: "ops" -> <missing>
+  /spec/replicas: 5 -> 2
+v1 ConfigMap default/config: missing
+# to ignore these changes, add to fleet.yaml:
+diff:
+  comparePatches:
+  - apiVersion: apps/v1
+    jsonPointers:
+    - /metadata/labels/team
+    - /spec/replicas
+    kind: Deployment
+    name: web
+    namespace: default
+
+`
+	if buf.String() != expected {
+		t.Errorf("unexpected output:\n%s", buf.String())
+	}
+
+	deployment.Changes = nil
+	buf.Reset()
+	if err := Print(buf, []BundleDeploymentDrift{{Resources: []fleet.ModifiedStatus{deployment}}}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `patch: {"spec":{"replicas":2}}`) || !strings.Contains(buf.String(), "enable correctDrift.report") {
+		t.Errorf("expected patch and hint without reported changes, got:\n%s", buf.String())
+	}
+}
@@ -63,23 +63,40 @@ func (c *Cloner) CloneRepo(opts *GitCloner) error {
 		return fmt.Errorf("failed to read CA bundle from file for %s: %w", repo(opts), err)
 	}
 
-	if opts.Branch == "" && opts.Revision == "" {
-		opts.Branch = defaultBranch
-		return cloneBranch(opts, auth, caBundle)
+	var keys *trustedKeys
+	if opts.VerificationKeysDir != "" {
+		keys, err = readTrustedKeys(opts.VerificationKeysDir)
+		if err != nil {
+			return fmt.Errorf("failed to read trusted keys for %s: %w", repo(opts), err)
+		}
 	}
 
-	if opts.Branch != "" {
+	var r *git.Repository
+	switch {
+	case opts.Branch == "" && opts.Revision == "":
+		opts.Branch = defaultBranch
+		r, err = cloneBranch(opts, auth, caBundle)
+	case opts.Branch != "":
 		if opts.Revision != "" {
 			logrus.Warn("Using branch for cloning the repo. Revision will be skipped.")
 		}
-		return cloneBranch(opts, auth, caBundle)
+		r, err = cloneBranch(opts, auth, caBundle)
+	default:
+		r, err = cloneRevision(opts, auth, caBundle)
+	}
+	if err != nil {
+		return err
 	}
 
-	return cloneRevision(opts, auth, caBundle)
+	// Refuse to use commits, which are not signed by a trusted key.
+	if keys != nil {
+		return verifyHead(r, keys)
+	}
+	return nil
 }
 
-func cloneBranch(opts *GitCloner, auth transport.AuthMethod, caBundle []byte) error {
-	_, err := plainClone(opts.Path, false, &git.CloneOptions{
+func cloneBranch(opts *GitCloner, auth transport.AuthMethod, caBundle []byte) (*git.Repository, error) {
+	r, err := plainClone(opts.Path, false, &git.CloneOptions{
 		URL:               opts.Repo,
 		Auth:              auth,
 		InsecureSkipTLS:   opts.InsecureSkipTLS,
@@ -90,12 +107,12 @@ func cloneBranch(opts *GitCloner, auth transport.AuthMethod, caBundle []byte) er
 	})
 
 	if err != nil {
-		return fmt.Errorf("failed to clone repo from branch %s: %w", repo(opts), err)
+		return nil, fmt.Errorf("failed to clone repo from branch %s: %w", repo(opts), err)
 	}
-	return nil
+	return r, nil
 }
 
-func cloneRevision(opts *GitCloner, auth transport.AuthMethod, caBundle []byte) error {
+func cloneRevision(opts *GitCloner, auth transport.AuthMethod, caBundle []byte) (*git.Repository, error) {
 	r, err := plainClone(opts.Path, false, &git.CloneOptions{
 		URL:               opts.Repo,
 		Auth:              auth,
@@ -104,22 +121,22 @@ func cloneRevision(opts *GitCloner, auth transport.AuthMethod, caBundle []byte)
 		RecurseSubmodules: git.DefaultSubmoduleRecursionDepth,
 	})
 	if err != nil {
-		return fmt.Errorf("failed to clone repo from revision %s: %w", repo(opts), err)
+		return nil, fmt.Errorf("failed to clone repo from revision %s: %w", repo(opts), err)
 	}
 	h, err := r.ResolveRevision(plumbing.Revision(opts.Revision))
 	if err != nil {
-		return fmt.Errorf("failed to resolve revision %s: %w", repo(opts), err)
+		return nil, fmt.Errorf("failed to resolve revision %s: %w", repo(opts), err)
 	}
 	w, err := r.Worktree()
 	if err != nil {
-		return fmt.Errorf("failed to get filesystem worktree for %s: %w", repo(opts), err)
+		return nil, fmt.Errorf("failed to get filesystem worktree for %s: %w", repo(opts), err)
 	}
 
 	if err := w.Checkout(&git.CheckoutOptions{Hash: *h}); err != nil {
-		return fmt.Errorf("failed to checkout in worktree %s: %w", repo(opts), err)
+		return nil, fmt.Errorf("failed to checkout in worktree %s: %w", repo(opts), err)
 	}
 
-	return nil
+	return r, nil
 }
 
 func getCABundleFromFile(path string) ([]byte, error) {
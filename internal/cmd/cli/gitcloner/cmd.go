@@ -21,6 +21,7 @@ type GitCloner struct {
 	GitHubAppID           int64
 	GitHubAppInstallation int64
 	GitHubAppKeyFile      string
+	VerificationKeysDir   string
 }
 
 var opts *GitCloner
@@ -48,6 +49,7 @@ func NewCmd(gitCloner CloneGit) *cobra.Command {
 	cmd.Flags().Int64Var(&opts.GitHubAppID, "github-app-id", 0, "GitHub App ID")
 	cmd.Flags().Int64Var(&opts.GitHubAppInstallation, "github-app-installation-id", 0, "GitHub App installation ID")
 	cmd.Flags().StringVar(&opts.GitHubAppKeyFile, "github-app-key-file", "", "path to GitHub App private-key PEM")
+	cmd.Flags().StringVar(&opts.VerificationKeysDir, "verification-keys-dir", "", "directory with trusted gpg (*.asc) and ssh (*.pub) keys, the cloned commit must be signed by one of them")
 
 	return cmd
 }
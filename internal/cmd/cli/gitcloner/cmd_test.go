@@ -9,7 +9,8 @@ func TestArgsAreSet(t *testing.T) {
 	cmd := NewCmd(mock)
 	cmd.SetArgs([]string{"test-repo", "test-path", "--branch", "master", "--revision", "v0.1.0", "--ca-bundle-file", "caFile", "--username", "user",
 		"--password-file", "passwordFile", "--ssh-private-key-file", "sshFile", "--insecure-skip-tls", "--github-app-id", "123",
-		"--github-app-installation-id", "456", "--github-app-key-file", "gitHubAppKeyFile"})
+		"--github-app-installation-id", "456", "--github-app-key-file", "gitHubAppKeyFile",
+		"--verification-keys-dir", "keysDir"})
 	err := cmd.Execute()
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -47,6 +48,9 @@ func TestArgsAreSet(t *testing.T) {
 	if mock.opts.GitHubAppKeyFile != "gitHubAppKeyFile" {
 		t.Fatalf("expected GitHubAppKeyFile gitHubAppKeyFile, got %v", mock.opts.GitHubAppKeyFile)
 	}
+	if mock.opts.VerificationKeysDir != "keysDir" {
+		t.Fatalf("expected VerificationKeysDir keysDir, got %v", mock.opts.VerificationKeysDir)
+	}
 }
 
 type clonerMock struct {
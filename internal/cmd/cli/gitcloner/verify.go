@@ -0,0 +1,231 @@
+package gitcloner
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	sshSignatureType      = "SSH SIGNATURE"
+	sshSignatureNamespace = "git"
+)
+
+var (
+	sshSignatureMagic = [6]byte{'S', 'S', 'H', 'S', 'I', 'G'}
+
+	verificationErrorRegex = regexp.MustCompile(`commit ([0-9a-f]{40,64}) failed signature verification: ([^\n]*)`)
+)
+
+// VerificationError is returned by the cloner, if the signature of the
+// cloned commit cannot be verified with the trusted keys.
+type VerificationError struct {
+	Commit string
+	Err    error
+}
+
+func (e *VerificationError) Error() string {
+	return fmt.Sprintf("commit %s failed signature verification: %v", e.Commit, e.Err)
+}
+
+func (e *VerificationError) Unwrap() error {
+	return e.Err
+}
+
+// ParseVerificationError finds a VerificationError in the output of the
+// cloner and returns the commit and the reason.
+func ParseVerificationError(output string) (string, string, bool) {
+	m := verificationErrorRegex.FindStringSubmatch(output)
+	if m == nil {
+		return "", "", false
+	}
+	return m[1], m[2], true
+}
+
+// trustedKeys are the keys, which are trusted to sign commits.
+type trustedKeys struct {
+	pgp openpgp.EntityList
+	ssh []ssh.PublicKey
+}
+
+// readTrustedKeys reads the keys from a directory, into which the
+// verification secret is mounted.
+func readTrustedKeys(dir string) (*trustedKeys, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trusted keys: %w", err)
+	}
+
+	keys := &trustedKeys{}
+	for _, e := range entries {
+		// skip the hidden directories of mounted secrets
+		if e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		ext := filepath.Ext(e.Name())
+		if ext != ".asc" && ext != ".pub" {
+			continue
+		}
+		data, err := readFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		switch ext {
+		case ".asc":
+			entities, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(data))
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse gpg keys in %s: %w", e.Name(), err)
+			}
+			keys.pgp = append(keys.pgp, entities...)
+		case ".pub":
+			for rest := data; len(bytes.TrimSpace(rest)) > 0; {
+				var key ssh.PublicKey
+				key, _, _, rest, err = ssh.ParseAuthorizedKey(rest)
+				if err != nil {
+					return nil, fmt.Errorf("failed to parse ssh keys in %s: %w", e.Name(), err)
+				}
+				keys.ssh = append(keys.ssh, key)
+			}
+		}
+	}
+	if len(keys.pgp) == 0 && len(keys.ssh) == 0 {
+		return nil, errors.New("no trusted keys found")
+	}
+	return keys, nil
+}
+
+// verifyHead verifies the signature of the checked out commit.
+func verifyHead(r *git.Repository, keys *trustedKeys) error {
+	head, err := r.Head()
+	if err != nil {
+		return fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	c, err := r.CommitObject(head.Hash())
+	if err != nil {
+		return fmt.Errorf("failed to get commit %s: %w", head.Hash(), err)
+	}
+	if err := verifyCommit(c, keys); err != nil {
+		return &VerificationError{Commit: c.Hash.String(), Err: err}
+	}
+	return nil
+}
+
+func verifyCommit(c *object.Commit, keys *trustedKeys) error {
+	if c.PGPSignature == "" {
+		return errors.New("commit is not signed")
+	}
+
+	encoded := &plumbing.MemoryObject{}
+	if err := c.EncodeWithoutSignature(encoded); err != nil {
+		return err
+	}
+	er, err := encoded.Reader()
+	if err != nil {
+		return err
+	}
+	message, err := io.ReadAll(er)
+	if err != nil {
+		return err
+	}
+
+	if strings.HasPrefix(c.PGPSignature, "-----BEGIN "+sshSignatureType) {
+		return verifySSHSignature(message, c.PGPSignature, keys.ssh)
+	}
+	if len(keys.pgp) == 0 {
+		return errors.New("commit has a gpg signature, but no trusted gpg keys are configured")
+	}
+	if _, err := openpgp.CheckArmoredDetachedSignature(keys.pgp, bytes.NewReader(message), strings.NewReader(c.PGPSignature), nil); err != nil {
+		return fmt.Errorf("gpg signature is not from a trusted key: %w", err)
+	}
+	return nil
+}
+
+// verifySSHSignature verifies an armored SSH signature of message, as
+// created by "git commit -S" with gpg.format=ssh. See
+// https://github.com/openssh/openssh-portable/blob/master/PROTOCOL.sshsig
+func verifySSHSignature(message []byte, armored string, trusted []ssh.PublicKey) error {
+	if len(trusted) == 0 {
+		return errors.New("commit has an ssh signature, but no trusted ssh keys are configured")
+	}
+
+	block, _ := pem.Decode([]byte(armored))
+	if block == nil || block.Type != sshSignatureType {
+		return errors.New("failed to decode ssh signature")
+	}
+	var sig struct {
+		Magic         [6]byte
+		Version       uint32
+		PublicKey     []byte
+		Namespace     string
+		Reserved      string
+		HashAlgorithm string
+		Signature     []byte
+	}
+	if err := ssh.Unmarshal(block.Bytes, &sig); err != nil {
+		return fmt.Errorf("failed to parse ssh signature: %w", err)
+	}
+	if sig.Magic != sshSignatureMagic || sig.Version != 1 {
+		return errors.New("unsupported ssh signature")
+	}
+	if sig.Namespace != sshSignatureNamespace {
+		return fmt.Errorf("ssh signature has namespace %q, expected %q", sig.Namespace, sshSignatureNamespace)
+	}
+
+	pub, err := ssh.ParsePublicKey(sig.PublicKey)
+	if err != nil {
+		return fmt.Errorf("failed to parse ssh signature key: %w", err)
+	}
+	isTrusted := false
+	for _, k := range trusted {
+		if bytes.Equal(k.Marshal(), pub.Marshal()) {
+			isTrusted = true
+			break
+		}
+	}
+	if !isTrusted {
+		return fmt.Errorf("ssh signature key %s is not trusted", ssh.FingerprintSHA256(pub))
+	}
+
+	var h hash.Hash
+	switch sig.HashAlgorithm {
+	case "sha256":
+		h = sha256.New()
+	case "sha512":
+		h = sha512.New()
+	default:
+		return fmt.Errorf("unsupported ssh signature hash algorithm %q", sig.HashAlgorithm)
+	}
+	h.Write(message)
+
+	signed := ssh.Marshal(struct {
+		Magic         [6]byte
+		Namespace     string
+		Reserved      string
+		HashAlgorithm string
+		Hash          []byte
+	}{sshSignatureMagic, sig.Namespace, sig.Reserved, sig.HashAlgorithm, h.Sum(nil)})
+
+	s := &ssh.Signature{}
+	if err := ssh.Unmarshal(sig.Signature, s); err != nil {
+		return fmt.Errorf("failed to parse ssh signature: %w", err)
+	}
+	if err := pub.Verify(signed, s); err != nil {
+		return fmt.Errorf("invalid ssh signature: %w", err)
+	}
+	return nil
+}
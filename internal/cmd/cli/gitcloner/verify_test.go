@@ -0,0 +1,225 @@
+package gitcloner
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/pem"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"golang.org/x/crypto/ssh"
+)
+
+// sshSigner creates SSH signatures in the format of "ssh-keygen -Y sign".
+type sshSigner struct {
+	signer    ssh.Signer
+	namespace string
+}
+
+func (s sshSigner) Sign(message io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(message)
+	if err != nil {
+		return nil, err
+	}
+	h := sha512.Sum512(data)
+	sig, err := s.signer.Sign(rand.Reader, ssh.Marshal(struct {
+		Magic         [6]byte
+		Namespace     string
+		Reserved      string
+		HashAlgorithm string
+		Hash          []byte
+	}{sshSignatureMagic, s.namespace, "", "sha512", h[:]}))
+	if err != nil {
+		return nil, err
+	}
+	blob := ssh.Marshal(struct {
+		Magic         [6]byte
+		Version       uint32
+		PublicKey     []byte
+		Namespace     string
+		Reserved      string
+		HashAlgorithm string
+		Signature     []byte
+	}{sshSignatureMagic, 1, s.signer.PublicKey().Marshal(), s.namespace, "", "sha512", ssh.Marshal(sig)})
+	return pem.EncodeToMemory(&pem.Block{Type: sshSignatureType, Bytes: blob}), nil
+}
+
+func newSSHSigner(t *testing.T) ssh.Signer {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer
+}
+
+func newPGPEntity(t *testing.T) *openpgp.Entity {
+	t.Helper()
+	e, err := openpgp.NewEntity("fleet", "", "fleet@example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+// commit creates a repository with a single commit, signed with opts.
+func commit(t *testing.T, opts git.CommitOptions) *git.Repository {
+	t.Helper()
+	fs := memfs.New()
+	r, err := git.Init(memory.NewStorage(), fs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := fs.Create("configmap.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.Write([]byte("kind: ConfigMap"))
+	f.Close()
+
+	w, err := r.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Add("configmap.yaml"); err != nil {
+		t.Fatal(err)
+	}
+	opts.Author = &object.Signature{Name: "fleet", Email: "fleet@example.com", When: time.Now()}
+	if _, err := w.Commit("add configmap", &opts); err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestVerifyHead(t *testing.T) {
+	trustedSSH := newSSHSigner(t)
+	trustedPGP := newPGPEntity(t)
+	keys := &trustedKeys{
+		pgp: openpgp.EntityList{trustedPGP},
+		ssh: []ssh.PublicKey{trustedSSH.PublicKey()},
+	}
+
+	tests := map[string]struct {
+		opts    git.CommitOptions
+		keys    *trustedKeys
+		wantErr string
+	}{
+		"ssh signature": {
+			opts: git.CommitOptions{Signer: sshSigner{signer: trustedSSH, namespace: "git"}},
+			keys: keys,
+		},
+		"gpg signature": {
+			opts: git.CommitOptions{SignKey: trustedPGP},
+			keys: keys,
+		},
+		"unsigned": {
+			keys:    keys,
+			wantErr: "commit is not signed",
+		},
+		"untrusted ssh key": {
+			opts:    git.CommitOptions{Signer: sshSigner{signer: newSSHSigner(t), namespace: "git"}},
+			keys:    keys,
+			wantErr: "is not trusted",
+		},
+		"wrong ssh namespace": {
+			opts:    git.CommitOptions{Signer: sshSigner{signer: trustedSSH, namespace: "file"}},
+			keys:    keys,
+			wantErr: `ssh signature has namespace "file"`,
+		},
+		"untrusted gpg key": {
+			opts:    git.CommitOptions{SignKey: newPGPEntity(t)},
+			keys:    keys,
+			wantErr: "gpg signature is not from a trusted key",
+		},
+		"no trusted gpg keys": {
+			opts:    git.CommitOptions{SignKey: trustedPGP},
+			keys:    &trustedKeys{ssh: keys.ssh},
+			wantErr: "no trusted gpg keys are configured",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := commit(t, tt.opts)
+			err := verifyHead(r, tt.keys)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			var verr *VerificationError
+			if !errors.As(err, &verr) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected verification error containing %q, got %v", tt.wantErr, err)
+			}
+			head, _ := r.Head()
+			commit, reason, ok := ParseVerificationError("Error: " + err.Error() + "\n")
+			if !ok || commit != head.Hash().String() || !strings.Contains(reason, tt.wantErr) {
+				t.Errorf("expected to parse verification error, got %q %q %v", commit, reason, ok)
+			}
+		})
+	}
+}
+
+func TestReadTrustedKeys(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := readTrustedKeys(dir); err == nil {
+		t.Errorf("expected error without keys")
+	}
+
+	var pub bytes.Buffer
+	w, err := armor.Encode(&pub, openpgp.PublicKeyType, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := newPGPEntity(t).Serialize(w); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+
+	authorizedKeys := append(ssh.MarshalAuthorizedKey(newSSHSigner(t).PublicKey()), ssh.MarshalAuthorizedKey(newSSHSigner(t).PublicKey())...)
+	for name, data := range map[string][]byte{
+		"alice.asc":    pub.Bytes(),
+		"team.pub":     authorizedKeys,
+		"README":       []byte("ignored"),
+		".hidden.asc":  []byte("ignored"),
+		"invalid.data": []byte("ignored"),
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	keys, err := readTrustedKeys(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys.pgp) != 1 || len(keys.ssh) != 2 {
+		t.Errorf("expected one gpg and two ssh keys, got %d and %d", len(keys.pgp), len(keys.ssh))
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "broken.pub"), []byte("ssh-ed25519 broken"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readTrustedKeys(dir); err == nil {
+		t.Errorf("expected error for invalid ssh key")
+	}
+}
@@ -69,7 +69,7 @@ func Match(ctx context.Context, opts *Options) error {
 	if opts.Target == "" {
 		m := bm.Match(opts.ClusterName, map[string]map[string]string{
 			opts.ClusterGroup: opts.ClusterGroupLabels,
-		}, opts.ClusterLabels)
+		}, opts.ClusterLabels, nil)
 		return printMatch(ctx, bundle, m, opts.Output)
 	}
 
@@ -89,7 +89,11 @@ func printMatch(ctx context.Context, bundle *fleet.Bundle, target *fleet.BundleT
 
 	manifest := manifest.New(bundle.Spec.Resources)
 
-	rel, err := helmdeployer.Template(ctx, bundle.Name, manifest, opts, "")
+	capabilities, err := helmdeployer.Capabilities("", nil)
+	if err != nil {
+		return err
+	}
+	rel, err := helmdeployer.Template(ctx, bundle.Name, manifest, opts, capabilities)
 	if err != nil {
 		return err
 	}
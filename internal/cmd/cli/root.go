@@ -28,6 +28,9 @@ func App() *cobra.Command {
 
 		NewTarget(),
 		NewDeploy(),
+		NewDiff(),
+		NewDrift(),
+		NewStatus(),
 		gitcloner.NewCmd(gitcloner.New()),
 	)
 
@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/labels"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	command "github.com/rancher/fleet/internal/cmd"
+	"github.com/rancher/fleet/internal/cmd/cli/status"
+)
+
+// NewStatus returns a subcommand to show the status of GitRepos, bundles and clusters.
+func NewStatus() *cobra.Command {
+	return command.Command(&Status{}, cobra.Command{
+		Use:   "status [flags]",
+		Short: "Show the status of GitRepos, their bundles and clusters as a tree",
+		Long: `Show the status of GitRepos, their bundles and clusters as a tree.
+
+Each GitRepo lists its bundles, each bundle the clusters it is deployed to and
+each cluster the resources, which are not ready or modified, with their
+messages. Bundles, which were not created from a GitRepo, are listed after the
+GitRepos.
+
+Use --state to only show bundle deployments in the given states, e.g.
+--state ErrApplied,NotReady to find out what is broken.`,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	})
+}
+
+type Status struct {
+	FleetClient
+	AllNamespaces bool     `usage:"Show GitRepos and bundles in all namespaces" short:"A" name:"all-namespaces"`
+	Selector      string   `usage:"Label selector for GitRepos and bundles" short:"l"`
+	State         []string `usage:"Only show bundle deployments in these states, e.g. ErrApplied,NotReady"`
+	Output        string   `usage:"Output format, one of tree, json, yaml" short:"o" default:"tree"`
+	Watch         bool     `usage:"Print the status again when it changes" short:"w"`
+}
+
+func (s *Status) PersistentPre(_ *cobra.Command, _ []string) error {
+	if err := s.SetupDebug(); err != nil {
+		return fmt.Errorf("failed to set up debug logging: %w", err)
+	}
+	return nil
+}
+
+func (s *Status) Run(cmd *cobra.Command, args []string) error {
+	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&zopts)))
+	ctx := log.IntoContext(cmd.Context(), ctrl.Log)
+
+	selector, err := labels.Parse(s.Selector)
+	if err != nil {
+		return fmt.Errorf("invalid selector: %w", err)
+	}
+	states, err := status.ParseStates(s.State)
+	if err != nil {
+		return err
+	}
+	opts := status.Options{
+		Namespace: s.Namespace,
+		Selector:  selector,
+		States:    states,
+	}
+	if s.AllNamespaces {
+		opts.Namespace = ""
+	}
+
+	cfg := ctrl.GetConfigOrDie()
+	c, err := client.NewWithWatch(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if !s.Watch {
+		tree, err := status.Get(ctx, c, opts)
+		if err != nil {
+			return err
+		}
+		return status.Print(out, tree, s.Output)
+	}
+
+	first := true
+	return status.Watch(ctx, c, opts, func(tree *status.Tree) error {
+		if !first {
+			separator := "\n"
+			if s.Output == status.OutputYAML {
+				separator = "---\n"
+			}
+			if _, err := fmt.Fprint(out, separator); err != nil {
+				return err
+			}
+		}
+		first = false
+		return status.Print(out, tree, s.Output)
+	})
+}
@@ -0,0 +1,120 @@
+package status
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Output formats supported by Print.
+const (
+	OutputTree = "tree"
+	OutputJSON = "json"
+	OutputYAML = "yaml"
+)
+
+// Print writes the tree in the given format.
+func Print(w io.Writer, tree *Tree, format string) error {
+	switch format {
+	case OutputJSON:
+		data, err := json.MarshalIndent(tree, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s\n", data)
+		return err
+	case OutputYAML:
+		data, err := yaml.Marshal(tree)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	case OutputTree, "":
+		return printTree(w, tree)
+	default:
+		return fmt.Errorf("unknown output format %q, must be one of %s, %s, %s", format, OutputTree, OutputJSON, OutputYAML)
+	}
+}
+
+func printTree(w io.Writer, tree *Tree) error {
+	p := &printer{w: w}
+	if len(tree.GitRepos) == 0 && len(tree.Bundles) == 0 {
+		p.printf("No resources found\n")
+		return p.err
+	}
+
+	for _, repo := range tree.GitRepos {
+		p.printf("gitrepo %s/%s%s%s%s\n", repo.Namespace, repo.Name,
+			state(repo.State), prefix(" commit ", short(repo.Commit)), prefix(": ", repo.Message))
+		for _, b := range repo.Bundles {
+			p.printBundle("  ", b)
+		}
+	}
+	for _, b := range tree.Bundles {
+		p.printBundle("", b)
+	}
+	return p.err
+}
+
+func (p *printer) printBundle(indent string, b Bundle) {
+	p.printf("%sbundle %s/%s%s%s%s\n", indent, b.Namespace, b.Name,
+		state(b.State), prefix(" ", b.ReadyClusters), prefix(": ", b.Message))
+	for _, c := range b.Clusters {
+		p.printf("%s  cluster %s/%s%s%s%s\n", indent, c.Namespace, c.Name,
+			wrap(" (", c.DisplayName, ")"), state(string(c.State)), prefix(": ", c.Message))
+		for _, r := range c.Resources {
+			id := strings.TrimSpace(fmt.Sprintf("%s %s %s", r.APIVersion, r.Kind, resourceName(r)))
+			p.printf("%s    %s: %s\n", indent, id, strings.Join(append([]string{r.State}, r.Messages...), ", "))
+		}
+		if c.Incomplete {
+			p.printf("%s    (list of resources is incomplete)\n", indent)
+		}
+	}
+}
+
+// printer keeps the first write error, so it only has to be checked once.
+type printer struct {
+	w   io.Writer
+	err error
+}
+
+func (p *printer) printf(format string, args ...interface{}) {
+	if p.err != nil {
+		return
+	}
+	_, p.err = fmt.Fprintf(p.w, format, args...)
+}
+
+func resourceName(r Resource) string {
+	if r.Namespace == "" {
+		return r.Name
+	}
+	return r.Namespace + "/" + r.Name
+}
+
+func state(s string) string {
+	return wrap(" [", s, "]")
+}
+
+func prefix(p, s string) string {
+	return wrap(p, s, "")
+}
+
+func wrap(before, s, after string) string {
+	if s == "" {
+		return ""
+	}
+	return before + s + after
+}
+
+// short abbreviates a commit hash, like git does.
+func short(commit string) string {
+	if len(commit) > 7 {
+		return commit[:7]
+	}
+	return commit
+}
@@ -0,0 +1,284 @@
+// Package status collects the state of GitRepos, their bundles and the
+// bundle deployments on each cluster, down to the non-ready resources.
+//
+// It is used by the "status" sub command of the fleet CLI, to show what is
+// broken without querying the status of each resource.
+package status
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/rancher/fleet/internal/cmd/controller/summary"
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	"github.com/rancher/wrangler/v3/pkg/condition"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// clusterDisplayNameLabel is set on clusters imported by Rancher.
+const clusterDisplayNameLabel = "management.cattle.io/cluster-display-name"
+
+type Options struct {
+	// Namespace of the GitRepos and bundles, all namespaces if empty.
+	Namespace string
+	// Selector filters GitRepos and bundles by their labels. A bundle of
+	// a GitRepo is shown, if either of them matches.
+	Selector labels.Selector
+	// States limits the result to bundle deployments in these states.
+	States []fleet.BundleState
+}
+
+// Tree is the status of the GitRepos and their bundles. Bundles, which were
+// not created from a GitRepo, e.g. by a HelmOp, are listed separately.
+type Tree struct {
+	GitRepos []GitRepo `json:"gitRepos,omitempty"`
+	Bundles  []Bundle  `json:"bundles,omitempty"`
+}
+
+type GitRepo struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Commit    string `json:"commit,omitempty"`
+	State     string `json:"state,omitempty"`
+	// Message is the message of the GitRepo's Ready condition, if it is
+	// not ready.
+	Message string   `json:"message,omitempty"`
+	Bundles []Bundle `json:"bundles,omitempty"`
+}
+
+type Bundle struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	State     string `json:"state,omitempty"`
+	// ReadyClusters is in the form "%d/%d", like the bundle's display.
+	ReadyClusters string    `json:"readyClusters,omitempty"`
+	Message       string    `json:"message,omitempty"`
+	Clusters      []Cluster `json:"clusters,omitempty"`
+}
+
+// Cluster is the state of a bundle deployment on a cluster.
+type Cluster struct {
+	Namespace   string            `json:"namespace"`
+	Name        string            `json:"name"`
+	DisplayName string            `json:"displayName,omitempty"`
+	State       fleet.BundleState `json:"state"`
+	Message     string            `json:"message,omitempty"`
+	// Incomplete is true if the agent truncated the list of resources.
+	Incomplete bool       `json:"incomplete,omitempty"`
+	Resources  []Resource `json:"resources,omitempty"`
+}
+
+// Resource is a non-ready or modified resource of a bundle deployment.
+type Resource struct {
+	APIVersion string   `json:"apiVersion,omitempty"`
+	Kind       string   `json:"kind"`
+	Namespace  string   `json:"namespace,omitempty"`
+	Name       string   `json:"name"`
+	State      string   `json:"state"`
+	Messages   []string `json:"messages,omitempty"`
+}
+
+// Get reads GitRepos, bundles, bundle deployments and clusters and returns
+// their status as a tree.
+func Get(ctx context.Context, c client.Reader, opts Options) (*Tree, error) {
+	if opts.Selector == nil {
+		opts.Selector = labels.Everything()
+	}
+
+	repos := &fleet.GitRepoList{}
+	if err := c.List(ctx, repos, listOptions(opts, repos)...); err != nil {
+		return nil, err
+	}
+	bundles := &fleet.BundleList{}
+	if err := c.List(ctx, bundles, listOptions(opts, bundles)...); err != nil {
+		return nil, err
+	}
+	bds := &fleet.BundleDeploymentList{}
+	if err := c.List(ctx, bds, listOptions(opts, bds)...); err != nil {
+		return nil, err
+	}
+	clusters := &fleet.ClusterList{}
+	if err := c.List(ctx, clusters, listOptions(opts, clusters)...); err != nil {
+		return nil, err
+	}
+
+	return build(opts, repos.Items, bundles.Items, bds.Items, clusters.Items), nil
+}
+
+// listOptions returns the options to list or watch the resources of the
+// tree. Bundle deployments are in the cluster namespaces and are selected by
+// their bundle's namespace instead.
+func listOptions(opts Options, list client.ObjectList) []client.ListOption {
+	if opts.Namespace == "" {
+		return nil
+	}
+	if _, ok := list.(*fleet.BundleDeploymentList); ok {
+		return []client.ListOption{client.MatchingLabels{fleet.BundleNamespaceLabel: opts.Namespace}}
+	}
+	return []client.ListOption{client.InNamespace(opts.Namespace)}
+}
+
+// build assembles the tree (pure function).
+func build(opts Options, repos []fleet.GitRepo, bundles []fleet.Bundle, bds []fleet.BundleDeployment, clusters []fleet.Cluster) *Tree {
+	displayNames := map[string]string{}
+	for _, c := range clusters {
+		displayNames[c.Namespace+"/"+c.Name] = c.Labels[clusterDisplayNameLabel]
+	}
+
+	bdsByBundle := map[string][]fleet.BundleDeployment{}
+	for _, bd := range bds {
+		key := bd.Labels[fleet.BundleNamespaceLabel] + "/" + bd.Labels[fleet.BundleLabel]
+		bdsByBundle[key] = append(bdsByBundle[key], bd)
+	}
+
+	repoMatches := map[string]bool{}
+	for _, repo := range repos {
+		repoMatches[repo.Namespace+"/"+repo.Name] = opts.Selector.Matches(labels.Set(repo.Labels))
+	}
+
+	tree := &Tree{}
+	bundlesByRepo := map[string][]Bundle{}
+	for _, b := range bundles {
+		repoKey := ""
+		if repo := b.Labels[fleet.RepoLabel]; repo != "" {
+			repoKey = b.Namespace + "/" + repo
+		}
+		if !opts.Selector.Matches(labels.Set(b.Labels)) && !repoMatches[repoKey] {
+			continue
+		}
+
+		bundle := Bundle{
+			Namespace:     b.Namespace,
+			Name:          b.Name,
+			State:         b.Status.Display.State,
+			ReadyClusters: b.Status.Display.ReadyClusters,
+		}
+		if !condition.Cond(fleet.Ready).IsTrue(&b) {
+			bundle.Message = condition.Cond(fleet.Ready).GetMessage(&b)
+		}
+		for _, bd := range bdsByBundle[b.Namespace+"/"+b.Name] {
+			cluster := clusterStatus(&bd)
+			if len(opts.States) > 0 && !slices.Contains(opts.States, cluster.State) {
+				continue
+			}
+			cluster.DisplayName = displayNames[cluster.Namespace+"/"+cluster.Name]
+			bundle.Clusters = append(bundle.Clusters, cluster)
+		}
+		if len(opts.States) > 0 && len(bundle.Clusters) == 0 {
+			continue
+		}
+		sort.Slice(bundle.Clusters, func(i, j int) bool {
+			return bundle.Clusters[i].Namespace+"/"+bundle.Clusters[i].Name < bundle.Clusters[j].Namespace+"/"+bundle.Clusters[j].Name
+		})
+
+		if _, ok := repoMatches[repoKey]; ok {
+			bundlesByRepo[repoKey] = append(bundlesByRepo[repoKey], bundle)
+		} else {
+			tree.Bundles = append(tree.Bundles, bundle)
+		}
+	}
+
+	for _, r := range repos {
+		key := r.Namespace + "/" + r.Name
+		if len(bundlesByRepo[key]) == 0 && (len(opts.States) > 0 || !repoMatches[key]) {
+			continue
+		}
+		repo := GitRepo{
+			Namespace: r.Namespace,
+			Name:      r.Name,
+			Commit:    r.Status.Commit,
+			State:     r.Status.Display.State,
+			Bundles:   bundlesByRepo[key],
+		}
+		if !condition.Cond(fleet.Ready).IsTrue(&r) {
+			repo.Message = condition.Cond(fleet.Ready).GetMessage(&r)
+		}
+		tree.GitRepos = append(tree.GitRepos, repo)
+	}
+
+	sort.Slice(tree.GitRepos, func(i, j int) bool {
+		return tree.GitRepos[i].Namespace+"/"+tree.GitRepos[i].Name < tree.GitRepos[j].Namespace+"/"+tree.GitRepos[j].Name
+	})
+	for _, repo := range tree.GitRepos {
+		sortBundles(repo.Bundles)
+	}
+	sortBundles(tree.Bundles)
+
+	return tree
+}
+
+func sortBundles(bundles []Bundle) {
+	sort.Slice(bundles, func(i, j int) bool {
+		return bundles[i].Namespace+"/"+bundles[i].Name < bundles[j].Namespace+"/"+bundles[j].Name
+	})
+}
+
+// clusterStatus returns the state of the bundle deployment with its
+// non-ready and modified resources.
+func clusterStatus(bd *fleet.BundleDeployment) Cluster {
+	cluster := Cluster{
+		Namespace:  bd.Labels[fleet.ClusterNamespaceLabel],
+		Name:       bd.Labels[fleet.ClusterLabel],
+		State:      summary.GetDeploymentState(bd),
+		Incomplete: bd.Status.IncompleteState,
+	}
+	if cluster.State != fleet.Ready {
+		cluster.Message = summary.MessageFromDeployment(bd)
+	}
+
+	for _, r := range bd.Status.NonReadyStatus {
+		cluster.Resources = append(cluster.Resources, Resource{
+			APIVersion: r.APIVersion,
+			Kind:       r.Kind,
+			Namespace:  r.Namespace,
+			Name:       r.Name,
+			State:      r.Summary.State,
+			Messages:   r.Summary.Message,
+		})
+	}
+	for _, r := range bd.Status.ModifiedStatus {
+		state := "modified"
+		switch {
+		case r.Create && r.Exist:
+			state = "not owned"
+		case r.Create:
+			state = "missing"
+		case r.Delete:
+			state = "orphaned"
+		}
+		cluster.Resources = append(cluster.Resources, Resource{
+			APIVersion: r.APIVersion,
+			Kind:       r.Kind,
+			Namespace:  r.Namespace,
+			Name:       r.Name,
+			State:      state,
+		})
+	}
+	return cluster
+}
+
+// ParseStates parses a list of bundle states, e.g. from a comma separated
+// flag. The states are case insensitive.
+func ParseStates(values []string) ([]fleet.BundleState, error) {
+	known := make([]string, 0, len(fleet.StateRank))
+	for state := range fleet.StateRank {
+		known = append(known, string(state))
+	}
+	sort.Strings(known)
+
+	var states []fleet.BundleState
+	for _, v := range values {
+		i := slices.IndexFunc(known, func(state string) bool { return strings.EqualFold(state, v) })
+		if i < 0 {
+			return nil, fmt.Errorf("invalid state %q, must be one of %s", v, strings.Join(known, ", "))
+		}
+		states = append(states, fleet.BundleState(known[i]))
+	}
+	return states, nil
+}
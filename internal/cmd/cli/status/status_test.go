@@ -0,0 +1,247 @@
+package status
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+	fleetsummary "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1/summary"
+
+	"github.com/rancher/wrangler/v3/pkg/genericcondition"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func bundle(name, repo string, ready bool) *fleet.Bundle {
+	b := &fleet.Bundle{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "fleet-default",
+			Labels:    map[string]string{},
+		},
+		Status: fleet.BundleStatus{
+			Display: fleet.BundleDisplay{ReadyClusters: "1/2", State: "NotReady"},
+			Conditions: []genericcondition.GenericCondition{{
+				Type: string(fleet.Ready), Status: corev1.ConditionFalse, Message: "NotReady(1) [Cluster fleet-default/b]",
+			}},
+		},
+	}
+	if repo != "" {
+		b.Labels[fleet.RepoLabel] = repo
+	}
+	if ready {
+		b.Status.Display = fleet.BundleDisplay{ReadyClusters: "2/2"}
+		b.Status.Conditions[0].Status = corev1.ConditionTrue
+	}
+	return b
+}
+
+func bundleDeployment(bundle, cluster string, ready bool) *fleet.BundleDeployment {
+	bd := &fleet.BundleDeployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      bundle,
+			Namespace: "cluster-fleet-default-" + cluster,
+			Labels: map[string]string{
+				fleet.BundleLabel:           bundle,
+				fleet.BundleNamespaceLabel:  "fleet-default",
+				fleet.ClusterLabel:          cluster,
+				fleet.ClusterNamespaceLabel: "fleet-default",
+			},
+		},
+		Spec: fleet.BundleDeploymentSpec{DeploymentID: "s-1", StagedDeploymentID: "s-1"},
+		Status: fleet.BundleDeploymentStatus{
+			AppliedDeploymentID: "s-1",
+			Ready:               true,
+			NonModified:         true,
+		},
+	}
+	if !ready {
+		bd.Status.Ready = false
+		bd.Status.NonReadyStatus = []fleet.NonReadyStatus{{
+			APIVersion: "apps/v1",
+			Kind:       "Deployment",
+			Namespace:  "default",
+			Name:       "web",
+			Summary:    fleetsummary.Summary{State: "in-progress", Message: []string{"Available: 0/1"}},
+		}}
+		bd.Status.Conditions = []genericcondition.GenericCondition{{
+			Type: "Monitored", Status: corev1.ConditionFalse, Message: "deployment web not available",
+		}}
+	}
+	return bd
+}
+
+func newClient(t *testing.T, objs ...client.Object) client.WithWatch {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := fleet.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := &fleet.GitRepo{
+		ObjectMeta: metav1.ObjectMeta{Name: "apps", Namespace: "fleet-default", Labels: map[string]string{"team": "web"}},
+	}
+	repo.Status.Commit = "0123456789abcdef"
+	repo.Status.Display.State = "NotReady"
+	cluster := &fleet.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "b",
+			Namespace: "fleet-default",
+			Labels:    map[string]string{clusterDisplayNameLabel: "production"},
+		},
+	}
+	objs = append(objs,
+		repo, cluster,
+		bundle("apps-web", "apps", false),
+		bundle("helm-db", "", true),
+		bundleDeployment("apps-web", "a", true),
+		bundleDeployment("apps-web", "b", false),
+		bundleDeployment("helm-db", "a", true),
+	)
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+}
+
+func TestGetAndPrint(t *testing.T) {
+	tree, err := Get(context.Background(), newClient(t), Options{Namespace: "fleet-default"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tree.GitRepos) != 1 || len(tree.GitRepos[0].Bundles) != 1 {
+		t.Fatalf("expected one GitRepo with one bundle, got %+v", tree.GitRepos)
+	}
+	if len(tree.Bundles) != 1 || tree.Bundles[0].Name != "helm-db" {
+		t.Fatalf("expected the bundle without GitRepo to be listed separately, got %+v", tree.Bundles)
+	}
+	clusters := tree.GitRepos[0].Bundles[0].Clusters
+	if len(clusters) != 2 || clusters[0].Name != "a" || clusters[1].Name != "b" {
+		t.Fatalf("expected clusters a and b, got %+v", clusters)
+	}
+	if clusters[1].State != fleet.NotReady || clusters[1].DisplayName != "production" || len(clusters[1].Resources) != 1 {
+		t.Errorf("expected cluster b to be not ready with one resource, got %+v", clusters[1])
+	}
+
+	var buf bytes.Buffer
+	if err := Print(&buf, tree, OutputTree); err != nil {
+		t.Fatal(err)
+	}
+	expected := `gitrepo fleet-default/apps [NotReady] commit 0123456
+  bundle fleet-default/apps-web [NotReady] 1/2: NotReady(1) [Cluster fleet-default/b]
+    cluster fleet-default/a [Ready]
+    cluster fleet-default/b (production) [NotReady]: deployment web not available
+      apps/v1 Deployment default/web: in-progress, Available: 0/1
+bundle fleet-default/helm-db 2/2
+  cluster fleet-default/a [Ready]
+`
+	if buf.String() != expected {
+		t.Errorf("unexpected output:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+
+	buf.Reset()
+	if err := Print(&buf, tree, OutputYAML); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "displayName: production") {
+		t.Errorf("expected yaml output to contain the display name, got:\n%s", buf.String())
+	}
+
+	if err := Print(&buf, tree, "wide"); err == nil {
+		t.Errorf("expected error for unknown output format")
+	}
+}
+
+func TestGetFiltered(t *testing.T) {
+	c := newClient(t)
+
+	tree, err := Get(context.Background(), c, Options{States: []fleet.BundleState{fleet.NotReady}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tree.Bundles) != 0 || len(tree.GitRepos) != 1 {
+		t.Fatalf("expected only the GitRepo with a not ready bundle, got %+v", tree)
+	}
+	if clusters := tree.GitRepos[0].Bundles[0].Clusters; len(clusters) != 1 || clusters[0].Name != "b" {
+		t.Errorf("expected only cluster b, got %+v", clusters)
+	}
+
+	tree, err = Get(context.Background(), c, Options{Selector: labels.SelectorFromSet(labels.Set{"team": "web"})})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tree.Bundles) != 0 || len(tree.GitRepos) != 1 || len(tree.GitRepos[0].Bundles) != 1 {
+		t.Errorf("expected the bundles of the matching GitRepo, got %+v", tree)
+	}
+
+	tree, err = Get(context.Background(), c, Options{Namespace: "other"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tree.Bundles) != 0 || len(tree.GitRepos) != 0 {
+		t.Errorf("expected no resources in other namespace, got %+v", tree)
+	}
+}
+
+func TestParseStates(t *testing.T) {
+	states, err := ParseStates([]string{"errapplied", "NotReady"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(states) != 2 || states[0] != fleet.ErrApplied || states[1] != fleet.NotReady {
+		t.Errorf("unexpected states %v", states)
+	}
+
+	if _, err := ParseStates([]string{"Broken"}); err == nil {
+		t.Errorf("expected error for unknown state")
+	}
+}
+
+func TestWatch(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	c := newClient(t)
+
+	trees := make(chan *Tree)
+	errs := make(chan error, 1)
+	go func() {
+		errs <- Watch(ctx, c, Options{States: []fleet.BundleState{fleet.NotReady}}, func(tree *Tree) error {
+			trees <- tree
+			return nil
+		})
+	}()
+
+	tree := <-trees
+	if len(tree.GitRepos) != 1 {
+		t.Fatalf("expected the not ready GitRepo, got %+v", tree)
+	}
+
+	bd := &fleet.BundleDeployment{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: "cluster-fleet-default-b", Name: "apps-web"}, bd); err != nil {
+		t.Fatal(err)
+	}
+	bd.Status.Ready = true
+	if err := c.Update(ctx, bd); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case tree = <-trees:
+		if len(tree.GitRepos) != 0 || len(tree.Bundles) != 0 {
+			t.Errorf("expected empty tree after the bundle deployment became ready, got %+v", tree)
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for the tree to change")
+	}
+
+	cancel()
+	if err := <-errs; err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
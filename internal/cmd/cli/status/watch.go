@@ -0,0 +1,112 @@
+package status
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	apiwatch "k8s.io/apimachinery/pkg/watch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// debounce delays rebuilding the tree after a change, as a single rollout
+// updates many resources in a short time.
+const debounce = time.Second
+
+// Watch calls print with the initial tree and again every time the tree
+// changes, until the context is cancelled.
+func Watch(ctx context.Context, c client.WithWatch, opts Options, print func(*Tree) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	changed := make(chan struct{}, 1)
+	errs := make(chan error, 4)
+	for _, list := range []client.ObjectList{
+		&fleet.GitRepoList{},
+		&fleet.BundleList{},
+		&fleet.BundleDeploymentList{},
+		&fleet.ClusterList{},
+	} {
+		// watches are started before the initial tree is built, so no
+		// change is missed
+		listOpts := listOptions(opts, list)
+		w, err := c.Watch(ctx, list, listOpts...)
+		if err != nil {
+			return err
+		}
+		go func() {
+			errs <- watch(ctx, c, w, list, listOpts, changed)
+		}()
+	}
+
+	var last []byte
+	update := func() error {
+		tree, err := Get(ctx, c, opts)
+		if err != nil {
+			return err
+		}
+		data, err := json.Marshal(tree)
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(data, last) {
+			return nil
+		}
+		last = data
+		return print(tree)
+	}
+
+	if err := update(); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-errs:
+			return err
+		case <-changed:
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(debounce):
+			}
+			if err := update(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// watch signals changes of the listed resources. It restarts the watch when
+// the API server closes it and returns when the context is cancelled.
+func watch(ctx context.Context, c client.WithWatch, w apiwatch.Interface, list client.ObjectList, opts []client.ListOption, changed chan<- struct{}) error {
+	logger := log.FromContext(ctx).WithName("status-watch")
+	for {
+		for range w.ResultChan() {
+			select {
+			case changed <- struct{}{}:
+			default:
+			}
+		}
+		w.Stop()
+		if ctx.Err() != nil {
+			return nil
+		}
+		logger.V(1).Info("Restarting watch", "list", fmt.Sprintf("%T", list))
+
+		var err error
+		w, err = c.Watch(ctx, list, opts...)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+	}
+}
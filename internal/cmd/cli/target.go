@@ -156,7 +156,7 @@ func (t *Target) Run(cmd *cobra.Command, args []string) error {
 	// create this many deployments if the bundle is new.
 	bundle.Status.MaxNew = len(matchedTargets)
 
-	if err := target.UpdatePartitions(&bundle.Status, matchedTargets); err != nil {
+	if _, err := target.UpdatePartitions(ctx, &bundle.Status, nil, matchedTargets, nil, nil); err != nil {
 		return err
 	}
 	for _, target := range matchedTargets {
@@ -0,0 +1,271 @@
+package denywindow
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	icalDate     = "20060102"
+	icalDateTime = "20060102T150405"
+)
+
+// ErrUnsupportedRecurrence is returned for recurrence rules, which do not
+// recur every year, e.g. "FREQ=WEEKLY" or "FREQ=YEARLY;BYMONTH=12".
+var ErrUnsupportedRecurrence = errors.New("unsupported recurrence")
+
+// Event is a calendar event. It is a source of deny windows.
+type Event struct {
+	Summary string
+	Start   time.Time
+	End     time.Time
+	// Yearly events recur every year, e.g. public holidays, until the
+	// optional Until time or for the optional Count of occurrences.
+	Yearly bool
+	Until  time.Time
+	Count  int
+}
+
+// Next implements Source.
+func (e Event) Next(t time.Time) (Window, bool) {
+	if !e.Yearly {
+		return e.occurrence(0), e.End.After(t)
+	}
+
+	// start with the previous year's occurrence, which might still last
+	for i := max(0, t.Year()-e.Start.Year()-1); ; i++ {
+		if e.Count > 0 && i >= e.Count {
+			return Window{}, false
+		}
+		w := e.occurrence(i)
+		if !e.Until.IsZero() && w.Start.After(e.Until) {
+			return Window{}, false
+		}
+		if w.End.After(t) {
+			return w, true
+		}
+	}
+}
+
+func (e Event) occurrence(year int) Window {
+	return Window{
+		Start: e.Start.AddDate(year, 0, 0),
+		End:   e.End.AddDate(year, 0, 0),
+		Event: e.Summary,
+	}
+}
+
+// ParseCalendar parses the events of an iCal file (RFC 5545). Times without
+// a time zone are in the location. Recurring events are only supported, if
+// they recur every year, optionally limited by COUNT or UNTIL. Events with
+// other recurrence rules are skipped, the summaries of skipped events are
+// returned along with the supported events.
+func ParseCalendar(data string, location *time.Location) ([]Event, []string, error) {
+	var (
+		events      []Event
+		skipped     []string
+		event       *Event
+		allDay      bool
+		duration    string
+		unsupported bool
+	)
+	for _, line := range unfold(data) {
+		name, params, value, ok := parseLine(line)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case name == "BEGIN" && value == "VEVENT":
+			event = &Event{}
+			allDay, duration, unsupported = false, "", false
+		case event == nil:
+			continue
+		case name == "END" && value == "VEVENT":
+			if unsupported {
+				skipped = append(skipped, event.Summary)
+				event = nil
+				continue
+			}
+			if event.Start.IsZero() {
+				return nil, nil, fmt.Errorf("event %q has no start", event.Summary)
+			}
+			if event.End.IsZero() {
+				switch {
+				case duration != "":
+					d, err := parseDuration(duration)
+					if err != nil {
+						return nil, nil, fmt.Errorf("invalid duration of event %q: %w", event.Summary, err)
+					}
+					event.End = event.Start.Add(d)
+				case allDay:
+					event.End = event.Start.AddDate(0, 0, 1)
+				default:
+					event.End = event.Start
+				}
+			}
+			events = append(events, *event)
+			event = nil
+		case name == "SUMMARY":
+			event.Summary = value
+		case name == "DTSTART":
+			t, date, err := parseTime(value, params, location)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid start of event %q: %w", event.Summary, err)
+			}
+			event.Start, allDay = t, date
+		case name == "DTEND":
+			t, _, err := parseTime(value, params, location)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid end of event %q: %w", event.Summary, err)
+			}
+			event.End = t
+		case name == "DURATION":
+			duration = value
+		case name == "RRULE":
+			err := parseRule(event, value, location)
+			if errors.Is(err, ErrUnsupportedRecurrence) {
+				unsupported = true
+				continue
+			}
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	return events, skipped, nil
+}
+
+// unfold joins the lines, which were folded by starting the continuation
+// with a space or tab.
+func unfold(data string) []string {
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n") {
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// parseLine splits a content line like "DTSTART;TZID=Europe/Berlin:20251224T120000"
+// into its name, parameters and value.
+func parseLine(line string) (string, map[string]string, string, bool) {
+	head, value, ok := strings.Cut(line, ":")
+	if !ok {
+		return "", nil, "", false
+	}
+	parts := strings.Split(head, ";")
+	params := map[string]string{}
+	for _, p := range parts[1:] {
+		k, v, _ := strings.Cut(p, "=")
+		params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+	}
+	return strings.ToUpper(parts[0]), params, strings.TrimSpace(value), true
+}
+
+// parseTime parses a date or date-time value. It returns true for dates,
+// i.e. all-day events.
+func parseTime(value string, params map[string]string, location *time.Location) (time.Time, bool, error) {
+	if tzid := params["TZID"]; tzid != "" {
+		loc, err := time.LoadLocation(tzid)
+		if err != nil {
+			return time.Time{}, false, err
+		}
+		location = loc
+	}
+
+	if params["VALUE"] == "DATE" || len(value) == len(icalDate) {
+		t, err := time.ParseInLocation(icalDate, value, location)
+		return t, true, err
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse(icalDateTime+"Z", value)
+		return t, false, err
+	}
+	t, err := time.ParseInLocation(icalDateTime, value, location)
+	return t, false, err
+}
+
+// parseDuration parses a duration value like "P1D" or "PT1H30M".
+func parseDuration(value string) (time.Duration, error) {
+	s, ok := strings.CutPrefix(strings.TrimPrefix(value, "+"), "P")
+	if !ok {
+		return 0, fmt.Errorf("%q is not a duration", value)
+	}
+
+	var d time.Duration
+	inTime := false
+	for s != "" {
+		if s[0] == 'T' {
+			inTime = true
+			s = s[1:]
+			continue
+		}
+		i := strings.IndexFunc(s, func(r rune) bool { return r < '0' || r > '9' })
+		if i <= 0 {
+			return 0, fmt.Errorf("%q is not a duration", value)
+		}
+		n, err := strconv.Atoi(s[:i])
+		if err != nil {
+			return 0, err
+		}
+		var unit time.Duration
+		switch {
+		case !inTime && s[i] == 'W':
+			unit = 7 * 24 * time.Hour
+		case !inTime && s[i] == 'D':
+			unit = 24 * time.Hour
+		case inTime && s[i] == 'H':
+			unit = time.Hour
+		case inTime && s[i] == 'M':
+			unit = time.Minute
+		case inTime && s[i] == 'S':
+			unit = time.Second
+		default:
+			return 0, fmt.Errorf("%q is not a duration", value)
+		}
+		d += time.Duration(n) * unit
+		s = s[i+1:]
+	}
+	return d, nil
+}
+
+// parseRule parses a yearly recurrence rule like "FREQ=YEARLY;COUNT=5". It
+// returns ErrUnsupportedRecurrence for other rules.
+func parseRule(event *Event, value string, location *time.Location) error {
+	for _, part := range strings.Split(value, ";") {
+		k, v, _ := strings.Cut(part, "=")
+		switch strings.ToUpper(k) {
+		case "FREQ":
+			if v != "YEARLY" {
+				return fmt.Errorf("%w of event %q: %s", ErrUnsupportedRecurrence, event.Summary, value)
+			}
+			event.Yearly = true
+		case "COUNT":
+			count, err := strconv.Atoi(v)
+			if err != nil {
+				return fmt.Errorf("invalid recurrence count of event %q: %w", event.Summary, err)
+			}
+			event.Count = count
+		case "UNTIL":
+			until, _, err := parseTime(v, nil, location)
+			if err != nil {
+				return fmt.Errorf("invalid recurrence end of event %q: %w", event.Summary, err)
+			}
+			event.Until = until
+		case "INTERVAL":
+			if v != "1" {
+				return fmt.Errorf("%w of event %q: %s", ErrUnsupportedRecurrence, event.Summary, value)
+			}
+		default:
+			return fmt.Errorf("%w of event %q: %s", ErrUnsupportedRecurrence, event.Summary, value)
+		}
+	}
+	return nil
+}
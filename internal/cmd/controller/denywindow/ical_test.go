@@ -0,0 +1,129 @@
+package denywindow
+
+import (
+	"testing"
+	"time"
+)
+
+const calendar = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"PRODID:-//example//holidays//EN\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:1\r\n" +
+	"SUMMARY:Christmas\r\n" +
+	"DTSTART;VALUE=DATE:20251224\r\n" +
+	"DTEND;VALUE=DATE:20251227\r\n" +
+	"RRULE:FREQ=YEARLY\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:2\r\n" +
+	"SUMMARY:Release\r\n" +
+	"  freeze\r\n" +
+	"DTSTART;TZID=Europe/Berlin:20251201T180000\r\n" +
+	"DURATION:PT12H\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:3\r\n" +
+	"SUMMARY:Maintenance\r\n" +
+	"DTSTART:20251205T100000Z\r\n" +
+	"DTEND:20251205T120000Z\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:4\r\n" +
+	"SUMMARY:Day off\r\n" +
+	"DTSTART;VALUE=DATE:20251208\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestParseCalendar(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	events, skipped, err := ParseCalendar(calendar, time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(skipped) != 0 {
+		t.Errorf("expected no skipped events, got %v", skipped)
+	}
+	if len(events) != 4 {
+		t.Fatalf("expected 4 events, got %d", len(events))
+	}
+
+	expected := []Event{
+		{
+			Summary: "Christmas",
+			Start:   time.Date(2025, 12, 24, 0, 0, 0, 0, time.UTC),
+			End:     time.Date(2025, 12, 27, 0, 0, 0, 0, time.UTC),
+			Yearly:  true,
+		},
+		{
+			Summary: "Release freeze",
+			Start:   time.Date(2025, 12, 1, 18, 0, 0, 0, berlin),
+			End:     time.Date(2025, 12, 2, 6, 0, 0, 0, berlin),
+		},
+		{
+			Summary: "Maintenance",
+			Start:   time.Date(2025, 12, 5, 10, 0, 0, 0, time.UTC),
+			End:     time.Date(2025, 12, 5, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			Summary: "Day off",
+			Start:   time.Date(2025, 12, 8, 0, 0, 0, 0, time.UTC),
+			End:     time.Date(2025, 12, 9, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	for i, e := range expected {
+		got := events[i]
+		if got.Summary != e.Summary || !got.Start.Equal(e.Start) || !got.End.Equal(e.End) || got.Yearly != e.Yearly {
+			t.Errorf("expected event %+v, got %+v", e, got)
+		}
+	}
+}
+
+func TestParseCalendarUnsupportedRule(t *testing.T) {
+	data := "BEGIN:VEVENT\nSUMMARY:Standup\nDTSTART:20251201T090000Z\nRRULE:FREQ=DAILY\nEND:VEVENT\n" +
+		"BEGIN:VEVENT\nSUMMARY:Advent\nDTSTART;VALUE=DATE:20251201\nRRULE:FREQ=YEARLY;BYMONTH=12\nEND:VEVENT\n" +
+		"BEGIN:VEVENT\nSUMMARY:New year\nDTSTART;VALUE=DATE:20260101\nRRULE:FREQ=YEARLY\nEND:VEVENT\n"
+	events, skipped, err := ParseCalendar(data, time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(events) != 1 || events[0].Summary != "New year" {
+		t.Errorf("expected only the yearly event, got %+v", events)
+	}
+	if len(skipped) != 2 || skipped[0] != "Standup" || skipped[1] != "Advent" {
+		t.Errorf("expected the daily and the monthly event to be skipped, got %v", skipped)
+	}
+
+	data = "BEGIN:VEVENT\nSUMMARY:Christmas\nDTSTART;VALUE=DATE:20251224\nRRULE:FREQ=YEARLY;COUNT=x\nEND:VEVENT\n"
+	if _, _, err := ParseCalendar(data, time.UTC); err == nil {
+		t.Errorf("expected error for invalid recurrence count")
+	}
+}
+
+func TestYearlyEventNext(t *testing.T) {
+	christmas := Event{
+		Summary: "Christmas",
+		Start:   time.Date(2024, 12, 24, 0, 0, 0, 0, time.UTC),
+		End:     time.Date(2024, 12, 27, 0, 0, 0, 0, time.UTC),
+		Yearly:  true,
+	}
+
+	w, ok := christmas.Next(time.Date(2025, 12, 25, 0, 0, 0, 0, time.UTC))
+	if !ok || !w.Start.Equal(time.Date(2025, 12, 24, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected this year's occurrence, got %v", w)
+	}
+
+	w, ok = christmas.Next(time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC))
+	if !ok || !w.Start.Equal(time.Date(2026, 12, 24, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected next year's occurrence, got %v", w)
+	}
+
+	christmas.Count = 2
+	if _, ok := christmas.Next(time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)); ok {
+		t.Errorf("expected no occurrence after the count")
+	}
+}
@@ -0,0 +1,103 @@
+// Package denywindow computes the deny windows of Deny schedules, in which
+// rollouts are held back.
+package denywindow
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/reugn/go-quartz/quartz"
+)
+
+// Window is a period of time, in which rollouts are denied.
+type Window struct {
+	Start time.Time
+	End   time.Time
+	// Event is the summary of the calendar event the window comes from.
+	Event string
+}
+
+// Source provides deny windows, e.g. a cron expression or a calendar event.
+type Source interface {
+	// Next returns the first window, which ends after t. It returns
+	// false if there is none.
+	Next(t time.Time) (Window, bool)
+}
+
+// Cron is a source of windows of a fixed duration, which start by a cron
+// expression.
+type Cron struct {
+	trigger  *quartz.CronTrigger
+	duration time.Duration
+	location *time.Location
+}
+
+// NewCron returns a source for the cron expression, which is evaluated in
+// the location.
+func NewCron(expression string, duration time.Duration, location *time.Location) (*Cron, error) {
+	if duration <= 0 {
+		return nil, fmt.Errorf("duration must be positive")
+	}
+	trigger, err := quartz.NewCronTriggerWithLoc(expression, location)
+	if err != nil {
+		return nil, err
+	}
+	return &Cron{trigger: trigger, duration: duration, location: location}, nil
+}
+
+// Next implements Source. A window ending after t started after t minus the
+// duration.
+func (c *Cron) Next(t time.Time) (Window, bool) {
+	next, err := c.trigger.NextFireTime(t.Add(-c.duration).UnixNano())
+	if err != nil {
+		return Window{}, false
+	}
+	start := time.Unix(0, next).In(c.location)
+	return Window{Start: start, End: start.Add(c.duration)}, true
+}
+
+// Evaluate returns the window active at now, which ends last, and the time
+// of the next change, i.e. the earliest end of an active window or start of
+// an upcoming window. The time of the next change is zero, if there is
+// none.
+func Evaluate(now time.Time, sources ...Source) (*Window, time.Time) {
+	var active *Window
+	var next time.Time
+	for _, source := range sources {
+		w, ok := source.Next(now)
+		if !ok {
+			continue
+		}
+
+		change := w.Start
+		if !w.Start.After(now) {
+			change = w.End
+			if active == nil || w.End.After(active.End) {
+				active = &w
+			}
+		}
+		if next.IsZero() || change.Before(next) {
+			next = change
+		}
+	}
+	return active, next
+}
+
+// NextStart returns the start of the first window, which starts after now,
+// or zero if there is none.
+func NextStart(now time.Time, sources ...Source) time.Time {
+	var next time.Time
+	for _, source := range sources {
+		w, ok := source.Next(now)
+		if ok && !w.Start.After(now) {
+			w, ok = source.Next(w.End)
+		}
+		if !ok {
+			continue
+		}
+		if next.IsZero() || w.Start.Before(next) {
+			next = w.Start
+		}
+	}
+	return next
+}
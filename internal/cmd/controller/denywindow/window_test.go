@@ -0,0 +1,105 @@
+package denywindow
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	// every day at 22:00 for 8 hours
+	cron, err := NewCron("0 0 22 * * *", 8*time.Hour, time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		now       time.Time
+		wantStart time.Time
+	}{
+		{
+			name:      "before window",
+			now:       time.Date(2025, 12, 1, 12, 0, 0, 0, time.UTC),
+			wantStart: time.Date(2025, 12, 1, 22, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "in window",
+			now:       time.Date(2025, 12, 2, 3, 0, 0, 0, time.UTC),
+			wantStart: time.Date(2025, 12, 1, 22, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "at end of window",
+			now:       time.Date(2025, 12, 2, 6, 0, 0, 0, time.UTC),
+			wantStart: time.Date(2025, 12, 2, 22, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, ok := cron.Next(tt.now)
+			if !ok {
+				t.Fatalf("expected a window")
+			}
+			if !w.Start.Equal(tt.wantStart) || !w.End.Equal(tt.wantStart.Add(8*time.Hour)) {
+				t.Errorf("expected window starting at %v, got %v - %v", tt.wantStart, w.Start, w.End)
+			}
+		})
+	}
+}
+
+func TestNewCronInvalid(t *testing.T) {
+	if _, err := NewCron("0 0 22 * * *", 0, time.UTC); err == nil {
+		t.Errorf("expected error for missing duration")
+	}
+	if _, err := NewCron("not a cron", time.Hour, time.UTC); err == nil {
+		t.Errorf("expected error for invalid expression")
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	now := time.Date(2025, 12, 24, 12, 0, 0, 0, time.UTC)
+	freeze := Event{
+		Summary: "freeze",
+		Start:   time.Date(2025, 12, 20, 0, 0, 0, 0, time.UTC),
+		End:     time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC),
+	}
+	christmas := Event{
+		Summary: "christmas",
+		Start:   time.Date(2025, 12, 24, 0, 0, 0, 0, time.UTC),
+		End:     time.Date(2025, 12, 27, 0, 0, 0, 0, time.UTC),
+	}
+	newYear := Event{
+		Summary: "new year",
+		Start:   time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		End:     time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC),
+	}
+	past := Event{
+		Summary: "past",
+		Start:   time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		End:     time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC),
+	}
+
+	active, next := Evaluate(now, christmas, freeze, newYear, past)
+	if active == nil || active.Event != "freeze" {
+		t.Fatalf("expected the window ending last to be active, got %v", active)
+	}
+	if !next.Equal(christmas.End) {
+		t.Errorf("expected next change at %v, got %v", christmas.End, next)
+	}
+
+	active, next = Evaluate(now, newYear, past)
+	if active != nil {
+		t.Errorf("expected no active window, got %v", active)
+	}
+	if !next.Equal(newYear.Start) {
+		t.Errorf("expected next change at %v, got %v", newYear.Start, next)
+	}
+
+	if start := NextStart(now, christmas, newYear); !start.Equal(newYear.Start) {
+		t.Errorf("expected next start at %v, got %v", newYear.Start, start)
+	}
+
+	active, next = Evaluate(now, past)
+	if active != nil || !next.IsZero() {
+		t.Errorf("expected no windows, got %v and %v", active, next)
+	}
+}
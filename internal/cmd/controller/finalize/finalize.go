@@ -20,6 +20,7 @@ const (
 	BundleDeploymentFinalizer = "fleet.cattle.io/bundle-deployment-finalizer"
 	ClusterFinalizer          = "fleet.cattle.io/cluster-finalizer"
 	ScheduleFinalizer         = "fleet.cattle.io/schedule-finalizer"
+	OCIRepoFinalizer          = "fleet.cattle.io/ocirepo-finalizer"
 )
 
 // PurgeBundles deletes all bundles related to the given resource namespaced name
@@ -42,6 +42,7 @@ const (
 	ociRegistryAuthVolumeName = "oci-auth"
 	gitClonerVolumeName       = "git-cloner"
 	emptyDirVolumeName        = "git-cloner-empty-dir"
+	gitVerificationVolumeName = "git-verification"
 
 	fleetHomeDir = "/fleet-home"
 
@@ -242,6 +243,17 @@ func (r *GitJobReconciler) newGitJob(ctx context.Context, obj *v1alpha1.GitRepo)
 		},
 	)
 
+	if obj.Spec.Verification != nil {
+		job.Spec.Template.Spec.Volumes = append(job.Spec.Template.Spec.Volumes, corev1.Volume{
+			Name: gitVerificationVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: obj.Spec.Verification.SecretName,
+				},
+			},
+		})
+	}
+
 	// Look for a `--ca-bundle-file` arg to the git cloner. This applies to cases where the GitRepo's `Spec.CABundle` is
 	// specified, but also to cases where a CA bundle secret has been created instead, with data from Rancher
 	// secrets.
@@ -576,6 +588,15 @@ func (r *GitJobReconciler) newGitCloner(
 		args = append(args, "--insecure-skip-tls")
 	}
 
+	if obj.Spec.Verification != nil {
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      gitVerificationVolumeName,
+			MountPath: "/gitjob/verification",
+			ReadOnly:  true,
+		})
+		args = append(args, "--verification-keys-dir", "/gitjob/verification")
+	}
+
 	var CABundleSecret corev1.Secret
 	err = r.Get(ctx, types.NamespacedName{
 		Namespace: obj.Namespace,
@@ -688,6 +709,9 @@ func argsAndEnvs(
 			args = append(args, "--correct-drift-keep-fail-history")
 		}
 	}
+	if gitrepo.Spec.CorrectDrift != nil && gitrepo.Spec.CorrectDrift.Report {
+		args = append(args, "--report-drift")
+	}
 
 	fleetApplyRetries := readIntEnvVar(logger, fleetapply.GetOnConflictRetries, fleetapply.FleetApplyConflictRetriesEnv)
 	bundleCreationMaxConcurrency := readIntEnvVar(logger, fleetapply.GetBundleCreationMaxConcurrency, fleetapply.BundleCreationMaxConcurrencyEnv)
@@ -12,6 +12,7 @@ import (
 	"github.com/go-logr/logr"
 	"github.com/reugn/go-quartz/quartz"
 
+	"github.com/rancher/fleet/internal/cmd/cli/gitcloner"
 	"github.com/rancher/fleet/internal/cmd/controller/finalize"
 	"github.com/rancher/fleet/internal/cmd/controller/imagescan"
 	ctrlquartz "github.com/rancher/fleet/internal/cmd/controller/quartz"
@@ -482,6 +483,11 @@ func (r *GitJobReconciler) validateExternalSecretExist(ctx context.Context, gitr
 			return fmt.Errorf("failed to look up helmSecretName, error: %w", err)
 		}
 	}
+	if gitrepo.Spec.Verification != nil {
+		if err := r.Get(ctx, types.NamespacedName{Namespace: gitrepo.Namespace, Name: gitrepo.Spec.Verification.SecretName}, &corev1.Secret{}); err != nil {
+			return fmt.Errorf("failed to look up verification secret, error: %w", err)
+		}
+	}
 	return nil
 }
 
@@ -724,6 +730,8 @@ func setStatusFromGitjob(ctx context.Context, c client.Client, gitRepo *v1alpha1
 		condition.Cond(con.Type.String()).Reason(gitRepo, con.Reason)
 	}
 
+	setVerifiedCondition(gitRepo, result.Status, terminationMessage, job.Annotations["commit"])
+
 	// status.Compute() possible results are
 	//   - InProgress
 	//   - Current
@@ -749,6 +757,36 @@ func setStatusFromGitjob(ctx context.Context, c client.Client, gitRepo *v1alpha1
 	return nil
 }
 
+// setVerifiedCondition sets the Verified condition from the result of the
+// git job, if commit signatures are verified. The cloner reports the commit
+// SHA, which failed the verification, in its output.
+func setVerifiedCondition(gitRepo *v1alpha1.GitRepo, jobStatus status.Status, terminationMessage, commit string) {
+	if gitRepo.Spec.Verification == nil {
+		conds := gitRepo.Status.Conditions[:0]
+		for _, c := range gitRepo.Status.Conditions {
+			if c.Type != v1alpha1.GitRepoVerifiedCondition {
+				conds = append(conds, c)
+			}
+		}
+		gitRepo.Status.Conditions = conds
+		return
+	}
+
+	cond := condition.Cond(v1alpha1.GitRepoVerifiedCondition)
+	switch jobStatus {
+	case status.FailedStatus:
+		if sha, reason, ok := gitcloner.ParseVerificationError(terminationMessage); ok {
+			cond.SetStatus(gitRepo, "False")
+			cond.Reason(gitRepo, "VerificationFailed")
+			cond.Message(gitRepo, fmt.Sprintf("commit %s failed signature verification: %s", sha, reason))
+		}
+	case status.CurrentStatus:
+		cond.SetStatus(gitRepo, "True")
+		cond.Reason(gitRepo, "")
+		cond.Message(gitRepo, fmt.Sprintf("commit %s is signed by a trusted key", commit))
+	}
+}
+
 // updateErrorStatus sets the condition in the status and tries to update the resource
 func updateErrorStatus(ctx context.Context, c client.Client, req types.NamespacedName, status v1alpha1.GitRepoStatus, orgErr error) error {
 	reconciler.SetCondition(v1alpha1.GitRepoAcceptedCondition, &status, orgErr)
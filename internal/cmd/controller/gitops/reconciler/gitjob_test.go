@@ -19,6 +19,7 @@ import (
 	"github.com/rancher/fleet/internal/mocks"
 	"github.com/rancher/fleet/internal/ssh"
 	fleetv1 "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+	"github.com/rancher/wrangler/v3/pkg/condition"
 	"github.com/rancher/wrangler/v3/pkg/genericcondition"
 	"go.uber.org/mock/gomock"
 
@@ -32,6 +33,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -426,6 +428,80 @@ func TestNewJob(t *testing.T) {
 				},
 			},
 		},
+		"verification": {
+			gitrepo: &fleetv1.GitRepo{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "gitrepo",
+					Namespace: "default",
+				},
+				Spec: fleetv1.GitRepoSpec{
+					Repo:         "repo",
+					Verification: &fleetv1.GitVerification{SecretName: "trusted-keys"},
+				},
+			},
+			expectedInitContainers: []corev1.Container{
+				{
+					Command: []string{
+						"log.sh",
+					},
+					Args: []string{
+						"fleet",
+						"gitcloner",
+						"repo",
+						"/workspace",
+						"--branch",
+						"master",
+						"--verification-keys-dir",
+						"/gitjob/verification",
+					},
+					Image: "test",
+					Name:  "gitcloner-initializer",
+					VolumeMounts: []corev1.VolumeMount{
+						{
+							Name:      gitClonerVolumeName,
+							MountPath: "/workspace",
+						},
+						{
+							Name:      emptyDirVolumeName,
+							MountPath: "/tmp",
+						},
+						{
+							Name:      gitVerificationVolumeName,
+							MountPath: "/gitjob/verification",
+							ReadOnly:  true,
+						},
+					},
+					SecurityContext: securityContext,
+					Env: []corev1.EnvVar{
+						{
+							Name:  fleetapply.JSONOutputEnvVar,
+							Value: "true",
+						},
+					},
+				},
+			},
+			expectedVolumes: []corev1.Volume{
+				{
+					Name: gitVerificationVolumeName,
+					VolumeSource: corev1.VolumeSource{
+						Secret: &corev1.SecretVolumeSource{
+							SecretName: "trusted-keys",
+						},
+					},
+				},
+			},
+			clientObjects: []runtime.Object{
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "known-hosts",
+						Namespace: "cattle-fleet-system",
+					},
+					Data: map[string]string{
+						"known_hosts": "",
+					},
+				},
+			},
+		},
 		"simple with custom branch": {
 			gitrepo: &fleetv1.GitRepo{
 				ObjectMeta: metav1.ObjectMeta{
@@ -2503,6 +2579,41 @@ ignore this line as well`,
 	}
 }
 
+func TestSetVerifiedCondition(t *testing.T) {
+	const commit = "a0b6bbf2f8aa1a2fb1b1e7b5d4e4c1d0a3f5e6d7"
+	output := "Error: commit " + commit + " failed signature verification: commit is not signed\n"
+
+	gitrepo := &fleetv1.GitRepo{
+		Spec: fleetv1.GitRepoSpec{Verification: &fleetv1.GitVerification{SecretName: "keys"}},
+	}
+	cond := condition.Cond(fleetv1.GitRepoVerifiedCondition)
+
+	setVerifiedCondition(gitrepo, status.FailedStatus, output, commit)
+	if !cond.IsFalse(gitrepo) {
+		t.Fatalf("expected Verified condition to be false, got %v", gitrepo.Status.Conditions)
+	}
+	if msg := cond.GetMessage(gitrepo); msg != "commit "+commit+" failed signature verification: commit is not signed" {
+		t.Errorf("unexpected message %q", msg)
+	}
+
+	// other failures do not change the condition
+	setVerifiedCondition(gitrepo, status.FailedStatus, "fleet apply failed", commit)
+	if !cond.IsFalse(gitrepo) {
+		t.Errorf("expected Verified condition to stay false")
+	}
+
+	setVerifiedCondition(gitrepo, status.CurrentStatus, "", commit)
+	if !cond.IsTrue(gitrepo) || !strings.Contains(cond.GetMessage(gitrepo), commit) {
+		t.Errorf("expected Verified condition to be true, got %v", gitrepo.Status.Conditions)
+	}
+
+	gitrepo.Spec.Verification = nil
+	setVerifiedCondition(gitrepo, status.CurrentStatus, "", commit)
+	if len(gitrepo.Status.Conditions) != 0 {
+		t.Errorf("expected Verified condition to be removed, got %v", gitrepo.Status.Conditions)
+	}
+}
+
 func getFakeClient(tolerations []corev1.Toleration, objs ...runtime.Object) client.Client {
 	scheme := runtime.NewScheme()
 	utilruntime.Must(corev1.AddToScheme(scheme))
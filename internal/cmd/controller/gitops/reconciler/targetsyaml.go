@@ -23,6 +23,7 @@ func newTargetsConfigMap(repo *fleet.GitRepo) (*corev1.ConfigMap, error) {
 			ClusterSelector:      target.ClusterSelector,
 			ClusterGroup:         target.ClusterGroup,
 			ClusterGroupSelector: target.ClusterGroupSelector,
+			ClusterFactSelector:  target.ClusterFactSelector,
 		})
 		spec.TargetRestrictions = append(spec.TargetRestrictions, fleet.BundleTargetRestriction(target))
 	}
@@ -232,6 +232,13 @@ func (r *HelmOpReconciler) calculateBundle(helmop *fleet.HelmOp) *fleet.Bundle {
 	bundle.Labels = labels.Merge(bundle.Labels, map[string]string{
 		fleet.HelmOpLabel: helmop.Name,
 	})
+	// the bundle is processed by the same shard as the helmop, like the
+	// bundles of a gitrepo
+	if shardID, ok := helmop.Labels[sharding.ShardingRefLabel]; ok {
+		bundle.Labels[sharding.ShardingRefLabel] = shardID
+	}
+
+	applyVersionRings(bundle, helmop)
 
 	// Setting the Resources to nil, the agent will download the helm chart
 	bundle.Spec.Resources = nil
@@ -262,13 +269,21 @@ func (r *HelmOpReconciler) handleVersion(ctx context.Context, oldBundle *fleet.B
 		return nil
 	}
 
-	version, err := getChartVersion(ctx, r.Client, *helmop)
+	// The version is resolved even if polling is enabled, so the bundle
+	// never uses the version constraint, which the agent would resolve
+	// without the version policy.
+	version, pending, err := resolveVersion(ctx, r.Client, *helmop, time.Now().UTC())
 	if err != nil {
 		return err
 	}
+	helmop.Status.PendingVersions = pending
 
-	if usesPolling(*helmop) {
-		return nil // Field updates will be run from the polling job, to prevent race conditions.
+	if version == "" && len(pending) > 0 {
+		if helmop.Status.Version == "" {
+			return fmt.Errorf("no chart version can be adopted yet, the version policy holds back %d version(s) for its soak time", len(pending))
+		}
+		// keep the adopted version, until a pending version has soaked
+		version = helmop.Status.Version
 	}
 
 	bundle.Spec.Helm.Version = version
@@ -433,6 +448,7 @@ func updateStatus(ctx context.Context, c client.Client, req types.NamespacedName
 		// selectively update the status fields this reconciler is responsible for
 		if t.Status.Version != objToPatchFrom.Status.Version && objToPatchFrom.Status.Version != "" {
 			t.Status.Version = objToPatchFrom.Status.Version
+			addVersionHistory(&t.Status, t.Status.Version, time.Now().UTC(), versionHistoryLimit(*t))
 			// (#3883)
 			// If orig.Status.Version is, for example, equal to 1.0.0, when
 			// assigning the Version to t.Status.Version both will be 1.0.0.
@@ -441,6 +457,14 @@ func updateStatus(ctx context.Context, c client.Client, req types.NamespacedName
 			// The following cleanup prevents that so Status.Version is taken into
 			// account when calculating the patch data.
 			objToPatchFrom.Status.Version = ""
+			objToPatchFrom.Status.VersionHistory = nil
+		}
+
+		if pending := objToPatchFrom.Status.PendingVersions; !equality.Semantic.DeepEqual(t.Status.PendingVersions, pending) {
+			// patch from the live pending versions, like for the
+			// version above
+			objToPatchFrom.Status.PendingVersions = t.Status.PendingVersions
+			t.Status.PendingVersions = pending
 		}
 
 		// only keep the Ready condition from live status, it's calculated by the status reconciler
@@ -519,16 +543,10 @@ func helmChartSpecChanged(o *fleet.HelmOptions, n *fleet.HelmOptions, statusVers
 // getChartVersion fetches the latest chart version from the Helm registry referenced by helmop, and returns it.
 // If this fails, it returns an empty version along with an error.
 func getChartVersion(ctx context.Context, c client.Client, helmop fleet.HelmOp) (string, error) {
-	auth := bundlereader.Auth{}
-	if helmop.Spec.HelmSecretName != "" {
-		req := types.NamespacedName{Namespace: helmop.Namespace, Name: helmop.Spec.HelmSecretName}
-		var err error
-		auth, err = bundlereader.ReadHelmAuthFromSecret(ctx, c, req)
-		if err != nil {
-			return "", fmt.Errorf("could not read Helm auth from secret: %w", err)
-		}
+	auth, err := helmAuth(ctx, c, helmop)
+	if err != nil {
+		return "", err
 	}
-	auth.InsecureSkipVerify = helmop.Spec.InsecureSkipTLSverify
 
 	version, err := bundlereader.ChartVersion(ctx, *helmop.Spec.Helm, auth)
 	if err != nil {
@@ -580,5 +598,5 @@ func validate(h fleet.HelmOp) error {
 		}
 	}
 
-	return nil
+	return validateVersionRings(h)
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"time"
 
 	"github.com/rancher/fleet/internal/cmd/controller/status"
 	"github.com/rancher/fleet/internal/cmd/controller/summary"
@@ -98,6 +99,8 @@ func (r *HelmOpStatusReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		return ctrl.Result{}, err
 	}
 
+	requeueAfter := setVersionRings(helmop, bdList.Items, time.Now().UTC())
+
 	statusPatch := client.MergeFrom(orig)
 	if patchData, err := statusPatch.Data(helmop); err == nil && string(patchData) != "{}" {
 		// skip update if patch is empty
@@ -107,7 +110,38 @@ func (r *HelmOpStatusReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		}
 	}
 
-	return ctrl.Result{}, nil
+	if len(helmop.Spec.VersionRings) > 0 {
+		if err := r.promoteVersionRings(ctx, helmop); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// promoteVersionRings patches the helm version overrides of the bundle's
+// targets to the versions of the version rings.
+func (r *HelmOpStatusReconciler) promoteVersionRings(ctx context.Context, helmop *fleet.HelmOp) error {
+	b := &fleet.Bundle{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: helmop.Namespace, Name: helmop.Name}, b); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("could not get bundle to promote version rings: %w", err)
+	}
+
+	orig := b.DeepCopy()
+	applyVersionRings(b, helmop)
+
+	patch := client.MergeFrom(orig)
+	if patchData, err := patch.Data(b); err == nil && string(patchData) == "{}" {
+		// skip update if patch is empty
+		return nil
+	}
+	if err := r.Patch(ctx, b, patch); err != nil {
+		return fmt.Errorf("could not patch bundle to promote version rings: %w", err)
+	}
+	return nil
 }
 
 func setStatusHelm(list *fleet.BundleDeploymentList, helmop *fleet.HelmOp) error {
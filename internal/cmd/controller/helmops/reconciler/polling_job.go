@@ -18,6 +18,7 @@ import (
 	"github.com/rancher/wrangler/v3/pkg/condition"
 	"github.com/rancher/wrangler/v3/pkg/kstatus"
 
+	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	errutil "k8s.io/apimachinery/pkg/util/errors"
@@ -124,32 +125,41 @@ func (j *helmPollingJob) pollHelm(ctx context.Context) error {
 		return j.updateErrorStatus(ctx, h, pollingTimestamp, origErr)
 	}
 
-	version, err := getChartVersion(ctx, j.client, *h)
+	version, pending, err := resolveVersion(ctx, j.client, *h, pollingTimestamp)
 	if err != nil {
 		return fail(err, "FailedToGetNewChartVersion")
 	}
 
-	b := &fleet.Bundle{}
-
-	if err := j.client.Get(ctx, nsName, b); err != nil {
-		return fail(fmt.Errorf("could not get bundle before patching its version: %w", err), "FailedToGetBundle")
-	}
+	pendingChanged := !equality.Semantic.DeepEqual(pending, h.Status.PendingVersions)
+	if version == "" {
+		// The version policy holds back all available versions, until
+		// they have existed for its soak time.
+		if !pendingChanged && !isInErrorState(h.Status) {
+			return nil
+		}
+	} else {
+		b := &fleet.Bundle{}
 
-	orig := b.DeepCopy()
-	b.Spec.Helm.Version = version
+		if err := j.client.Get(ctx, nsName, b); err != nil {
+			return fail(fmt.Errorf("could not get bundle before patching its version: %w", err), "FailedToGetBundle")
+		}
 
-	if version != h.Status.Version {
-		j.recorder.Event(h, fleetevent.Normal, "GotNewChartVersion", version)
-	}
+		orig := b.DeepCopy()
+		b.Spec.Helm.Version = version
 
-	patch := client.MergeFrom(orig)
-	if patchData, err := patch.Data(b); err == nil && string(patchData) == "{}" && !isInErrorState(h.Status) {
-		// skip update if patch is empty
-		return nil
-	}
+		if version != h.Status.Version {
+			j.recorder.Event(h, fleetevent.Normal, "GotNewChartVersion", version)
+		}
 
-	if err := j.client.Patch(ctx, b, patch); err != nil {
-		return fail(fmt.Errorf("could not patch bundle to set the resolved version: %w", err), "FailedToPatchBundle")
+		patch := client.MergeFrom(orig)
+		if patchData, err := patch.Data(b); err == nil && string(patchData) == "{}" {
+			if !pendingChanged && !isInErrorState(h.Status) {
+				// skip update if patch is empty
+				return nil
+			}
+		} else if err := j.client.Patch(ctx, b, patch); err != nil {
+			return fail(fmt.Errorf("could not patch bundle to set the resolved version: %w", err), "FailedToPatchBundle")
+		}
 	}
 
 	nsn := types.NamespacedName{Name: h.Name, Namespace: h.Namespace}
@@ -161,7 +171,11 @@ func (j *helmPollingJob) pollHelm(ctx context.Context) error {
 		}
 
 		t.Status.LastPollingTime = metav1.Time{Time: pollingTimestamp}
-		t.Status.Version = version
+		if version != "" {
+			t.Status.Version = version
+			addVersionHistory(&t.Status, version, pollingTimestamp, versionHistoryLimit(*t))
+		}
+		t.Status.PendingVersions = pending
 
 		condition.Cond(fleet.HelmOpAcceptedCondition).SetStatusBool(&t.Status, true)
 		condition.Cond(fleet.HelmOpPolledCondition).SetStatusBool(&t.Status, true)
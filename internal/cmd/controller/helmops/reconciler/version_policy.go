@@ -0,0 +1,268 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+
+	"github.com/rancher/fleet/internal/bundlereader"
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// alphabeticalOrderAsc selects the lowest version, like the
+	// alphabetical policy of an ImageScan.
+	alphabeticalOrderAsc = "ASC"
+
+	allowedUpdatesPatch = "patch"
+	allowedUpdatesMinor = "minor"
+
+	defaultVersionHistoryLimit = 10
+
+	// maxPendingVersions is the number of pending versions kept in the
+	// status. The latest pending versions are kept.
+	maxPendingVersions = 10
+)
+
+// resolveVersion returns the chart version of helmop. With a version policy,
+// this is the version the policy adopts, along with the pending versions.
+// Versions pinned in the spec, e.g. from the version history, are not
+// subject to the version policy.
+func resolveVersion(ctx context.Context, c client.Client, helmop fleet.HelmOp, now time.Time) (string, []fleet.HelmOpVersion, error) {
+	if _, err := semver.StrictNewVersion(helmop.Spec.Helm.Version); err != nil && helmop.Spec.VersionPolicy != nil {
+		return getPolicyVersion(ctx, c, helmop, now)
+	}
+	version, err := getChartVersion(ctx, c, helmop)
+	return version, nil, err
+}
+
+// getPolicyVersion fetches all versions of the chart referenced by helmop and
+// returns the version its version policy adopts, along with the versions
+// which are pending, because of the soak time. The returned version is empty
+// if no version can be adopted yet. It returns an error, if no version
+// matches the version policy.
+func getPolicyVersion(ctx context.Context, c client.Client, helmop fleet.HelmOp, now time.Time) (string, []fleet.HelmOpVersion, error) {
+	auth, err := helmAuth(ctx, c, helmop)
+	if err != nil {
+		return "", nil, err
+	}
+
+	available, err := bundlereader.ChartVersions(ctx, *helmop.Spec.Helm, auth)
+	if err != nil {
+		return "", nil, fmt.Errorf("could not get chart versions: %w", err)
+	}
+
+	version, pending, err := selectVersion(*helmop.Spec.VersionPolicy, helmop.Spec.Helm.Version, helmop.Status, available, now)
+	if err == nil && version == "" && len(pending) == 0 {
+		return "", nil, fmt.Errorf("no chart version matches the version policy")
+	}
+	return version, pending, err
+}
+
+// helmAuth returns the credentials to access the Helm registry referenced by helmop.
+func helmAuth(ctx context.Context, c client.Client, helmop fleet.HelmOp) (bundlereader.Auth, error) {
+	auth := bundlereader.Auth{}
+	if helmop.Spec.HelmSecretName != "" {
+		req := types.NamespacedName{Namespace: helmop.Namespace, Name: helmop.Spec.HelmSecretName}
+		var err error
+		auth, err = bundlereader.ReadHelmAuthFromSecret(ctx, c, req)
+		if err != nil {
+			return auth, fmt.Errorf("could not read Helm auth from secret: %w", err)
+		}
+	}
+	auth.InsecureSkipVerify = helmop.Spec.InsecureSkipTLSverify
+	return auth, nil
+}
+
+// selectVersion returns the latest of the available versions, which the
+// policy allows and which have existed for the soak time. Versions which are
+// only held back by the soak time are returned as pending, with the time
+// they were created, or first seen if the registry does not record it.
+// Before a version was adopted, versions without creation time count as
+// soaked, as their age is unknown. Otherwise all existing tags of an OCI
+// registry would be held back for the soak time.
+func selectVersion(
+	policy fleet.HelmOpVersionPolicy,
+	constraint string,
+	status fleet.HelmOpStatus,
+	available []bundlereader.AvailableChartVersion,
+	now time.Time,
+) (string, []fleet.HelmOpVersion, error) {
+	var exclude *regexp.Regexp
+	if policy.Exclude != "" {
+		var err error
+		if exclude, err = regexp.Compile(policy.Exclude); err != nil {
+			return "", nil, fmt.Errorf("invalid exclude pattern in version policy: %w", err)
+		}
+	}
+
+	less, allowed, err := versionOrder(policy, constraint, status.Version)
+	if err != nil {
+		return "", nil, err
+	}
+
+	firstResolution := status.Version == ""
+	firstSeen := map[string]metav1.Time{}
+	for _, v := range status.PendingVersions {
+		firstSeen[v.Version] = v.Time
+	}
+
+	var soakTime time.Duration
+	if policy.SoakTime != nil {
+		soakTime = policy.SoakTime.Duration
+	}
+
+	latest := status.Version
+	var pending []fleet.HelmOpVersion
+	for _, v := range available {
+		if v.Version == status.Version || !allowed(v.Version) {
+			continue
+		}
+		if exclude != nil && exclude.MatchString(v.Version) {
+			continue
+		}
+
+		created := metav1.Time{Time: v.Created}
+		if created.IsZero() {
+			if t, ok := firstSeen[v.Version]; ok {
+				created = t
+			} else if !firstResolution {
+				created = metav1.Time{Time: now}
+			}
+		}
+		if !created.IsZero() && now.Sub(created.Time) < soakTime {
+			pending = append(pending, fleet.HelmOpVersion{Version: v.Version, Time: created})
+			continue
+		}
+
+		if latest == "" || less(latest, v.Version) {
+			latest = v.Version
+		}
+	}
+
+	// versions older than the adopted one will never be adopted
+	var stillPending []fleet.HelmOpVersion
+	for _, v := range pending {
+		if latest == "" || less(latest, v.Version) {
+			stillPending = append(stillPending, v)
+		}
+	}
+
+	sort.SliceStable(stillPending, func(i, j int) bool { return less(stillPending[i].Version, stillPending[j].Version) })
+	if len(stillPending) > maxPendingVersions {
+		stillPending = stillPending[len(stillPending)-maxPendingVersions:]
+	}
+
+	return latest, stillPending, nil
+}
+
+// versionOrder returns how to order versions according to the policy, and
+// which versions it allows as an update of the current version.
+func versionOrder(policy fleet.HelmOpVersionPolicy, constraint, current string) (func(a, b string) bool, func(v string) bool, error) {
+	if a := policy.Policy.Alphabetical; a != nil {
+		less := func(a, b string) bool { return a < b }
+		if strings.ToUpper(a.Order) == alphabeticalOrderAsc {
+			less = func(a, b string) bool { return a > b }
+		}
+		allowed := func(v string) bool {
+			return current == "" || less(current, v)
+		}
+		return less, allowed, nil
+	}
+
+	if constraint == "" {
+		// like resolving an empty version, this excludes pre-releases
+		constraint = "*"
+	}
+	var constraints []*semver.Constraints
+	for _, c := range []string{constraint, semverRange(policy.Policy.SemVer)} {
+		if c == "" {
+			continue
+		}
+		parsed, err := semver.NewConstraint(c)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid version constraint %q: %w", c, err)
+		}
+		constraints = append(constraints, parsed)
+	}
+
+	currentVersion, _ := semver.NewVersion(current)
+	less := func(a, b string) bool {
+		va, errA := semver.NewVersion(a)
+		vb, errB := semver.NewVersion(b)
+		if errA != nil || errB != nil {
+			return errA != nil && errB == nil
+		}
+		return va.LessThan(vb)
+	}
+	allowed := func(v string) bool {
+		sv, err := semver.NewVersion(v)
+		if err != nil {
+			return false
+		}
+		for _, c := range constraints {
+			if !c.Check(sv) {
+				return false
+			}
+		}
+		if currentVersion == nil {
+			return true
+		}
+		if !currentVersion.LessThan(sv) {
+			return false
+		}
+		switch policy.AllowedUpdates {
+		case allowedUpdatesPatch:
+			return sv.Major() == currentVersion.Major() && sv.Minor() == currentVersion.Minor()
+		case allowedUpdatesMinor:
+			return sv.Major() == currentVersion.Major()
+		}
+		return true
+	}
+	return less, allowed, nil
+}
+
+func semverRange(policy *fleet.SemVerPolicy) string {
+	if policy == nil {
+		return ""
+	}
+	return policy.Range
+}
+
+// addVersionHistory records version as the latest resolved version, keeping
+// at most limit versions in the history.
+func addVersionHistory(status *fleet.HelmOpStatus, version string, now time.Time, limit int) {
+	if version == "" || (len(status.VersionHistory) > 0 && status.VersionHistory[0].Version == version) {
+		return
+	}
+	if limit <= 0 {
+		limit = defaultVersionHistoryLimit
+	}
+	history := []fleet.HelmOpVersion{{Version: version, Time: metav1.Time{Time: now}}}
+	for _, v := range status.VersionHistory {
+		if len(history) >= limit {
+			break
+		}
+		if v.Version != version {
+			history = append(history, v)
+		}
+	}
+	status.VersionHistory = history
+}
+
+// versionHistoryLimit returns the number of versions to keep in the status.
+func versionHistoryLimit(helmop fleet.HelmOp) int {
+	if helmop.Spec.VersionPolicy == nil {
+		return defaultVersionHistoryLimit
+	}
+	return helmop.Spec.VersionPolicy.HistoryLimit
+}
@@ -0,0 +1,259 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/rancher/fleet/internal/bundlereader"
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSelectVersion(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	ago := func(d time.Duration) time.Time { return now.Add(-d) }
+	available := []bundlereader.AvailableChartVersion{
+		{Version: "1.2.3", Created: ago(30 * 24 * time.Hour)},
+		{Version: "1.2.4", Created: ago(48 * time.Hour)},
+		{Version: "1.2.5", Created: ago(time.Hour)},
+		{Version: "1.3.0", Created: ago(72 * time.Hour)},
+		{Version: "1.4.0-rc.1", Created: ago(72 * time.Hour)},
+		{Version: "2.0.0", Created: ago(72 * time.Hour)},
+	}
+	soak := &metav1.Duration{Duration: 24 * time.Hour}
+
+	cases := []struct {
+		name       string
+		policy     fleet.HelmOpVersionPolicy
+		constraint string
+		status     fleet.HelmOpStatus
+		available  []bundlereader.AvailableChartVersion
+		version    string
+		pending    []string
+	}{
+		{
+			name:    "latest version without restrictions",
+			status:  fleet.HelmOpStatus{Version: "1.2.3"},
+			version: "2.0.0",
+		},
+		{
+			name:    "patch updates only",
+			policy:  fleet.HelmOpVersionPolicy{AllowedUpdates: "patch"},
+			status:  fleet.HelmOpStatus{Version: "1.2.3"},
+			version: "1.2.5",
+		},
+		{
+			name:    "minor updates only",
+			policy:  fleet.HelmOpVersionPolicy{AllowedUpdates: "minor"},
+			status:  fleet.HelmOpStatus{Version: "1.2.3"},
+			version: "1.3.0",
+		},
+		{
+			name:    "patch updates after the soak time",
+			policy:  fleet.HelmOpVersionPolicy{AllowedUpdates: "patch", SoakTime: soak},
+			status:  fleet.HelmOpStatus{Version: "1.2.3"},
+			version: "1.2.4",
+			pending: []string{"1.2.5"},
+		},
+		{
+			name:       "pre-releases allowed by the constraint, but excluded by pattern",
+			policy:     fleet.HelmOpVersionPolicy{Exclude: "-rc"},
+			constraint: ">= 1.0.0-0 < 2.0.0",
+			status:     fleet.HelmOpStatus{Version: "1.2.3"},
+			version:    "1.3.0",
+		},
+		{
+			name:       "semver range of the policy",
+			policy:     fleet.HelmOpVersionPolicy{Policy: fleet.ImagePolicyChoice{SemVer: &fleet.SemVerPolicy{Range: "< 1.3.0"}}},
+			constraint: "1.x",
+			version:    "1.2.5",
+		},
+		{
+			name:    "no downgrades",
+			status:  fleet.HelmOpStatus{Version: "2.0.0"},
+			version: "2.0.0",
+		},
+		{
+			name:    "nothing to adopt during the soak time",
+			policy:  fleet.HelmOpVersionPolicy{SoakTime: &metav1.Duration{Duration: 100 * 24 * time.Hour}},
+			version: "",
+			pending: []string{"1.2.3", "1.2.4", "1.2.5", "1.3.0", "2.0.0"},
+		},
+		{
+			name:   "versions without creation time are soaked from when they were first seen",
+			policy: fleet.HelmOpVersionPolicy{SoakTime: soak},
+			status: fleet.HelmOpStatus{
+				Version:         "1.0.0",
+				PendingVersions: []fleet.HelmOpVersion{{Version: "1.1.0", Time: metav1.Time{Time: ago(25 * time.Hour)}}},
+			},
+			available: []bundlereader.AvailableChartVersion{
+				{Version: "1.0.0"}, {Version: "1.1.0"}, {Version: "1.2.0"},
+			},
+			version: "1.1.0",
+			pending: []string{"1.2.0"},
+		},
+		{
+			name:   "alphabetical order",
+			policy: fleet.HelmOpVersionPolicy{Policy: fleet.ImagePolicyChoice{Alphabetical: &fleet.AlphabeticalPolicy{}}},
+			status: fleet.HelmOpStatus{Version: "2024-01"},
+			available: []bundlereader.AvailableChartVersion{
+				{Version: "2023-12"}, {Version: "2024-01"}, {Version: "2024-02"},
+			},
+			version: "2024-02",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			versions := available
+			if c.available != nil {
+				versions = c.available
+			}
+			version, pending, err := selectVersion(c.policy, c.constraint, c.status, versions, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if version != c.version {
+				t.Errorf("expected version %q, got %q", c.version, version)
+			}
+			var pendingVersions []string
+			for _, v := range pending {
+				pendingVersions = append(pendingVersions, v.Version)
+			}
+			if !reflect.DeepEqual(pendingVersions, c.pending) {
+				t.Errorf("expected pending versions %v, got %v", c.pending, pendingVersions)
+			}
+		})
+	}
+
+	if _, _, err := selectVersion(fleet.HelmOpVersionPolicy{Exclude: "("}, "", fleet.HelmOpStatus{}, available, now); err == nil {
+		t.Error("expected error for invalid exclude pattern")
+	}
+}
+
+func TestSelectVersionKeepsFirstSeen(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	policy := fleet.HelmOpVersionPolicy{SoakTime: &metav1.Duration{Duration: time.Hour}}
+	available := []bundlereader.AvailableChartVersion{{Version: "1.0.0"}, {Version: "1.1.0"}}
+	status := fleet.HelmOpStatus{Version: "1.0.0"}
+
+	version, pending, err := selectVersion(policy, "", status, available, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != "1.0.0" || len(pending) != 1 || !pending[0].Time.Time.Equal(now) {
+		t.Fatalf("expected 1.1.0 to be pending since now, got %q, %v", version, pending)
+	}
+
+	status.PendingVersions = pending
+	version, pending, err = selectVersion(policy, "", status, available, now.Add(30*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != "1.0.0" || len(pending) != 1 || !pending[0].Time.Time.Equal(now) {
+		t.Fatalf("expected 1.1.0 to be still pending since the first poll, got %q, %v", version, pending)
+	}
+
+	status.PendingVersions = pending
+	version, pending, err = selectVersion(policy, "", status, available, now.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != "1.1.0" || len(pending) != 0 {
+		t.Fatalf("expected 1.1.0 to be adopted after the soak time, got %q, %v", version, pending)
+	}
+}
+
+func TestSelectVersionFirstResolution(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	policy := fleet.HelmOpVersionPolicy{SoakTime: &metav1.Duration{Duration: time.Hour}}
+	available := []bundlereader.AvailableChartVersion{{Version: "1.0.0"}, {Version: "1.1.0"}}
+
+	// the tags of an OCI registry existed before the first resolution
+	version, pending, err := selectVersion(policy, "", fleet.HelmOpStatus{}, available, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != "1.1.0" || len(pending) != 0 {
+		t.Fatalf("expected existing versions to be soaked, got %q, %v", version, pending)
+	}
+
+	available = append(available, bundlereader.AvailableChartVersion{Version: "1.2.0"})
+	version, pending, err = selectVersion(policy, "", fleet.HelmOpStatus{Version: version}, available, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != "1.1.0" || len(pending) != 1 || pending[0].Version != "1.2.0" {
+		t.Fatalf("expected new version to be pending, got %q, %v", version, pending)
+	}
+}
+
+func TestSelectVersionLimitsPendingVersions(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	policy := fleet.HelmOpVersionPolicy{SoakTime: &metav1.Duration{Duration: time.Hour}}
+	var available []bundlereader.AvailableChartVersion
+	for i := 30; i >= 0; i-- {
+		available = append(available, bundlereader.AvailableChartVersion{Version: fmt.Sprintf("1.%d.0", i)})
+	}
+
+	_, pending, err := selectVersion(policy, "", fleet.HelmOpStatus{Version: "1.0.0"}, available, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != maxPendingVersions {
+		t.Fatalf("expected %d pending versions, got %d", maxPendingVersions, len(pending))
+	}
+	if pending[0].Version != "1.21.0" || pending[len(pending)-1].Version != "1.30.0" {
+		t.Errorf("expected the latest versions to be pending in order, got %v", pending)
+	}
+}
+
+func TestHandleVersionAppliesVersionPolicy(t *testing.T) {
+	svr := createHelmServer()
+	defer svr.Close()
+
+	for _, polling := range []bool{false, true} {
+		helmop := &fleet.HelmOp{
+			ObjectMeta: metav1.ObjectMeta{Name: "helmop", Namespace: "default"},
+			Spec: fleet.HelmOpSpec{
+				BundleSpec: fleet.BundleSpec{BundleDeploymentOptions: fleet.BundleDeploymentOptions{
+					Helm: &fleet.HelmOptions{Repo: svr.URL, Chart: "alpine", Version: "0.x.x"},
+				}},
+				InsecureSkipTLSverify: true,
+				VersionPolicy:         &fleet.HelmOpVersionPolicy{Exclude: `^0\.2\.`},
+			},
+		}
+		if polling {
+			helmop.Spec.PollingInterval = &metav1.Duration{Duration: time.Minute}
+		}
+		r := &HelmOpReconciler{}
+		bundle := r.calculateBundle(helmop)
+
+		if err := r.handleVersion(context.Background(), &fleet.Bundle{}, bundle, helmop); err != nil {
+			t.Fatal(err)
+		}
+		if bundle.Spec.Helm.Version != "0.1.0" || helmop.Status.Version != "0.1.0" {
+			t.Errorf("expected the version policy to exclude 0.2.0 with polling %t, got %q", polling, bundle.Spec.Helm.Version)
+		}
+	}
+}
+
+func TestAddVersionHistory(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	status := &fleet.HelmOpStatus{}
+	for _, v := range []string{"1.0.0", "1.1.0", "1.1.0", "1.2.0", "1.0.0"} {
+		addVersionHistory(status, v, now, 3)
+	}
+
+	var versions []string
+	for _, v := range status.VersionHistory {
+		versions = append(versions, v.Version)
+	}
+	if expected := []string{"1.0.0", "1.2.0", "1.1.0"}; !reflect.DeepEqual(versions, expected) {
+		t.Errorf("expected history %v, got %v", expected, versions)
+	}
+}
@@ -0,0 +1,171 @@
+package reconciler
+
+import (
+	"fmt"
+	"time"
+
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	"github.com/rancher/wrangler/v3/pkg/condition"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// validateVersionRings returns an error if a version ring references a
+// target, which does not exist or which is part of another ring.
+func validateVersionRings(h fleet.HelmOp) error {
+	targets := map[string]bool{}
+	for i, target := range h.Spec.Targets {
+		name := target.Name
+		if name == "" {
+			name = fmt.Sprintf("target%03d", i)
+		}
+		targets[name] = true
+	}
+
+	rings := map[string]bool{}
+	inRing := map[string]string{}
+	for _, ring := range h.Spec.VersionRings {
+		if ring.Name == "" {
+			return fmt.Errorf("version ring without a name")
+		}
+		if rings[ring.Name] {
+			return fmt.Errorf("duplicate version ring %s", ring.Name)
+		}
+		rings[ring.Name] = true
+
+		for _, target := range ring.Targets {
+			if !targets[target] {
+				return fmt.Errorf("version ring %s references unknown target %s", ring.Name, target)
+			}
+			if other, ok := inRing[target]; ok {
+				return fmt.Errorf("target %s is part of version rings %s and %s", target, other, ring.Name)
+			}
+			if errs := validation.IsValidLabelValue(target); len(errs) > 0 {
+				return fmt.Errorf("target %s of version ring %s is not a valid label value: %v", target, ring.Name, errs)
+			}
+			inRing[target] = ring.Name
+		}
+	}
+	return nil
+}
+
+// applyVersionRings renders the versions of all rings after the first as helm
+// version overrides of their targets. The first ring, and targets without a
+// ring, use the bundle's version.
+func applyVersionRings(bundle *fleet.Bundle, helmop *fleet.HelmOp) {
+	versions := map[string]string{}
+	for i, ring := range helmop.Spec.VersionRings {
+		if i == 0 {
+			continue
+		}
+		status := ringStatus(helmop.Status, ring.Name)
+		if status == nil || status.Version == "" {
+			continue
+		}
+		for _, target := range ring.Targets {
+			versions[target] = status.Version
+		}
+	}
+
+	for i := range bundle.Spec.Targets {
+		target := &bundle.Spec.Targets[i]
+		version, ok := versions[target.Name]
+		if !ok {
+			continue
+		}
+		if target.Helm == nil {
+			target.Helm = &fleet.HelmOptions{}
+		}
+		target.Helm.Version = version
+	}
+}
+
+// setVersionRings updates the version and readiness of each version ring.
+// The first ring follows the resolved version of the HelmOp. A later ring is
+// promoted to the version of the previous ring, once the previous ring has
+// been ready on it for its ReadyFor duration. Rings without a version yet,
+// e.g. when the HelmOp is created, start with the resolved version.
+// It returns the time until the next promotion is due, or zero.
+func setVersionRings(helmop *fleet.HelmOp, bds []fleet.BundleDeployment, now time.Time) time.Duration {
+	if len(helmop.Spec.VersionRings) == 0 || helmop.Status.Version == "" {
+		helmop.Status.VersionRings = nil
+		return 0
+	}
+
+	byTarget := map[string][]*fleet.BundleDeployment{}
+	for i := range bds {
+		target := bds[i].Labels[fleet.BundleTargetLabel]
+		byTarget[target] = append(byTarget[target], &bds[i])
+	}
+
+	var requeueAfter time.Duration
+	rings := make([]fleet.HelmOpVersionRingStatus, 0, len(helmop.Spec.VersionRings))
+	for i, ring := range helmop.Spec.VersionRings {
+		status := fleet.HelmOpVersionRingStatus{Name: ring.Name}
+		if s := ringStatus(helmop.Status, ring.Name); s != nil {
+			status = *s.DeepCopy()
+		}
+
+		if i == 0 || status.Version == "" {
+			if status.Version != helmop.Status.Version {
+				status.Version = helmop.Status.Version
+				status.ReadySince = nil
+			}
+		} else if previous := rings[i-1]; previous.Version != status.Version && previous.ReadySince != nil {
+			var readyFor time.Duration
+			if d := helmop.Spec.VersionRings[i-1].ReadyFor; d != nil {
+				readyFor = d.Duration
+			}
+			if wait := readyFor - now.Sub(previous.ReadySince.Time); wait > 0 {
+				if requeueAfter == 0 || wait < requeueAfter {
+					requeueAfter = wait
+				}
+			} else {
+				status.Version = previous.Version
+				status.ReadySince = nil
+			}
+		}
+
+		if ringReady(ring, status.Version, byTarget) {
+			if status.ReadySince == nil {
+				status.ReadySince = &metav1.Time{Time: now}
+			}
+		} else {
+			status.ReadySince = nil
+		}
+		rings = append(rings, status)
+	}
+
+	helmop.Status.VersionRings = rings
+	return requeueAfter
+}
+
+// ringReady returns true if all bundle deployments of the ring's targets are
+// ready on the version. Rings without bundle deployments are ready.
+func ringReady(ring fleet.HelmOpVersionRing, version string, byTarget map[string][]*fleet.BundleDeployment) bool {
+	for _, target := range ring.Targets {
+		for _, bd := range byTarget[target] {
+			if bd.Spec.Options.Helm == nil || bd.Spec.Options.Helm.Version != version {
+				return false
+			}
+			if bd.Spec.DeploymentID != bd.Status.AppliedDeploymentID {
+				return false
+			}
+			if !condition.Cond(fleet.BundleDeploymentConditionReady).IsTrue(bd) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func ringStatus(status fleet.HelmOpStatus, name string) *fleet.HelmOpVersionRingStatus {
+	for i := range status.VersionRings {
+		if status.VersionRings[i].Name == name {
+			return &status.VersionRings[i]
+		}
+	}
+	return nil
+}
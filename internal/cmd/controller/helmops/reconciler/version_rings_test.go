@@ -0,0 +1,159 @@
+package reconciler
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+	"github.com/rancher/wrangler/v3/pkg/genericcondition"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func ringsHelmOp() *fleet.HelmOp {
+	h := &fleet.HelmOp{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "fleet-default"},
+	}
+	h.Spec.Targets = []fleet.BundleTarget{{Name: "canary"}, {Name: "staging"}, {}}
+	h.Spec.VersionRings = []fleet.HelmOpVersionRing{
+		{Name: "first", Targets: []string{"canary"}, ReadyFor: &metav1.Duration{Duration: time.Hour}},
+		{Name: "second", Targets: []string{"staging", "target002"}},
+	}
+	h.Status.Version = "1.0.0"
+	return h
+}
+
+func ringBD(target, version string, ready bool) fleet.BundleDeployment {
+	bd := fleet.BundleDeployment{
+		ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{fleet.BundleTargetLabel: target}},
+	}
+	bd.Spec.Options.Helm = &fleet.HelmOptions{Version: version}
+	bd.Spec.DeploymentID = "id"
+	bd.Status.AppliedDeploymentID = "id"
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	bd.Status.Conditions = []genericcondition.GenericCondition{{Type: string(fleet.BundleDeploymentConditionReady), Status: status}}
+	return bd
+}
+
+func TestValidateVersionRings(t *testing.T) {
+	if err := validateVersionRings(*ringsHelmOp()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cases := map[string]struct {
+		rings []fleet.HelmOpVersionRing
+		err   string
+	}{
+		"unknown target": {
+			rings: []fleet.HelmOpVersionRing{{Name: "first", Targets: []string{"prod"}}},
+			err:   "references unknown target prod",
+		},
+		"target in two rings": {
+			rings: []fleet.HelmOpVersionRing{
+				{Name: "first", Targets: []string{"canary"}},
+				{Name: "second", Targets: []string{"canary"}},
+			},
+			err: "target canary is part of version rings first and second",
+		},
+		"duplicate ring": {
+			rings: []fleet.HelmOpVersionRing{{Name: "first"}, {Name: "first"}},
+			err:   "duplicate version ring first",
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			h := ringsHelmOp()
+			h.Spec.VersionRings = c.rings
+			err := validateVersionRings(*h)
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Errorf("expected error %q, got %v", c.err, err)
+			}
+		})
+	}
+}
+
+func TestSetVersionRings(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	h := ringsHelmOp()
+
+	// all rings start with the resolved version
+	setVersionRings(h, nil, now)
+	if v := ringVersions(h); v != "first=1.0.0,second=1.0.0" {
+		t.Fatalf("unexpected initial versions %s", v)
+	}
+
+	// a new version only reaches the first ring
+	h.Status.Version = "1.1.0"
+	bds := []fleet.BundleDeployment{
+		ringBD("canary", "1.0.0", true),
+		ringBD("staging", "1.0.0", true),
+	}
+	if requeue := setVersionRings(h, bds, now); requeue != 0 {
+		t.Errorf("expected no requeue while the first ring is not ready, got %s", requeue)
+	}
+	if v := ringVersions(h); v != "first=1.1.0,second=1.0.0" {
+		t.Fatalf("expected only the first ring to be updated, got %s", v)
+	}
+	if h.Status.VersionRings[0].ReadySince != nil {
+		t.Error("expected first ring not to be ready on the new version")
+	}
+
+	// the first ring becomes ready, the second ring waits
+	bds[0] = ringBD("canary", "1.1.0", true)
+	requeue := setVersionRings(h, bds, now)
+	if requeue != time.Hour {
+		t.Errorf("expected to requeue after the ready duration, got %s", requeue)
+	}
+	if v := ringVersions(h); v != "first=1.1.0,second=1.0.0" {
+		t.Fatalf("expected the second ring to wait, got %s", v)
+	}
+
+	// still waiting, the ready time is kept
+	requeue = setVersionRings(h, bds, now.Add(40*time.Minute))
+	if requeue != 20*time.Minute {
+		t.Errorf("expected to requeue after the remaining ready duration, got %s", requeue)
+	}
+
+	// an unready bundle deployment resets the ready time
+	bds[0] = ringBD("canary", "1.1.0", false)
+	setVersionRings(h, bds, now.Add(50*time.Minute))
+	bds[0] = ringBD("canary", "1.1.0", true)
+	setVersionRings(h, bds, now.Add(70*time.Minute))
+	if v := ringVersions(h); v != "first=1.1.0,second=1.0.0" {
+		t.Fatalf("expected the second ring to wait after the first ring was unready, got %s", v)
+	}
+
+	// promoted after the ready duration
+	setVersionRings(h, bds, now.Add(130*time.Minute))
+	if v := ringVersions(h); v != "first=1.1.0,second=1.1.0" {
+		t.Fatalf("expected the second ring to be promoted, got %s", v)
+	}
+	if h.Status.VersionRings[1].ReadySince != nil {
+		t.Error("expected the promoted ring not to be ready on the new version")
+	}
+
+	b := &fleet.Bundle{}
+	b.Spec.Targets = []fleet.BundleTarget{{Name: "canary"}, {Name: "staging"}, {Name: "target002"}}
+	applyVersionRings(b, h)
+	if b.Spec.Targets[0].Helm != nil {
+		t.Errorf("expected no override for the first ring, got %+v", b.Spec.Targets[0].Helm)
+	}
+	for _, target := range b.Spec.Targets[1:] {
+		if target.Helm == nil || target.Helm.Version != "1.1.0" {
+			t.Errorf("expected version override for target %s, got %+v", target.Name, target.Helm)
+		}
+	}
+}
+
+func ringVersions(h *fleet.HelmOp) string {
+	var versions []string
+	for _, r := range h.Status.VersionRings {
+		versions = append(versions, r.Name+"="+r.Version)
+	}
+	return strings.Join(versions, ",")
+}
@@ -10,12 +10,14 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/go-git/go-git/v5/plumbing/transport"
@@ -25,6 +27,7 @@ import (
 	"github.com/reugn/go-quartz/quartz"
 	"golang.org/x/sync/semaphore"
 
+	"github.com/rancher/fleet/internal/cmd/controller/imagescan/pullrequest"
 	"github.com/rancher/fleet/internal/cmd/controller/imagescan/update"
 	fleetgithub "github.com/rancher/fleet/internal/github"
 	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
@@ -44,6 +47,7 @@ import (
 
 const (
 	defaultMessageTemplate = `Update from image update automation`
+	pullRequestTokenKey    = "token"
 )
 
 var (
@@ -207,15 +211,45 @@ func (j *GitCommitJob) cloneAndReplace(ctx context.Context) {
 		}
 	}
 
-	commit, err := commitAllAndPush(ctx, repo, auth, *gitrepo.Spec.ImageScanCommit)
+	commitSpec := *gitrepo.Spec.ImageScanCommit
+	message, err := commitMessage(commitSpec)
+	if err != nil {
+		err = j.updateErrorStatus(ctx, gitrepo, err)
+		logger.V(1).Info("Cannot render commit message", "error", err)
+		return
+	}
+
+	branch := ""
+	if commitSpec.Mode == fleet.CommitModePullRequest {
+		branch = pullRequestBranch(gitrepo)
+	}
+
+	commit, changed, err := commitAllAndPush(ctx, repo, auth, commitSpec, message, branch)
 	if err != nil {
 		err = j.updateErrorStatus(ctx, gitrepo, err)
 		logger.V(1).Info("Cannot commit and push to repo", "error", err)
 		return
 	}
 	if commit != "" {
-		logger.Info("Created commit in repo", "repo", gitrepo.Spec.Repo, "commit", commit)
+		logger.Info("Created commit in repo", "repo", gitrepo.Spec.Repo, "commit", commit, "branch", branch)
+	}
+
+	// the pull request is ensured even if the branch was pushed before, as
+	// opening it might have failed or it might have been closed since.
+	// Without changes, a previous pull request was merged or is obsolete.
+	url := ""
+	if branch != "" && changed {
+		url, err = j.ensurePullRequest(ctx, gitrepo, auth, scans, message, branch)
+		if err != nil {
+			err = j.updateErrorStatus(ctx, gitrepo, err)
+			logger.V(1).Info("Cannot open pull request", "error", err)
+			return
+		}
+		if url != gitrepo.Status.ImageScanPullRequestURL {
+			logger.Info("Opened pull request for image updates", "repo", gitrepo.Spec.Repo, "url", url)
+		}
 	}
+	gitrepo.Status.ImageScanPullRequestURL = url
 	interval := gitrepo.Spec.ImageSyncInterval
 	if interval == nil || interval.Seconds() == 0.0 {
 		interval = &DefaultInterval
@@ -339,19 +373,7 @@ func setupKnownHosts(gitrepo *fleet.GitRepo, data []byte) error {
 	return nil
 }
 
-func commitAllAndPush(ctx context.Context, repo *gogit.Repository, auth transport.AuthMethod, commit fleet.CommitSpec) (string, error) {
-	working, err := repo.Worktree()
-	if err != nil {
-		return "", err
-	}
-
-	status, err := working.Status()
-	if err != nil {
-		return "", err
-	} else if status.IsClean() {
-		return "", nil
-	}
-
+func commitMessage(commit fleet.CommitSpec) (string, error) {
 	msgTmpl := commit.MessageTemplate
 	if msgTmpl == "" {
 		msgTmpl = defaultMessageTemplate
@@ -364,9 +386,29 @@ func commitAllAndPush(ctx context.Context, repo *gogit.Repository, auth transpor
 	if err := tmpl.Execute(buf, "no data! yet"); err != nil {
 		return "", err
 	}
+	return buf.String(), nil
+}
+
+// commitAllAndPush commits all changes in the worktree and pushes the commit.
+// If branch is empty, the commit is pushed to the cloned branch. Otherwise
+// the branch is overwritten with the commit, unless it already contains the
+// same changes. It returns the pushed commit, if any, and whether the worktree
+// had any changes.
+func commitAllAndPush(ctx context.Context, repo *gogit.Repository, auth transport.AuthMethod, commit fleet.CommitSpec, message string, branch string) (string, bool, error) {
+	working, err := repo.Worktree()
+	if err != nil {
+		return "", false, err
+	}
+
+	status, err := working.Status()
+	if err != nil {
+		return "", false, err
+	} else if status.IsClean() {
+		return "", false, nil
+	}
 
 	var rev plumbing.Hash
-	if rev, err = working.Commit(buf.String(), &gogit.CommitOptions{
+	if rev, err = working.Commit(message, &gogit.CommitOptions{
 		All: true,
 		Author: &object.Signature{
 			Name:  commit.AuthorName,
@@ -374,10 +416,162 @@ func commitAllAndPush(ctx context.Context, repo *gogit.Repository, auth transpor
 			When:  time.Now(),
 		},
 	}); err != nil {
-		return "", err
+		return "", false, err
 	}
 
-	return rev.String(), repo.PushContext(ctx, &gogit.PushOptions{
+	opts := &gogit.PushOptions{
 		Auth: auth,
+	}
+	if branch != "" {
+		// the pull request branch is rewritten on every sync, skip the
+		// push if it already contains the same updates
+		upToDate, err := remoteBranchMatches(ctx, repo, auth, rev, branch)
+		if err != nil {
+			return "", true, err
+		}
+		if upToDate {
+			return "", true, nil
+		}
+		// go-git does not resolve HEAD as the source of a refspec
+		head, err := repo.Head()
+		if err != nil {
+			return "", true, err
+		}
+		opts.RefSpecs = []config.RefSpec{
+			config.RefSpec(fmt.Sprintf("+%s:%s", head.Name(), plumbing.NewBranchReferenceName(branch))),
+		}
+		opts.Force = true
+	}
+
+	return rev.String(), true, repo.PushContext(ctx, opts)
+}
+
+// remoteBranchMatches returns true if the remote branch exists and its head
+// commit has the same tree as the commit rev.
+func remoteBranchMatches(ctx context.Context, repo *gogit.Repository, auth transport.AuthMethod, rev plumbing.Hash, branch string) (bool, error) {
+	remote, err := repo.Remote("origin")
+	if err != nil {
+		return false, err
+	}
+	refs, err := remote.ListContext(ctx, &gogit.ListOptions{Auth: auth})
+	if err != nil {
+		return false, err
+	}
+	name := plumbing.NewBranchReferenceName(branch)
+	if !slices.ContainsFunc(refs, func(r *plumbing.Reference) bool { return r.Name() == name }) {
+		return false, nil
+	}
+
+	remoteName := plumbing.NewRemoteReferenceName("origin", branch)
+	err = remote.FetchContext(ctx, &gogit.FetchOptions{
+		Auth:     auth,
+		RefSpecs: []config.RefSpec{config.RefSpec(fmt.Sprintf("+%s:%s", name, remoteName))},
+		Depth:    1,
+		Tags:     gogit.NoTags,
+	})
+	if err != nil && !errors.Is(err, gogit.NoErrAlreadyUpToDate) {
+		return false, err
+	}
+
+	ref, err := repo.Reference(remoteName, true)
+	if err != nil {
+		return false, err
+	}
+	remoteCommit, err := repo.CommitObject(ref.Hash())
+	if err != nil {
+		return false, err
+	}
+	localCommit, err := repo.CommitObject(rev)
+	if err != nil {
+		return false, err
+	}
+	return remoteCommit.TreeHash == localCommit.TreeHash, nil
+}
+
+// pullRequestBranch returns the branch image updates are pushed to in pull request mode.
+func pullRequestBranch(gitrepo *fleet.GitRepo) string {
+	if pr := gitrepo.Spec.ImageScanCommit.PullRequest; pr != nil && pr.Branch != "" {
+		return pr.Branch
+	}
+	return "fleet/imagescan-" + gitrepo.Name
+}
+
+// ensurePullRequest opens or updates the pull request from branch into the
+// GitRepo's branch and returns its URL.
+func (j *GitCommitJob) ensurePullRequest(
+	ctx context.Context,
+	gitrepo *fleet.GitRepo,
+	auth transport.AuthMethod,
+	scans []*fleet.ImageScan,
+	message string,
+	branch string,
+) (string, error) {
+	spec := fleet.PullRequestSpec{}
+	if gitrepo.Spec.ImageScanCommit.PullRequest != nil {
+		spec = *gitrepo.Spec.ImageScanCommit.PullRequest
+	}
+
+	token, err := readPullRequestToken(ctx, j.client, gitrepo, spec, auth)
+	if err != nil {
+		return "", err
+	}
+
+	provider, err := pullrequest.New(pullrequest.Options{
+		Provider: spec.Provider,
+		APIURL:   spec.APIURL,
+		RepoURL:  gitrepo.Spec.Repo,
+		Token:    token,
 	})
+	if err != nil {
+		return "", err
+	}
+
+	title := spec.Title
+	if title == "" {
+		title, _, _ = strings.Cut(message, "\n")
+	}
+
+	body := &strings.Builder{}
+	fmt.Fprintf(body, "Image updates for GitRepo %s/%s:\n\n", gitrepo.Namespace, gitrepo.Name)
+	for _, scan := range scans {
+		fmt.Fprintf(body, "- %s: `%s`\n", scan.Spec.TagName, scan.Status.LatestImage)
+	}
+
+	return provider.Ensure(ctx, pullrequest.PullRequest{
+		Head:  branch,
+		Base:  gitrepo.Spec.Branch,
+		Title: title,
+		Body:  body.String(),
+	})
+}
+
+// readPullRequestToken returns the token for the pull request API. It is read
+// from the pull request secret, which can contain a GitHub App, or from the
+// password of the git credentials.
+func readPullRequestToken(ctx context.Context, c client.Client, gitrepo *fleet.GitRepo, spec fleet.PullRequestSpec, auth transport.AuthMethod) (string, error) {
+	if spec.SecretName == "" {
+		if basic, ok := auth.(*http.BasicAuth); ok {
+			return basic.Password, nil
+		}
+		return "", errors.New("pull request mode requires a token, set pullRequest.secretName when using ssh credentials")
+	}
+
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: gitrepo.Namespace, Name: spec.SecretName}, secret); err != nil {
+		return "", err
+	}
+
+	if fleetgithub.HasGitHubAppKeys(secret) {
+		appAuth, err := fleetgithub.GetGithubAppAuthFromSecret(secret, fleetgithub.DefaultAppAuthGetter{})
+		if err != nil {
+			return "", err
+		}
+		return appAuth.Password, nil
+	}
+
+	token, ok := secret.Data[pullRequestTokenKey]
+	if !ok || len(token) == 0 {
+		return "", fmt.Errorf("secret %s/%s has no %q key", secret.Namespace, secret.Name, pullRequestTokenKey)
+	}
+	return string(token), nil
 }
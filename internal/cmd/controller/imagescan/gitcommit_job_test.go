@@ -0,0 +1,112 @@
+package imagescan
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+)
+
+// cloneWithChange clones the main branch of the remote and changes a file in
+// the clone.
+func cloneWithChange(t *testing.T, remote string, content string) *gogit.Repository {
+	t.Helper()
+	dir := t.TempDir()
+	repo, err := gogit.PlainClone(dir, false, &gogit.CloneOptions{
+		URL:           remote,
+		ReferenceName: plumbing.NewBranchReferenceName("main"),
+		SingleBranch:  true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "deployment.yaml"), []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return repo
+}
+
+func TestCommitAllAndPushSkipsUnchangedBranch(t *testing.T) {
+	remote := t.TempDir()
+	if _, err := gogit.PlainInit(remote, true); err != nil {
+		t.Fatal(err)
+	}
+
+	// seed the remote with a main branch
+	dir := t.TempDir()
+	seed, err := gogit.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "deployment.yaml"), []byte("image: nginx:1.0\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	w, err := seed.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Add("deployment.yaml"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Commit("initial", &gogit.CommitOptions{Author: &object.Signature{Name: "test", When: time.Now()}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := seed.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{remote}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := seed.Push(&gogit.PushOptions{RefSpecs: []config.RefSpec{"refs/heads/master:refs/heads/main"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	commit := fleet.CommitSpec{AuthorName: "fleet", AuthorEmail: "fleet@example.com"}
+
+	repo := cloneWithChange(t, remote, "image: nginx:1.1\n")
+	rev, changed, err := commitAllAndPush(context.TODO(), repo, nil, commit, "update", "updates")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rev == "" || !changed {
+		t.Fatal("expected a commit to be pushed to a new branch")
+	}
+
+	// the same update is not pushed again
+	repo = cloneWithChange(t, remote, "image: nginx:1.1\n")
+	rev, changed, err = commitAllAndPush(context.TODO(), repo, nil, commit, "update", "updates")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rev != "" {
+		t.Errorf("expected no commit for an unchanged branch, got %s", rev)
+	}
+	if !changed {
+		t.Error("expected changes to be reported for an unchanged branch, so the pull request is ensured")
+	}
+
+	// a different update replaces the branch
+	repo = cloneWithChange(t, remote, "image: nginx:1.2\n")
+	rev, changed, err = commitAllAndPush(context.TODO(), repo, nil, commit, "update", "updates")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rev == "" || !changed {
+		t.Fatal("expected a commit for a changed branch")
+	}
+
+	// nothing to commit in a clean worktree
+	repo = cloneWithChange(t, remote, "image: nginx:1.0\n")
+	rev, changed, err = commitAllAndPush(context.TODO(), repo, nil, commit, "update", "updates")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rev != "" || changed {
+		t.Errorf("expected no changes for a clean worktree, got %s", rev)
+	}
+}
@@ -0,0 +1,148 @@
+package pullrequest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// github implements the pull request API of GitHub and GitHub Enterprise.
+type github struct {
+	*apiClient
+	repo string
+}
+
+type githubPullRequest struct {
+	Number  int    `json:"number"`
+	HTMLURL string `json:"html_url"`
+	Title   string `json:"title"`
+	Body    string `json:"body"`
+}
+
+func (g *github) Ensure(ctx context.Context, pr PullRequest) (string, error) {
+	owner, _, _ := strings.Cut(g.repo, "/")
+	q := url.Values{}
+	q.Set("state", "open")
+	q.Set("head", owner+":"+pr.Head)
+	q.Set("base", pr.Base)
+
+	var existing []githubPullRequest
+	if err := g.do(ctx, http.MethodGet, fmt.Sprintf("/repos/%s/pulls?%s", g.repo, q.Encode()), nil, &existing); err != nil {
+		return "", err
+	}
+
+	body := map[string]string{"title": pr.Title, "body": pr.Body}
+	var result githubPullRequest
+	if len(existing) > 0 {
+		if existing[0].Title == pr.Title && existing[0].Body == pr.Body {
+			return existing[0].HTMLURL, nil
+		}
+		if err := g.do(ctx, http.MethodPatch, fmt.Sprintf("/repos/%s/pulls/%d", g.repo, existing[0].Number), body, &result); err != nil {
+			return "", err
+		}
+		return result.HTMLURL, nil
+	}
+
+	body["head"] = pr.Head
+	body["base"] = pr.Base
+	if err := g.do(ctx, http.MethodPost, fmt.Sprintf("/repos/%s/pulls", g.repo), body, &result); err != nil {
+		return "", err
+	}
+	return result.HTMLURL, nil
+}
+
+// gitlab implements the merge request API of GitLab.
+type gitlab struct {
+	*apiClient
+	// project is the URL encoded path of the project.
+	project string
+}
+
+type gitlabMergeRequest struct {
+	IID         int    `json:"iid"`
+	WebURL      string `json:"web_url"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+func (g *gitlab) Ensure(ctx context.Context, pr PullRequest) (string, error) {
+	q := url.Values{}
+	q.Set("state", "opened")
+	q.Set("source_branch", pr.Head)
+	q.Set("target_branch", pr.Base)
+
+	var existing []gitlabMergeRequest
+	if err := g.do(ctx, http.MethodGet, fmt.Sprintf("/projects/%s/merge_requests?%s", g.project, q.Encode()), nil, &existing); err != nil {
+		return "", err
+	}
+
+	body := map[string]string{"title": pr.Title, "description": pr.Body}
+	var result gitlabMergeRequest
+	if len(existing) > 0 {
+		if existing[0].Title == pr.Title && existing[0].Description == pr.Body {
+			return existing[0].WebURL, nil
+		}
+		if err := g.do(ctx, http.MethodPut, fmt.Sprintf("/projects/%s/merge_requests/%d", g.project, existing[0].IID), body, &result); err != nil {
+			return "", err
+		}
+		return result.WebURL, nil
+	}
+
+	body["source_branch"] = pr.Head
+	body["target_branch"] = pr.Base
+	if err := g.do(ctx, http.MethodPost, fmt.Sprintf("/projects/%s/merge_requests", g.project), body, &result); err != nil {
+		return "", err
+	}
+	return result.WebURL, nil
+}
+
+// gitea implements the pull request API of Gitea and Forgejo.
+type gitea struct {
+	*apiClient
+	repo string
+}
+
+type giteaPullRequest struct {
+	Number  int    `json:"number"`
+	HTMLURL string `json:"html_url"`
+	Title   string `json:"title"`
+	Body    string `json:"body"`
+	Head    struct {
+		Ref string `json:"ref"`
+	} `json:"head"`
+	Base struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+}
+
+func (g *gitea) Ensure(ctx context.Context, pr PullRequest) (string, error) {
+	// the list API of Gitea does not filter by branch
+	var existing []giteaPullRequest
+	if err := g.do(ctx, http.MethodGet, fmt.Sprintf("/repos/%s/pulls?state=open&limit=50", g.repo), nil, &existing); err != nil {
+		return "", err
+	}
+
+	body := map[string]string{"title": pr.Title, "body": pr.Body}
+	var result giteaPullRequest
+	for _, e := range existing {
+		if e.Head.Ref != pr.Head || e.Base.Ref != pr.Base {
+			continue
+		}
+		if e.Title == pr.Title && e.Body == pr.Body {
+			return e.HTMLURL, nil
+		}
+		if err := g.do(ctx, http.MethodPatch, fmt.Sprintf("/repos/%s/pulls/%d", g.repo, e.Number), body, &result); err != nil {
+			return "", err
+		}
+		return result.HTMLURL, nil
+	}
+
+	body["head"] = pr.Head
+	body["base"] = pr.Base
+	if err := g.do(ctx, http.MethodPost, fmt.Sprintf("/repos/%s/pulls", g.repo), body, &result); err != nil {
+		return "", err
+	}
+	return result.HTMLURL, nil
+}
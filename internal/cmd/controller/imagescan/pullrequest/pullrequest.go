@@ -0,0 +1,193 @@
+// Package pullrequest opens pull requests, or merge requests, on git hosting
+// services, so image scan updates can be reviewed instead of being pushed to
+// a protected branch.
+package pullrequest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	giturls "github.com/rancher/fleet/pkg/git-urls"
+)
+
+const (
+	GitHub = "github"
+	GitLab = "gitlab"
+	Gitea  = "gitea"
+)
+
+// PullRequest describes a pull request from the Head branch into the Base
+// branch of a repository.
+type PullRequest struct {
+	Head  string
+	Base  string
+	Title string
+	Body  string
+}
+
+// Provider opens pull requests on a git hosting service.
+type Provider interface {
+	// Ensure opens a pull request, or updates the title and body of an
+	// already open pull request for the same head and base branches, if
+	// they changed. It returns the web URL of the pull request.
+	Ensure(ctx context.Context, pr PullRequest) (string, error)
+}
+
+// Options configure a provider for a single repository.
+type Options struct {
+	// Provider is one of GitHub, GitLab or Gitea. Detected from the
+	// repository URL if empty.
+	Provider string
+	// APIURL is the base URL of the API, derived from the repository URL if empty.
+	APIURL string
+	// RepoURL is the git URL of the repository, as used for cloning.
+	RepoURL string
+	// Token authenticates against the API.
+	Token string
+	// Client is used for requests to the API, defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+// New returns a provider for the repository in opts.
+func New(opts Options) (Provider, error) {
+	u, err := giturls.Parse(opts.RepoURL)
+	if err != nil {
+		return nil, err
+	}
+	repoPath := strings.TrimSuffix(strings.Trim(u.Path, "/"), ".git")
+	if strings.Count(repoPath, "/") < 1 {
+		return nil, fmt.Errorf("cannot determine owner and repository from %q", opts.RepoURL)
+	}
+
+	provider := opts.Provider
+	if provider == "" {
+		provider, err = detect(u.Hostname())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// the API of repositories cloned via ssh is served via https, on the
+	// default port
+	scheme, host := u.Scheme, u.Host
+	if scheme != "http" && scheme != "https" {
+		scheme, host = "https", u.Hostname()
+	}
+
+	c := &apiClient{
+		client: opts.Client,
+		token:  opts.Token,
+	}
+	if c.client == nil {
+		c.client = http.DefaultClient
+	}
+
+	switch provider {
+	case GitHub:
+		c.baseURL = opts.APIURL
+		if c.baseURL == "" {
+			c.baseURL = "https://api.github.com"
+			if u.Hostname() != "github.com" {
+				c.baseURL = fmt.Sprintf("%s://%s/api/v3", scheme, host)
+			}
+		}
+		c.authHeader = "Bearer " + opts.Token
+		return &github{apiClient: c, repo: repoPath}, nil
+	case GitLab:
+		c.baseURL = opts.APIURL
+		if c.baseURL == "" {
+			c.baseURL = fmt.Sprintf("%s://%s/api/v4", scheme, host)
+		}
+		c.authHeader = "Bearer " + opts.Token
+		return &gitlab{apiClient: c, project: url.PathEscape(repoPath)}, nil
+	case Gitea:
+		c.baseURL = opts.APIURL
+		if c.baseURL == "" {
+			c.baseURL = fmt.Sprintf("%s://%s/api/v1", scheme, host)
+		}
+		c.authHeader = "token " + opts.Token
+		return &gitea{apiClient: c, repo: repoPath}, nil
+	default:
+		return nil, fmt.Errorf("unsupported pull request provider %q", provider)
+	}
+}
+
+func detect(host string) (string, error) {
+	switch {
+	case host == "github.com" || strings.HasPrefix(host, "github."):
+		return GitHub, nil
+	case host == "gitlab.com" || strings.HasPrefix(host, "gitlab."):
+		return GitLab, nil
+	case host == "gitea.com" || host == "codeberg.org" || strings.HasPrefix(host, "gitea.") || strings.HasPrefix(host, "forgejo."):
+		return Gitea, nil
+	}
+	return "", fmt.Errorf("cannot detect the pull request provider for host %q, please specify it", host)
+}
+
+type apiClient struct {
+	client     *http.Client
+	baseURL    string
+	authHeader string
+	token      string
+}
+
+// do sends a JSON request and decodes the JSON response into out, if not nil.
+func (c *apiClient) do(ctx context.Context, method, path string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(c.baseURL, "/")+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", c.authHeader)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &APIError{Method: method, Path: path, StatusCode: resp.StatusCode, Body: string(data)}
+	}
+
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
+
+// APIError is returned for unsuccessful responses of a provider's API.
+type APIError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s %s: unexpected status code %d: %s", e.Method, e.Path, e.StatusCode, e.Body)
+}
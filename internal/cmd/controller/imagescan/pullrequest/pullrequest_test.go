@@ -0,0 +1,253 @@
+package pullrequest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type request struct {
+	method string
+	path   string
+	auth   string
+	body   map[string]string
+}
+
+// fakeAPI records requests and replies with the response registered for
+// the request's method and path.
+func fakeAPI(t *testing.T, responses map[string]string) (*httptest.Server, *[]request) {
+	t.Helper()
+	var requests []request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := request{method: r.Method, path: r.URL.RequestURI(), auth: r.Header.Get("Authorization")}
+		if r.Body != nil && r.Method != http.MethodGet {
+			_ = json.NewDecoder(r.Body).Decode(&req.body)
+		}
+		requests = append(requests, req)
+
+		resp, ok := responses[r.Method+" "+r.URL.RequestURI()]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, resp)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func TestNew(t *testing.T) {
+	tests := map[string]struct {
+		opts        Options
+		expectedURL string
+		expectedErr bool
+	}{
+		"github.com via https": {
+			opts:        Options{RepoURL: "https://github.com/rancher/fleet.git"},
+			expectedURL: "https://api.github.com",
+		},
+		"github enterprise via ssh": {
+			opts:        Options{RepoURL: "git@github.example.com:rancher/fleet.git", Provider: GitHub},
+			expectedURL: "https://github.example.com/api/v3",
+		},
+		"gitlab subgroup": {
+			opts:        Options{RepoURL: "https://gitlab.com/group/sub/project"},
+			expectedURL: "https://gitlab.com/api/v4",
+		},
+		"gitea with port": {
+			opts:        Options{RepoURL: "http://gitea.local:3000/org/repo.git"},
+			expectedURL: "http://gitea.local:3000/api/v1",
+		},
+		"explicit api url": {
+			opts:        Options{RepoURL: "https://git.example.com/org/repo", Provider: Gitea, APIURL: "https://api.example.com"},
+			expectedURL: "https://api.example.com",
+		},
+		"unknown host": {
+			opts:        Options{RepoURL: "https://git.example.com/org/repo"},
+			expectedErr: true,
+		},
+		"unknown provider": {
+			opts:        Options{RepoURL: "https://git.example.com/org/repo", Provider: "svn"},
+			expectedErr: true,
+		},
+		"missing owner": {
+			opts:        Options{RepoURL: "https://github.com/repo"},
+			expectedErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			p, err := New(test.opts)
+			if test.expectedErr {
+				if err == nil {
+					t.Fatalf("expected an error, got provider %#v", p)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var baseURL string
+			switch p := p.(type) {
+			case *github:
+				baseURL = p.baseURL
+			case *gitlab:
+				baseURL = p.baseURL
+			case *gitea:
+				baseURL = p.baseURL
+			}
+			if baseURL != test.expectedURL {
+				t.Errorf("expected API URL %q, got %q", test.expectedURL, baseURL)
+			}
+		})
+	}
+}
+
+func TestGitHubCreate(t *testing.T) {
+	srv, requests := fakeAPI(t, map[string]string{
+		"GET /repos/rancher/fleet/pulls?base=main&head=rancher%3Afleet%2Fimagescan-app&state=open": `[]`,
+		"POST /repos/rancher/fleet/pulls": `{"number": 1, "html_url": "https://github.com/rancher/fleet/pull/1"}`,
+	})
+
+	p, err := New(Options{RepoURL: "https://github.com/rancher/fleet", APIURL: srv.URL, Token: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	url, err := p.Ensure(context.TODO(), PullRequest{Head: "fleet/imagescan-app", Base: "main", Title: "update", Body: "body"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if url != "https://github.com/rancher/fleet/pull/1" {
+		t.Errorf("unexpected url %q", url)
+	}
+
+	if len(*requests) != 2 {
+		t.Fatalf("expected 2 requests, got %v", *requests)
+	}
+	post := (*requests)[1]
+	if post.auth != "Bearer secret" {
+		t.Errorf("unexpected authorization header %q", post.auth)
+	}
+	if post.body["head"] != "fleet/imagescan-app" || post.body["base"] != "main" || post.body["title"] != "update" {
+		t.Errorf("unexpected body %v", post.body)
+	}
+}
+
+func TestGitHubUpdate(t *testing.T) {
+	srv, requests := fakeAPI(t, map[string]string{
+		"GET /repos/rancher/fleet/pulls?base=main&head=rancher%3Aupdates&state=open": `[{"number": 7, "html_url": "https://github.com/rancher/fleet/pull/7"}]`,
+		"PATCH /repos/rancher/fleet/pulls/7":                                         `{"number": 7, "html_url": "https://github.com/rancher/fleet/pull/7"}`,
+	})
+
+	p, err := New(Options{RepoURL: "https://github.com/rancher/fleet", APIURL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	url, err := p.Ensure(context.TODO(), PullRequest{Head: "updates", Base: "main", Title: "new title"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if url != "https://github.com/rancher/fleet/pull/7" {
+		t.Errorf("unexpected url %q", url)
+	}
+	patch := (*requests)[1]
+	if patch.auth != "" {
+		t.Errorf("expected no authorization header without a token, got %q", patch.auth)
+	}
+	if patch.body["title"] != "new title" || patch.body["head"] != "" {
+		t.Errorf("unexpected body %v", patch.body)
+	}
+}
+
+func TestGitHubUnchanged(t *testing.T) {
+	srv, requests := fakeAPI(t, map[string]string{
+		"GET /repos/rancher/fleet/pulls?base=main&head=rancher%3Aupdates&state=open": `[{"number": 7, "html_url": "https://github.com/rancher/fleet/pull/7", "title": "update", "body": "body"}]`,
+	})
+
+	p, err := New(Options{RepoURL: "https://github.com/rancher/fleet", APIURL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	url, err := p.Ensure(context.TODO(), PullRequest{Head: "updates", Base: "main", Title: "update", Body: "body"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if url != "https://github.com/rancher/fleet/pull/7" {
+		t.Errorf("unexpected url %q", url)
+	}
+	if len(*requests) != 1 {
+		t.Errorf("expected no update of an unchanged pull request, got %v", *requests)
+	}
+}
+
+func TestGitLabCreate(t *testing.T) {
+	srv, requests := fakeAPI(t, map[string]string{
+		"GET /projects/group%2Fsub%2Fproject/merge_requests?source_branch=updates&state=opened&target_branch=main": `[]`,
+		"POST /projects/group%2Fsub%2Fproject/merge_requests":                                                      `{"iid": 3, "web_url": "https://gitlab.com/group/sub/project/-/merge_requests/3"}`,
+	})
+
+	p, err := New(Options{RepoURL: "git@gitlab.com:group/sub/project.git", APIURL: srv.URL, Token: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	url, err := p.Ensure(context.TODO(), PullRequest{Head: "updates", Base: "main", Title: "update", Body: "body"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if url != "https://gitlab.com/group/sub/project/-/merge_requests/3" {
+		t.Errorf("unexpected url %q", url)
+	}
+	post := (*requests)[1]
+	if post.body["source_branch"] != "updates" || post.body["target_branch"] != "main" || post.body["description"] != "body" {
+		t.Errorf("unexpected body %v", post.body)
+	}
+}
+
+func TestGiteaUpdate(t *testing.T) {
+	srv, requests := fakeAPI(t, map[string]string{
+		"GET /repos/org/repo/pulls?state=open&limit=50": `[
+			{"number": 1, "html_url": "https://gitea.com/org/repo/pulls/1", "head": {"ref": "other"}, "base": {"ref": "main"}},
+			{"number": 2, "html_url": "https://gitea.com/org/repo/pulls/2", "head": {"ref": "updates"}, "base": {"ref": "main"}}
+		]`,
+		"PATCH /repos/org/repo/pulls/2": `{"number": 2, "html_url": "https://gitea.com/org/repo/pulls/2"}`,
+	})
+
+	p, err := New(Options{RepoURL: "https://gitea.com/org/repo", APIURL: srv.URL, Token: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	url, err := p.Ensure(context.TODO(), PullRequest{Head: "updates", Base: "main", Title: "update"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if url != "https://gitea.com/org/repo/pulls/2" {
+		t.Errorf("unexpected url %q", url)
+	}
+	if auth := (*requests)[1].auth; auth != "token secret" {
+		t.Errorf("unexpected authorization header %q", auth)
+	}
+}
+
+func TestAPIError(t *testing.T) {
+	srv, _ := fakeAPI(t, map[string]string{})
+
+	p, err := New(Options{RepoURL: "https://gitea.com/org/repo", APIURL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = p.Ensure(context.TODO(), PullRequest{Head: "updates", Base: "main"})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+}
@@ -0,0 +1,287 @@
+// Package notifier detects state transitions of bundles, gitrepos and
+// clusters and sends notifications about them to sinks.
+package notifier
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/rancher/fleet/internal/cmd/controller/summary"
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	"github.com/rancher/wrangler/v3/pkg/genericcondition"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DefaultTemplate is used to render messages, if the notifier does not
+	// define a template.
+	DefaultTemplate = `{{.Kind}} {{.Namespace}}/{{.Name}} {{if .PreviousState}}changed from {{.PreviousState}} to{{else}}is{{end}} {{.State}}{{with .Message}}: {{.}}{{end}}`
+
+	defaultDedupeWindow    = 10 * time.Minute
+	defaultRateLimitPeriod = time.Minute
+)
+
+// Resource is the observed state of a bundle, gitrepo or cluster.
+type Resource struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	State     string `json:"state"`
+	Message   string `json:"message,omitempty"`
+}
+
+func (r Resource) key() string {
+	return r.Kind + "/" + r.Namespace + "/" + r.Name
+}
+
+// Event is a state transition of a resource.
+type Event struct {
+	Resource
+	PreviousState string    `json:"previousState,omitempty"`
+	Time          time.Time `json:"time"`
+}
+
+// State returns the state of a resource from its bundle summary. This is
+// the highest ranked state, which at least one bundledeployment is in, or
+// Ready. The state is empty, if no bundledeployments are desired (pure
+// function).
+func State(summary fleet.BundleSummary) string {
+	if summary.DesiredReady == 0 {
+		return ""
+	}
+
+	var state fleet.BundleState
+	for s, count := range map[fleet.BundleState]int{
+		fleet.NotReady:    summary.NotReady,
+		fleet.Pending:     summary.Pending,
+		fleet.OutOfSync:   summary.OutOfSync,
+		fleet.Modified:    summary.Modified,
+		fleet.WaitApplied: summary.WaitApplied,
+		fleet.ErrApplied:  summary.ErrApplied,
+	} {
+		if count > 0 && fleet.StateRank[s] > fleet.StateRank[state] {
+			state = s
+		}
+	}
+	if state == "" {
+		return string(fleet.Ready)
+	}
+	return string(state)
+}
+
+// Render renders the message for the event with the notifier's template.
+func Render(tmpl string, ev Event) (string, error) {
+	if tmpl == "" {
+		tmpl = DefaultTemplate
+	}
+	t, err := template.New("notification").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template: %w", err)
+	}
+	var b bytes.Buffer
+	if err := t.Execute(&b, ev); err != nil {
+		return "", fmt.Errorf("failed to render template: %w", err)
+	}
+	return b.String(), nil
+}
+
+// Tracker keeps the last observed state of the resources per notifier in
+// memory, as well as the data needed for deduplication and rate limiting.
+// After a restart of the controller, the first observation of a notifier
+// records the states without notifying.
+type Tracker struct {
+	mu        sync.Mutex
+	notifiers map[types.NamespacedName]*notifierState
+	now       func() time.Time
+}
+
+type notifierState struct {
+	uid types.UID
+	// states maps resource keys to their last state
+	states map[string]string
+	// notified maps resource keys to the time their state was last notified
+	notified map[string]notified
+	// sent contains the send times within the rate limit period
+	sent []time.Time
+}
+
+type notified struct {
+	state string
+	time  time.Time
+}
+
+// NewTracker returns an empty tracker.
+func NewTracker() *Tracker {
+	return &Tracker{
+		notifiers: map[types.NamespacedName]*notifierState{},
+		now:       time.Now,
+	}
+}
+
+func (t *Tracker) state(n *fleet.Notifier) *notifierState {
+	key := types.NamespacedName{Namespace: n.Namespace, Name: n.Name}
+	s, ok := t.notifiers[key]
+	if !ok || s.uid != n.UID {
+		s = &notifierState{uid: n.UID, notified: map[string]notified{}}
+		t.notifiers[key] = s
+	}
+	return s
+}
+
+// Transitions returns the transitions of the resources since the last
+// observation. Resources, which are no longer observed, are forgotten.
+// Transitions are only recorded by Commit, so they are returned again if
+// sending the notification failed. The first observation of a notifier
+// records the states of all resources and returns no transitions.
+func (t *Tracker) Transitions(n *fleet.Notifier, resources []Resource) []Event {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s := t.state(n)
+	now := t.now()
+
+	current := make(map[string]bool, len(resources))
+	for _, r := range resources {
+		current[r.key()] = true
+	}
+
+	if s.states == nil {
+		s.states = map[string]string{}
+		for _, r := range resources {
+			s.states[r.key()] = r.State
+		}
+		return nil
+	}
+
+	for key := range s.states {
+		if !current[key] {
+			delete(s.states, key)
+			delete(s.notified, key)
+		}
+	}
+
+	var events []Event
+	for _, r := range resources {
+		previous := s.states[r.key()]
+		if previous == r.State {
+			continue
+		}
+		events = append(events, Event{Resource: r, PreviousState: previous, Time: now})
+	}
+	return events
+}
+
+// Commit records the state of the event's resource.
+func (t *Tracker) Commit(n *fleet.Notifier, ev Event) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s := t.state(n)
+	if s.states == nil {
+		s.states = map[string]string{}
+	}
+	s.states[ev.key()] = ev.State
+}
+
+// Duplicate returns true, if the state of the event's resource was already
+// notified within the dedupe window.
+func (t *Tracker) Duplicate(n *fleet.Notifier, ev Event) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	window := defaultDedupeWindow
+	if n.Spec.DedupeWindow != nil {
+		window = n.Spec.DedupeWindow.Duration
+	}
+
+	last, ok := t.state(n).notified[ev.key()]
+	return ok && last.state == ev.State && t.now().Sub(last.time) < window
+}
+
+// Allow returns true, if sending another notification does not exceed the
+// rate limit of the notifier. Only notifications recorded by Sent are
+// accounted for, so failed sends do not use up the limit.
+func (t *Tracker) Allow(n *fleet.Notifier) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s := t.state(n)
+	now := t.now()
+	limit := n.Spec.RateLimit
+	if limit == nil {
+		return true
+	}
+	period := defaultRateLimitPeriod
+	if limit.Period != nil {
+		period = limit.Period.Duration
+	}
+
+	sent := s.sent[:0]
+	for _, ts := range s.sent {
+		if now.Sub(ts) < period {
+			sent = append(sent, ts)
+		}
+	}
+	s.sent = sent
+
+	return len(s.sent) < limit.Limit
+}
+
+// Sent records that the event was notified, for deduplication and rate
+// limiting.
+func (t *Tracker) Sent(n *fleet.Notifier, ev Event) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s := t.state(n)
+	now := t.now()
+	s.notified[ev.key()] = notified{state: ev.State, time: now}
+	if n.Spec.RateLimit != nil {
+		s.sent = append(s.sent, now)
+	}
+}
+
+// Forget removes all data of a deleted notifier.
+func (t *Tracker) Forget(key types.NamespacedName) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.notifiers, key)
+}
+
+// ResourceOf returns the observed state of a bundle, gitrepo or cluster. It
+// returns false for other objects.
+func ResourceOf(obj client.Object) (Resource, bool) {
+	var (
+		kind  string
+		sum   fleet.BundleSummary
+		conds []genericcondition.GenericCondition
+	)
+	switch o := obj.(type) {
+	case *fleet.Bundle:
+		kind, sum, conds = fleet.NotifierResourceBundle, o.Status.Summary, o.Status.Conditions
+	case *fleet.GitRepo:
+		kind, sum, conds = fleet.NotifierResourceGitRepo, o.Status.Summary, o.Status.Conditions
+	case *fleet.Cluster:
+		kind, sum, conds = fleet.NotifierResourceCluster, o.Status.Summary, o.Status.Conditions
+	default:
+		return Resource{}, false
+	}
+
+	r := Resource{
+		Kind:      kind,
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+		State:     State(sum),
+	}
+	if r.State != string(fleet.Ready) {
+		r.Message = summary.MessageFromCondition("Ready", conds)
+	}
+	return r, true
+}
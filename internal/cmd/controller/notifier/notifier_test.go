@@ -0,0 +1,194 @@
+package notifier
+
+import (
+	"testing"
+	"time"
+
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestState(t *testing.T) {
+	tests := []struct {
+		name    string
+		summary fleet.BundleSummary
+		want    string
+	}{
+		{
+			name:    "no bundledeployments",
+			summary: fleet.BundleSummary{},
+			want:    "",
+		},
+		{
+			name:    "ready",
+			summary: fleet.BundleSummary{DesiredReady: 2, Ready: 2},
+			want:    "Ready",
+		},
+		{
+			name:    "highest ranked state",
+			summary: fleet.BundleSummary{DesiredReady: 3, Ready: 1, NotReady: 1, ErrApplied: 1},
+			want:    "ErrApplied",
+		},
+		{
+			name:    "modified",
+			summary: fleet.BundleSummary{DesiredReady: 2, Ready: 1, Modified: 1},
+			want:    "Modified",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := State(tt.summary); got != tt.want {
+				t.Errorf("expected state %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	ev := Event{
+		Resource: Resource{
+			Kind:      "GitRepo",
+			Namespace: "fleet-default",
+			Name:      "app",
+			State:     "ErrApplied",
+			Message:   "helm install failed",
+		},
+		PreviousState: "Ready",
+	}
+
+	msg, err := Render("", ev)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "GitRepo fleet-default/app changed from Ready to ErrApplied: helm install failed"; msg != want {
+		t.Errorf("expected %q, got %q", want, msg)
+	}
+
+	ev.PreviousState = ""
+	ev.Message = ""
+	msg, err = Render("", ev)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "GitRepo fleet-default/app is ErrApplied"; msg != want {
+		t.Errorf("expected %q, got %q", want, msg)
+	}
+
+	msg, err = Render(":rotating_light: {{.Name}} {{.State}}", ev)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := ":rotating_light: app ErrApplied"; msg != want {
+		t.Errorf("expected %q, got %q", want, msg)
+	}
+
+	if _, err := Render("{{.Unknown}}", ev); err == nil {
+		t.Errorf("expected error for unknown field")
+	}
+}
+
+func TestTracker(t *testing.T) {
+	now := time.Now()
+	tracker := NewTracker()
+	tracker.now = func() time.Time { return now }
+
+	n := &fleet.Notifier{ObjectMeta: metav1.ObjectMeta{Name: "n", Namespace: "ns", UID: "1"}}
+	bundle := func(state string) Resource {
+		return Resource{Kind: "Bundle", Namespace: "ns", Name: "b", State: state}
+	}
+
+	// the first observation records the states
+	if events := tracker.Transitions(n, []Resource{bundle("Ready")}); len(events) != 0 {
+		t.Fatalf("expected no transitions, got %+v", events)
+	}
+
+	events := tracker.Transitions(n, []Resource{bundle("ErrApplied")})
+	if len(events) != 1 || events[0].PreviousState != "Ready" || events[0].State != "ErrApplied" {
+		t.Fatalf("expected transition from Ready to ErrApplied, got %+v", events)
+	}
+
+	// transitions are returned until committed
+	if events := tracker.Transitions(n, []Resource{bundle("ErrApplied")}); len(events) != 1 {
+		t.Fatalf("expected uncommitted transition, got %+v", events)
+	}
+	tracker.Sent(n, events[0])
+	tracker.Commit(n, events[0])
+	if events := tracker.Transitions(n, []Resource{bundle("ErrApplied")}); len(events) != 0 {
+		t.Fatalf("expected no transitions, got %+v", events)
+	}
+
+	// flapping back into the notified state is deduplicated
+	events = tracker.Transitions(n, []Resource{bundle("Ready")})
+	tracker.Commit(n, events[0])
+	events = tracker.Transitions(n, []Resource{bundle("ErrApplied")})
+	if !tracker.Duplicate(n, events[0]) {
+		t.Errorf("expected transition to be a duplicate")
+	}
+	now = now.Add(defaultDedupeWindow)
+	if tracker.Duplicate(n, events[0]) {
+		t.Errorf("expected transition after the dedupe window not to be a duplicate")
+	}
+
+	// deleted resources are forgotten
+	if events := tracker.Transitions(n, nil); len(events) != 0 {
+		t.Fatalf("expected no transitions, got %+v", events)
+	}
+	events = tracker.Transitions(n, []Resource{bundle("ErrApplied")})
+	if len(events) != 1 || events[0].PreviousState != "" {
+		t.Fatalf("expected transition of a new resource, got %+v", events)
+	}
+
+	// a recreated notifier starts from scratch
+	tracker.Forget(types.NamespacedName{Namespace: "ns", Name: "n"})
+	if events := tracker.Transitions(n, []Resource{bundle("Ready")}); len(events) != 0 {
+		t.Fatalf("expected no transitions, got %+v", events)
+	}
+}
+
+func TestTrackerAllow(t *testing.T) {
+	now := time.Now()
+	tracker := NewTracker()
+	tracker.now = func() time.Time { return now }
+
+	n := &fleet.Notifier{ObjectMeta: metav1.ObjectMeta{Name: "n", Namespace: "ns"}}
+	for range 10 {
+		if !tracker.Allow(n) {
+			t.Fatalf("expected notifications without rate limit to be allowed")
+		}
+	}
+
+	n.Spec.RateLimit = &fleet.NotifierRateLimit{Limit: 2, Period: &metav1.Duration{Duration: time.Minute}}
+	tracker = NewTracker()
+	tracker.now = func() time.Time { return now }
+	ev := Event{Resource: Resource{Kind: "Bundle", Namespace: "ns", Name: "b", State: "Ready"}}
+	for range 2 {
+		if !tracker.Allow(n) {
+			t.Fatalf("expected notifications within the limit to be allowed")
+		}
+		tracker.Sent(n, ev)
+	}
+	if tracker.Allow(n) {
+		t.Errorf("expected notification exceeding the limit to be dropped")
+	}
+
+	now = now.Add(time.Minute)
+	if !tracker.Allow(n) {
+		t.Errorf("expected notification after the period to be allowed")
+	}
+}
+
+func TestTrackerAllowFailedSend(t *testing.T) {
+	tracker := NewTracker()
+	n := &fleet.Notifier{ObjectMeta: metav1.ObjectMeta{Name: "n", Namespace: "ns"}}
+	n.Spec.RateLimit = &fleet.NotifierRateLimit{Limit: 1}
+
+	// notifications, which failed to send, are not recorded by Sent
+	for range 3 {
+		if !tracker.Allow(n) {
+			t.Fatalf("expected failed notifications not to use up the limit")
+		}
+	}
+}
@@ -0,0 +1,277 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	defaultSMTPPort = 587
+	httpTimeout     = 10 * time.Second
+
+	reportingController = "fleet.cattle.io/notifier"
+)
+
+// Sink sends a notification about an event with the rendered message.
+type Sink interface {
+	Send(ctx context.Context, ev Event, message string) error
+}
+
+// SendMailFunc sends an email, it has the signature of smtp.SendMail.
+type SendMailFunc func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+
+// SinkFactory creates sinks for notifiers.
+type SinkFactory struct {
+	Client     client.Client
+	HTTPClient *http.Client
+	SendMail   SendMailFunc
+}
+
+// NewSinkFactory returns a SinkFactory, which reads secrets and creates
+// events with c.
+func NewSinkFactory(c client.Client) *SinkFactory {
+	return &SinkFactory{
+		Client:     c,
+		HTTPClient: &http.Client{Timeout: httpTimeout},
+		SendMail:   smtp.SendMail,
+	}
+}
+
+// Sink returns the sink configured in the notifier.
+func (f *SinkFactory) Sink(ctx context.Context, n *fleet.Notifier) (Sink, error) {
+	sink := n.Spec.Sink
+	set := 0
+	for _, s := range []bool{sink.Webhook != nil, sink.Slack != nil, sink.SMTP != nil, sink.Event != nil} {
+		if s {
+			set++
+		}
+	}
+	if set != 1 {
+		return nil, errors.New("exactly one of webhook, slack, smtp or event must be set as sink")
+	}
+
+	switch {
+	case sink.Webhook != nil:
+		secret, err := f.secret(ctx, n.Namespace, sink.Webhook.SecretName)
+		if err != nil {
+			return nil, err
+		}
+		url := valueOr(secret, "url", sink.Webhook.URL)
+		if url == "" {
+			return nil, errors.New("webhook sink requires a url")
+		}
+		return &webhookSink{client: f.HTTPClient, url: url, token: valueOr(secret, "token", "")}, nil
+	case sink.Slack != nil:
+		secret, err := f.secret(ctx, n.Namespace, sink.Slack.SecretName)
+		if err != nil {
+			return nil, err
+		}
+		url := valueOr(secret, "url", sink.Slack.URL)
+		if url == "" {
+			return nil, errors.New("slack sink requires a url")
+		}
+		return &slackSink{client: f.HTTPClient, url: url, channel: sink.Slack.Channel, username: sink.Slack.Username}, nil
+	case sink.SMTP != nil:
+		secret, err := f.secret(ctx, n.Namespace, sink.SMTP.SecretName)
+		if err != nil {
+			return nil, err
+		}
+		if sink.SMTP.Host == "" || sink.SMTP.From == "" || len(sink.SMTP.To) == 0 {
+			return nil, errors.New("smtp sink requires host, from and to")
+		}
+		port := sink.SMTP.Port
+		if port == 0 {
+			port = defaultSMTPPort
+		}
+		var auth smtp.Auth
+		if username := valueOr(secret, "username", ""); username != "" {
+			auth = smtp.PlainAuth("", username, valueOr(secret, "password", ""), sink.SMTP.Host)
+		}
+		return &smtpSink{
+			sendMail: f.SendMail,
+			addr:     net.JoinHostPort(sink.SMTP.Host, strconv.Itoa(port)),
+			auth:     auth,
+			from:     sink.SMTP.From,
+			to:       sink.SMTP.To,
+		}, nil
+	default:
+		namespace := sink.Event.Namespace
+		if namespace == "" {
+			namespace = n.Namespace
+		}
+		return &eventSink{client: f.Client, namespace: namespace}, nil
+	}
+}
+
+// secret returns the secret, or nil if name is empty.
+func (f *SinkFactory) secret(ctx context.Context, namespace, name string) (*corev1.Secret, error) {
+	if name == "" {
+		return nil, nil
+	}
+	secret := &corev1.Secret{}
+	if err := f.Client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, secret); err != nil {
+		return nil, fmt.Errorf("failed to get sink secret %s/%s: %w", namespace, name, err)
+	}
+	return secret, nil
+}
+
+func valueOr(secret *corev1.Secret, key, def string) string {
+	if secret == nil {
+		return def
+	}
+	if v, ok := secret.Data[key]; ok && len(v) > 0 {
+		return string(v)
+	}
+	return def
+}
+
+type webhookSink struct {
+	client *http.Client
+	url    string
+	token  string
+}
+
+// webhookPayload is the JSON body posted to generic webhooks.
+type webhookPayload struct {
+	Event
+	Text string `json:"text"`
+}
+
+func (s *webhookSink) Send(ctx context.Context, ev Event, message string) error {
+	return postJSON(ctx, s.client, s.url, s.token, webhookPayload{Event: ev, Text: message})
+}
+
+type slackSink struct {
+	client   *http.Client
+	url      string
+	channel  string
+	username string
+}
+
+type slackPayload struct {
+	Text     string `json:"text"`
+	Channel  string `json:"channel,omitempty"`
+	Username string `json:"username,omitempty"`
+}
+
+func (s *slackSink) Send(ctx context.Context, _ Event, message string) error {
+	return postJSON(ctx, s.client, s.url, "", slackPayload{Text: message, Channel: s.channel, Username: s.username})
+}
+
+func postJSON(ctx context.Context, c *http.Client, url, token string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("failed to send notification: status code %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+type smtpSink struct {
+	sendMail SendMailFunc
+	addr     string
+	auth     smtp.Auth
+	from     string
+	to       []string
+}
+
+func (s *smtpSink) Send(_ context.Context, ev Event, message string) error {
+	subject := fmt.Sprintf("[fleet] %s %s/%s is %s", ev.Kind, ev.Namespace, ev.Name, ev.State)
+	if err := s.sendMail(s.addr, s.auth, s.from, s.to, mailMessage(s.from, s.to, subject, message)); err != nil {
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+	return nil
+}
+
+// mailMessage returns a plain text email (pure function).
+func mailMessage(from string, to []string, subject, body string) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", subject)
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	b.WriteString("\r\n")
+	return b.Bytes()
+}
+
+type eventSink struct {
+	client    client.Client
+	namespace string
+}
+
+// Send creates an events.k8s.io event, which unlike core events can refer
+// to a resource in another namespace.
+func (s *eventSink) Send(ctx context.Context, ev Event, message string) error {
+	eventType := corev1.EventTypeWarning
+	if ev.State == string(fleet.Ready) {
+		eventType = corev1.EventTypeNormal
+	}
+	event := &eventsv1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: strings.ToLower(ev.Name) + "-",
+			Namespace:    s.namespace,
+		},
+		EventTime:           metav1.NewMicroTime(ev.Time),
+		ReportingController: reportingController,
+		ReportingInstance:   reportingController,
+		Action:              "StateChanged",
+		Reason:              ev.State,
+		Type:                eventType,
+		Note:                truncate(message, 1024),
+		Regarding: corev1.ObjectReference{
+			APIVersion: fleet.SchemeGroupVersion.String(),
+			Kind:       ev.Kind,
+			Namespace:  ev.Namespace,
+			Name:       ev.Name,
+		},
+	}
+	if err := s.client.Create(ctx, event); err != nil {
+		return fmt.Errorf("failed to create event: %w", err)
+	}
+	return nil
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
@@ -0,0 +1,195 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var testEvent = Event{
+	Resource:      Resource{Kind: "Bundle", Namespace: "fleet-default", Name: "app", State: "ErrApplied"},
+	PreviousState: "Ready",
+	Time:          time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+}
+
+func newFactory(t *testing.T, objs ...client.Object) *SinkFactory {
+	t.Helper()
+	sch := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(sch); err != nil {
+		t.Fatal(err)
+	}
+	return NewSinkFactory(fake.NewClientBuilder().WithScheme(sch).WithObjects(objs...).Build())
+}
+
+func newNotifier(sink fleet.NotifierSink) *fleet.Notifier {
+	return &fleet.Notifier{
+		ObjectMeta: metav1.ObjectMeta{Name: "n", Namespace: "fleet-default"},
+		Spec:       fleet.NotifierSpec{Sink: sink},
+	}
+}
+
+func TestWebhookSink(t *testing.T) {
+	var got map[string]interface{}
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer srv.Close()
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "hook", Namespace: "fleet-default"},
+		Data:       map[string][]byte{"url": []byte(srv.URL), "token": []byte("s3cr3t")},
+	}
+	f := newFactory(t, secret)
+	sink, err := f.Sink(context.TODO(), newNotifier(fleet.NotifierSink{
+		Webhook: &fleet.WebhookSink{URL: "http://ignored.example.com", SecretName: "hook"},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sink.Send(context.TODO(), testEvent, "app failed"); err != nil {
+		t.Fatal(err)
+	}
+	if auth != "Bearer s3cr3t" {
+		t.Errorf("expected bearer token, got %q", auth)
+	}
+	if got["text"] != "app failed" || got["state"] != "ErrApplied" || got["previousState"] != "Ready" || got["kind"] != "Bundle" {
+		t.Errorf("unexpected payload %v", got)
+	}
+}
+
+func TestSlackSink(t *testing.T) {
+	var got slackPayload
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte("invalid_token"))
+	}))
+	defer srv.Close()
+
+	f := newFactory(t)
+	sink, err := f.Sink(context.TODO(), newNotifier(fleet.NotifierSink{
+		Slack: &fleet.SlackSink{URL: srv.URL, Channel: "#oncall"},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sink.Send(context.TODO(), testEvent, "app failed"); err != nil {
+		t.Fatal(err)
+	}
+	if got.Text != "app failed" || got.Channel != "#oncall" {
+		t.Errorf("unexpected payload %+v", got)
+	}
+
+	status = http.StatusForbidden
+	if err := sink.Send(context.TODO(), testEvent, "app failed"); err == nil || !strings.Contains(err.Error(), "status code 403: invalid_token") {
+		t.Errorf("expected error for status code, got %v", err)
+	}
+}
+
+func TestSMTPSink(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "mail", Namespace: "fleet-default"},
+		Data:       map[string][]byte{"username": []byte("fleet"), "password": []byte("pass")},
+	}
+	f := newFactory(t, secret)
+
+	var addr string
+	var auth smtp.Auth
+	var msg []byte
+	f.SendMail = func(a string, au smtp.Auth, _ string, _ []string, m []byte) error {
+		addr, auth, msg = a, au, m
+		return nil
+	}
+
+	sink, err := f.Sink(context.TODO(), newNotifier(fleet.NotifierSink{
+		SMTP: &fleet.SMTPSink{Host: "mail.example.com", From: "fleet@example.com", To: []string{"oncall@example.com"}, SecretName: "mail"},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Send(context.TODO(), testEvent, "app failed"); err != nil {
+		t.Fatal(err)
+	}
+
+	if addr != "mail.example.com:587" {
+		t.Errorf("expected default port, got %q", addr)
+	}
+	if auth == nil {
+		t.Errorf("expected authentication")
+	}
+	if !strings.Contains(string(msg), "Subject: [fleet] Bundle fleet-default/app is ErrApplied\r\n") ||
+		!strings.HasSuffix(string(msg), "\r\n\r\napp failed\r\n") {
+		t.Errorf("unexpected message %q", msg)
+	}
+}
+
+func TestEventSink(t *testing.T) {
+	sch := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(sch); err != nil {
+		t.Fatal(err)
+	}
+	c := fake.NewClientBuilder().WithScheme(sch).Build()
+	f := NewSinkFactory(c)
+
+	sink, err := f.Sink(context.TODO(), newNotifier(fleet.NotifierSink{Event: &fleet.EventSink{Namespace: "oncall"}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Send(context.TODO(), testEvent, "app failed"); err != nil {
+		t.Fatal(err)
+	}
+
+	events := &eventsv1.EventList{}
+	if err := c.List(context.TODO(), events, client.InNamespace("oncall")); err != nil {
+		t.Fatal(err)
+	}
+	if len(events.Items) != 1 {
+		t.Fatalf("expected one event, got %d", len(events.Items))
+	}
+	ev := events.Items[0]
+	if ev.Type != corev1.EventTypeWarning || ev.Reason != "ErrApplied" || ev.Note != "app failed" ||
+		ev.Regarding.Kind != "Bundle" || ev.Regarding.Namespace != "fleet-default" || ev.Regarding.Name != "app" {
+		t.Errorf("unexpected event %+v", ev)
+	}
+}
+
+func TestSinkValidation(t *testing.T) {
+	f := newFactory(t)
+	for name, sink := range map[string]fleet.NotifierSink{
+		"no sink":        {},
+		"two sinks":      {Slack: &fleet.SlackSink{URL: "http://a"}, Event: &fleet.EventSink{}},
+		"no url":         {Webhook: &fleet.WebhookSink{}},
+		"missing secret": {Slack: &fleet.SlackSink{SecretName: "missing"}},
+		"no recipients":  {SMTP: &fleet.SMTPSink{Host: "mail", From: "fleet@example.com"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := f.Sink(context.TODO(), newNotifier(sink)); err == nil {
+				t.Errorf("expected error")
+			}
+		})
+	}
+}
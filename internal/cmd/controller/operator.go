@@ -7,12 +7,14 @@ import (
 	"github.com/reugn/go-quartz/quartz"
 
 	"github.com/rancher/fleet/internal/cmd"
+	"github.com/rancher/fleet/internal/cmd/controller/notifier"
 	"github.com/rancher/fleet/internal/cmd/controller/reconciler"
 	"github.com/rancher/fleet/internal/cmd/controller/target"
 	"github.com/rancher/fleet/internal/config"
 	"github.com/rancher/fleet/internal/experimental"
 	"github.com/rancher/fleet/internal/manifest"
 	"github.com/rancher/fleet/internal/metrics"
+	"github.com/rancher/fleet/internal/ocistorage"
 	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
 
 	"k8s.io/apimachinery/pkg/runtime"
@@ -26,6 +28,10 @@ import (
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 )
 
+// leaderElectionID is the name of the default shard's lease, other shards
+// append their shard ID.
+const leaderElectionID = "fleet-controller-leader-election-shard"
+
 var (
 	scheme = runtime.NewScheme()
 )
@@ -46,6 +52,7 @@ func start(
 	bindAddresses BindAddresses,
 	disableMetrics bool,
 	shardID string,
+	shardCoordinator bool,
 ) error {
 	setupLog.Info("listening for changes on local cluster",
 		"disableMetrics", disableMetrics,
@@ -59,6 +66,13 @@ func start(
 		metrics.RegisterMetrics()
 	}
 
+	if shardCoordinator && shardID != "" {
+		return fmt.Errorf("the shard coordinator can only run on the default shard, not on shard %q", shardID)
+	}
+	if shardCoordinator && !leaderElection {
+		return fmt.Errorf("the shard coordinator requires leader election to detect shards")
+	}
+
 	var leaderElectionSuffix string
 	if shardID != "" {
 		leaderElectionSuffix = fmt.Sprintf("-%s", shardID)
@@ -70,7 +84,7 @@ func start(
 		HealthProbeBindAddress: bindAddresses.HealthProbe,
 
 		LeaderElection:          leaderElection,
-		LeaderElectionID:        fmt.Sprintf("fleet-controller-leader-election-shard%s", leaderElectionSuffix),
+		LeaderElectionID:        leaderElectionID + leaderElectionSuffix,
 		LeaderElectionNamespace: systemNamespace,
 		LeaseDuration:           &leaderOpts.LeaseDuration,
 		RenewDeadline:           &leaderOpts.RenewDeadline,
@@ -119,10 +133,11 @@ func start(
 		Scheme:   mgr.GetScheme(),
 		Recorder: mgr.GetEventRecorderFor(fmt.Sprintf("fleet-bundle-ctrl%s", shardIDSuffix)),
 
-		Builder: builder,
-		Store:   store,
-		Query:   builder,
-		ShardID: shardID,
+		Builder:  builder,
+		Store:    store,
+		Query:    builder,
+		ShardID:  shardID,
+		Analyzer: target.NewAnalyzer(nil),
 
 		Workers: workersOpts.Bundle,
 	}).SetupWithManager(mgr); err != nil {
@@ -203,6 +218,46 @@ func start(
 		return err
 	}
 
+	if err = (&reconciler.NotifierReconciler{
+		Client:  mgr.GetClient(),
+		Scheme:  mgr.GetScheme(),
+		ShardID: shardID,
+
+		Tracker: notifier.NewTracker(),
+		Sinks:   notifier.NewSinkFactory(mgr.GetClient()),
+
+		Workers: workersOpts.Notifier,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Notifier")
+		return err
+	}
+
+	if err = (&reconciler.OCIRepoReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor(fmt.Sprintf("fleet-ocirepo-ctrl%s", shardIDSuffix)),
+		ShardID:  shardID,
+
+		Puller: ocistorage.NewOCIWrapper(),
+
+		Workers: workersOpts.OCIRepo,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "OCIRepo")
+		return err
+	}
+
+	if shardCoordinator {
+		if err = (&reconciler.ShardCoordinatorReconciler{
+			Client:           mgr.GetClient(),
+			Reader:           mgr.GetAPIReader(),
+			SystemNamespace:  systemNamespace,
+			LeaderElectionID: leaderElectionID,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "ShardCoordinator")
+			return err
+		}
+	}
+
 	//+kubebuilder:scaffold:builder
 
 	if err := reconciler.Load(ctx, mgr.GetAPIReader(), systemNamespace); err != nil {
@@ -87,6 +87,35 @@ func Merge(base, custom fleet.BundleDeploymentOptions) fleet.BundleDeploymentOpt
 		if custom.Kustomize.Dir != "" {
 			result.Kustomize.Dir = custom.Kustomize.Dir
 		}
+		if custom.Kustomize.LoadRestrictions != "" {
+			result.Kustomize.LoadRestrictions = custom.Kustomize.LoadRestrictions
+		}
+		result.Kustomize.EnableHelm = result.Kustomize.EnableHelm || custom.Kustomize.EnableHelm
+		result.Kustomize.EnableAlphaPlugins = result.Kustomize.EnableAlphaPlugins || custom.Kustomize.EnableAlphaPlugins
+	}
+	if custom.Jsonnet != nil {
+		if result.Jsonnet == nil {
+			result.Jsonnet = &fleet.JsonnetOptions{}
+		}
+		if custom.Jsonnet.File != "" {
+			result.Jsonnet.File = custom.Jsonnet.File
+		}
+		if custom.Jsonnet.LibraryPaths != nil {
+			result.Jsonnet.LibraryPaths = custom.Jsonnet.LibraryPaths
+		}
+		result.Jsonnet.TopLevelArgs = mergeGenericMaps(result.Jsonnet.TopLevelArgs, custom.Jsonnet.TopLevelArgs)
+	}
+	if custom.CUE != nil {
+		if result.CUE == nil {
+			result.CUE = &fleet.CUEOptions{}
+		}
+		if custom.CUE.Dir != "" {
+			result.CUE.Dir = custom.CUE.Dir
+		}
+		if custom.CUE.Expression != "" {
+			result.CUE.Expression = custom.CUE.Expression
+		}
+		result.CUE.TopLevelArgs = mergeGenericMaps(result.CUE.TopLevelArgs, custom.CUE.TopLevelArgs)
 	}
 	if custom.Diff != nil {
 		if result.Diff == nil {
@@ -100,6 +129,9 @@ func Merge(base, custom fleet.BundleDeploymentOptions) fleet.BundleDeploymentOpt
 		}
 		result.YAML.Overlays = append(result.YAML.Overlays, custom.YAML.Overlays...)
 	}
+	if custom.PostRender != nil {
+		result.PostRender = append(result.PostRender, custom.PostRender...)
+	}
 	if custom.ForceSyncGeneration > 0 {
 		result.ForceSyncGeneration = custom.ForceSyncGeneration
 	}
@@ -110,3 +142,14 @@ func Merge(base, custom fleet.BundleDeploymentOptions) fleet.BundleDeploymentOpt
 
 	return result
 }
+
+// mergeGenericMaps merges custom into base, base is modified.
+func mergeGenericMaps(base, custom *fleet.GenericMap) *fleet.GenericMap {
+	if base == nil {
+		return custom
+	}
+	if custom != nil {
+		base.Data = data.MergeMaps(base.Data, custom.Data)
+	}
+	return base
+}
@@ -0,0 +1,62 @@
+package reconciler
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+	"github.com/rancher/fleet/pkg/sharding"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("BundleReconciler approvals", func() {
+	var (
+		ctx      context.Context
+		r        *BundleReconciler
+		approval *fleet.Approval
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		sch := runtime.NewScheme()
+		Expect(fleet.AddToScheme(sch)).To(Succeed())
+		cl := fake.NewClientBuilder().WithScheme(sch).WithObjects(&fleet.Bundle{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "app",
+				Namespace: "fleet-default",
+				Labels:    map[string]string{sharding.ShardingRefLabel: "shard1"},
+			},
+		}).Build()
+		r = &BundleReconciler{Client: cl, Scheme: sch}
+
+		// approvals have no shard label
+		approval = &fleet.Approval{
+			ObjectMeta: metav1.ObjectMeta{Name: "approve-prod", Namespace: "fleet-default"},
+			Spec:       fleet.ApprovalSpec{BundleName: "app", Gate: "prod", BundleGeneration: 1},
+		}
+	})
+
+	It("enqueues the approved bundle for the bundle's shard", func() {
+		r.ShardID = "shard1"
+		requests := r.mapApprovalToBundle(ctx, approval)
+		Expect(requests).To(HaveLen(1))
+		Expect(requests[0].Namespace).To(Equal("fleet-default"))
+		Expect(requests[0].Name).To(Equal("app"))
+	})
+
+	It("does not enqueue the approved bundle for other shards", func() {
+		Expect(r.mapApprovalToBundle(ctx, approval)).To(BeEmpty())
+	})
+
+	It("does not enqueue missing bundles", func() {
+		r.ShardID = "shard1"
+		approval.Spec.BundleName = "missing"
+		Expect(r.mapApprovalToBundle(ctx, approval)).To(BeEmpty())
+	})
+})
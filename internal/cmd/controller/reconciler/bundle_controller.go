@@ -19,6 +19,7 @@ import (
 	"github.com/rancher/fleet/internal/cmd/controller/finalize"
 	"github.com/rancher/fleet/internal/cmd/controller/summary"
 	"github.com/rancher/fleet/internal/cmd/controller/target"
+	"github.com/rancher/fleet/internal/config"
 	"github.com/rancher/fleet/internal/experimental"
 	"github.com/rancher/fleet/internal/helmvalues"
 	"github.com/rancher/fleet/internal/manifest"
@@ -75,13 +76,19 @@ type BundleReconciler struct {
 	Store   Store
 	Query   BundleQuery
 	ShardID string
+	// Analyzer runs the analysis of rollout partitions
+	Analyzer target.Analyzer
 
 	Workers int
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *BundleReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &fleet.Bundle{}, config.BundleDependsOnIndex, indexBundleDependsOn); err != nil {
+		return err
+	}
+
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&fleet.Bundle{},
 			builder.WithPredicates(
 				// do not trigger for bundle status changes (except for cache sync)
@@ -91,6 +98,7 @@ func (r *BundleReconciler) SetupWithManager(mgr ctrl.Manager) error {
 					predicate.AnnotationChangedPredicate{},
 					predicate.LabelChangedPredicate{},
 				),
+				sharding.FilterByShardID(r.ShardID),
 			),
 		).
 		// Note: Maybe improve with WatchesMetadata, does it have access to labels?
@@ -98,7 +106,7 @@ func (r *BundleReconciler) SetupWithManager(mgr ctrl.Manager) error {
 			// Fan out from bundledeployment to bundle, this is useful to update the
 			// bundle's status fields.
 			&fleet.BundleDeployment{}, handler.EnqueueRequestsFromMapFunc(BundleDeploymentMapFunc(r)),
-			builder.WithPredicates(bundleDeploymentStatusChangedPredicate()),
+			builder.WithPredicates(bundleDeploymentStatusChangedPredicate(), sharding.FilterByShardID(r.ShardID)),
 		).
 		Watches(
 			// Fan out from cluster to bundle, this is useful for targeting and templating.
@@ -124,16 +132,61 @@ func (r *BundleReconciler) SetupWithManager(mgr ctrl.Manager) error {
 
 				return requests
 			}),
-			builder.WithPredicates(clusterChangedPredicate()),
+			builder.WithPredicates(clusterChangedPredicate(), sharding.FilterByShardID(r.ShardID)),
+		).
+		Watches(
+			// Fan out from approval to bundle, to continue the rollout.
+			// Approvals have no shard label, the shard of the approved
+			// bundle decides.
+			&fleet.Approval{},
+			handler.EnqueueRequestsFromMapFunc(r.mapApprovalToBundle),
+		)
+
+	if experimental.SchedulesEnabled() {
+		// Fan out from deny schedule to bundles, to hold back or continue their rollouts.
+		b = b.Watches(
+			&fleet.Schedule{},
+			handler.EnqueueRequestsFromMapFunc(r.mapDenyScheduleToBundles),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{}, sharding.FilterByShardID(r.ShardID)),
 		).
-		WithEventFilter(sharding.FilterByShardID(r.ShardID)).
+			// Fan out from calendar config map to the bundles of its deny
+			// schedules. Config maps have no shard label, the schedules and
+			// bundles are filtered instead.
+			Watches(
+				&corev1.ConfigMap{},
+				handler.EnqueueRequestsFromMapFunc(r.mapCalendarToBundles),
+			)
+	}
+
+	return b.
 		WithOptions(controller.Options{MaxConcurrentReconciles: r.Workers}).
 		Complete(r)
 }
 
+// mapApprovalToBundle enqueues the bundle approved by an approval, if it
+// belongs to the controller's shard.
+func (r *BundleReconciler) mapApprovalToBundle(ctx context.Context, a client.Object) []ctrl.Request {
+	approval := a.(*fleet.Approval)
+	bundle := &fleet.Bundle{}
+	key := types.NamespacedName{Namespace: approval.Namespace, Name: approval.Spec.BundleName}
+	if err := r.Get(ctx, key, bundle); err != nil {
+		if !apierrors.IsNotFound(err) {
+			log.FromContext(ctx).Error(err, "Failed to get approved bundle", "approval", approval.Name)
+		}
+		return nil
+	}
+	if !sharding.ShouldProcess(bundle, r.ShardID) {
+		return nil
+	}
+	return []ctrl.Request{{NamespacedName: key}}
+}
+
 //+kubebuilder:rbac:groups=fleet.cattle.io,resources=bundles,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=fleet.cattle.io,resources=bundles/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=fleet.cattle.io,resources=bundles/finalizers,verbs=update
+//+kubebuilder:rbac:groups=fleet.cattle.io,resources=approvals,verbs=get;list;watch
+//+kubebuilder:rbac:groups=fleet.cattle.io,resources=schedules,verbs=get;list;watch
+//+kubebuilder:rbac:groups=fleet.cattle.io,resources=gitreporestrictions,verbs=get;list;watch
 
 // Reconcile creates bundle deployments for a bundle
 //
@@ -189,6 +242,10 @@ func (r *BundleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 		}
 	}
 
+	if err := r.checkRestrictions(ctx, bundle); err != nil {
+		return r.computeResult(ctx, logger, bundleOrig, bundle, "failed to apply restrictions", err)
+	}
+
 	contentsInOCI := bundle.Spec.ContentsID != "" && ocistorage.OCIIsEnabled()
 	contentsInHelmChart := bundle.Spec.HelmOpOptions != nil
 
@@ -253,7 +310,21 @@ func (r *BundleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 			)
 	}
 
-	if (!contentsInOCI && !contentsInHelmChart) && len(matchedTargets) > 0 {
+	// the manifest ID to deploy differs from the bundle's, if the bundle was rolled back. Rolling back
+	// replaces the deployment IDs and options of the targets with the known-good ones.
+	deployedManifestID := manifestID
+	var rollbackRequeueAfter time.Duration
+	autoRollback := autoRollbackPolicy(bundle) != nil && !contentsInOCI && !contentsInHelmChart
+	if autoRollback {
+		deployedManifestID, rollbackRequeueAfter, err = r.autoRollback(ctx, bundle, manifestID, matchedTargets)
+		if err != nil {
+			return r.computeResult(ctx, logger, bundleOrig, bundle, "failed to check for automatic rollback", err)
+		}
+	} else if err := r.releaseKnownGood(ctx, bundle); err != nil {
+		return r.computeResult(ctx, logger, bundleOrig, bundle, "failed to release known-good contents", err)
+	}
+
+	if (!contentsInOCI && !contentsInHelmChart) && len(matchedTargets) > 0 && deployedManifestID == manifestID {
 		// when not using the OCI registry or helm chart we need to create a contents resource
 		// so the BundleDeployments are able to access the contents to be deployed.
 		// Otherwise, do not create a content resource if there are no targets.
@@ -269,7 +340,7 @@ func (r *BundleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 			return r.computeResult(ctx, logger, bundleOrig, bundle, "could not copy manifest into Content resource", err)
 		}
 	}
-	logger = logger.WithValues("manifestID", manifestID)
+	logger = logger.WithValues("manifestID", deployedManifestID)
 
 	if err := resetStatus(&bundle.Status, matchedTargets); err != nil {
 		err = fmt.Errorf("failed to reset bundle status from targets: %w", err)
@@ -277,12 +348,41 @@ func (r *BundleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 		return ctrl.Result{}, r.updateErrorStatus(ctx, bundleOrig, bundle, err)
 	}
 
+	if err := r.checkDependencies(ctx, bundle, matchedTargets); err != nil {
+		return r.computeResult(ctx, logger, bundleOrig, bundle, "failed to check dependencies", err)
+	}
+
+	denyRequeueAfter, err := r.checkDenyWindows(ctx, bundle, matchedTargets)
+	if err != nil {
+		return r.computeResult(ctx, logger, bundleOrig, bundle, "failed to check deny windows", err)
+	}
+
+	approvals := &fleet.ApprovalList{}
+	if target.RequireApproval(matchedTargets) {
+		if err := r.List(ctx, approvals, client.InNamespace(bundle.Namespace)); err != nil {
+			return r.computeResult(ctx, logger, bundleOrig, bundle, "failed to list approvals", err)
+		}
+	}
+
 	// this will add the defaults for a new bundledeployment. It propagates stagedOptions to options.
-	if err := target.UpdatePartitions(&bundle.Status, matchedTargets); err != nil {
+	requeueAfter, err := target.UpdatePartitions(ctx, &bundle.Status, bundleOrig.Status.PartitionStatus, matchedTargets, r.Analyzer, approvals.Items)
+	if err != nil {
 		err = fmt.Errorf("failed to update partitions: %w", err)
 
 		return ctrl.Result{}, r.updateErrorStatus(ctx, bundleOrig, bundle, err)
 	}
+	if rollbackRequeueAfter > 0 && (requeueAfter == 0 || rollbackRequeueAfter < requeueAfter) {
+		requeueAfter = rollbackRequeueAfter
+	}
+	if denyRequeueAfter > 0 && (requeueAfter == 0 || denyRequeueAfter < requeueAfter) {
+		requeueAfter = denyRequeueAfter
+	}
+
+	if autoRollback {
+		if err := r.recordKnownGood(ctx, bundle, deployedManifestID, matchedTargets); err != nil {
+			return r.computeResult(ctx, logger, bundleOrig, bundle, "failed to record known-good deployment", err)
+		}
+	}
 
 	if contentsInOCI {
 		url, err := r.getOCIReference(ctx, bundle)
@@ -341,7 +441,7 @@ func (r *BundleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 			if bd.Labels == nil {
 				bd.Labels = make(map[string]string)
 			}
-			bd.Labels[fleet.ContentNameLabel] = manifestID
+			bd.Labels[fleet.ContentNameLabel] = deployedManifestID
 		}
 
 		helmvalues.ClearOptions(bd)
@@ -401,7 +501,8 @@ func (r *BundleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 		return ctrl.Result{}, errutil.NewAggregate(merr)
 	}
 
-	return ctrl.Result{}, errutil.NewAggregate(merr)
+	// re-check paused rollouts, pending analyses of rollout partitions and deny windows
+	return ctrl.Result{RequeueAfter: requeueAfter}, errutil.NewAggregate(merr)
 }
 
 // handleDelete runs cleanup for resources associated to a Bundle, finally removing the finalizer to unblock the deletion of the object from kubernetes.
@@ -427,6 +528,10 @@ func (r *BundleReconciler) handleDelete(ctx context.Context, logger logr.Logger,
 		return ctrl.Result{}, err
 	}
 
+	if err := r.releaseKnownGood(ctx, bundle); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	metrics.BundleCollector.Delete(req.Name, req.Namespace)
 	controllerutil.RemoveFinalizer(bundle, finalize.BundleFinalizer)
 	if err := r.Update(ctx, bundle); err != nil {
@@ -709,6 +814,25 @@ func (r *BundleReconciler) handleContentAccessSecrets(ctx context.Context, bundl
 			)
 		}
 	}
+	// the agent verifies the signature of the chart with the keys from the
+	// verification secret
+	if contentsInHelmChart && bundle.Spec.Helm != nil && bundle.Spec.Helm.Verification != nil {
+		if err := r.cloneSecret(
+			ctx,
+			bundle.Namespace,
+			bundle.Spec.Helm.Verification.SecretName,
+			"",
+			bd,
+		); err != nil {
+			return fmt.Errorf(
+				"%w: failed to clone secret %s/%s to downstream cluster namespace: %w",
+				fleetutil.ErrRetryable,
+				bundle.Namespace,
+				bundle.Spec.Helm.Verification.SecretName,
+				err,
+			)
+		}
+	}
 	return nil
 }
 
@@ -907,6 +1031,10 @@ func clusterChangedPredicate() predicate.Funcs {
 			if n.Status.Agent.Namespace != o.Status.Agent.Namespace {
 				return true
 			}
+			// facts are used for templating and targeting
+			if !reflect.DeepEqual(n.Status.Facts, o.Status.Facts) {
+				return true
+			}
 
 			if n.Status.Scheduled != o.Status.Scheduled {
 				return true
@@ -1009,16 +1137,19 @@ func BundleDeploymentMapFunc(r *BundleReconciler) func(ctx context.Context, a cl
 			return nil
 		}
 
+		// wake up the bundles, which wait for this bundledeployment
+		requests := r.dependentBundles(ctx, bd)
+
 		ns, name := target.BundleFromDeployment(labels)
 		if ns != "" && name != "" {
-			return []reconcile.Request{{
+			requests = append(requests, reconcile.Request{
 				NamespacedName: types.NamespacedName{
 					Namespace: ns,
 					Name:      name,
 				},
-			}}
+			})
 		}
 
-		return nil
+		return requests
 	}
 }
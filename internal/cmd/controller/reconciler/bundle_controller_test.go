@@ -15,6 +15,7 @@ import (
 	"github.com/rancher/fleet/internal/cmd/controller/finalize"
 	"github.com/rancher/fleet/internal/cmd/controller/reconciler"
 	"github.com/rancher/fleet/internal/cmd/controller/target"
+	"github.com/rancher/fleet/internal/config"
 	"github.com/rancher/fleet/internal/mocks"
 	fleetv1 "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
 	"github.com/rancher/fleet/pkg/sharding"
@@ -32,6 +33,7 @@ import (
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
@@ -980,8 +982,19 @@ func getBundleReadyCondition(b *fleetv1.Bundle) (genericcondition.GenericConditi
 	return genericcondition.GenericCondition{}, false
 }
 
+// dependencyClient returns a fake client with the bundles and the index
+// used to find the bundles depending on a bundle.
+func dependencyClient(bundles ...client.Object) client.Client {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(fleetv1.AddToScheme(scheme))
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(bundles...).
+		WithIndex(&fleetv1.Bundle{}, config.BundleDependsOnIndex, func(obj client.Object) []string {
+			return target.DependencyIndexKeys(obj.(*fleetv1.Bundle))
+		}).Build()
+}
+
 func TestBundleDeploymentMapFunc(t *testing.T) {
-	r := &reconciler.BundleReconciler{ShardID: "test-shard"}
+	r := &reconciler.BundleReconciler{Client: dependencyClient(), ShardID: "test-shard"}
 	mapFunc := reconciler.BundleDeploymentMapFunc(r)
 
 	testCases := []struct {
@@ -1070,7 +1083,7 @@ func TestBundleDeploymentMapFunc(t *testing.T) {
 	}
 
 	t.Run("Default Shard ID", func(t *testing.T) {
-		r := &reconciler.BundleReconciler{ShardID: ""}
+		r := &reconciler.BundleReconciler{Client: dependencyClient(), ShardID: ""}
 		mapFunc := reconciler.BundleDeploymentMapFunc(r)
 
 		bd := &fleetv1.BundleDeployment{
@@ -1095,6 +1108,53 @@ func TestBundleDeploymentMapFunc(t *testing.T) {
 	})
 }
 
+func TestBundleDeploymentMapFuncDependents(t *testing.T) {
+	dependent := &fleetv1.Bundle{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "fleet-ns"},
+		Spec:       fleetv1.BundleSpec{DependsOn: []fleetv1.BundleRef{{Name: "my-bundle"}}},
+	}
+	other := &fleetv1.Bundle{
+		ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "fleet-ns"},
+		Spec:       fleetv1.BundleSpec{DependsOn: []fleetv1.BundleRef{{Name: "database"}}},
+	}
+	selected := &fleetv1.Bundle{
+		ObjectMeta: metav1.ObjectMeta{Name: "monitoring", Namespace: "fleet-ns"},
+		Spec: fleetv1.BundleSpec{DependsOn: []fleetv1.BundleRef{
+			{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "backend"}}},
+		}},
+	}
+	otherSelected := &fleetv1.Bundle{
+		ObjectMeta: metav1.ObjectMeta{Name: "frontend", Namespace: "fleet-ns"},
+		Spec: fleetv1.BundleSpec{DependsOn: []fleetv1.BundleRef{
+			{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "frontend"}}},
+		}},
+	}
+	r := &reconciler.BundleReconciler{Client: dependencyClient(dependent, other, selected, otherSelected)}
+	mapFunc := reconciler.BundleDeploymentMapFunc(r)
+
+	bd := &fleetv1.BundleDeployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-bundle",
+			Namespace: "cluster-ns",
+			Labels: map[string]string{
+				fleetv1.BundleLabel:          "my-bundle",
+				fleetv1.BundleNamespaceLabel: "fleet-ns",
+				"tier":                       "backend",
+			},
+		},
+	}
+
+	expected := []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Namespace: "fleet-ns", Name: "monitoring"}},
+		{NamespacedName: types.NamespacedName{Namespace: "fleet-ns", Name: "app"}},
+		{NamespacedName: types.NamespacedName{Namespace: "fleet-ns", Name: "my-bundle"}},
+	}
+	reqs := mapFunc(context.Background(), bd)
+	if diff := cmp.Diff(expected, reqs); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
 func expectGetWithFinalizer(mockCli *mocks.MockK8sClient, bundle fleetv1.Bundle) {
 	mockCli.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&fleetv1.Bundle{}), gomock.Any()).DoAndReturn(
 		func(ctx context.Context, req types.NamespacedName, b *fleetv1.Bundle, opts ...interface{}) error {
@@ -0,0 +1,163 @@
+package reconciler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rancher/fleet/internal/cmd/controller/denywindow"
+	fleetutil "github.com/rancher/fleet/internal/cmd/controller/errorutil"
+	"github.com/rancher/fleet/internal/cmd/controller/target"
+	"github.com/rancher/fleet/internal/cmd/controller/target/matcher"
+	"github.com/rancher/fleet/internal/experimental"
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+	"github.com/rancher/fleet/pkg/sharding"
+
+	"github.com/rancher/wrangler/v3/pkg/condition"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// checkDenyWindows holds back the targets, whose cluster is in an active
+// deny window of a Deny schedule targeting the bundle, and sets the bundle's
+// deny window status. It returns the duration until the next window starts
+// or ends. Invalid schedules hold back all targets, until they are fixed, and
+// are shown in the bundle's deny windows condition.
+func (r *BundleReconciler) checkDenyWindows(ctx context.Context, bundle *fleet.Bundle, targets []*target.Target) (time.Duration, error) {
+	bundle.Status.DenyWindow = nil
+	cond := condition.Cond(fleet.BundleConditionDenyWindows)
+	if !experimental.SchedulesEnabled() {
+		if cond.GetStatus(&bundle.Status) != "" {
+			cond.SetStatusBool(&bundle.Status, true)
+			cond.Message(&bundle.Status, "")
+		}
+		return 0, nil
+	}
+
+	schedules := &fleet.ScheduleList{}
+	if err := r.List(ctx, schedules, client.InNamespace(bundle.Namespace)); err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	var (
+		requeueAfter time.Duration
+		invalid      []string
+	)
+	for i := range schedules.Items {
+		schedule := &schedules.Items[i]
+		if schedule.Spec.Type != fleet.DenySchedule {
+			continue
+		}
+		active, next, m, err := r.activeDenyWindow(ctx, now, schedule, bundle)
+		if errors.Is(err, fleetutil.ErrRetryable) {
+			return 0, err
+		}
+		if err != nil {
+			for _, t := range targets {
+				t.DenySchedule = schedule.Name
+			}
+			invalid = append(invalid, fmt.Sprintf("%s: %v", schedule.Name, err))
+			continue
+		}
+		if !next.IsZero() && (requeueAfter == 0 || next.Sub(now) < requeueAfter) {
+			requeueAfter = next.Sub(now)
+		}
+		if active == nil {
+			continue
+		}
+
+		held := false
+		for _, t := range targets {
+			if m != nil && !m.MatchCluster(t.Cluster.Name, target.ClusterGroupsToLabelMap(t.ClusterGroups), t.Cluster.Labels) {
+				continue
+			}
+			t.DenySchedule = schedule.Name
+			held = true
+		}
+
+		// show the window, which holds back the rollout the longest
+		if held && (bundle.Status.DenyWindow == nil || active.End.After(bundle.Status.DenyWindow.End.Time)) {
+			bundle.Status.DenyWindow = &fleet.DenyWindow{
+				Schedule: schedule.Name,
+				Event:    active.Event,
+				Start:    metav1.Time{Time: active.Start},
+				End:      metav1.Time{Time: active.End},
+			}
+		}
+	}
+
+	if len(invalid) == 0 {
+		cond.SetStatusBool(&bundle.Status, true)
+		cond.Message(&bundle.Status, "")
+		return requeueAfter, nil
+	}
+	cond.SetStatusBool(&bundle.Status, false)
+	cond.Message(&bundle.Status, "held back by invalid deny schedules: "+strings.Join(invalid, "; "))
+	return requeueAfter, nil
+}
+
+// activeDenyWindow returns the active deny window of the Deny schedule, if it
+// targets the bundle, the time of the next change of its windows and the
+// matcher for its cluster targets. The matcher is nil, if the schedule
+// targets all clusters.
+func (r *BundleReconciler) activeDenyWindow(ctx context.Context, now time.Time, schedule *fleet.Schedule, bundle *fleet.Bundle) (*denywindow.Window, time.Time, *matcher.ScheduleMatch, error) {
+	matches, err := denyScheduleMatchesBundle(ctx, r.Client, schedule, bundle)
+	if err != nil || !matches {
+		return nil, time.Time{}, nil, err
+	}
+	sources, _, err := denyWindowSources(ctx, r.Client, schedule)
+	if err != nil {
+		return nil, time.Time{}, nil, err
+	}
+
+	var m *matcher.ScheduleMatch
+	if len(schedule.Spec.Targets.Clusters) > 0 {
+		if m, err = matcher.NewScheduleMatch(schedule); err != nil {
+			return nil, time.Time{}, nil, err
+		}
+	}
+
+	active, next := denywindow.Evaluate(now, sources...)
+	return active, next, m, nil
+}
+
+// mapDenyScheduleToBundles returns requests for all bundles in the namespace
+// of a Deny schedule, so they are re-evaluated when the schedule changes.
+func (r *BundleReconciler) mapDenyScheduleToBundles(ctx context.Context, a client.Object) []ctrl.Request {
+	schedule, ok := a.(*fleet.Schedule)
+	if !ok || schedule.Spec.Type != fleet.DenySchedule {
+		return nil
+	}
+
+	bundles := &fleet.BundleList{}
+	if err := r.List(ctx, bundles, client.InNamespace(schedule.Namespace)); err != nil {
+		log.FromContext(ctx).V(1).Info("Failed to list bundles for deny schedule", "schedule", schedule.Name, "error", err)
+		return nil
+	}
+
+	requests := make([]ctrl.Request, 0, len(bundles.Items))
+	for _, bundle := range bundles.Items {
+		if !sharding.ShouldProcess(&bundle, r.ShardID) {
+			continue
+		}
+		requests = append(requests, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(&bundle)})
+	}
+	return requests
+}
+
+// mapCalendarToBundles returns requests for the bundles of the Deny
+// schedules, whose calendar is in the config map, so changes of the
+// calendar take effect.
+func (r *BundleReconciler) mapCalendarToBundles(ctx context.Context, a client.Object) []ctrl.Request {
+	var requests []ctrl.Request
+	for _, schedule := range calendarSchedules(ctx, r.Client, r.ShardID, a) {
+		requests = append(requests, r.mapDenyScheduleToBundles(ctx, &schedule)...)
+	}
+	return requests
+}
@@ -0,0 +1,189 @@
+package reconciler
+
+import (
+	"context"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/rancher/fleet/internal/cmd/controller/target"
+	"github.com/rancher/fleet/internal/experimental"
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	"github.com/rancher/wrangler/v3/pkg/condition"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("BundleReconciler deny windows", func() {
+	var (
+		ctx      context.Context
+		r        *BundleReconciler
+		bundle   *fleet.Bundle
+		schedule *fleet.Schedule
+		targets  []*target.Target
+	)
+
+	denyTarget := func(name string, labels map[string]string) *target.Target {
+		return &target.Target{Cluster: &fleet.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "fleet-default", Labels: labels},
+		}}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		Expect(os.Setenv(experimental.SchedulesFlag, "true")).To(Succeed())
+
+		bundle = &fleet.Bundle{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "app",
+				Namespace: "fleet-default",
+				Labels:    map[string]string{fleet.RepoLabel: "apps"},
+			},
+		}
+		schedule = &fleet.Schedule{
+			ObjectMeta: metav1.ObjectMeta{Name: "freeze", Namespace: "fleet-default"},
+			Spec: fleet.ScheduleSpec{
+				Type:     fleet.DenySchedule,
+				Location: "UTC",
+				Calendar: &fleet.ScheduleCalendar{ConfigMapName: "holidays", Key: "holidays.ics"},
+				Targets: fleet.ScheduleTargets{
+					Clusters: []fleet.ScheduleTarget{{
+						ClusterSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}},
+					}},
+					Bundles: []fleet.ScheduleBundleTarget{{GitRepoName: "apps"}},
+				},
+			},
+		}
+		targets = []*target.Target{
+			denyTarget("prod", map[string]string{"env": "prod"}),
+			denyTarget("dev", map[string]string{"env": "dev"}),
+		}
+	})
+
+	AfterEach(func() {
+		Expect(os.Unsetenv(experimental.SchedulesFlag)).To(Succeed())
+	})
+
+	JustBeforeEach(func() {
+		sch := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(sch)).To(Succeed())
+		Expect(fleet.AddToScheme(sch)).To(Succeed())
+
+		now := time.Now().UTC()
+		calendar := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "holidays", Namespace: "fleet-default"},
+			Data: map[string]string{
+				"holidays.ics": "BEGIN:VCALENDAR\nBEGIN:VEVENT\nSUMMARY:Year end\n" +
+					"DTSTART:" + now.Add(-time.Hour).Format("20060102T150405Z") + "\n" +
+					"DTEND:" + now.Add(2*time.Hour).Format("20060102T150405Z") + "\n" +
+					"END:VEVENT\nEND:VCALENDAR\n",
+			},
+		}
+		cl := fake.NewClientBuilder().WithScheme(sch).WithObjects(schedule, calendar).Build()
+		r = &BundleReconciler{Client: cl, Scheme: sch}
+	})
+
+	It("holds back the targeted clusters while the window is active", func() {
+		requeueAfter, err := r.checkDenyWindows(ctx, bundle, targets)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(requeueAfter).To(BeNumerically("~", 2*time.Hour, time.Minute))
+
+		Expect(targets[0].DenySchedule).To(Equal("freeze"))
+		Expect(targets[1].DenySchedule).To(BeEmpty())
+
+		Expect(bundle.Status.DenyWindow).ToNot(BeNil())
+		Expect(bundle.Status.DenyWindow.Schedule).To(Equal("freeze"))
+		Expect(bundle.Status.DenyWindow.Event).To(Equal("Year end"))
+		Expect(bundle.Status.DenyWindow.End.Time).To(BeTemporally(">", time.Now()))
+	})
+
+	When("the schedule targets other bundles", func() {
+		BeforeEach(func() {
+			schedule.Spec.Targets.Bundles = []fleet.ScheduleBundleTarget{{BundleName: "other"}}
+			bundle.Status.DenyWindow = &fleet.DenyWindow{Schedule: "freeze"}
+		})
+
+		It("does not hold back the bundle and clears its deny window", func() {
+			requeueAfter, err := r.checkDenyWindows(ctx, bundle, targets)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(requeueAfter).To(BeZero())
+			Expect(targets[0].DenySchedule).To(BeEmpty())
+			Expect(bundle.Status.DenyWindow).To(BeNil())
+		})
+	})
+
+	When("the calendar of the schedule is missing", func() {
+		BeforeEach(func() {
+			schedule.Spec.Calendar.ConfigMapName = "missing"
+		})
+
+		It("holds back all targets and reports the invalid schedule", func() {
+			_, err := r.checkDenyWindows(ctx, bundle, targets)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(targets[0].DenySchedule).To(Equal("freeze"))
+			Expect(targets[1].DenySchedule).To(Equal("freeze"))
+
+			cond := condition.Cond(fleet.BundleConditionDenyWindows)
+			Expect(cond.IsFalse(&bundle.Status)).To(BeTrue())
+			Expect(cond.GetMessage(&bundle.Status)).To(ContainSubstring("missing"))
+		})
+
+		It("enqueues the bundles when the calendar is created", func() {
+			requests := r.mapCalendarToBundles(ctx, &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "missing", Namespace: "fleet-default"},
+			})
+			Expect(requests).To(BeEmpty())
+
+			Expect(r.Create(ctx, bundle)).To(Succeed())
+			requests = r.mapCalendarToBundles(ctx, &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "missing", Namespace: "fleet-default"},
+			})
+			Expect(requests).To(HaveLen(1))
+			Expect(requests[0].Name).To(Equal("app"))
+		})
+	})
+
+	When("the calendar has events with unsupported recurrence", func() {
+		BeforeEach(func() {
+			schedule.Spec.Calendar.ConfigMapName = "weekly"
+		})
+
+		JustBeforeEach(func() {
+			Expect(r.Create(ctx, &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "weekly", Namespace: "fleet-default"},
+				Data: map[string]string{
+					"holidays.ics": "BEGIN:VCALENDAR\nBEGIN:VEVENT\nSUMMARY:Weekend\n" +
+						"DTSTART:20251206T000000Z\nDTEND:20251208T000000Z\nRRULE:FREQ=WEEKLY\n" +
+						"END:VEVENT\nEND:VCALENDAR\n",
+				},
+			})).To(Succeed())
+		})
+
+		It("skips these events", func() {
+			_, err := r.checkDenyWindows(ctx, bundle, targets)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(targets[0].DenySchedule).To(BeEmpty())
+			Expect(condition.Cond(fleet.BundleConditionDenyWindows).IsTrue(&bundle.Status)).To(BeTrue())
+		})
+	})
+
+	When("schedules are disabled", func() {
+		BeforeEach(func() {
+			Expect(os.Unsetenv(experimental.SchedulesFlag)).To(Succeed())
+		})
+
+		It("does not hold back the bundle", func() {
+			_, err := r.checkDenyWindows(ctx, bundle, targets)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(targets[0].DenySchedule).To(BeEmpty())
+			Expect(bundle.Status.DenyWindow).To(BeNil())
+		})
+	})
+})
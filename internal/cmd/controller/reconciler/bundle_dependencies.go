@@ -0,0 +1,153 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rancher/fleet/internal/cmd/controller/target"
+	"github.com/rancher/fleet/internal/config"
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+	"github.com/rancher/fleet/pkg/sharding"
+
+	"github.com/rancher/wrangler/v3/pkg/condition"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// checkDependencies holds back the targets, whose dependencies are not
+// ready on their cluster, or on the clusters a cross-cluster dependency is
+// scoped to, and sets the bundle's dependencies condition. If the
+// dependencies form a cycle, all targets are held back.
+func (r *BundleReconciler) checkDependencies(ctx context.Context, bundle *fleet.Bundle, targets []*target.Target) error {
+	cond := condition.Cond(fleet.BundleConditionDependencies)
+	if len(bundle.Spec.DependsOn) == 0 {
+		if cond.GetStatus(&bundle.Status) != "" {
+			cond.SetStatusBool(&bundle.Status, true)
+			cond.Message(&bundle.Status, "")
+		}
+		return nil
+	}
+
+	deps, err := target.Dependencies(bundle)
+	if err != nil {
+		return err
+	}
+
+	// cycles are only detected within the bundle's namespace
+	bundles := &fleet.BundleList{}
+	if err := r.List(ctx, bundles, client.InNamespace(bundle.Namespace)); err != nil {
+		return err
+	}
+	cycle, err := target.FindDependencyCycle(bundle, bundles.Items)
+	if err != nil {
+		return err
+	}
+	if cycle != nil {
+		for _, t := range targets {
+			t.PendingDependencies = cycle
+		}
+		cond.SetStatusBool(&bundle.Status, false)
+		cond.Message(&bundle.Status, "dependency cycle: "+strings.Join(cycle, " -> "))
+		return nil
+	}
+
+	if err := r.scopeDependencies(ctx, bundle.Namespace, deps); err != nil {
+		return err
+	}
+
+	var bds []fleet.BundleDeployment
+	for _, dep := range deps {
+		list := &fleet.BundleDeploymentList{}
+		if err := r.List(ctx, list, client.MatchingLabelsSelector{Selector: dep.Selector}); err != nil {
+			return err
+		}
+		bds = append(bds, list.Items...)
+	}
+	target.HoldForDependencies(targets, deps, bds)
+
+	var waiting []string
+	for _, t := range targets {
+		if len(t.PendingDependencies) > 0 {
+			waiting = append(waiting, fmt.Sprintf("%s/%s (%s)", t.Cluster.Namespace, t.Cluster.Name, strings.Join(t.PendingDependencies, ", ")))
+		}
+	}
+	if len(waiting) == 0 {
+		cond.SetStatusBool(&bundle.Status, true)
+		cond.Message(&bundle.Status, "")
+		return nil
+	}
+
+	msg := fmt.Sprintf("waiting for dependencies on %d cluster(s): %s", len(waiting), strings.Join(waiting, ", "))
+	if len(waiting) > 3 {
+		msg = fmt.Sprintf("waiting for dependencies on %d cluster(s): %s, ...", len(waiting), strings.Join(waiting[:3], ", "))
+	}
+	cond.SetStatusBool(&bundle.Status, false)
+	cond.Message(&bundle.Status, msg)
+	return nil
+}
+
+// scopeDependencies sets the clusters of the cross-cluster dependencies.
+// Clusters are only listed, if there is at least one of them.
+func (r *BundleReconciler) scopeDependencies(ctx context.Context, namespace string, deps []target.Dependency) error {
+	crossCluster := false
+	for i := range deps {
+		crossCluster = crossCluster || deps[i].CrossCluster()
+	}
+	if !crossCluster {
+		return nil
+	}
+
+	clusters := &fleet.ClusterList{}
+	if err := r.List(ctx, clusters, client.InNamespace(namespace)); err != nil {
+		return err
+	}
+	for i := range deps {
+		if !deps[i].CrossCluster() {
+			continue
+		}
+		for j := range clusters.Items {
+			if deps[i].MatchesCluster(&clusters.Items[j]) {
+				deps[i].Clusters = append(deps[i].Clusters, &clusters.Items[j])
+			}
+		}
+	}
+	return nil
+}
+
+// dependentBundles returns requests for the bundles, which depend on the
+// bundledeployment, so they are reconciled as soon as it becomes ready.
+func (r *BundleReconciler) dependentBundles(ctx context.Context, bd *fleet.BundleDeployment) []reconcile.Request {
+	seen := map[types.NamespacedName]bool{}
+	var requests []reconcile.Request
+	for _, key := range target.DependentIndexKeys(bd) {
+		bundles := &fleet.BundleList{}
+		if err := r.List(ctx, bundles, client.MatchingFields{config.BundleDependsOnIndex: key}); err != nil {
+			log.FromContext(ctx).V(1).Info("Failed to list bundles with dependencies", "error", err)
+			return nil
+		}
+
+		for i := range bundles.Items {
+			bundle := &bundles.Items[i]
+			nsn := types.NamespacedName{Namespace: bundle.Namespace, Name: bundle.Name}
+			if seen[nsn] || !sharding.ShouldProcess(bundle, r.ShardID) || !target.DependsOnDeployment(bundle, bd) {
+				continue
+			}
+			seen[nsn] = true
+			requests = append(requests, reconcile.Request{NamespacedName: nsn})
+		}
+	}
+	return requests
+}
+
+// indexBundleDependsOn indexes the bundles by the bundles they depend on.
+func indexBundleDependsOn(obj client.Object) []string {
+	bundle, ok := obj.(*fleet.Bundle)
+	if !ok {
+		return nil
+	}
+	return target.DependencyIndexKeys(bundle)
+}
@@ -0,0 +1,72 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+
+	fleetutil "github.com/rancher/fleet/internal/cmd/controller/errorutil"
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// checkRestrictions returns an error if a bundle enables kustomize features,
+// which a GitRepoRestriction in its namespace disallows. This applies to all
+// bundles, not only to bundles created from GitRepos, so the restrictions
+// cannot be bypassed by creating bundles directly. Restrictions are only
+// listed if the bundle enables any of these features.
+func (r *BundleReconciler) checkRestrictions(ctx context.Context, bundle *fleet.Bundle) error {
+	features := kustomizeFeatures(bundle)
+	if len(features) == 0 {
+		return nil
+	}
+
+	restrictions := &fleet.GitRepoRestrictionList{}
+	if err := r.List(ctx, restrictions, client.InNamespace(bundle.Namespace)); err != nil {
+		return fmt.Errorf("%w, listing GitRepoRestrictions: %w", fleetutil.ErrRetryable, err)
+	}
+	sort.Slice(restrictions.Items, func(i, j int) bool {
+		return restrictions.Items[i].Name < restrictions.Items[j].Name
+	})
+	for _, restriction := range restrictions.Items {
+		for _, feature := range features {
+			if slices.Contains(restriction.DisallowedKustomizeFeatures, feature) {
+				return fmt.Errorf("kustomize feature %s is disallowed by GitRepoRestriction %s", feature, restriction.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// kustomizeFeatures returns the kustomize features enabled by the bundle or
+// any of its targets.
+func kustomizeFeatures(bundle *fleet.Bundle) []fleet.KustomizeFeature {
+	options := []*fleet.KustomizeOptions{bundle.Spec.Kustomize}
+	for _, target := range bundle.Spec.Targets {
+		options = append(options, target.Kustomize)
+	}
+
+	var features []fleet.KustomizeFeature
+	add := func(feature fleet.KustomizeFeature) {
+		if !slices.Contains(features, feature) {
+			features = append(features, feature)
+		}
+	}
+	for _, opts := range options {
+		if opts == nil {
+			continue
+		}
+		if opts.EnableHelm {
+			add(fleet.KustomizeFeatureHelmCharts)
+		}
+		if opts.LoadRestrictions == fleet.KustomizeLoadRestrictionsNone {
+			add(fleet.KustomizeFeatureLoadRestrictionsNone)
+		}
+		if opts.EnableAlphaPlugins {
+			add(fleet.KustomizeFeatureAlphaPlugins)
+		}
+	}
+	return features
+}
@@ -0,0 +1,69 @@
+package reconciler
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("BundleReconciler restrictions", func() {
+	var (
+		ctx         context.Context
+		r           *BundleReconciler
+		bundle      *fleet.Bundle
+		restriction *fleet.GitRepoRestriction
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		bundle = &fleet.Bundle{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "app",
+				Namespace: "fleet-default",
+				Labels:    map[string]string{fleet.RepoLabel: "apps"},
+			},
+		}
+		restriction = &fleet.GitRepoRestriction{
+			ObjectMeta:                  metav1.ObjectMeta{Name: "restricted", Namespace: "fleet-default"},
+			DisallowedKustomizeFeatures: []fleet.KustomizeFeature{fleet.KustomizeFeatureAlphaPlugins},
+		}
+	})
+
+	JustBeforeEach(func() {
+		sch := runtime.NewScheme()
+		Expect(fleet.AddToScheme(sch)).To(Succeed())
+		cl := fake.NewClientBuilder().WithScheme(sch).WithObjects(restriction).Build()
+		r = &BundleReconciler{Client: cl, Scheme: sch}
+	})
+
+	It("allows bundles without kustomize features", func() {
+		Expect(r.checkRestrictions(ctx, bundle)).To(Succeed())
+	})
+
+	It("allows kustomize features, which are not disallowed", func() {
+		bundle.Spec.Kustomize = &fleet.KustomizeOptions{EnableHelm: true}
+		Expect(r.checkRestrictions(ctx, bundle)).To(Succeed())
+	})
+
+	It("rejects disallowed kustomize features of targets", func() {
+		target := fleet.BundleTarget{Name: "prod"}
+		target.Kustomize = &fleet.KustomizeOptions{EnableAlphaPlugins: true}
+		bundle.Spec.Targets = []fleet.BundleTarget{target}
+		err := r.checkRestrictions(ctx, bundle)
+		Expect(err).To(MatchError("kustomize feature AlphaPlugins is disallowed by GitRepoRestriction restricted"))
+	})
+
+	It("rejects disallowed kustomize features of bundles, which were not created from a GitRepo", func() {
+		bundle.Labels = nil
+		bundle.Spec.Kustomize = &fleet.KustomizeOptions{EnableAlphaPlugins: true}
+		err := r.checkRestrictions(ctx, bundle)
+		Expect(err).To(MatchError("kustomize feature AlphaPlugins is disallowed by GitRepoRestriction restricted"))
+	})
+})
@@ -0,0 +1,411 @@
+package reconciler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/rancher/fleet/internal/cmd/controller/options"
+	"github.com/rancher/fleet/internal/cmd/controller/summary"
+	"github.com/rancher/fleet/internal/cmd/controller/target"
+	"github.com/rancher/fleet/internal/names"
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	"github.com/rancher/wrangler/v3/pkg/condition"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	defaultRollbackHistoryLimit     = 3
+	defaultRollbackProgressDeadline = 10 * time.Minute
+
+	// rollbackDeploymentsKey is the key in the secret of a known-good
+	// deployment, which holds the deployment IDs and options of the
+	// clusters.
+	rollbackDeploymentsKey = "deployments"
+)
+
+// knownGoodDeployment is the deployment of a known-good version of a bundle
+// to a cluster.
+type knownGoodDeployment struct {
+	DeploymentID string                        `json:"deploymentID"`
+	Options      fleet.BundleDeploymentOptions `json:"options"`
+}
+
+// autoRollbackPolicy returns the auto rollback policy of the bundle, or nil
+// if auto rollback is disabled.
+func autoRollbackPolicy(bundle *fleet.Bundle) *fleet.AutoRollback {
+	if bundle.Spec.RolloutStrategy == nil {
+		return nil
+	}
+	return bundle.Spec.RolloutStrategy.AutoRollback
+}
+
+// bundleDeploymentID returns the ID of the bundle's version: the manifest ID
+// and a hash of the options of the bundle and its targets, from which the
+// deployment IDs of the targets are computed (pure function).
+func bundleDeploymentID(bundle *fleet.Bundle, manifestID string) (string, error) {
+	h := sha256.New()
+	if err := json.NewEncoder(h).Encode(&bundle.Spec.BundleDeploymentOptions); err != nil {
+		return "", err
+	}
+	if err := json.NewEncoder(h).Encode(bundle.Spec.Targets); err != nil {
+		return "", err
+	}
+	return manifestID + ":" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// autoRollback returns the manifest ID to deploy. This is the bundle's
+// manifestID, unless too many of the targets failed to deploy it and a
+// known-good deployment exists. Once rolled back, the deployment IDs and
+// options of the targets are replaced by the known-good ones, until the
+// bundle's manifestID or options change. The returned duration is non-zero,
+// if the targets should be re-checked after the progress deadline.
+func (r *BundleReconciler) autoRollback(ctx context.Context, bundle *fleet.Bundle, manifestID string, targets []*target.Target) (string, time.Duration, error) {
+	policy := autoRollbackPolicy(bundle)
+	if bundle.Status.Rollback == nil {
+		bundle.Status.Rollback = &fleet.RollbackStatus{}
+	}
+	status := bundle.Status.Rollback
+	cond := condition.Cond(fleet.BundleConditionRolledBack)
+	now := time.Now().UTC()
+
+	deploymentID, err := bundleDeploymentID(bundle, manifestID)
+	if err != nil {
+		return "", 0, err
+	}
+	if status.DeploymentID != deploymentID {
+		status.ManifestID = manifestID
+		status.DeploymentID = deploymentID
+		status.StartedAt = metav1.Time{Time: now}
+		status.RolledBackTo = ""
+		cond.SetStatusBool(&bundle.Status, false)
+		cond.Message(&bundle.Status, "")
+	}
+
+	if status.RolledBackTo != "" {
+		for _, h := range status.History {
+			if h.DeploymentID != status.RolledBackTo {
+				continue
+			}
+			if ok, err := r.restoreKnownGood(ctx, bundle, h, targets); err != nil {
+				return "", 0, err
+			} else if ok {
+				return h.ManifestID, 0, nil
+			}
+		}
+		// the known-good deployment is gone, look for another one
+		status.RolledBackTo = ""
+	}
+
+	deadline := defaultRollbackProgressDeadline
+	if policy.ProgressDeadline != nil {
+		deadline = policy.ProgressDeadline.Duration
+	}
+	failed, requeueAfter := failedTargets(targets, status.StartedAt.Add(deadline), now)
+
+	maxFailures := 0
+	if policy.MaxFailures != nil {
+		var err error
+		maxFailures, err = intstr.GetScaledValueFromIntOrPercent(policy.MaxFailures, len(targets), false)
+		if err != nil {
+			return "", 0, fmt.Errorf("invalid auto rollback maxFailures: %w", err)
+		}
+	}
+	if failed <= maxFailures {
+		return manifestID, requeueAfter, nil
+	}
+
+	var rollbackTo *fleet.RollbackHistory
+	for i, h := range status.History {
+		if h.DeploymentID == deploymentID {
+			continue
+		}
+		ok, err := r.restoreKnownGood(ctx, bundle, h, targets)
+		if err != nil {
+			return "", 0, err
+		}
+		if ok {
+			rollbackTo = &status.History[i]
+			break
+		}
+	}
+
+	if rollbackTo == nil {
+		cond.SetStatusBool(&bundle.Status, false)
+		cond.Message(&bundle.Status, fmt.Sprintf("%d clusters failed to deploy the bundle, but there is no known-good deployment to roll back to", failed))
+		return manifestID, 0, nil
+	}
+
+	log.FromContext(ctx).Info("Rolling back bundle to known-good deployment", "failedClusters", failed, "rolledBackTo", rollbackTo.DeploymentID)
+	status.RolledBackTo = rollbackTo.DeploymentID
+	cond.SetStatusBool(&bundle.Status, true)
+	cond.Message(&bundle.Status, fmt.Sprintf("%d clusters failed to deploy the bundle, rolled back to %s", failed, rollbackTo.ManifestID))
+
+	return rollbackTo.ManifestID, 0, nil
+}
+
+// restoreKnownGood replaces the deployment IDs and options of the targets
+// with the ones of the known-good deployment. Clusters, which were not
+// targeted by the known-good deployment, deploy its manifest with their
+// current options. Returns false, if the content or the secret of the
+// known-good deployment was deleted.
+func (r *BundleReconciler) restoreKnownGood(ctx context.Context, bundle *fleet.Bundle, h fleet.RollbackHistory, targets []*target.Target) (bool, error) {
+	// contents in the history are kept, unless deleted manually
+	if err := r.Get(ctx, types.NamespacedName{Name: h.ManifestID}, &fleet.Content{}); apierrors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: bundle.Namespace, Name: h.SecretName}, secret); apierrors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	deployments := map[string]knownGoodDeployment{}
+	if err := json.Unmarshal(secret.Data[rollbackDeploymentsKey], &deployments); err != nil {
+		return false, fmt.Errorf("failed to read known-good deployment from secret %s: %w", h.SecretName, err)
+	}
+
+	for _, t := range targets {
+		if d, ok := deployments[clusterKey(t)]; ok {
+			t.DeploymentID = d.DeploymentID
+			t.Options = d.Options
+			continue
+		}
+		id, err := options.DeploymentID(h.ManifestID, t.Options)
+		if err != nil {
+			return false, err
+		}
+		t.DeploymentID = id
+	}
+	return true, nil
+}
+
+// failedTargets counts the targets which failed to deploy their
+// DeploymentID. Targets which are not ready after the deadline count as
+// failed. If targets are not ready before the deadline, the duration until
+// the deadline is returned (pure function).
+func failedTargets(targets []*target.Target, deadline time.Time, now time.Time) (int, time.Duration) {
+	failed := 0
+	pending := false
+	for _, t := range targets {
+		// only count bundledeployments which are rolled out
+		if t.Deployment == nil || t.Deployment.Spec.DeploymentID != t.DeploymentID {
+			continue
+		}
+
+		switch summary.GetDeploymentState(t.Deployment) {
+		case fleet.ErrApplied:
+			failed++
+		case fleet.WaitApplied, fleet.NotReady:
+			if now.Before(deadline) {
+				pending = true
+			} else {
+				failed++
+			}
+		}
+	}
+
+	if pending {
+		return failed, deadline.Sub(now)
+	}
+	return failed, 0
+}
+
+// allReady returns true if all targets deployed their DeploymentID and are
+// ready (pure function).
+func allReady(targets []*target.Target) bool {
+	if len(targets) == 0 {
+		return false
+	}
+	for _, t := range targets {
+		if t.Deployment == nil ||
+			t.Deployment.Spec.DeploymentID != t.DeploymentID ||
+			t.Deployment.Status.AppliedDeploymentID != t.DeploymentID ||
+			!t.Deployment.Status.Ready {
+			return false
+		}
+	}
+	return true
+}
+
+// recordKnownGood adds the bundle's current version to its known-good
+// deployments, if it is ready on all targets. The deployment IDs and options
+// of the targets are stored in a secret and the content of manifestID is
+// labeled, so it is not deleted when no bundledeployment references it
+// anymore.
+func (r *BundleReconciler) recordKnownGood(ctx context.Context, bundle *fleet.Bundle, manifestID string, targets []*target.Target) error {
+	status := bundle.Status.Rollback
+	if status == nil || status.RolledBackTo != "" || !allReady(targets) {
+		return nil
+	}
+	if len(status.History) > 0 && status.History[0].DeploymentID == status.DeploymentID {
+		return nil
+	}
+
+	limit := autoRollbackPolicy(bundle).HistoryLimit
+	if limit <= 0 {
+		limit = defaultRollbackHistoryLimit
+	}
+
+	secretName, err := r.storeKnownGood(ctx, bundle, status.DeploymentID, targets)
+	if err != nil {
+		return err
+	}
+	history := []fleet.RollbackHistory{{
+		ManifestID:   manifestID,
+		DeploymentID: status.DeploymentID,
+		SecretName:   secretName,
+		ReadyAt:      metav1.Now(),
+	}}
+	for _, h := range status.History {
+		if h.DeploymentID != status.DeploymentID {
+			history = append(history, h)
+		}
+	}
+
+	if err := r.labelContent(ctx, bundle, manifestID, true); err != nil {
+		return err
+	}
+	if len(history) > limit {
+		if err := r.deleteKnownGood(ctx, bundle, history[limit:], history[:limit]); err != nil {
+			return err
+		}
+		history = history[:limit]
+	}
+
+	status.History = history
+	return nil
+}
+
+// storeKnownGood writes the deployment IDs and options of the targets to the
+// secret of the known-good deployment and returns the secret's name.
+func (r *BundleReconciler) storeKnownGood(ctx context.Context, bundle *fleet.Bundle, deploymentID string, targets []*target.Target) (string, error) {
+	deployments := map[string]knownGoodDeployment{}
+	for _, t := range targets {
+		deployments[clusterKey(t)] = knownGoodDeployment{DeploymentID: t.DeploymentID, Options: t.Options}
+	}
+	data, err := json.Marshal(deployments)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.Sum256([]byte(deploymentID))
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      names.SafeConcatName(bundle.Name, "rollback", hex.EncodeToString(h[:])[:12]),
+			Namespace: bundle.Namespace,
+		},
+	}
+	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, secret, func() error {
+		if bundle.UID != "" {
+			if err := controllerutil.SetControllerReference(bundle, secret, r.Scheme); err != nil {
+				return err
+			}
+		}
+		secret.Type = fleet.SecretTypeBundleRollback
+		secret.Data = map[string][]byte{rollbackDeploymentsKey: data}
+		return nil
+	}); err != nil {
+		return "", fmt.Errorf("failed to store known-good deployment: %w", err)
+	}
+	return secret.Name, nil
+}
+
+// deleteKnownGood deletes the secrets of the known-good deployments and
+// removes the labels from their contents, unless the contents are used by
+// one of the kept deployments.
+func (r *BundleReconciler) deleteKnownGood(ctx context.Context, bundle *fleet.Bundle, deleted, kept []fleet.RollbackHistory) error {
+	keep := map[string]bool{}
+	for _, h := range kept {
+		keep[h.ManifestID] = true
+	}
+	for _, h := range deleted {
+		if h.SecretName != "" {
+			secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: bundle.Namespace, Name: h.SecretName}}
+			if err := r.Delete(ctx, secret); client.IgnoreNotFound(err) != nil {
+				return err
+			}
+		}
+		if keep[h.ManifestID] {
+			continue
+		}
+		if err := r.labelContent(ctx, bundle, h.ManifestID, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// releaseKnownGood deletes all known-good deployments of the bundle and
+// resets the rollback status, e.g. when auto rollback is disabled or the
+// bundle is deleted.
+func (r *BundleReconciler) releaseKnownGood(ctx context.Context, bundle *fleet.Bundle) error {
+	if bundle.Status.Rollback == nil {
+		return nil
+	}
+	if err := r.deleteKnownGood(ctx, bundle, bundle.Status.Rollback.History, nil); err != nil {
+		return err
+	}
+	bundle.Status.Rollback = nil
+
+	cond := condition.Cond(fleet.BundleConditionRolledBack)
+	if cond.GetStatus(&bundle.Status) != "" {
+		cond.SetStatusBool(&bundle.Status, false)
+		cond.Message(&bundle.Status, "")
+	}
+	return nil
+}
+
+// labelContent adds or removes the bundle's rollback label on a content
+// resource.
+func (r *BundleReconciler) labelContent(ctx context.Context, bundle *fleet.Bundle, manifestID string, keep bool) error {
+	content := &fleet.Content{}
+	if err := r.Get(ctx, types.NamespacedName{Name: manifestID}, content); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+
+	key := contentRollbackLabel(bundle)
+	if _, ok := content.Labels[key]; ok == keep {
+		return nil
+	}
+
+	orig := content.DeepCopy()
+	if keep {
+		if content.Labels == nil {
+			content.Labels = map[string]string{}
+		}
+		content.Labels[key] = "true"
+	} else {
+		delete(content.Labels, key)
+	}
+	return client.IgnoreNotFound(r.Patch(ctx, content, client.MergeFrom(orig)))
+}
+
+// contentRollbackLabel returns the label key, which marks contents as
+// known-good deployments of the bundle. Bundle namespace and name are hashed
+// to fit into the length limit of label keys.
+func contentRollbackLabel(bundle *fleet.Bundle) string {
+	h := sha256.Sum256([]byte(bundle.Namespace + "/" + bundle.Name))
+	return fleet.ContentRollbackLabelPrefix + hex.EncodeToString(h[:])[:16]
+}
+
+// clusterKey identifies the cluster of the target in the secret of a
+// known-good deployment.
+func clusterKey(t *target.Target) string {
+	return t.Cluster.Namespace + "/" + t.Cluster.Name
+}
@@ -0,0 +1,236 @@
+package reconciler
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/rancher/fleet/internal/cmd/controller/options"
+	"github.com/rancher/fleet/internal/cmd/controller/target"
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	"github.com/rancher/wrangler/v3/pkg/condition"
+	"github.com/rancher/wrangler/v3/pkg/genericcondition"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// rollbackTarget returns a target for deploymentID on cluster, whose
+// bundledeployment applied deploymentID with the given readiness, or failed
+// to apply it.
+func rollbackTarget(cluster, deploymentID string, ready, errApplied bool) *target.Target {
+	bd := &fleet.BundleDeployment{
+		Spec:   fleet.BundleDeploymentSpec{DeploymentID: deploymentID, StagedDeploymentID: deploymentID},
+		Status: fleet.BundleDeploymentStatus{AppliedDeploymentID: deploymentID, Ready: ready},
+	}
+	if errApplied {
+		bd.Status.AppliedDeploymentID = ""
+		bd.Status.Conditions = []genericcondition.GenericCondition{{Type: fleet.BundleDeploymentConditionDeployed, Status: "False"}}
+	}
+	return &target.Target{
+		Cluster:      &fleet.Cluster{ObjectMeta: metav1.ObjectMeta{Name: cluster, Namespace: "fleet-default"}},
+		Deployment:   bd,
+		DeploymentID: deploymentID,
+	}
+}
+
+var _ = Describe("BundleReconciler auto rollback", func() {
+	var (
+		ctx    context.Context
+		r      *BundleReconciler
+		bundle *fleet.Bundle
+	)
+
+	// deploy rolls out manifestID to the targets and records it as
+	// known-good, if all targets are ready.
+	deploy := func(manifestID string, targets ...*target.Target) {
+		GinkgoHelper()
+		_, _, err := r.autoRollback(ctx, bundle, manifestID, targets)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(r.recordKnownGood(ctx, bundle, manifestID, targets)).To(Succeed())
+	}
+
+	secretExists := func(name string) bool {
+		GinkgoHelper()
+		err := r.Get(ctx, client.ObjectKey{Namespace: bundle.Namespace, Name: name}, &corev1.Secret{})
+		if apierrors.IsNotFound(err) {
+			return false
+		}
+		Expect(err).ToNot(HaveOccurred())
+		return true
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		sch := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(sch)).To(Succeed())
+		Expect(fleet.AddToScheme(sch)).To(Succeed())
+
+		cl := fake.NewClientBuilder().WithScheme(sch).WithObjects(
+			&fleet.Content{ObjectMeta: metav1.ObjectMeta{Name: "s-good"}},
+			&fleet.Content{ObjectMeta: metav1.ObjectMeta{Name: "s-older"}},
+			&fleet.Content{ObjectMeta: metav1.ObjectMeta{Name: "s-new"}},
+		).Build()
+		r = &BundleReconciler{Client: cl, Scheme: sch}
+
+		bundle = &fleet.Bundle{
+			ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "fleet-default"},
+			Spec: fleet.BundleSpec{
+				RolloutStrategy: &fleet.RolloutStrategy{
+					AutoRollback: &fleet.AutoRollback{
+						MaxFailures:  &intstr.IntOrString{Type: intstr.Int, IntVal: 1},
+						HistoryLimit: 2,
+					},
+				},
+			},
+		}
+	})
+
+	It("records known-good deployments and keeps their contents", func() {
+		deploy("s-older", rollbackTarget("a", "s-older:1", true, false))
+		deploy("s-good", rollbackTarget("a", "s-good:1", true, false))
+
+		// not ready deployments are not recorded
+		deploy("s-new", rollbackTarget("a", "s-new:1", false, false))
+
+		history := bundle.Status.Rollback.History
+		Expect(history).To(HaveLen(2))
+		Expect(history[0].ManifestID).To(Equal("s-good"))
+		Expect(history[0].DeploymentID).To(HavePrefix("s-good:"))
+		Expect(history[1].ManifestID).To(Equal("s-older"))
+		Expect(secretExists(history[0].SecretName)).To(BeTrue())
+		Expect(secretExists(history[1].SecretName)).To(BeTrue())
+		older := history[1].SecretName
+
+		content := &fleet.Content{}
+		Expect(r.Get(ctx, client.ObjectKey{Name: "s-good"}, content)).To(Succeed())
+		Expect(content.Labels).To(HaveKey(contentRollbackLabel(bundle)))
+
+		// the history is bounded, evicted deployments are released
+		Expect(r.recordKnownGood(ctx, bundle, "s-new", []*target.Target{rollbackTarget("a", "s-new:1", true, false)})).To(Succeed())
+		Expect(bundle.Status.Rollback.History).To(HaveLen(2))
+		Expect(r.Get(ctx, client.ObjectKey{Name: "s-older"}, content)).To(Succeed())
+		Expect(content.Labels).ToNot(HaveKey(contentRollbackLabel(bundle)))
+		Expect(secretExists(older)).To(BeFalse())
+
+		good := bundle.Status.Rollback.History[1].SecretName
+		Expect(r.releaseKnownGood(ctx, bundle)).To(Succeed())
+		Expect(bundle.Status.Rollback).To(BeNil())
+		Expect(r.Get(ctx, client.ObjectKey{Name: "s-good"}, content)).To(Succeed())
+		Expect(content.Labels).ToNot(HaveKey(contentRollbackLabel(bundle)))
+		Expect(secretExists(good)).To(BeFalse())
+	})
+
+	It("rolls back once more clusters than allowed failed", func() {
+		good := rollbackTarget("a", "s-good:1", true, false)
+		good.Options.DefaultNamespace = "good"
+		deploy("s-good", good, rollbackTarget("b", "s-good:2", true, false))
+
+		targets := []*target.Target{
+			rollbackTarget("a", "s-new:1", false, true),
+			rollbackTarget("b", "s-new:2", true, false),
+		}
+		id, _, err := r.autoRollback(ctx, bundle, "s-new", targets)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(id).To(Equal("s-new"))
+		Expect(targets[0].DeploymentID).To(Equal("s-new:1"))
+		Expect(condition.Cond(fleet.BundleConditionRolledBack).IsTrue(&bundle.Status)).To(BeFalse())
+
+		// a cluster, which was added after the known-good deployment
+		added := rollbackTarget("c", "s-new:3", false, true)
+		added.Options.DefaultNamespace = "added"
+		targets = []*target.Target{
+			rollbackTarget("a", "s-new:1", false, true),
+			rollbackTarget("b", "s-new:2", false, true),
+			added,
+		}
+		id, _, err = r.autoRollback(ctx, bundle, "s-new", targets)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(id).To(Equal("s-good"))
+		Expect(bundle.Status.Rollback.RolledBackTo).To(HavePrefix("s-good:"))
+		Expect(condition.Cond(fleet.BundleConditionRolledBack).IsTrue(&bundle.Status)).To(BeTrue())
+		Expect(condition.Cond(fleet.BundleConditionRolledBack).GetMessage(&bundle.Status)).To(ContainSubstring("3 clusters failed"))
+
+		// the deployment IDs and options of the known-good deployment are restored
+		Expect(targets[0].DeploymentID).To(Equal("s-good:1"))
+		Expect(targets[0].Options.DefaultNamespace).To(Equal("good"))
+		Expect(targets[1].DeploymentID).To(Equal("s-good:2"))
+		expected, err := options.DeploymentID("s-good", added.Options)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(targets[2].DeploymentID).To(Equal(expected))
+		Expect(targets[2].Options.DefaultNamespace).To(Equal("added"))
+
+		// the rollback sticks until the bundle changes
+		targets = []*target.Target{rollbackTarget("a", "s-new:1", false, true)}
+		id, _, err = r.autoRollback(ctx, bundle, "s-new", targets)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(id).To(Equal("s-good"))
+		Expect(targets[0].DeploymentID).To(Equal("s-good:1"))
+
+		id, _, err = r.autoRollback(ctx, bundle, "s-fixed", nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(id).To(Equal("s-fixed"))
+		Expect(bundle.Status.Rollback.RolledBackTo).To(BeEmpty())
+		Expect(condition.Cond(fleet.BundleConditionRolledBack).IsFalse(&bundle.Status)).To(BeTrue())
+	})
+
+	It("rolls back changed options of the same manifest", func() {
+		good := rollbackTarget("a", "s-good:1", true, false)
+		good.Options.DefaultNamespace = "good"
+		deploy("s-good", good, rollbackTarget("b", "s-good:2", true, false))
+
+		bundle.Spec.DefaultNamespace = "broken"
+		targets := []*target.Target{
+			rollbackTarget("a", "s-good:3", false, true),
+			rollbackTarget("b", "s-good:4", false, true),
+		}
+		id, _, err := r.autoRollback(ctx, bundle, "s-good", targets)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(id).To(Equal("s-good"))
+		Expect(condition.Cond(fleet.BundleConditionRolledBack).IsTrue(&bundle.Status)).To(BeTrue())
+		Expect(targets[0].DeploymentID).To(Equal("s-good:1"))
+		Expect(targets[0].Options.DefaultNamespace).To(Equal("good"))
+	})
+
+	It("counts not ready clusters as failed after the progress deadline", func() {
+		deploy("s-good", rollbackTarget("a", "s-good:1", true, false))
+
+		bundle.Spec.RolloutStrategy.AutoRollback.MaxFailures = nil
+		targets := []*target.Target{rollbackTarget("a", "s-new:1", false, false)}
+		_, _, err := r.autoRollback(ctx, bundle, "s-new", targets)
+		Expect(err).ToNot(HaveOccurred())
+		bundle.Status.Rollback.StartedAt = metav1.Time{Time: time.Now().Add(-5 * time.Minute)}
+
+		id, requeueAfter, err := r.autoRollback(ctx, bundle, "s-new", targets)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(id).To(Equal("s-new"))
+		Expect(requeueAfter).To(BeNumerically("~", 5*time.Minute, time.Minute))
+
+		bundle.Status.Rollback.StartedAt = metav1.Time{Time: time.Now().Add(-15 * time.Minute)}
+		id, _, err = r.autoRollback(ctx, bundle, "s-new", targets)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(id).To(Equal("s-good"))
+	})
+
+	It("does not roll back without a known-good deployment", func() {
+		deploy("s-good", rollbackTarget("a", "s-good:1", true, false))
+		// the secret of the only known-good deployment is gone
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: bundle.Namespace, Name: bundle.Status.Rollback.History[0].SecretName}}
+		Expect(r.Delete(ctx, secret)).To(Succeed())
+
+		bundle.Spec.RolloutStrategy.AutoRollback.MaxFailures = nil
+		id, _, err := r.autoRollback(ctx, bundle, "s-new", []*target.Target{rollbackTarget("a", "s-new:1", false, true)})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(id).To(Equal("s-new"))
+		Expect(condition.Cond(fleet.BundleConditionRolledBack).GetMessage(&bundle.Status)).To(ContainSubstring("no known-good deployment"))
+	})
+})
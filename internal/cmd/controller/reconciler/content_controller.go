@@ -2,6 +2,7 @@ package reconciler
 
 import (
 	"context"
+	"strings"
 
 	"github.com/rancher/fleet/internal/config"
 	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
@@ -62,6 +63,12 @@ func (r *ContentReconciler) SetupWithManager(mgr ctrl.Manager) error {
 				},
 			),
 		).
+		Watches(
+			// Reconcile contents, which are no longer kept as known-good deployments of a bundle
+			&fleet.Content{},
+			&handler.EnqueueRequestForObject{},
+			builder.WithPredicates(predicate.LabelChangedPredicate{}),
+		).
 		WithEventFilter(sharding.FilterByShardID(r.ShardID)).
 		WithOptions(controller.Options{MaxConcurrentReconciles: r.Workers}).
 		Complete(r)
@@ -94,6 +101,12 @@ func (r *ContentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		}
 	}
 
+	// Known-good deployments of bundles are kept for rollbacks, keep the
+	// previous reference count so the content is deleted once released.
+	if newReferenceCount == 0 && keptForRollback(content) {
+		return ctrl.Result{}, nil
+	}
+
 	// If the Content resource has no more references... delete it
 	if newReferenceCount == 0 && content.Status.ReferenceCount > 0 {
 		logger.V(1).Info("Content resource has no more references, deleting it")
@@ -134,3 +147,14 @@ func (r *ContentReconciler) mapBundleDeploymentToContent(ctx context.Context, ob
 		},
 	}
 }
+
+// keptForRollback returns true if the content is a known-good deployment of
+// any bundle with auto rollback.
+func keptForRollback(content *fleet.Content) bool {
+	for key := range content.Labels {
+		if strings.HasPrefix(key, fleet.ContentRollbackLabelPrefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -118,6 +118,51 @@ var _ = Describe("ContentReconciler", func() {
 			})
 		})
 
+		Context("when Content without references is kept for rollbacks", func() {
+			BeforeEach(func() {
+				content = &fleet.Content{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:   "content-known-good",
+						Labels: map[string]string{fleet.ContentRollbackLabelPrefix + "0123456789abcdef": "true"},
+					},
+					Status: fleet.ContentStatus{ReferenceCount: 1},
+				}
+				cl = fake.NewClientBuilder().WithScheme(sch).
+					WithIndex(&fleet.BundleDeployment{}, config.ContentNameIndex, func(obj client.Object) []string {
+						bd, ok := obj.(*fleet.BundleDeployment)
+						if !ok {
+							return nil
+						}
+						if val, exists := bd.Labels[fleet.ContentNameLabel]; exists {
+							return []string{val}
+						}
+						return nil
+					}).
+					WithObjects(content).
+					WithStatusSubresource(&fleet.Content{}).
+					Build()
+			})
+
+			It("keeps the content and its reference count until the label is removed", func() {
+				_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKey{Name: content.Name}})
+				Expect(err).ToNot(HaveOccurred())
+
+				got := &fleet.Content{}
+				Expect(cl.Get(ctx, client.ObjectKey{Name: content.Name}, got)).To(Succeed())
+				Expect(got.Status.ReferenceCount).To(Equal(1))
+
+				got.Labels = nil
+				Expect(cl.Update(ctx, got)).To(Succeed())
+
+				_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKey{Name: content.Name}})
+				Expect(err).ToNot(HaveOccurred())
+
+				list := &fleet.ContentList{}
+				Expect(cl.List(ctx, list)).To(Succeed())
+				Expect(list.Items).To(BeEmpty())
+			})
+		})
+
 		Context("when there are referencing BundleDeployments", func() {
 			BeforeEach(func() {
 				content = &fleet.Content{
@@ -35,11 +35,7 @@ type CronDurationJob struct {
 // Internally, it assigns Location = Local if no location was specified in the schedule,
 // and from that point onward, any time-related calculations are performed using this location.
 func newCronDurationJob(ctx context.Context, schedule *fleet.Schedule, scheduler quartz.Scheduler, c client.Client) (*CronDurationJob, error) {
-	locationStr := schedule.Spec.Location
-	if locationStr == "" {
-		locationStr = "Local"
-	}
-	location, err := time.LoadLocation(locationStr)
+	location, err := scheduleLocation(schedule)
 	if err != nil {
 		return nil, err
 	}
@@ -90,6 +86,15 @@ func (c *CronDurationJob) Description() string {
 	return fmt.Sprintf("CronDurationJob-%s", c.hash)
 }
 
+// scheduleLocation returns the location of the schedule, which defaults to Local.
+func scheduleLocation(schedule *fleet.Schedule) (*time.Location, error) {
+	locationStr := schedule.Spec.Location
+	if locationStr == "" {
+		locationStr = "Local"
+	}
+	return time.LoadLocation(locationStr)
+}
+
 // scheduleKey builds a quartz.JobKey for the given fleet Schedule
 func scheduleKey(schedule *fleet.Schedule) *quartz.JobKey {
 	return quartz.NewJobKey(fmt.Sprintf("schedule-%s/%s", schedule.Namespace, schedule.Name))
@@ -0,0 +1,235 @@
+package reconciler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rancher/fleet/internal/cmd/controller/denywindow"
+	fleetutil "github.com/rancher/fleet/internal/cmd/controller/errorutil"
+	"github.com/rancher/fleet/internal/cmd/controller/target/matcher"
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+	"github.com/rancher/fleet/pkg/sharding"
+
+	"github.com/rancher/wrangler/v3/pkg/condition"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// defaultCalendarKey is the key of the iCal file in a schedule's calendar config map.
+const defaultCalendarKey = "calendar.ics"
+
+// handleDenySchedule updates the status of a Deny schedule from its deny
+// windows. Deny schedules do not toggle clusters, instead the bundle
+// reconciler holds back rollouts while a window is active. The schedule is
+// requeued for the next change of its windows.
+func (r *ScheduleReconciler) handleDenySchedule(ctx context.Context, schedule *fleet.Schedule) (ctrl.Result, error) {
+	// the schedule might have been an Allow schedule before
+	if err := deleteSchedule(ctx, schedule, r.Scheduler); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	status := fleet.ScheduleStatus{Conditions: schedule.Status.Conditions}
+	sources, skipped, err := denyWindowSources(ctx, r.Client, schedule)
+	if err == nil {
+		status.MatchingClusters, err = denyScheduleClusters(ctx, r.Client, schedule)
+	}
+	if err != nil {
+		if errors.Is(err, fleetutil.ErrRetryable) {
+			return ctrl.Result{}, err
+		}
+		setScheduleReadyCondition(&status, err)
+		return ctrl.Result{}, setScheduleStatus(ctx, r.Client, schedule, status)
+	}
+
+	now := time.Now()
+	active, next := denywindow.Evaluate(now, sources...)
+	status.Active = active != nil
+	if start := denywindow.NextStart(now, sources...); !start.IsZero() {
+		status.NextStartTime = metav1.Time{Time: start}
+	}
+	setScheduleCalendarCondition(&status, schedule, skipped)
+	setScheduleReadyCondition(&status, nil)
+	if err := setScheduleStatus(ctx, r.Client, schedule, status); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if next.IsZero() {
+		return ctrl.Result{}, nil
+	}
+	return ctrl.Result{RequeueAfter: next.Sub(now)}, nil
+}
+
+// setScheduleCalendarCondition sets the calendar condition of a Deny
+// schedule, which lists the calendar events skipped because of their
+// unsupported recurrence.
+func setScheduleCalendarCondition(status *fleet.ScheduleStatus, schedule *fleet.Schedule, skipped []string) {
+	cond := condition.Cond(fleet.ScheduleConditionCalendar)
+	if schedule.Spec.Calendar == nil {
+		if cond.GetStatus(status) != "" {
+			cond.SetStatusBool(status, true)
+			cond.Message(status, "")
+		}
+		return
+	}
+	if len(skipped) == 0 {
+		cond.SetStatusBool(status, true)
+		cond.Message(status, "")
+		return
+	}
+	cond.SetStatusBool(status, false)
+	cond.Message(status, fmt.Sprintf("skipped %d event(s) with unsupported recurrence: %s", len(skipped), strings.Join(skipped, ", ")))
+}
+
+// denyWindowSources returns the sources of the deny windows of the Deny
+// schedule: its cron expression and the events of its calendar. It also
+// returns the summaries of the calendar events, which were skipped because
+// their recurrence is not supported.
+func denyWindowSources(ctx context.Context, c client.Client, schedule *fleet.Schedule) ([]denywindow.Source, []string, error) {
+	location, err := scheduleLocation(schedule)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var sources []denywindow.Source
+	if schedule.Spec.Schedule != "" {
+		if err := checkScheduleAndDuration(schedule, location); err != nil {
+			return nil, nil, err
+		}
+		cron, err := denywindow.NewCron(schedule.Spec.Schedule, schedule.Spec.Duration.Duration, location)
+		if err != nil {
+			return nil, nil, err
+		}
+		sources = append(sources, cron)
+	}
+
+	var skipped []string
+	if cal := schedule.Spec.Calendar; cal != nil {
+		cm := &corev1.ConfigMap{}
+		if err := c.Get(ctx, client.ObjectKey{Namespace: schedule.Namespace, Name: cal.ConfigMapName}, cm); apierrors.IsNotFound(err) {
+			return nil, nil, fmt.Errorf("calendar config map %s not found", cal.ConfigMapName)
+		} else if err != nil {
+			return nil, nil, fmt.Errorf("%w, getting calendar config map %s: %w", fleetutil.ErrRetryable, cal.ConfigMapName, err)
+		}
+		key := cal.Key
+		if key == "" {
+			key = defaultCalendarKey
+		}
+		data, ok := cm.Data[key]
+		if !ok {
+			return nil, nil, fmt.Errorf("calendar config map %s has no key %s", cal.ConfigMapName, key)
+		}
+		events, skippedEvents, err := denywindow.ParseCalendar(data, location)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid calendar in config map %s: %w", cal.ConfigMapName, err)
+		}
+		for _, event := range events {
+			sources = append(sources, event)
+		}
+		skipped = skippedEvents
+	}
+
+	if len(sources) == 0 && len(skipped) == 0 {
+		return nil, nil, fmt.Errorf("deny schedule needs a schedule or a calendar")
+	}
+	return sources, skipped, nil
+}
+
+// calendarSchedules returns the Deny schedules of the shard, whose calendar
+// is in the config map.
+func calendarSchedules(ctx context.Context, c client.Client, shardID string, cm client.Object) []fleet.Schedule {
+	schedules := &fleet.ScheduleList{}
+	if err := c.List(ctx, schedules, client.InNamespace(cm.GetNamespace())); err != nil {
+		log.FromContext(ctx).V(1).Info("Failed to list schedules for calendar", "configmap", cm.GetName(), "error", err)
+		return nil
+	}
+
+	var result []fleet.Schedule
+	for _, schedule := range schedules.Items {
+		if schedule.Spec.Type != fleet.DenySchedule || schedule.Spec.Calendar == nil || schedule.Spec.Calendar.ConfigMapName != cm.GetName() {
+			continue
+		}
+		if !sharding.ShouldProcess(&schedule, shardID) {
+			continue
+		}
+		result = append(result, schedule)
+	}
+	return result
+}
+
+// denyScheduleClusters returns the names of the clusters targeted by the Deny
+// schedule. Without cluster targets, all clusters in the namespace are
+// targeted.
+func denyScheduleClusters(ctx context.Context, c client.Client, schedule *fleet.Schedule) ([]string, error) {
+	if len(schedule.Spec.Targets.Clusters) > 0 {
+		m, err := matcher.NewScheduleMatch(schedule)
+		if err != nil {
+			return nil, err
+		}
+		return matchingClusters(ctx, m, c, schedule.Namespace)
+	}
+
+	clusters := &fleet.ClusterList{}
+	if err := c.List(ctx, clusters, client.InNamespace(schedule.Namespace)); err != nil {
+		return nil, fmt.Errorf("%w, listing clusters: %w", fleetutil.ErrRetryable, err)
+	}
+	names := make([]string, 0, len(clusters.Items))
+	for _, cluster := range clusters.Items {
+		names = append(names, cluster.Name)
+	}
+	return names, nil
+}
+
+// denyScheduleMatchesBundle returns true if the Deny schedule targets the
+// bundle. Without bundle targets, all bundles in the namespace are
+// targeted. GitRepos are only listed for GitRepo selectors.
+func denyScheduleMatchesBundle(ctx context.Context, c client.Client, schedule *fleet.Schedule, bundle *fleet.Bundle) (bool, error) {
+	if bundle.Namespace != schedule.Namespace {
+		return false, nil
+	}
+	if len(schedule.Spec.Targets.Bundles) == 0 {
+		return true, nil
+	}
+
+	repoName := bundle.Labels[fleet.RepoLabel]
+	for _, t := range schedule.Spec.Targets.Bundles {
+		if t.BundleName != "" && t.BundleName == bundle.Name {
+			return true, nil
+		}
+		if t.GitRepoName != "" && t.GitRepoName == repoName {
+			return true, nil
+		}
+		if t.BundleSelector != nil {
+			selector, err := metav1.LabelSelectorAsSelector(t.BundleSelector)
+			if err != nil {
+				return false, err
+			}
+			if selector.Matches(labels.Set(bundle.Labels)) {
+				return true, nil
+			}
+		}
+		if t.GitRepoSelector != nil && repoName != "" {
+			selector, err := metav1.LabelSelectorAsSelector(t.GitRepoSelector)
+			if err != nil {
+				return false, err
+			}
+			repos := &fleet.GitRepoList{}
+			if err := c.List(ctx, repos, client.InNamespace(bundle.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+				return false, fmt.Errorf("%w, listing gitrepos: %w", fleetutil.ErrRetryable, err)
+			}
+			if slices.ContainsFunc(repos.Items, func(repo fleet.GitRepo) bool { return repo.Name == repoName }) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,271 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/rancher/fleet/internal/cmd/controller/notifier"
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+	"github.com/rancher/fleet/pkg/sharding"
+	"github.com/rancher/wrangler/v3/pkg/condition"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// SinkProvider returns the sink configured in a notifier.
+type SinkProvider interface {
+	Sink(ctx context.Context, n *fleet.Notifier) (notifier.Sink, error)
+}
+
+// NotifierReconciler sends notifications about state transitions of
+// bundles, gitrepos and clusters.
+type NotifierReconciler struct {
+	client.Client
+	Scheme  *runtime.Scheme
+	ShardID string
+
+	Tracker *notifier.Tracker
+	Sinks   SinkProvider
+
+	Workers int
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *NotifierReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&fleet.Notifier{},
+			builder.WithPredicates(
+				predicate.GenerationChangedPredicate{},
+				sharding.FilterByShardID(r.ShardID),
+			),
+		).
+		// watched resources are not filtered by their shard, as
+		// mapToNotifiers selects the notifiers of this shard
+		Watches(
+			&fleet.Bundle{},
+			handler.EnqueueRequestsFromMapFunc(r.mapToNotifiers),
+			builder.WithPredicates(stateChangedPredicate()),
+		).
+		Watches(
+			&fleet.GitRepo{},
+			handler.EnqueueRequestsFromMapFunc(r.mapToNotifiers),
+			builder.WithPredicates(stateChangedPredicate()),
+		).
+		Watches(
+			&fleet.Cluster{},
+			handler.EnqueueRequestsFromMapFunc(r.mapToNotifiers),
+			builder.WithPredicates(stateChangedPredicate()),
+		).
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.Workers}).
+		Complete(r)
+}
+
+//+kubebuilder:rbac:groups=fleet.cattle.io,resources=notifiers,verbs=get;list;watch
+//+kubebuilder:rbac:groups=fleet.cattle.io,resources=notifiers/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=events.k8s.io,resources=events,verbs=create
+
+// Reconcile compares the states of the notifier's resources with the last
+// observed states and sends notifications about the transitions.
+func (r *NotifierReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithName("notifier")
+
+	n := &fleet.Notifier{}
+	if err := r.Get(ctx, req.NamespacedName, n); err != nil {
+		if apierrors.IsNotFound(err) {
+			r.Tracker.Forget(req.NamespacedName)
+		}
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	status := n.Status.DeepCopy()
+	cond := condition.Cond(fleet.NotifierConditionReady)
+
+	selector := labels.Everything()
+	if n.Spec.Selector != nil {
+		var err error
+		selector, err = metav1.LabelSelectorAsSelector(n.Spec.Selector)
+		if err != nil {
+			cond.SetError(status, "", fmt.Errorf("invalid selector: %w", err))
+			return ctrl.Result{}, r.updateStatus(ctx, req.NamespacedName, *status)
+		}
+	}
+
+	resources, err := r.resources(ctx, n, selector)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// configErr is reported in the status, sendErr is retried
+	var configErr, sendErr error
+	var sink notifier.Sink
+	for _, ev := range r.Tracker.Transitions(n, resources) {
+		if n.Spec.Suspend || (len(n.Spec.States) > 0 && !slices.Contains(n.Spec.States, ev.State)) {
+			r.Tracker.Commit(n, ev)
+			continue
+		}
+		if r.Tracker.Duplicate(n, ev) {
+			status.Deduplicated++
+			r.Tracker.Commit(n, ev)
+			continue
+		}
+
+		if sink == nil {
+			if sink, configErr = r.Sinks.Sink(ctx, n); configErr != nil {
+				break
+			}
+		}
+		var msg string
+		if msg, configErr = notifier.Render(n.Spec.Template, ev); configErr != nil {
+			break
+		}
+
+		if !r.Tracker.Allow(n) {
+			logger.V(1).Info("Dropping notification, rate limit exceeded", "kind", ev.Kind, "name", ev.Name, "state", ev.State)
+			status.RateLimited++
+			r.Tracker.Commit(n, ev)
+			continue
+		}
+		if sendErr = sink.Send(ctx, ev, msg); sendErr != nil {
+			break
+		}
+		logger.V(1).Info("Sent notification", "kind", ev.Kind, "name", ev.Name, "state", ev.State, "previousState", ev.PreviousState)
+		status.Sent++
+		status.LastSentTime = &metav1.Time{Time: ev.Time}
+		r.Tracker.Sent(n, ev)
+		r.Tracker.Commit(n, ev)
+	}
+
+	err = configErr
+	if err == nil {
+		err = sendErr
+	}
+	if err != nil {
+		logger.Error(err, "Failed to send notification")
+	}
+	cond.SetError(status, "", err)
+
+	if !equality.Semantic.DeepEqual(n.Status, *status) {
+		if err := r.updateStatus(ctx, req.NamespacedName, *status); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	return ctrl.Result{}, sendErr
+}
+
+// resources returns the current state of the resources watched by the
+// notifier.
+func (r *NotifierReconciler) resources(ctx context.Context, n *fleet.Notifier, selector labels.Selector) ([]notifier.Resource, error) {
+	opts := []client.ListOption{client.InNamespace(n.Namespace), client.MatchingLabelsSelector{Selector: selector}}
+
+	kinds := n.Spec.Resources
+	if len(kinds) == 0 {
+		kinds = []fleet.NotifierResource{fleet.NotifierResourceBundle, fleet.NotifierResourceGitRepo, fleet.NotifierResourceCluster}
+	}
+
+	var objs []client.Object
+	for _, kind := range kinds {
+		switch kind {
+		case fleet.NotifierResourceBundle:
+			list := &fleet.BundleList{}
+			if err := r.List(ctx, list, opts...); err != nil {
+				return nil, err
+			}
+			for i := range list.Items {
+				objs = append(objs, &list.Items[i])
+			}
+		case fleet.NotifierResourceGitRepo:
+			list := &fleet.GitRepoList{}
+			if err := r.List(ctx, list, opts...); err != nil {
+				return nil, err
+			}
+			for i := range list.Items {
+				objs = append(objs, &list.Items[i])
+			}
+		case fleet.NotifierResourceCluster:
+			list := &fleet.ClusterList{}
+			if err := r.List(ctx, list, opts...); err != nil {
+				return nil, err
+			}
+			for i := range list.Items {
+				objs = append(objs, &list.Items[i])
+			}
+		}
+	}
+
+	resources := make([]notifier.Resource, 0, len(objs))
+	for _, obj := range objs {
+		res, ok := notifier.ResourceOf(obj)
+		if !ok || res.State == "" {
+			continue
+		}
+		resources = append(resources, res)
+	}
+	return resources, nil
+}
+
+func (r *NotifierReconciler) updateStatus(ctx context.Context, req types.NamespacedName, status fleet.NotifierStatus) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		n := &fleet.Notifier{}
+		if err := r.Get(ctx, req, n); err != nil {
+			return err
+		}
+		n.Status = status
+		return r.Status().Update(ctx, n)
+	})
+}
+
+// mapToNotifiers enqueues all notifiers of the shard in the namespace of the
+// object.
+func (r *NotifierReconciler) mapToNotifiers(ctx context.Context, obj client.Object) []ctrl.Request {
+	notifiers := &fleet.NotifierList{}
+	if err := r.List(ctx, notifiers, client.InNamespace(obj.GetNamespace())); err != nil {
+		log.FromContext(ctx).WithName("notifier-handler").Error(err, "Failed to list notifiers", "namespace", obj.GetNamespace())
+		return nil
+	}
+
+	requests := make([]ctrl.Request, 0, len(notifiers.Items))
+	for _, n := range notifiers.Items {
+		if !sharding.ShouldProcess(&n, r.ShardID) {
+			continue
+		}
+		requests = append(requests, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: n.Namespace, Name: n.Name}})
+	}
+	return requests
+}
+
+// stateChangedPredicate filters updates of bundles, gitrepos and clusters,
+// which do not change their notifier state.
+func stateChangedPredicate() predicate.Funcs {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			o, ok := notifier.ResourceOf(e.ObjectOld)
+			if !ok {
+				return false
+			}
+			n, ok := notifier.ResourceOf(e.ObjectNew)
+			if !ok {
+				return false
+			}
+			return o.State != n.State || !equality.Semantic.DeepEqual(e.ObjectOld.GetLabels(), e.ObjectNew.GetLabels())
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return false
+		},
+	}
+}
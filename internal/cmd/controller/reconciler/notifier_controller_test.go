@@ -0,0 +1,210 @@
+package reconciler
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/rancher/fleet/internal/cmd/controller/notifier"
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+	"github.com/rancher/fleet/pkg/sharding"
+
+	"github.com/rancher/wrangler/v3/pkg/condition"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type fakeSink struct {
+	messages []string
+	err      error
+}
+
+func (s *fakeSink) Send(_ context.Context, _ notifier.Event, message string) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.messages = append(s.messages, message)
+	return nil
+}
+
+func (s *fakeSink) Sink(context.Context, *fleet.Notifier) (notifier.Sink, error) {
+	return s, nil
+}
+
+var _ = Describe("NotifierReconciler", func() {
+	var (
+		ctx  context.Context
+		r    *NotifierReconciler
+		sink *fakeSink
+		req  ctrl.Request
+	)
+
+	setBundleState := func(name string, summary fleet.BundleSummary) {
+		bundle := &fleet.Bundle{}
+		Expect(r.Get(ctx, types.NamespacedName{Namespace: "fleet-default", Name: name}, bundle)).To(Succeed())
+		bundle.Status.Summary = summary
+		Expect(r.Status().Update(ctx, bundle)).To(Succeed())
+	}
+	ready := fleet.BundleSummary{DesiredReady: 1, Ready: 1}
+	failed := fleet.BundleSummary{DesiredReady: 1, ErrApplied: 1}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		sch := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(sch)).To(Succeed())
+		Expect(fleet.AddToScheme(sch)).To(Succeed())
+
+		bundle := &fleet.Bundle{
+			ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "fleet-default", Labels: map[string]string{"team": "a"}},
+			Status:     fleet.BundleStatus{Summary: ready},
+		}
+		other := &fleet.Bundle{
+			ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "fleet-default", Labels: map[string]string{"team": "b"}},
+			Status:     fleet.BundleStatus{Summary: ready},
+		}
+		n := &fleet.Notifier{
+			ObjectMeta: metav1.ObjectMeta{Name: "oncall", Namespace: "fleet-default"},
+			Spec: fleet.NotifierSpec{
+				Resources: []fleet.NotifierResource{fleet.NotifierResourceBundle},
+				Selector:  &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}},
+				States:    []string{"ErrApplied"},
+				Template:  "{{.Name}}: {{.PreviousState}} -> {{.State}}",
+			},
+		}
+
+		cl := fake.NewClientBuilder().WithScheme(sch).
+			WithObjects(bundle, other, n).
+			WithStatusSubresource(&fleet.Bundle{}, &fleet.Notifier{}).
+			Build()
+
+		sink = &fakeSink{}
+		r = &NotifierReconciler{Client: cl, Scheme: sch, Tracker: notifier.NewTracker(), Sinks: sink}
+		req = ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "fleet-default", Name: "oncall"}}
+
+		// the first reconcile records the current states
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("notifies transitions of selected resources into selected states", func() {
+		setBundleState("app", failed)
+		setBundleState("other", failed)
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sink.messages).To(Equal([]string{"app: Ready -> ErrApplied"}))
+
+		// recovering is not notified, failing again is deduplicated
+		setBundleState("app", ready)
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+		setBundleState("app", failed)
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sink.messages).To(HaveLen(1))
+
+		n := &fleet.Notifier{}
+		Expect(r.Get(ctx, req.NamespacedName, n)).To(Succeed())
+		Expect(n.Status.Sent).To(BeEquivalentTo(1))
+		Expect(n.Status.Deduplicated).To(BeEquivalentTo(1))
+		Expect(n.Status.LastSentTime).ToNot(BeNil())
+		Expect(condition.Cond(fleet.NotifierConditionReady).IsTrue(&n.Status)).To(BeTrue())
+	})
+
+	It("retries failed notifications", func() {
+		sink.err = errors.New("connection refused")
+		setBundleState("app", failed)
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).To(HaveOccurred())
+
+		n := &fleet.Notifier{}
+		Expect(r.Get(ctx, req.NamespacedName, n)).To(Succeed())
+		Expect(condition.Cond(fleet.NotifierConditionReady).IsFalse(&n.Status)).To(BeTrue())
+		Expect(condition.Cond(fleet.NotifierConditionReady).GetMessage(&n.Status)).To(ContainSubstring("connection refused"))
+
+		sink.err = nil
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sink.messages).To(Equal([]string{"app: Ready -> ErrApplied"}))
+	})
+
+	It("reports invalid templates in the status", func() {
+		n := &fleet.Notifier{}
+		Expect(r.Get(ctx, req.NamespacedName, n)).To(Succeed())
+		n.Spec.Template = "{{.Name"
+		Expect(r.Update(ctx, n)).To(Succeed())
+		setBundleState("app", failed)
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sink.messages).To(BeEmpty())
+
+		Expect(r.Get(ctx, req.NamespacedName, n)).To(Succeed())
+		Expect(condition.Cond(fleet.NotifierConditionReady).GetMessage(&n.Status)).To(ContainSubstring("failed to parse template"))
+	})
+
+	It("drops notifications exceeding the rate limit", func() {
+		n := &fleet.Notifier{}
+		Expect(r.Get(ctx, req.NamespacedName, n)).To(Succeed())
+		n.Spec.Selector = nil
+		n.Spec.RateLimit = &fleet.NotifierRateLimit{Limit: 1}
+		Expect(r.Update(ctx, n)).To(Succeed())
+		setBundleState("app", failed)
+		setBundleState("other", failed)
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sink.messages).To(HaveLen(1))
+
+		Expect(r.Get(ctx, client.ObjectKeyFromObject(n), n)).To(Succeed())
+		Expect(n.Status.RateLimited).To(BeEquivalentTo(1))
+	})
+
+	It("does not count failed notifications against the rate limit", func() {
+		n := &fleet.Notifier{}
+		Expect(r.Get(ctx, req.NamespacedName, n)).To(Succeed())
+		n.Spec.RateLimit = &fleet.NotifierRateLimit{Limit: 1}
+		Expect(r.Update(ctx, n)).To(Succeed())
+		sink.err = errors.New("connection refused")
+		setBundleState("app", failed)
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).To(HaveOccurred())
+
+		sink.err = nil
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sink.messages).To(Equal([]string{"app: Ready -> ErrApplied"}))
+
+		Expect(r.Get(ctx, req.NamespacedName, n)).To(Succeed())
+		Expect(n.Status.RateLimited).To(BeZero())
+	})
+
+	It("enqueues only the notifiers of its shard", func() {
+		sharded := &fleet.Notifier{ObjectMeta: metav1.ObjectMeta{
+			Name:      "sharded",
+			Namespace: "fleet-default",
+			Labels:    map[string]string{sharding.ShardingRefLabel: "shard1"},
+		}}
+		Expect(r.Create(ctx, sharded)).To(Succeed())
+		bundle := &fleet.Bundle{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "fleet-default"}}
+
+		Expect(r.mapToNotifiers(ctx, bundle)).To(ConsistOf(req))
+
+		r.ShardID = "shard1"
+		Expect(r.mapToNotifiers(ctx, bundle)).To(ConsistOf(ctrl.Request{NamespacedName: client.ObjectKeyFromObject(sharded)}))
+
+		// clusters are usually not labelled with a shard
+		cluster := &fleet.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "local", Namespace: "fleet-default"}}
+		Expect(r.mapToNotifiers(ctx, cluster)).To(ConsistOf(ctrl.Request{NamespacedName: client.ObjectKeyFromObject(sharded)}))
+	})
+})
@@ -0,0 +1,354 @@
+package reconciler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/rancher/fleet/internal/cmd/cli/apply"
+	"github.com/rancher/fleet/internal/cmd/controller/finalize"
+	"github.com/rancher/fleet/internal/cmd/controller/status"
+	"github.com/rancher/fleet/internal/cmd/controller/summary"
+	"github.com/rancher/fleet/internal/ocistorage"
+	"github.com/rancher/fleet/internal/resourcestatus"
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+	"github.com/rancher/fleet/pkg/durations"
+	"github.com/rancher/fleet/pkg/sharding"
+	"github.com/rancher/wrangler/v3/pkg/condition"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// drivenScanSeparator separates the base and the options file of the bundles
+// of an OCIRepo. Paths cannot contain NUL bytes.
+const drivenScanSeparator = "\x00"
+
+// ArtifactPuller resolves and pulls OCI artifacts.
+type ArtifactPuller interface {
+	ResolveArtifact(ctx context.Context, opts ocistorage.OCIOpts, tag, semverRange string) (string, string, error)
+	PullArtifact(ctx context.Context, opts ocistorage.OCIOpts, ref, dir string) error
+}
+
+// OCIRepoReconciler polls the registry of an OCIRepo and creates bundles
+// from the pulled artifact, like fleet apply does for a GitRepo.
+type OCIRepoReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	ShardID  string
+
+	Puller ArtifactPuller
+
+	Workers int
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *OCIRepoReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&fleet.OCIRepo{},
+			builder.WithPredicates(
+				predicate.Or(
+					predicate.GenerationChangedPredicate{},
+					predicate.LabelChangedPredicate{},
+				),
+			),
+		).
+		// Fan out from bundle to OCIRepo
+		WatchesRawSource(source.TypedKind(
+			mgr.GetCache(),
+			&fleet.Bundle{},
+			handler.TypedEnqueueRequestsFromMapFunc(func(ctx context.Context, b *fleet.Bundle) []ctrl.Request {
+				repo := b.GetLabels()[fleet.OCIRepoLabel]
+				if repo == "" {
+					return nil
+				}
+				return []ctrl.Request{{
+					NamespacedName: types.NamespacedName{Namespace: b.GetNamespace(), Name: repo},
+				}}
+			}),
+			sharding.TypedFilterByShardID[*fleet.Bundle](r.ShardID), // WatchesRawSources ignores event filters, we need to use a predicate
+			status.BundleStatusChangedPredicate(),
+		)).
+		WithEventFilter(sharding.FilterByShardID(r.ShardID)).
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.Workers}).
+		Named("OCIRepo").
+		Complete(r)
+}
+
+//+kubebuilder:rbac:groups=fleet.cattle.io,resources=ocirepos,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=fleet.cattle.io,resources=ocirepos/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=fleet.cattle.io,resources=ocirepos/finalizers,verbs=update
+
+// Reconcile polls the registry of the OCIRepo, once its polling interval
+// has passed or its spec changed, and creates bundles from the pulled
+// artifact, if its digest changed. Bundles, which are no longer part of the
+// artifact, are deleted. It also summarizes the bundle deployments of the
+// OCIRepo in its status.
+func (r *OCIRepoReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithName("ocirepo")
+
+	repo := &fleet.OCIRepo{}
+	if err := r.Get(ctx, req.NamespacedName, repo); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if !repo.DeletionTimestamp.IsZero() {
+		if !controllerutil.ContainsFinalizer(repo, finalize.OCIRepoFinalizer) {
+			return ctrl.Result{}, nil
+		}
+		if err := finalize.PurgeBundles(ctx, r.Client, req.NamespacedName, fleet.OCIRepoLabel); err != nil {
+			return ctrl.Result{}, err
+		}
+		controllerutil.RemoveFinalizer(repo, finalize.OCIRepoFinalizer)
+		return ctrl.Result{}, client.IgnoreNotFound(r.Update(ctx, repo))
+	}
+
+	if err := finalize.EnsureFinalizer(ctx, r.Client, repo, finalize.OCIRepoFinalizer); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	logger = logger.WithValues("generation", repo.Generation, "url", repo.Spec.URL)
+	ctx = log.IntoContext(ctx, logger)
+
+	orig := repo.DeepCopy()
+	now := time.Now().UTC()
+	interval := ociRepoPollingInterval(repo)
+
+	var syncErr error
+	requeueAfter := interval - now.Sub(repo.Status.LastPollingTime.Time)
+	if repo.Generation != repo.Status.ObservedGeneration || requeueAfter <= 0 {
+		logger.V(1).Info("Polling OCI registry")
+		syncErr = r.sync(ctx, repo, now)
+		setOCIRepoAcceptedCondition(&repo.Status, syncErr)
+		requeueAfter = interval
+	}
+
+	bdList := &fleet.BundleDeploymentList{}
+	if err := r.List(ctx, bdList, client.MatchingLabels{
+		fleet.OCIRepoLabel:         repo.Name,
+		fleet.BundleNamespaceLabel: repo.Namespace,
+	}); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := setOCIRepoStatus(bdList, repo); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	statusPatch := client.MergeFrom(orig)
+	if patchData, err := statusPatch.Data(repo); err == nil && string(patchData) != "{}" {
+		// skip update if patch is empty
+		if err := r.Status().Patch(ctx, repo, statusPatch); err != nil {
+			return ctrl.Result{}, client.IgnoreNotFound(err)
+		}
+	}
+
+	if syncErr != nil {
+		logger.Error(syncErr, "Failed to create bundles from OCI artifact")
+		return ctrl.Result{}, syncErr
+	}
+
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// sync resolves the artifact of the OCIRepo and, if its digest or the
+// OCIRepo changed, creates the bundles from it.
+func (r *OCIRepoReconciler) sync(ctx context.Context, repo *fleet.OCIRepo, now time.Time) error {
+	repo.Status.LastPollingTime = metav1.Time{Time: now}
+
+	// bundles are pruned by their repo name, which must not be shared with a GitRepo
+	gitrepo := &fleet.GitRepo{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: repo.Namespace, Name: repo.Name}, gitrepo); err == nil {
+		return fmt.Errorf("a GitRepo named %q exists in namespace %q, OCIRepos and GitRepos cannot share a name", repo.Name, repo.Namespace)
+	} else if !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	opts, err := r.ociOpts(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	tag, digest, err := r.Puller.ResolveArtifact(ctx, opts, repo.Spec.Tag, repo.Spec.Semver)
+	if err != nil {
+		return err
+	}
+	if digest == repo.Status.Digest && repo.Generation == repo.Status.ObservedGeneration {
+		return nil
+	}
+
+	tmp, err := os.MkdirTemp("", "ocirepo-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	root := filepath.Join(tmp, "artifact")
+	if err := os.Mkdir(root, 0o700); err != nil {
+		return err
+	}
+	if err := r.Puller.PullArtifact(ctx, opts, digest, root); err != nil {
+		return err
+	}
+
+	targetsFile := filepath.Join(tmp, "targets.yaml")
+	if err := writeOCIRepoTargets(repo, targetsFile); err != nil {
+		return err
+	}
+
+	if err := r.createBundles(ctx, repo, root, targetsFile); err != nil {
+		return err
+	}
+
+	repo.Status.Tag = tag
+	repo.Status.Digest = digest
+	repo.Status.ObservedGeneration = repo.Generation
+	return nil
+}
+
+// createBundles runs the bundle scan of fleet apply over the artifact's tree
+// in root. Unlike for a GitRepo, the scan runs inside the controller, so the
+// bundles are restricted to the local files of the artifact.
+func (r *OCIRepoReconciler) createBundles(ctx context.Context, repo *fleet.OCIRepo, root, targetsFile string) error {
+	opts := apply.Options{
+		Namespace: repo.Namespace,
+		Labels: labels.Merge(repo.Labels, map[string]string{
+			fleet.RepoLabel:    repo.Name,
+			fleet.OCIRepoLabel: repo.Name,
+		}),
+		TargetsFile:     targetsFile,
+		ServiceAccount:  repo.Spec.ServiceAccount,
+		TargetNamespace: repo.Spec.TargetNamespace,
+		Paused:          repo.Spec.Paused,
+		SyncGeneration:  repo.Spec.ForceSyncGeneration,
+		KeepResources:   repo.Spec.KeepResources,
+		DeleteNamespace: repo.Spec.DeleteNamespace,
+		RootDir:         root,
+		LocalOnly:       true,
+	}
+	if c := repo.Spec.CorrectDrift; c != nil {
+		opts.CorrectDrift = c.Enabled
+		opts.CorrectDriftForce = c.Force
+		opts.CorrectDriftKeepFailHistory = c.KeepFailHistory
+		opts.ReportDrift = c.Report
+	}
+
+	if len(repo.Spec.Bundles) > 0 {
+		opts.DrivenScan = true
+		opts.DrivenScanSeparator = drivenScanSeparator
+		paths := make([]string, 0, len(repo.Spec.Bundles))
+		for _, b := range repo.Spec.Bundles {
+			path := b.Base
+			if b.Options != "" {
+				path = path + drivenScanSeparator + b.Options
+			}
+			paths = append(paths, path)
+		}
+		return apply.CreateBundlesDriven(ctx, r.Client, r.Recorder, repo.Name, paths, opts)
+	}
+	return apply.CreateBundles(ctx, r.Client, r.Recorder, repo.Name, repo.Spec.Paths, opts)
+}
+
+// ociOpts returns the options to access the registry of the OCIRepo.
+func (r *OCIRepoReconciler) ociOpts(ctx context.Context, repo *fleet.OCIRepo) (ocistorage.OCIOpts, error) {
+	opts := ocistorage.OCIOpts{
+		Reference:       repo.Spec.URL,
+		BasicHTTP:       repo.Spec.BasicHTTP,
+		InsecureSkipTLS: repo.Spec.InsecureSkipTLSverify,
+	}
+	if repo.Spec.ClientSecretName == "" {
+		return opts, nil
+	}
+
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: repo.Namespace, Name: repo.Spec.ClientSecretName}, secret); err != nil {
+		return opts, fmt.Errorf("failed to get client secret: %w", err)
+	}
+	opts.Username = string(secret.Data[corev1.BasicAuthUsernameKey])
+	opts.Password = string(secret.Data[corev1.BasicAuthPasswordKey])
+	return opts, nil
+}
+
+// writeOCIRepoTargets writes the targets of the OCIRepo to a targets file for
+// fleet apply. Like for a GitRepo, the targets are also target restrictions.
+func writeOCIRepoTargets(repo *fleet.OCIRepo, path string) error {
+	targets := repo.Spec.Targets
+	if len(targets) == 0 {
+		targets = []fleet.GitTarget{{Name: "default", ClusterGroup: "default"}}
+	}
+
+	spec := &fleet.BundleSpec{}
+	for _, target := range targets {
+		spec.Targets = append(spec.Targets, fleet.BundleTarget{
+			Name:                 target.Name,
+			ClusterName:          target.ClusterName,
+			ClusterSelector:      target.ClusterSelector,
+			ClusterGroup:         target.ClusterGroup,
+			ClusterGroupSelector: target.ClusterGroupSelector,
+			ClusterFactSelector:  target.ClusterFactSelector,
+		})
+		spec.TargetRestrictions = append(spec.TargetRestrictions, fleet.BundleTargetRestriction(target))
+	}
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+func ociRepoPollingInterval(repo *fleet.OCIRepo) time.Duration {
+	if repo.Spec.PollingInterval == nil || repo.Spec.PollingInterval.Duration <= 0 {
+		return durations.DefaultOCIRepoPollingInterval
+	}
+	return repo.Spec.PollingInterval.Duration
+}
+
+// setOCIRepoAcceptedCondition sets the condition and updates the timestamp, if the condition changed
+func setOCIRepoAcceptedCondition(status *fleet.OCIRepoStatus, err error) {
+	cond := condition.Cond(fleet.OCIRepoAcceptedCondition)
+	origStatus := status.DeepCopy()
+	cond.SetError(status, "", err)
+	if !equality.Semantic.DeepEqual(origStatus, status) {
+		cond.LastUpdated(status, time.Now().UTC().Format(time.RFC3339))
+	}
+}
+
+func setOCIRepoStatus(list *fleet.BundleDeploymentList, repo *fleet.OCIRepo) error {
+	// sort bundledeployments so lists in status are always in the same order
+	sort.Slice(list.Items, func(i, j int) bool {
+		return list.Items[i].UID < list.Items[j].UID
+	})
+
+	if err := status.SetFields(list, &repo.Status.StatusBase); err != nil {
+		return err
+	}
+
+	resourcestatus.SetResources(list.Items, &repo.Status.StatusBase)
+
+	summary.SetReadyConditions(&repo.Status, "Bundle", repo.Status.Summary)
+
+	repo.Status.Display.ReadyBundleDeployments = fmt.Sprintf("%d/%d",
+		repo.Status.Summary.Ready,
+		repo.Status.Summary.DesiredReady)
+
+	return nil
+}
@@ -0,0 +1,235 @@
+package reconciler
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/rancher/fleet/internal/cmd/controller/finalize"
+	"github.com/rancher/fleet/internal/ocistorage"
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	"github.com/rancher/wrangler/v3/pkg/condition"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// fakePuller serves artifacts, which are trees of files, by tag.
+type fakePuller struct {
+	artifacts map[string]map[string]string
+	digests   map[string]string
+	pulls     int
+}
+
+func (p *fakePuller) ResolveArtifact(_ context.Context, _ ocistorage.OCIOpts, tag, _ string) (string, string, error) {
+	return tag, p.digests[tag], nil
+}
+
+func (p *fakePuller) PullArtifact(_ context.Context, _ ocistorage.OCIOpts, ref, dir string) error {
+	p.pulls++
+	for tag, digest := range p.digests {
+		if digest != ref {
+			continue
+		}
+		for name, data := range p.artifacts[tag] {
+			path := filepath.Join(dir, name)
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				return err
+			}
+			if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+var _ = Describe("OCIRepoReconciler", func() {
+	const cm = "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\n"
+
+	var (
+		ctx    context.Context
+		r      *OCIRepoReconciler
+		puller *fakePuller
+		repo   *fleet.OCIRepo
+		req    ctrl.Request
+	)
+
+	bundleNames := func() []string {
+		bundles := &fleet.BundleList{}
+		Expect(r.List(ctx, bundles)).To(Succeed())
+		var names []string
+		for _, b := range bundles.Items {
+			names = append(names, b.Name)
+		}
+		return names
+	}
+
+	getRepo := func() *fleet.OCIRepo {
+		repo := &fleet.OCIRepo{}
+		Expect(r.Get(ctx, req.NamespacedName, repo)).To(Succeed())
+		return repo
+	}
+
+	setTag := func(tag string) {
+		repo := getRepo()
+		repo.Spec.Tag = tag
+		repo.Generation++
+		Expect(r.Update(ctx, repo)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		sch := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(sch)).To(Succeed())
+		Expect(fleet.AddToScheme(sch)).To(Succeed())
+
+		repo = &fleet.OCIRepo{
+			ObjectMeta: metav1.ObjectMeta{Name: "manifests", Namespace: "fleet-default", Generation: 1},
+			Spec: fleet.OCIRepoSpec{
+				URL:             "oci://registry.example.com/manifests",
+				Tag:             "1.0.0",
+				Paths:           []string{"apps"},
+				PollingInterval: &metav1.Duration{Duration: time.Minute},
+			},
+		}
+		puller = &fakePuller{
+			artifacts: map[string]map[string]string{
+				"1.0.0": {
+					"apps/frontend/fleet.yaml": "namespace: frontend\n",
+					"apps/frontend/cm.yaml":    cm,
+					"apps/backend/fleet.yaml":  "namespace: backend\n",
+					"apps/backend/cm.yaml":     cm,
+				},
+				"1.1.0": {
+					"apps/frontend/fleet.yaml": "namespace: frontend\n",
+					"apps/frontend/cm.yaml":    cm,
+				},
+			},
+			digests: map[string]string{"1.0.0": "sha256:aaa", "1.1.0": "sha256:bbb"},
+		}
+		cl := fake.NewClientBuilder().WithScheme(sch).
+			WithObjects(repo).
+			WithStatusSubresource(&fleet.OCIRepo{}, &fleet.Bundle{}).
+			Build()
+		r = &OCIRepoReconciler{Client: cl, Scheme: sch, Recorder: record.NewFakeRecorder(10), Puller: puller}
+		req = ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "fleet-default", Name: "manifests"}}
+	})
+
+	It("creates bundles from the artifact and prunes removed bundles", func() {
+		res, err := r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res.RequeueAfter).To(Equal(time.Minute))
+		Expect(bundleNames()).To(ConsistOf("manifests-apps-backend", "manifests-apps-frontend"))
+
+		bundle := &fleet.Bundle{}
+		Expect(r.Get(ctx, types.NamespacedName{Namespace: "fleet-default", Name: "manifests-apps-frontend"}, bundle)).To(Succeed())
+		Expect(bundle.Labels).To(HaveKeyWithValue(fleet.OCIRepoLabel, "manifests"))
+		Expect(bundle.Labels).To(HaveKeyWithValue(fleet.RepoLabel, "manifests"))
+		Expect(bundle.Spec.Targets).To(HaveLen(1))
+		Expect(bundle.Spec.Targets[0].ClusterGroup).To(Equal("default"))
+
+		repo := getRepo()
+		Expect(repo.Finalizers).To(ContainElement(finalize.OCIRepoFinalizer))
+		Expect(repo.Status.Tag).To(Equal("1.0.0"))
+		Expect(repo.Status.Digest).To(Equal("sha256:aaa"))
+		Expect(repo.Status.ObservedGeneration).To(Equal(int64(1)))
+		Expect(condition.Cond(fleet.OCIRepoAcceptedCondition).IsTrue(repo)).To(BeTrue())
+
+		setTag("1.1.0")
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(bundleNames()).To(ConsistOf("manifests-apps-frontend"))
+		Expect(getRepo().Status.Digest).To(Equal("sha256:bbb"))
+	})
+
+	It("pulls the artifact only when its digest changed", func() {
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(puller.pulls).To(Equal(1))
+
+		// polling interval has not passed yet
+		res, err := r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res.RequeueAfter).To(BeNumerically("<=", time.Minute))
+		Expect(getRepo().Status.LastPollingTime).ToNot(BeZero())
+
+		// polling interval has passed, but the digest is unchanged
+		repo := getRepo()
+		repo.Status.LastPollingTime = metav1.Time{Time: time.Now().Add(-2 * time.Minute)}
+		Expect(r.Status().Update(ctx, repo)).To(Succeed())
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(puller.pulls).To(Equal(1))
+
+		puller.digests["1.0.0"] = "sha256:ccc"
+		repo = getRepo()
+		repo.Status.LastPollingTime = metav1.Time{Time: time.Now().Add(-2 * time.Minute)}
+		Expect(r.Status().Update(ctx, repo)).To(Succeed())
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(puller.pulls).To(Equal(2))
+		Expect(getRepo().Status.Digest).To(Equal("sha256:ccc"))
+	})
+
+	It("creates bundles from the bundle paths of a driven scan", func() {
+		repo := getRepo()
+		repo.Spec.Paths = nil
+		repo.Spec.Bundles = []fleet.BundlePath{{Base: "apps/backend"}}
+		Expect(r.Update(ctx, repo)).To(Succeed())
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(bundleNames()).To(ConsistOf("manifests-apps-backend"))
+	})
+
+	It("rejects an OCIRepo with the name of a GitRepo", func() {
+		gitrepo := &fleet.GitRepo{ObjectMeta: metav1.ObjectMeta{Name: "manifests", Namespace: "fleet-default"}}
+		Expect(r.Create(ctx, gitrepo)).To(Succeed())
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).To(MatchError(ContainSubstring("cannot share a name")))
+		Expect(bundleNames()).To(BeEmpty())
+		Expect(condition.Cond(fleet.OCIRepoAcceptedCondition).IsFalse(getRepo())).To(BeTrue())
+	})
+
+	It("rejects charts outside of the artifact", func() {
+		puller.artifacts["1.0.0"]["apps/backend/fleet.yaml"] = "helm:\n  chart: /var/run/secrets/kubernetes.io/serviceaccount\n"
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).To(MatchError(ContainSubstring("must be a local path")))
+		Expect(bundleNames()).To(BeEmpty())
+	})
+
+	It("rejects bundle paths outside of the artifact", func() {
+		repo := getRepo()
+		repo.Spec.Paths = nil
+		repo.Spec.Bundles = []fleet.BundlePath{{Base: "../.."}}
+		Expect(r.Update(ctx, repo)).To(Succeed())
+
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).To(MatchError(ContainSubstring("invalid bundle directory")))
+		Expect(bundleNames()).To(BeEmpty())
+	})
+
+	It("deletes the bundles of a deleted OCIRepo", func() {
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(bundleNames()).To(HaveLen(2))
+
+		Expect(r.Delete(ctx, getRepo())).To(Succeed())
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(bundleNames()).To(BeEmpty())
+	})
+})
@@ -14,6 +14,7 @@ import (
 	"github.com/rancher/wrangler/v3/pkg/condition"
 	"github.com/reugn/go-quartz/quartz"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -45,6 +46,18 @@ type ScheduleReconciler struct {
 //+kubebuilder:rbac:groups=fleet.cattle.io,resources=clusters,verbs=get;list;watch;update;patch
 //+kubebuilder:rbac:groups=fleet.cattle.io,resources=clusters/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=fleet.cattle.io,resources=clustergroups,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
+
+// mapCalendarToSchedules returns requests for the Deny schedules, whose
+// calendar is in the config map.
+func (r *ScheduleReconciler) mapCalendarToSchedules(ctx context.Context, a client.Object) []ctrl.Request {
+	schedules := calendarSchedules(ctx, r.Client, r.ShardID, a)
+	requests := make([]ctrl.Request, 0, len(schedules))
+	for _, schedule := range schedules {
+		requests = append(requests, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(&schedule)})
+	}
+	return requests
+}
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *ScheduleReconciler) SetupWithManager(mgr ctrl.Manager) error {
@@ -52,14 +65,20 @@ func (r *ScheduleReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		For(&fleet.Schedule{},
 			builder.WithPredicates(
 				predicate.GenerationChangedPredicate{},
+				sharding.FilterByShardID(r.ShardID),
 			),
 		).
 		Watches(
 			&fleet.Cluster{},
 			handler.EnqueueRequestsFromMapFunc(r.mapClustersToSchedules),
-			builder.WithPredicates(clusterChangedPredicate()),
+			builder.WithPredicates(clusterChangedPredicate(), sharding.FilterByShardID(r.ShardID)),
+		).
+		// Config maps have no shard label, their Deny schedules are
+		// filtered instead.
+		Watches(
+			&corev1.ConfigMap{},
+			handler.EnqueueRequestsFromMapFunc(r.mapCalendarToSchedules),
 		).
-		WithEventFilter(sharding.FilterByShardID(r.ShardID)).
 		WithOptions(controller.Options{MaxConcurrentReconciles: r.Workers}).
 		Complete(r)
 }
@@ -82,6 +101,10 @@ func (r *ScheduleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 
 	logger.Info("Reconciling Schedule")
 
+	if schedule.Spec.Type == fleet.DenySchedule {
+		return r.handleDenySchedule(ctx, schedule)
+	}
+
 	if err := r.handleSchedule(ctx, schedule); err != nil {
 		// If the error is retryable (e.g., a transient k8s client error),
 		// return it to trigger a requeue by the controller runtime.
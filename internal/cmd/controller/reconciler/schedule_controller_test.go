@@ -2,6 +2,7 @@ package reconciler
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -10,6 +11,7 @@ import (
 	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
 	"github.com/rancher/wrangler/v3/pkg/condition"
 	"github.com/reugn/go-quartz/quartz"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -372,6 +374,92 @@ var _ = Describe("ScheduleReconciler", func() {
 				expected{scheduledJob: true, statusScheduled: true, statusActiveSchedule: false})
 		})
 	})
+
+	Context("Deny schedule", func() {
+		BeforeEach(func() {
+			schedule.Spec = fleet.ScheduleSpec{
+				Type:     fleet.DenySchedule,
+				Location: "UTC",
+				Calendar: &fleet.ScheduleCalendar{ConfigMapName: "holidays"},
+			}
+			now := time.Now().UTC()
+			calendar := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "holidays", Namespace: "default"},
+				Data: map[string]string{
+					"calendar.ics": "BEGIN:VCALENDAR\nBEGIN:VEVENT\nSUMMARY:Freeze\n" +
+						"DTSTART:" + now.Add(-time.Hour).Format("20060102T150405Z") + "\n" +
+						"DTEND:" + now.Add(time.Hour).Format("20060102T150405Z") + "\n" +
+						"END:VEVENT\nEND:VCALENDAR\n",
+				},
+			}
+			k8sclient = fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(schedule, cluster, calendar).
+				WithStatusSubresource(&fleet.Schedule{}, &fleet.Cluster{}).
+				Build()
+		})
+
+		It("should set the active window without toggling the clusters", func() {
+			result, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+			Expect(result.RequeueAfter).To(BeNumerically("<=", time.Hour))
+
+			updatedSchedule := &fleet.Schedule{}
+			Expect(k8sclient.Get(ctx, req.NamespacedName, updatedSchedule)).To(Succeed())
+			Expect(updatedSchedule.Status.Active).To(BeTrue())
+			Expect(updatedSchedule.Status.MatchingClusters).To(ConsistOf("test-cluster"))
+			Expect(condition.Cond(fleet.Ready).IsTrue(updatedSchedule)).To(BeTrue())
+
+			checkState(scheduler, k8sclient, "test-cluster", "default",
+				expected{scheduledJob: false, statusScheduled: false, statusActiveSchedule: false})
+		})
+
+		It("should report a missing calendar", func() {
+			Expect(k8sclient.Delete(ctx, &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "holidays", Namespace: "default"},
+			})).To(Succeed())
+
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+
+			updatedSchedule := &fleet.Schedule{}
+			Expect(k8sclient.Get(ctx, req.NamespacedName, updatedSchedule)).To(Succeed())
+			Expect(updatedSchedule.Status.Active).To(BeFalse())
+			Expect(condition.Cond(fleet.Ready).GetMessage(updatedSchedule)).To(ContainSubstring("holidays"))
+		})
+
+		It("should report skipped calendar events", func() {
+			calendar := &corev1.ConfigMap{}
+			Expect(k8sclient.Get(ctx, types.NamespacedName{Name: "holidays", Namespace: "default"}, calendar)).To(Succeed())
+			calendar.Data["calendar.ics"] = strings.Replace(calendar.Data["calendar.ics"], "END:VCALENDAR",
+				"BEGIN:VEVENT\nSUMMARY:Weekend\nDTSTART:20251206T000000Z\nDTEND:20251208T000000Z\n"+
+					"RRULE:FREQ=WEEKLY\nEND:VEVENT\nEND:VCALENDAR", 1)
+			Expect(k8sclient.Update(ctx, calendar)).To(Succeed())
+
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+
+			updatedSchedule := &fleet.Schedule{}
+			Expect(k8sclient.Get(ctx, req.NamespacedName, updatedSchedule)).To(Succeed())
+			Expect(updatedSchedule.Status.Active).To(BeTrue())
+			Expect(condition.Cond(fleet.Ready).IsTrue(updatedSchedule)).To(BeTrue())
+			Expect(condition.Cond(fleet.ScheduleConditionCalendar).IsFalse(updatedSchedule)).To(BeTrue())
+			Expect(condition.Cond(fleet.ScheduleConditionCalendar).GetMessage(updatedSchedule)).To(ContainSubstring("Weekend"))
+		})
+
+		It("should enqueue the schedules of a changed calendar", func() {
+			requests := reconciler.mapCalendarToSchedules(ctx, &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "holidays", Namespace: "default"},
+			})
+			Expect(requests).To(ConsistOf(req))
+
+			requests = reconciler.mapCalendarToSchedules(ctx, &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default"},
+			})
+			Expect(requests).To(BeEmpty())
+		})
+	})
 })
 
 //nolint:unparam // namespace is always default, for now. That may change.
@@ -0,0 +1,289 @@
+package reconciler
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rancher/fleet/internal/metrics"
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+	"github.com/rancher/fleet/pkg/sharding"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// defaultShardCoordinatorInterval is the interval in which the leases
+	// of the shards are checked.
+	defaultShardCoordinatorInterval = 30 * time.Second
+	// defaultShardGracePeriod is the time after the expiry of a shard's
+	// lease, before its resources are moved to another shard.
+	defaultShardGracePeriod = time.Minute
+)
+
+// shardCoordinatorRequest is the only request of the shard coordinator, all
+// events are mapped to it, as every run assigns all resources.
+var shardCoordinatorRequest = reconcile.Request{NamespacedName: types.NamespacedName{Name: "shard-coordinator"}}
+
+// ShardCoordinatorReconciler assigns gitrepos, helmops and bundles without
+// a shard label to the live controller shards by consistent hashing. Shards
+// are detected by the leader election leases of their controllers. If a
+// shard disappears, its assigned resources are moved to the remaining
+// shards. Resources labeled by users are never changed.
+type ShardCoordinatorReconciler struct {
+	client.Client
+	// Reader reads the leases, which are not cached.
+	Reader          client.Reader
+	SystemNamespace string
+	// LeaderElectionID is the name of the default shard's lease, the
+	// leases of the other shards have the shard ID as a suffix.
+	LeaderElectionID string
+
+	Interval    time.Duration
+	GracePeriod time.Duration
+	// now is used by tests.
+	now func() time.Time
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ShardCoordinatorReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	toCoordinator := handler.EnqueueRequestsFromMapFunc(func(context.Context, client.Object) []reconcile.Request {
+		return []reconcile.Request{shardCoordinatorRequest}
+	})
+	// run on new resources and if a shard label was changed
+	labelsChanged := builder.WithPredicates(predicate.Funcs{
+		CreateFunc: func(event.CreateEvent) bool { return true },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return e.ObjectOld.GetLabels()[sharding.ShardingRefLabel] != e.ObjectNew.GetLabels()[sharding.ShardingRefLabel] ||
+				e.ObjectOld.GetLabels()[fleet.RepoLabel] != e.ObjectNew.GetLabels()[fleet.RepoLabel] ||
+				e.ObjectOld.GetLabels()[fleet.HelmOpLabel] != e.ObjectNew.GetLabels()[fleet.HelmOpLabel]
+		},
+		DeleteFunc:  func(event.DeleteEvent) bool { return true },
+		GenericFunc: func(event.GenericEvent) bool { return false },
+	})
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("shard-coordinator").
+		Watches(&fleet.GitRepo{}, toCoordinator, labelsChanged).
+		Watches(&fleet.HelmOp{}, toCoordinator, labelsChanged).
+		Watches(&fleet.Bundle{}, toCoordinator, labelsChanged).
+		WithOptions(controller.Options{MaxConcurrentReconciles: 1}).
+		Complete(r)
+}
+
+//+kubebuilder:rbac:groups=fleet.cattle.io,resources=gitrepos;helmops;bundles,verbs=get;list;watch;patch
+//+kubebuilder:rbac:groups=coordination.k8s.io,resources=leases,verbs=list
+
+// Reconcile assigns all unassigned resources to a live shard and moves
+// resources from shards, which disappeared.
+func (r *ShardCoordinatorReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithName("shard-coordinator")
+
+	interval := r.Interval
+	if interval == 0 {
+		interval = defaultShardCoordinatorInterval
+	}
+
+	live, err := r.liveShards(ctx)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	ring := sharding.NewRing(live...)
+
+	gitrepos := &fleet.GitRepoList{}
+	if err := r.List(ctx, gitrepos); err != nil {
+		return ctrl.Result{}, err
+	}
+	helmops := &fleet.HelmOpList{}
+	if err := r.List(ctx, helmops); err != nil {
+		return ctrl.Result{}, err
+	}
+	bundles := &fleet.BundleList{}
+	if err := r.List(ctx, bundles); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	load := map[string]map[string]int{}
+	count := func(kind string, obj client.Object) {
+		id := obj.GetLabels()[sharding.ShardingRefLabel]
+		if load[id] == nil {
+			load[id] = map[string]int{}
+		}
+		load[id][kind]++
+	}
+
+	// shard IDs of gitrepos and helmops, which are not labeled by users,
+	// their bundles follow them
+	parents := map[string]string{}
+	for i := range gitrepos.Items {
+		obj := &gitrepos.Items[i]
+		if err := r.assign(ctx, "GitRepo", obj, ring.Get(shardKey("GitRepo", obj.Namespace, obj.Name))); err != nil {
+			return ctrl.Result{}, err
+		}
+		if !isUserLabeled(obj) {
+			parents[shardKey("GitRepo", obj.Namespace, obj.Name)] = obj.Labels[sharding.ShardingRefLabel]
+		}
+		count("GitRepo", obj)
+	}
+	for i := range helmops.Items {
+		obj := &helmops.Items[i]
+		if err := r.assign(ctx, "HelmOp", obj, ring.Get(shardKey("HelmOp", obj.Namespace, obj.Name))); err != nil {
+			return ctrl.Result{}, err
+		}
+		if !isUserLabeled(obj) {
+			parents[shardKey("HelmOp", obj.Namespace, obj.Name)] = obj.Labels[sharding.ShardingRefLabel]
+		}
+		count("HelmOp", obj)
+	}
+	for i := range bundles.Items {
+		obj := &bundles.Items[i]
+		if parent := bundleParentKey(obj); parent != "" {
+			// bundles of a gitrepo or helmop are processed by the same shard
+			if id, ok := parents[parent]; ok {
+				if err := r.setShard(ctx, "Bundle", obj, id); err != nil {
+					return ctrl.Result{}, err
+				}
+			}
+		} else if err := r.assign(ctx, "Bundle", obj, ring.Get(shardKey("Bundle", obj.Namespace, obj.Name))); err != nil {
+			return ctrl.Result{}, err
+		}
+		count("Bundle", obj)
+	}
+
+	logger.V(1).Info("Assigned resources to shards", "shards", live, "load", load)
+	metrics.SetShardLoad(live, load)
+
+	return ctrl.Result{RequeueAfter: interval}, nil
+}
+
+// liveShards returns the sorted IDs of the shards, whose leader election
+// lease is held and was renewed recently. The default shard is not
+// included, as it processes resources without a shard label.
+func (r *ShardCoordinatorReconciler) liveShards(ctx context.Context) ([]string, error) {
+	leases := &coordinationv1.LeaseList{}
+	if err := r.Reader.List(ctx, leases, client.InNamespace(r.SystemNamespace)); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if r.now != nil {
+		now = r.now()
+	}
+	grace := r.GracePeriod
+	if grace == 0 {
+		grace = defaultShardGracePeriod
+	}
+
+	var live []string
+	for _, lease := range leases.Items {
+		id, ok := strings.CutPrefix(lease.Name, r.LeaderElectionID+"-")
+		if !ok || id == "" {
+			continue
+		}
+		spec := lease.Spec
+		if spec.HolderIdentity == nil || *spec.HolderIdentity == "" || spec.RenewTime == nil {
+			continue
+		}
+		var duration time.Duration
+		if spec.LeaseDurationSeconds != nil {
+			duration = time.Duration(*spec.LeaseDurationSeconds) * time.Second
+		}
+		if spec.RenewTime.Add(duration + grace).Before(now) {
+			continue
+		}
+		live = append(live, id)
+	}
+	sort.Strings(live)
+	return live, nil
+}
+
+// assign sets the shard label of a resource, unless it was labeled by a
+// user.
+func (r *ShardCoordinatorReconciler) assign(ctx context.Context, kind string, obj client.Object, shardID string) error {
+	if isUserLabeled(obj) {
+		return nil
+	}
+	return r.setShard(ctx, kind, obj, shardID)
+}
+
+// setShard patches the shard label and the assigned annotation of obj. An
+// empty shardID removes both, so the default shard processes it.
+func (r *ShardCoordinatorReconciler) setShard(ctx context.Context, kind string, obj client.Object, shardID string) error {
+	assigned := shardID != ""
+	current, hasLabel := obj.GetLabels()[sharding.ShardingRefLabel]
+	if current == shardID && hasLabel == (shardID != "") && isAssigned(obj) == assigned {
+		return nil
+	}
+	if obj.GetDeletionTimestamp() != nil {
+		return nil
+	}
+
+	orig := obj.DeepCopyObject().(client.Object)
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	if shardID == "" {
+		delete(labels, sharding.ShardingRefLabel)
+	} else {
+		labels[sharding.ShardingRefLabel] = shardID
+	}
+	if assigned {
+		annotations[sharding.ShardingAssignedAnnotation] = "true"
+	} else {
+		delete(annotations, sharding.ShardingAssignedAnnotation)
+	}
+	obj.SetLabels(labels)
+	obj.SetAnnotations(annotations)
+
+	log.FromContext(ctx).Info("Moving resource to shard",
+		"kind", kind,
+		"namespace", obj.GetNamespace(),
+		"name", obj.GetName(),
+		"from", current,
+		"to", shardID,
+	)
+	return client.IgnoreNotFound(r.Patch(ctx, obj, client.MergeFrom(orig)))
+}
+
+// isUserLabeled returns true if the shard label was not set by the
+// coordinator.
+func isUserLabeled(obj client.Object) bool {
+	_, ok := obj.GetLabels()[sharding.ShardingRefLabel]
+	return ok && !isAssigned(obj)
+}
+
+func isAssigned(obj client.Object) bool {
+	return obj.GetAnnotations()[sharding.ShardingAssignedAnnotation] == "true"
+}
+
+func shardKey(kind, namespace, name string) string {
+	return kind + "/" + namespace + "/" + name
+}
+
+// bundleParentKey returns the shard key of the gitrepo or helmop, which
+// created the bundle.
+func bundleParentKey(bundle *fleet.Bundle) string {
+	if name := bundle.Labels[fleet.RepoLabel]; name != "" {
+		return shardKey("GitRepo", bundle.Namespace, name)
+	}
+	if name := bundle.Labels[fleet.HelmOpLabel]; name != "" {
+		return shardKey("HelmOp", bundle.Namespace, name)
+	}
+	return ""
+}
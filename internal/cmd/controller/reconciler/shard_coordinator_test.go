@@ -0,0 +1,141 @@
+package reconciler
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+	"github.com/rancher/fleet/pkg/sharding"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("ShardCoordinatorReconciler", func() {
+	var (
+		ctx context.Context
+		r   *ShardCoordinatorReconciler
+		now time.Time
+	)
+
+	lease := func(shardID string, renewed time.Time) *coordinationv1.Lease {
+		name := "fleet-controller-leader-election-shard"
+		if shardID != "" {
+			name += "-" + shardID
+		}
+		return &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "cattle-fleet-system", Name: name},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       ptr.To("fleet-controller-" + shardID),
+				LeaseDurationSeconds: ptr.To[int32](15),
+				RenewTime:            &metav1.MicroTime{Time: renewed},
+			},
+		}
+	}
+	objectMeta := func(name string, labels, annotations map[string]string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Namespace: "fleet-default", Name: name, Labels: labels, Annotations: annotations}
+	}
+	// shardOf returns the shard assigned by the coordinator, or an empty
+	// string for the default shard
+	shardOf := func(obj client.Object, name string) string {
+		Expect(r.Get(ctx, types.NamespacedName{Namespace: "fleet-default", Name: name}, obj)).To(Succeed())
+		id, ok := obj.GetLabels()[sharding.ShardingRefLabel]
+		if ok {
+			Expect(obj.GetAnnotations()).To(HaveKeyWithValue(sharding.ShardingAssignedAnnotation, "true"))
+		} else {
+			Expect(obj.GetAnnotations()).ToNot(HaveKey(sharding.ShardingAssignedAnnotation))
+		}
+		return id
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		now = time.Now()
+
+		sch := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(sch)).To(Succeed())
+		Expect(fleet.AddToScheme(sch)).To(Succeed())
+
+		c := fake.NewClientBuilder().WithScheme(sch).WithObjects(
+			lease("", now),
+			lease("shard0", now),
+			lease("shard1", now),
+			&fleet.GitRepo{ObjectMeta: objectMeta("repo", nil, nil)},
+			&fleet.GitRepo{ObjectMeta: objectMeta("manual", map[string]string{sharding.ShardingRefLabel: "gone"}, nil)},
+			&fleet.HelmOp{ObjectMeta: objectMeta("helmop", nil, nil)},
+			&fleet.Bundle{ObjectMeta: objectMeta("repo-app", map[string]string{fleet.RepoLabel: "repo"}, nil)},
+			&fleet.Bundle{ObjectMeta: objectMeta("manual-app", map[string]string{fleet.RepoLabel: "manual", sharding.ShardingRefLabel: "gone"}, nil)},
+			&fleet.Bundle{ObjectMeta: objectMeta("standalone", nil, nil)},
+		).Build()
+
+		r = &ShardCoordinatorReconciler{
+			Client:           c,
+			Reader:           c,
+			SystemNamespace:  "cattle-fleet-system",
+			LeaderElectionID: "fleet-controller-leader-election-shard",
+			now:              func() time.Time { return now },
+		}
+	})
+
+	It("assigns unlabeled resources to live shards", func() {
+		res, err := r.Reconcile(ctx, shardCoordinatorRequest)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res.RequeueAfter).To(Equal(defaultShardCoordinatorInterval))
+
+		repo := shardOf(&fleet.GitRepo{}, "repo")
+		Expect(repo).To(BeElementOf("shard0", "shard1"))
+
+		Expect(shardOf(&fleet.Bundle{}, "repo-app")).To(Equal(repo), "bundles follow their gitrepo")
+
+		Expect(shardOf(&fleet.HelmOp{}, "helmop")).To(BeElementOf("shard0", "shard1"))
+		Expect(shardOf(&fleet.Bundle{}, "standalone")).To(BeElementOf("shard0", "shard1"))
+
+		By("not changing resources labeled by users")
+		manual := &fleet.GitRepo{}
+		Expect(r.Get(ctx, types.NamespacedName{Namespace: "fleet-default", Name: "manual"}, manual)).To(Succeed())
+		Expect(manual.Labels).To(HaveKeyWithValue(sharding.ShardingRefLabel, "gone"))
+		Expect(manual.Annotations).ToNot(HaveKey(sharding.ShardingAssignedAnnotation))
+		manualBundle := &fleet.Bundle{}
+		Expect(r.Get(ctx, types.NamespacedName{Namespace: "fleet-default", Name: "manual-app"}, manualBundle)).To(Succeed())
+		Expect(manualBundle.Labels).To(HaveKeyWithValue(sharding.ShardingRefLabel, "gone"))
+	})
+
+	It("moves resources when their shard disappears", func() {
+		_, err := r.Reconcile(ctx, shardCoordinatorRequest)
+		Expect(err).ToNot(HaveOccurred())
+		repo := shardOf(&fleet.GitRepo{}, "repo")
+		remaining := map[string]string{"shard0": "shard1", "shard1": "shard0"}[repo]
+
+		By("waiting for the grace period of an expired lease")
+		expired := &coordinationv1.Lease{}
+		Expect(r.Get(ctx, client.ObjectKeyFromObject(lease(repo, now)), expired)).To(Succeed())
+		expired.Spec.RenewTime = &metav1.MicroTime{Time: now.Add(-time.Minute)}
+		Expect(r.Update(ctx, expired)).To(Succeed())
+		_, err = r.Reconcile(ctx, shardCoordinatorRequest)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(shardOf(&fleet.GitRepo{}, "repo")).To(Equal(repo))
+
+		now = now.Add(time.Minute)
+		_, err = r.Reconcile(ctx, shardCoordinatorRequest)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(shardOf(&fleet.GitRepo{}, "repo")).To(Equal(remaining))
+		Expect(shardOf(&fleet.Bundle{}, "repo-app")).To(Equal(remaining))
+
+		By("returning resources to the default shard without live shards")
+		Expect(r.Delete(ctx, lease(remaining, now))).To(Succeed())
+		_, err = r.Reconcile(ctx, shardCoordinatorRequest)
+		Expect(err).ToNot(HaveOccurred())
+		for name, obj := range map[string]client.Object{"repo": &fleet.GitRepo{}, "repo-app": &fleet.Bundle{}, "helmop": &fleet.HelmOp{}, "standalone": &fleet.Bundle{}} {
+			Expect(shardOf(obj, name)).To(BeEmpty(), name)
+		}
+	})
+})
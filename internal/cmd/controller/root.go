@@ -30,6 +30,7 @@ type FleetController struct {
 	Namespace            string `usage:"namespace to watch" default:"cattle-fleet-system" env:"NAMESPACE"`
 	DisableMetrics       bool   `usage:"disable metrics" name:"disable-metrics"`
 	ShardID              string `usage:"only manage resources labeled with a specific shard ID" name:"shard-id"`
+	ShardCoordinator     bool   `usage:"assign resources without a shard ID to the live shards, only valid without --shard-id" name:"shard-coordinator"`
 	EnableLeaderElection bool   `name:"leader-elect" default:"true" usage:"Enable leader election for controller manager. Enabling this will ensure there is only one active controller manager."`
 }
 
@@ -41,6 +42,8 @@ type ControllerReconcilerWorkers struct {
 	ImageScan        int
 	Schedule         int
 	Content          int
+	Notifier         int
+	OCIRepo          int
 }
 
 type BindAddresses struct {
@@ -143,6 +146,22 @@ func (f *FleetController) Run(cmd *cobra.Command, args []string) error {
 		workersOpts.Content = w
 	}
 
+	if d := os.Getenv("NOTIFIER_RECONCILER_WORKERS"); d != "" {
+		w, err := strconv.Atoi(d)
+		if err != nil {
+			setupLog.Error(err, "failed to parse NOTIFIER_RECONCILER_WORKERS", "value", d)
+		}
+		workersOpts.Notifier = w
+	}
+
+	if d := os.Getenv("OCIREPO_RECONCILER_WORKERS"); d != "" {
+		w, err := strconv.Atoi(d)
+		if err != nil {
+			setupLog.Error(err, "failed to parse OCIREPO_RECONCILER_WORKERS", "value", d)
+		}
+		workersOpts.OCIRepo = w
+	}
+
 	go func() {
 		log.Println(http.ListenAndServe("localhost:6060", nil)) //nolint:gosec // Debugging only
 	}()
@@ -156,6 +175,7 @@ func (f *FleetController) Run(cmd *cobra.Command, args []string) error {
 		bindAddresses,
 		f.DisableMetrics,
 		f.ShardID,
+		f.ShardCoordinator,
 	); err != nil {
 		return err
 	}
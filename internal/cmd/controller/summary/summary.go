@@ -37,13 +37,27 @@ func IncrementState(summary *fleet.BundleSummary, name string, state fleet.Bundl
 				Name:           name,
 				State:          state,
 				Message:        message,
-				ModifiedStatus: modified,
+				ModifiedStatus: withoutChanges(modified),
 				NonReadyStatus: nonReady,
 			})
 		}
 	}
 }
 
+// withoutChanges removes the field changes, which are only reported on the
+// bundle deployment, to keep summaries small.
+func withoutChanges(modified []fleet.ModifiedStatus) []fleet.ModifiedStatus {
+	if modified == nil {
+		return nil
+	}
+	result := make([]fleet.ModifiedStatus, len(modified))
+	for i, m := range modified {
+		m.Changes = nil
+		result[i] = m
+	}
+	return result
+}
+
 func IsReady(summary fleet.BundleSummary) bool {
 	return summary.DesiredReady == summary.Ready
 }
@@ -0,0 +1,241 @@
+package target
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+)
+
+// prometheusQueryTimeout bounds the queries of an analysis, which run while
+// reconciling the bundle.
+const prometheusQueryTimeout = 5 * time.Second
+
+// AnalysisPhase is the outcome of a partition analysis.
+type AnalysisPhase string
+
+const (
+	// AnalysisPending means the checks did not yet produce a result, e.g.
+	// a job is still running or the Prometheus server could not be reached.
+	AnalysisPending AnalysisPhase = "Pending"
+	// AnalysisPassed means all checks passed.
+	AnalysisPassed AnalysisPhase = "Passed"
+	// AnalysisFailed means at least one check failed.
+	AnalysisFailed AnalysisPhase = "Failed"
+)
+
+// Analyzer runs the analysis of a partition, which must pass before the
+// rollout continues with the next partition.
+type Analyzer interface {
+	Analyze(ctx context.Context, analysis *fleet.PartitionAnalysis, targets []*Target) (AnalysisPhase, string)
+}
+
+// NewAnalyzer returns an Analyzer, which uses client to query Prometheus
+// servers.
+func NewAnalyzer(client *http.Client) Analyzer {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &analyzer{client: client, timeout: prometheusQueryTimeout}
+}
+
+type analyzer struct {
+	client  *http.Client
+	timeout time.Duration
+}
+
+func (a *analyzer) Analyze(ctx context.Context, analysis *fleet.PartitionAnalysis, targets []*Target) (AnalysisPhase, string) {
+	if analysis.Job != nil {
+		if phase, msg := analyzeJob(analysis.Job, targets); phase != AnalysisPassed {
+			return phase, msg
+		}
+	}
+	if analysis.Prometheus != nil {
+		if phase, msg := a.analyzePrometheus(ctx, analysis.Prometheus); phase != AnalysisPassed {
+			return phase, msg
+		}
+	}
+	return AnalysisPassed, ""
+}
+
+// analyzeJob checks the job is ready on the clusters of all targets, based
+// on the resources reported in the bundledeployments' status (pure function).
+func analyzeJob(job *fleet.JobAnalysis, targets []*Target) (AnalysisPhase, string) {
+	if msg, failed := failedJob(job, targets); failed {
+		return AnalysisFailed, msg
+	}
+
+	for _, target := range targets {
+		if target.Deployment == nil {
+			return AnalysisPending, fmt.Sprintf("waiting for job %s to be deployed to cluster %s/%s", job.Name, target.Cluster.Namespace, target.Cluster.Name)
+		}
+		status := target.Deployment.Status
+
+		namespace := job.Namespace
+		if namespace == "" {
+			namespace = jobNamespace(target.Options)
+		}
+
+		for _, nonReady := range status.NonReadyStatus {
+			if nonReady.Kind != "Job" || nonReady.Name != job.Name || nonReady.Namespace != namespace {
+				continue
+			}
+			return AnalysisPending, fmt.Sprintf("waiting for job %s/%s to complete on cluster %s/%s", namespace, job.Name, target.Cluster.Namespace, target.Cluster.Name)
+		}
+
+		found := false
+		for _, r := range status.Resources {
+			if r.Kind == "Job" && r.Name == job.Name && r.Namespace == namespace {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return AnalysisFailed, fmt.Sprintf("job %s/%s is not deployed to cluster %s/%s", namespace, job.Name, target.Cluster.Namespace, target.Cluster.Name)
+		}
+	}
+	return AnalysisPassed, ""
+}
+
+// failedJob returns a message and true, if the job failed on the cluster of
+// any target (pure function).
+func failedJob(job *fleet.JobAnalysis, targets []*Target) (string, bool) {
+	for _, target := range targets {
+		if target.Deployment == nil {
+			continue
+		}
+		namespace := job.Namespace
+		if namespace == "" {
+			namespace = jobNamespace(target.Options)
+		}
+		for _, nonReady := range target.Deployment.Status.NonReadyStatus {
+			if nonReady.Kind == "Job" && nonReady.Name == job.Name && nonReady.Namespace == namespace && nonReady.Summary.Error {
+				return fmt.Sprintf("job %s/%s failed on cluster %s/%s: %s", namespace, job.Name, target.Cluster.Namespace, target.Cluster.Name, nonReady.Summary.String()), true
+			}
+		}
+	}
+	return "", false
+}
+
+// jobNamespace returns the namespace resources without a namespace are
+// deployed to.
+func jobNamespace(opts fleet.BundleDeploymentOptions) string {
+	if opts.TargetNamespace != "" {
+		return opts.TargetNamespace
+	}
+	if opts.DefaultNamespace != "" {
+		return opts.DefaultNamespace
+	}
+	return "default"
+}
+
+type prometheusResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		ResultType string          `json:"resultType"`
+		Result     json.RawMessage `json:"result"`
+	} `json:"data"`
+}
+
+// analyzePrometheus runs an instant query. Errors to reach the server, or
+// queries exceeding the timeout, are reported as pending, so the query is
+// retried.
+func (a *analyzer) analyzePrometheus(ctx context.Context, prom *fleet.PrometheusAnalysis) (AnalysisPhase, string) {
+	ctx, cancel := context.WithTimeout(ctx, a.timeout)
+	defer cancel()
+
+	u := strings.TrimSuffix(prom.Address, "/") + "/api/v1/query?" + url.Values{"query": {prom.Query}}.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return AnalysisFailed, fmt.Sprintf("invalid prometheus address %q: %v", prom.Address, err)
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return AnalysisPending, fmt.Sprintf("failed to query prometheus: %v", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return AnalysisPending, fmt.Sprintf("failed to read prometheus response: %v", err)
+	}
+
+	result := prometheusResponse{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return AnalysisPending, fmt.Sprintf("failed to query prometheus: status code %d", resp.StatusCode)
+	}
+	if result.Status != "success" {
+		// bad queries are reported with status code 400 and won't
+		// succeed when retried
+		if resp.StatusCode == http.StatusBadRequest {
+			return AnalysisFailed, fmt.Sprintf("prometheus query %q failed: %s", prom.Query, result.Error)
+		}
+		return AnalysisPending, fmt.Sprintf("prometheus query %q failed: %s", prom.Query, result.Error)
+	}
+
+	values, err := sampleValues(result.Data.ResultType, result.Data.Result)
+	if err != nil {
+		return AnalysisFailed, fmt.Sprintf("prometheus query %q: %v", prom.Query, err)
+	}
+	if len(values) == 0 {
+		return AnalysisFailed, fmt.Sprintf("prometheus query %q returned no samples", prom.Query)
+	}
+	for _, v := range values {
+		if v == 0 {
+			return AnalysisFailed, fmt.Sprintf("prometheus query %q returned a zero sample", prom.Query)
+		}
+	}
+
+	return AnalysisPassed, ""
+}
+
+// sampleValues parses the values of a scalar or vector query result.
+func sampleValues(resultType string, result json.RawMessage) ([]float64, error) {
+	var samples [][]interface{}
+	switch resultType {
+	case "scalar":
+		var sample []interface{}
+		if err := json.Unmarshal(result, &sample); err != nil {
+			return nil, err
+		}
+		samples = append(samples, sample)
+	case "vector":
+		var vector []struct {
+			Value []interface{} `json:"value"`
+		}
+		if err := json.Unmarshal(result, &vector); err != nil {
+			return nil, err
+		}
+		for _, v := range vector {
+			samples = append(samples, v.Value)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported result type %q", resultType)
+	}
+
+	values := make([]float64, 0, len(samples))
+	for _, sample := range samples {
+		if len(sample) != 2 {
+			return nil, fmt.Errorf("invalid sample %v", sample)
+		}
+		s, ok := sample[1].(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid sample value %v", sample[1])
+		}
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
@@ -0,0 +1,181 @@
+package target
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+	"github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1/summary"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAnalyzePrometheus(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		response   string
+		want       AnalysisPhase
+		wantMsg    string
+	}{
+		{
+			name:     "vector with non-zero samples",
+			response: `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1700000000,"1"]},{"metric":{},"value":[1700000000,"0.5"]}]}}`,
+			want:     AnalysisPassed,
+		},
+		{
+			name:     "scalar",
+			response: `{"status":"success","data":{"resultType":"scalar","result":[1700000000,"1"]}}`,
+			want:     AnalysisPassed,
+		},
+		{
+			name:     "empty vector",
+			response: `{"status":"success","data":{"resultType":"vector","result":[]}}`,
+			want:     AnalysisFailed,
+			wantMsg:  "returned no samples",
+		},
+		{
+			name:     "zero sample",
+			response: `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1700000000,"0"]}]}}`,
+			want:     AnalysisFailed,
+			wantMsg:  "returned a zero sample",
+		},
+		{
+			name:     "matrix",
+			response: `{"status":"success","data":{"resultType":"matrix","result":[]}}`,
+			want:     AnalysisFailed,
+			wantMsg:  "unsupported result type",
+		},
+		{
+			name:       "bad query",
+			statusCode: http.StatusBadRequest,
+			response:   `{"status":"error","errorType":"bad_data","error":"parse error"}`,
+			want:       AnalysisFailed,
+			wantMsg:    "parse error",
+		},
+		{
+			name:       "unavailable",
+			statusCode: http.StatusServiceUnavailable,
+			response:   `{"status":"error","errorType":"unavailable","error":"not ready"}`,
+			want:       AnalysisPending,
+			wantMsg:    "not ready",
+		},
+		{
+			name:       "no prometheus response",
+			statusCode: http.StatusBadGateway,
+			response:   `bad gateway`,
+			want:       AnalysisPending,
+			wantMsg:    "status code 502",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/api/v1/query" || r.URL.Query().Get("query") != "up == 1" {
+					t.Errorf("unexpected request %s", r.URL)
+				}
+				if tt.statusCode != 0 {
+					w.WriteHeader(tt.statusCode)
+				}
+				fmt.Fprint(w, tt.response)
+			}))
+			defer srv.Close()
+
+			a := NewAnalyzer(srv.Client())
+			phase, msg := a.Analyze(context.TODO(), &fleet.PartitionAnalysis{
+				Prometheus: &fleet.PrometheusAnalysis{Address: srv.URL + "/", Query: "up == 1"},
+			}, nil)
+			if phase != tt.want {
+				t.Errorf("expected phase %s, got %s: %s", tt.want, phase, msg)
+			}
+			if !strings.Contains(msg, tt.wantMsg) {
+				t.Errorf("expected message to contain %q, got %q", tt.wantMsg, msg)
+			}
+		})
+	}
+}
+
+func TestAnalyzePrometheusTimeout(t *testing.T) {
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	defer srv.Close()
+	defer close(done)
+
+	a := &analyzer{client: srv.Client(), timeout: 10 * time.Millisecond}
+	phase, msg := a.Analyze(context.TODO(), &fleet.PartitionAnalysis{
+		Prometheus: &fleet.PrometheusAnalysis{Address: srv.URL, Query: "up == 1"},
+	}, nil)
+	if phase != AnalysisPending {
+		t.Errorf("expected phase %s, got %s: %s", AnalysisPending, phase, msg)
+	}
+	if !strings.Contains(msg, "deadline exceeded") {
+		t.Errorf("expected message to report the timeout, got %q", msg)
+	}
+}
+
+func TestAnalyzeJob(t *testing.T) {
+	job := &fleet.JobAnalysis{Name: "smoke-test"}
+	newTarget := func(status fleet.BundleDeploymentStatus) *Target {
+		return &Target{
+			Cluster:    &fleet.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "c1", Namespace: "fleet-default"}},
+			Options:    fleet.BundleDeploymentOptions{DefaultNamespace: "app"},
+			Deployment: &fleet.BundleDeployment{Status: status},
+		}
+	}
+	deployed := []fleet.BundleDeploymentResource{{Kind: "Job", APIVersion: "batch/v1", Namespace: "app", Name: "smoke-test"}}
+
+	tests := []struct {
+		name    string
+		targets []*Target
+		want    AnalysisPhase
+	}{
+		{
+			name:    "completed",
+			targets: []*Target{newTarget(fleet.BundleDeploymentStatus{Resources: deployed})},
+			want:    AnalysisPassed,
+		},
+		{
+			name: "running",
+			targets: []*Target{newTarget(fleet.BundleDeploymentStatus{
+				Resources:      deployed,
+				NonReadyStatus: []fleet.NonReadyStatus{{Kind: "Job", Namespace: "app", Name: "smoke-test", Summary: summary.Summary{Transitioning: true}}},
+			})},
+			want: AnalysisPending,
+		},
+		{
+			name: "failed",
+			targets: []*Target{newTarget(fleet.BundleDeploymentStatus{
+				Resources:      deployed,
+				NonReadyStatus: []fleet.NonReadyStatus{{Kind: "Job", Namespace: "app", Name: "smoke-test", Summary: summary.Summary{Error: true}}},
+			})},
+			want: AnalysisFailed,
+		},
+		{
+			name:    "not deployed",
+			targets: []*Target{newTarget(fleet.BundleDeploymentStatus{})},
+			want:    AnalysisFailed,
+		},
+		{
+			name: "no bundledeployment yet",
+			targets: []*Target{{
+				Cluster: &fleet.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "c1", Namespace: "fleet-default"}},
+			}},
+			want: AnalysisPending,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if phase, msg := analyzeJob(job, tt.targets); phase != tt.want {
+				t.Errorf("expected phase %s, got %s: %s", tt.want, phase, msg)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"cmp"
 	"context"
+	"encoding/json"
 	"fmt"
 	"maps"
 	"sort"
@@ -70,19 +71,28 @@ func (m *Manager) Targets(ctx context.Context, bundle *fleet.Bundle, manifestID
 				return nil, err
 			}
 
-			target := bm.Match(cluster.Name, ClusterGroupsToLabelMap(clusterGroups), cluster.Labels)
+			target := bm.Match(cluster.Name, ClusterGroupsToLabelMap(clusterGroups), cluster.Labels, cluster.Status.Facts)
 			if target == nil {
 				continue
 			}
+			var gates []string
+			if target.RequireApproval {
+				gates = append(gates, target.Name)
+			}
 			// check if there is any matching targetCustomization that should be applied
+			targetName := target.Name
 			targetOpts := target.BundleDeploymentOptions
-			targetCustomized := bm.MatchTargetCustomizations(cluster.Name, ClusterGroupsToLabelMap(clusterGroups), cluster.Labels)
+			targetCustomized := bm.MatchTargetCustomizations(cluster.Name, ClusterGroupsToLabelMap(clusterGroups), cluster.Labels, cluster.Status.Facts)
 			if targetCustomized != nil {
 				if targetCustomized.DoNotDeploy {
 					logger.V(1).Info("BundleDeployment creation for Bundle was skipped because doNotDeploy is set to true.")
 					continue
 				}
+				targetName = targetCustomized.Name
 				targetOpts = targetCustomized.BundleDeploymentOptions
+				if targetCustomized.RequireApproval && targetCustomized.Name != target.Name {
+					gates = append(gates, targetCustomized.Name)
+				}
 			}
 
 			opts := options.Merge(bundle.Spec.BundleDeploymentOptions, targetOpts)
@@ -90,6 +100,9 @@ func (m *Manager) Targets(ctx context.Context, bundle *fleet.Bundle, manifestID
 			if err != nil {
 				return nil, fmt.Errorf("cluster %s in namespace %s: %w", cluster.Name, cluster.Namespace, err)
 			}
+			if err := preprocessTopLevelArgs(&opts, &cluster); err != nil {
+				return nil, fmt.Errorf("cluster %s in namespace %s: %w", cluster.Name, cluster.Namespace, err)
+			}
 
 			deploymentID, err := options.DeploymentID(manifestID, opts)
 			if err != nil {
@@ -102,6 +115,8 @@ func (m *Manager) Targets(ctx context.Context, bundle *fleet.Bundle, manifestID
 				Bundle:        bundle,
 				Options:       opts,
 				DeploymentID:  deploymentID,
+				TargetName:    targetName,
+				ApprovalGates: gates,
 			})
 		}
 	}
@@ -191,14 +206,7 @@ func (m *Manager) getNamespacesForBundle(ctx context.Context, bundle *fleet.Bund
 }
 
 func preprocessHelmValues(logger logr.Logger, opts *fleet.BundleDeploymentOptions, cluster *fleet.Cluster) (err error) {
-	clusterLabels := yaml.CleanAnnotationsForExport(cluster.Labels)
-	clusterAnnotations := yaml.CleanAnnotationsForExport(cluster.Annotations)
-
-	for k, v := range cluster.Labels {
-		if strings.HasPrefix(k, "fleet.cattle.io/") || strings.HasPrefix(k, "management.cattle.io/") {
-			clusterLabels[k] = v
-		}
-	}
+	clusterLabels := exportedClusterLabels(cluster)
 	if len(clusterLabels) == 0 {
 		return nil
 	}
@@ -226,18 +234,7 @@ func preprocessHelmValues(logger logr.Logger, opts *fleet.BundleDeploymentOption
 	}
 
 	if !opts.Helm.DisablePreProcess {
-		templateValues := map[string]interface{}{}
-		if cluster.Spec.TemplateValues != nil {
-			templateValues = cluster.Spec.TemplateValues.Data
-		}
-
-		values := map[string]interface{}{
-			"ClusterNamespace":   cluster.Namespace,
-			"ClusterName":        cluster.Name,
-			"ClusterLabels":      toDict(clusterLabels),
-			"ClusterAnnotations": toDict(clusterAnnotations),
-			"ClusterValues":      templateValues,
-		}
+		values := templateContext(cluster, clusterLabels)
 
 		opts.Helm.Values.Data, err = processTemplateValues(opts.Helm.Values.Data, values)
 		if err != nil {
@@ -258,6 +255,80 @@ func preprocessHelmValues(logger logr.Logger, opts *fleet.BundleDeploymentOption
 
 }
 
+// preprocessTopLevelArgs renders the templates in the top-level arguments of
+// Jsonnet and CUE bundles, with the same values as templates in helm values.
+func preprocessTopLevelArgs(opts *fleet.BundleDeploymentOptions, cluster *fleet.Cluster) error {
+	if opts.Jsonnet == nil && opts.CUE == nil {
+		return nil
+	}
+	values := templateContext(cluster, exportedClusterLabels(cluster))
+
+	if opts.Jsonnet != nil && opts.Jsonnet.TopLevelArgs != nil {
+		opts.Jsonnet = opts.Jsonnet.DeepCopy()
+		data, err := processTemplateValues(opts.Jsonnet.TopLevelArgs.Data, values)
+		if err != nil {
+			return fmt.Errorf("jsonnet top-level arguments: %w", err)
+		}
+		opts.Jsonnet.TopLevelArgs.Data = data
+	}
+
+	if opts.CUE != nil && opts.CUE.TopLevelArgs != nil {
+		opts.CUE = opts.CUE.DeepCopy()
+		data, err := processTemplateValues(opts.CUE.TopLevelArgs.Data, values)
+		if err != nil {
+			return fmt.Errorf("cue top-level arguments: %w", err)
+		}
+		opts.CUE.TopLevelArgs.Data = data
+	}
+
+	return nil
+}
+
+// exportedClusterLabels returns the labels of the cluster, which are
+// available to templates.
+func exportedClusterLabels(cluster *fleet.Cluster) map[string]string {
+	clusterLabels := yaml.CleanAnnotationsForExport(cluster.Labels)
+	for k, v := range cluster.Labels {
+		if strings.HasPrefix(k, "fleet.cattle.io/") || strings.HasPrefix(k, "management.cattle.io/") {
+			clusterLabels[k] = v
+		}
+	}
+	return clusterLabels
+}
+
+// templateContext returns the values, which templates for the cluster are
+// rendered with.
+func templateContext(cluster *fleet.Cluster, clusterLabels map[string]string) map[string]interface{} {
+	templateValues := map[string]interface{}{}
+	if cluster.Spec.TemplateValues != nil {
+		templateValues = cluster.Spec.TemplateValues.Data
+	}
+
+	return map[string]interface{}{
+		"ClusterNamespace":   cluster.Namespace,
+		"ClusterName":        cluster.Name,
+		"ClusterLabels":      toDict(clusterLabels),
+		"ClusterAnnotations": toDict(yaml.CleanAnnotationsForExport(cluster.Annotations)),
+		"ClusterValues":      templateValues,
+		"ClusterFacts":       factsDict(cluster.Status.Facts),
+	}
+}
+
+// factsDict returns the facts with the field names used in the cluster's
+// status, e.g. "kubernetesVersion".
+func factsDict(facts *fleet.ClusterFacts) map[string]interface{} {
+	dict := map[string]interface{}{}
+	if facts == nil {
+		return dict
+	}
+	data, err := json.Marshal(facts)
+	if err != nil {
+		return dict
+	}
+	_ = json.Unmarshal(data, &dict)
+	return dict
+}
+
 // sprig dictionary functions like "default" and "hasKey" expect map[string]interface{}
 func toDict(values map[string]string) map[string]interface{} {
 	dict := make(map[string]interface{}, len(values))
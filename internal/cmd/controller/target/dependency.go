@@ -0,0 +1,304 @@
+package target
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	"github.com/rancher/wrangler/v3/pkg/condition"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Dependency is a dependency of a bundle with its label selectors.
+type Dependency struct {
+	// Selector matches the bundledeployments of the dependency.
+	Selector labels.Selector
+	// ClusterName and ClusterSelector scope the dependency to other
+	// clusters in the bundle's namespace. If both are empty, the dependency
+	// must be ready on the target's own cluster.
+	ClusterName     string
+	ClusterSelector labels.Selector
+	// Clusters are the clusters the dependency is scoped to. They are set
+	// by the caller for cross-cluster dependencies.
+	Clusters []*fleet.Cluster
+}
+
+// CrossCluster returns true if the dependency is scoped to other clusters.
+func (d *Dependency) CrossCluster() bool {
+	return d.ClusterName != "" || d.ClusterSelector != nil
+}
+
+// MatchesCluster returns true if the cross-cluster dependency is scoped to
+// the cluster.
+func (d *Dependency) MatchesCluster(cluster *fleet.Cluster) bool {
+	if d.ClusterName != "" && d.ClusterName == cluster.Name {
+		return true
+	}
+	return d.ClusterSelector != nil && d.ClusterSelector.Matches(labels.Set(cluster.Labels))
+}
+
+// scope describes the clusters of a cross-cluster dependency.
+func (d *Dependency) scope() string {
+	var scopes []string
+	if d.ClusterName != "" {
+		scopes = append(scopes, "cluster "+d.ClusterName)
+	}
+	if d.ClusterSelector != nil {
+		scopes = append(scopes, "clusters matching "+d.ClusterSelector.String())
+	}
+	return strings.Join(scopes, " or ")
+}
+
+// Dependencies returns the dependencies of the bundle. Empty references,
+// e.g. caused by a typo in fleet.yaml, are skipped.
+func Dependencies(bundle *fleet.Bundle) ([]Dependency, error) {
+	var deps []Dependency
+	for _, depend := range bundle.Spec.DependsOn {
+		if depend.Name == "" && depend.Selector == nil {
+			continue
+		}
+
+		ls := &metav1.LabelSelector{}
+		if depend.Selector != nil {
+			ls = depend.Selector.DeepCopy()
+		}
+		// depend.Name is just a shortcut for matchLabels: {bundle-name: name}
+		if depend.Name != "" {
+			ls = metav1.AddLabelToSelector(ls, fleet.BundleLabel, depend.Name)
+			ls = metav1.AddLabelToSelector(ls, fleet.BundleNamespaceLabel, bundle.Namespace)
+		}
+
+		selector, err := metav1.LabelSelectorAsSelector(ls)
+		if err != nil {
+			return nil, fmt.Errorf("invalid dependency of bundle %s/%s: %w", bundle.Namespace, bundle.Name, err)
+		}
+		dep := Dependency{Selector: selector, ClusterName: depend.ClusterName}
+		if depend.ClusterSelector != nil {
+			dep.ClusterSelector, err = metav1.LabelSelectorAsSelector(depend.ClusterSelector)
+			if err != nil {
+				return nil, fmt.Errorf("invalid cluster selector in dependency of bundle %s/%s: %w", bundle.Namespace, bundle.Name, err)
+			}
+		}
+		deps = append(deps, dep)
+	}
+	return deps, nil
+}
+
+// DependencySelectors returns the label selectors for the bundledeployments
+// the bundle depends on, regardless of their cluster.
+func DependencySelectors(bundle *fleet.Bundle) ([]labels.Selector, error) {
+	deps, err := Dependencies(bundle)
+	if err != nil {
+		return nil, err
+	}
+	selectors := make([]labels.Selector, 0, len(deps))
+	for _, dep := range deps {
+		selectors = append(selectors, dep.Selector)
+	}
+	return selectors, nil
+}
+
+// DependsOnDeployment returns true if one of the bundle's dependencies
+// matches the labels of the bundledeployment.
+func DependsOnDeployment(bundle *fleet.Bundle, bd *fleet.BundleDeployment) bool {
+	selectors, err := DependencySelectors(bundle)
+	if err != nil {
+		return false
+	}
+	for _, selector := range selectors {
+		if selector.Matches(labels.Set(bd.Labels)) {
+			return true
+		}
+	}
+	return false
+}
+
+// SelectorDependencyKey is the dependency index key of bundles, which
+// depend on bundles selected by labels.
+const SelectorDependencyKey = "selector"
+
+// DependencyIndexKeys returns the keys to index the bundle by its
+// dependencies: the namespaced names of the bundles it depends on by name
+// and SelectorDependencyKey, if it depends on bundles selected by labels.
+func DependencyIndexKeys(bundle *fleet.Bundle) []string {
+	var keys []string
+	for _, depend := range bundle.Spec.DependsOn {
+		switch {
+		case depend.Name != "":
+			keys = append(keys, bundle.Namespace+"/"+depend.Name)
+		case depend.Selector != nil:
+			keys = append(keys, SelectorDependencyKey)
+		}
+	}
+	return keys
+}
+
+// DependentIndexKeys returns the dependency index keys of the bundles,
+// which might depend on the bundledeployment.
+func DependentIndexKeys(bd *fleet.BundleDeployment) []string {
+	keys := []string{SelectorDependencyKey}
+	if ns, name := BundleFromDeployment(bd.Labels); ns != "" && name != "" {
+		keys = append(keys, ns+"/"+name)
+	}
+	return keys
+}
+
+// FindDependencyCycle returns the names of the bundles forming a dependency
+// cycle, which starts and ends at bundle, or nil if there is none. Bundles
+// are matched against the dependencies by the labels their
+// bundledeployments would have (pure function).
+func FindDependencyCycle(bundle *fleet.Bundle, bundles []fleet.Bundle) ([]string, error) {
+	byKey := map[string]*fleet.Bundle{}
+	for i := range bundles {
+		byKey[bundleKey(&bundles[i])] = &bundles[i]
+	}
+	// the bundle might be newer than the listed one
+	start := bundleKey(bundle)
+	byKey[start] = bundle
+
+	graph, err := dependencyGraph(byKey, start)
+	if err != nil {
+		return nil, err
+	}
+
+	visited := map[string]bool{}
+	var path []string
+	var visit func(key string) bool
+	visit = func(key string) bool {
+		visited[key] = true
+		path = append(path, key)
+		for _, dep := range graph[key] {
+			if dep == start {
+				path = append(path, dep)
+				return true
+			}
+			if !visited[dep] && visit(dep) {
+				return true
+			}
+		}
+		path = path[:len(path)-1]
+		return false
+	}
+
+	if !visit(start) {
+		return nil, nil
+	}
+
+	names := make([]string, 0, len(path))
+	for _, key := range path {
+		b := byKey[key]
+		if b.Namespace == bundle.Namespace {
+			names = append(names, b.Name)
+		} else {
+			names = append(names, key)
+		}
+	}
+	return names, nil
+}
+
+// dependencyGraph returns the sorted keys of the dependencies of each
+// bundle. Invalid dependencies of other bundles than start are skipped, they
+// are reported on their own bundle.
+func dependencyGraph(byKey map[string]*fleet.Bundle, start string) (map[string][]string, error) {
+	keys := make([]string, 0, len(byKey))
+	for key := range byKey {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	sets := make(map[string]labels.Set, len(keys))
+	for _, key := range keys {
+		sets[key] = labels.Set((&Target{Bundle: byKey[key]}).BundleDeploymentLabels("", ""))
+	}
+
+	graph := make(map[string][]string, len(keys))
+	for _, key := range keys {
+		selectors, err := DependencySelectors(byKey[key])
+		if err != nil {
+			if key == start {
+				return nil, err
+			}
+			continue
+		}
+		for _, dep := range keys {
+			for _, selector := range selectors {
+				if selector.Matches(sets[dep]) {
+					graph[key] = append(graph[key], dep)
+					break
+				}
+			}
+		}
+	}
+	return graph, nil
+}
+
+// HoldForDependencies sets the pending dependencies of each target from the
+// bundledeployments matching the dependency selectors. A dependency on a
+// cluster is pending, if no bundledeployment in the cluster's namespace
+// matches its selector, or if a matching one is not ready. Cross-cluster
+// dependencies are checked on their scoped clusters instead of the target's
+// cluster. Targets with pending dependencies are not updated to their staged
+// deployment (pure function).
+func HoldForDependencies(targets []*Target, deps []Dependency, bds []fleet.BundleDeployment) {
+	byNamespace := map[string][]*fleet.BundleDeployment{}
+	for i := range bds {
+		byNamespace[bds[i].Namespace] = append(byNamespace[bds[i].Namespace], &bds[i])
+	}
+
+	// pending returns the dependency names, which are not ready in the
+	// cluster namespace
+	pending := func(dep *Dependency, ns string) []string {
+		var result []string
+		matched := false
+		for _, bd := range byNamespace[ns] {
+			if !dep.Selector.Matches(labels.Set(bd.Labels)) {
+				continue
+			}
+			matched = true
+			if !condition.Cond(fleet.BundleDeploymentConditionReady).IsTrue(bd) {
+				result = append(result, bd.Labels[fleet.BundleLabel])
+			}
+		}
+		if !matched {
+			result = append(result, dep.Selector.String())
+		}
+		return result
+	}
+
+	// cross-cluster dependencies are the same for all targets
+	var crossCluster []string
+	for i := range deps {
+		dep := &deps[i]
+		if !dep.CrossCluster() {
+			continue
+		}
+		if len(dep.Clusters) == 0 {
+			crossCluster = append(crossCluster, fmt.Sprintf("%s on %s: no cluster found", dep.Selector.String(), dep.scope()))
+			continue
+		}
+		for _, cluster := range dep.Clusters {
+			for _, name := range pending(dep, cluster.Status.Namespace) {
+				crossCluster = append(crossCluster, fmt.Sprintf("%s on cluster %s", name, cluster.Name))
+			}
+		}
+	}
+
+	for _, t := range targets {
+		t.PendingDependencies = nil
+		for i := range deps {
+			if deps[i].CrossCluster() {
+				continue
+			}
+			t.PendingDependencies = append(t.PendingDependencies, pending(&deps[i], t.Cluster.Status.Namespace)...)
+		}
+		t.PendingDependencies = append(t.PendingDependencies, crossCluster...)
+	}
+}
+
+func bundleKey(b *fleet.Bundle) string {
+	return b.Namespace + "/" + b.Name
+}
@@ -0,0 +1,177 @@
+package target
+
+import (
+	"reflect"
+	"testing"
+
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	"github.com/rancher/wrangler/v3/pkg/genericcondition"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func dependentBundle(name string, labels map[string]string, deps ...fleet.BundleRef) fleet.Bundle {
+	return fleet.Bundle{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "fleet-local", Labels: labels},
+		Spec:       fleet.BundleSpec{DependsOn: deps},
+	}
+}
+
+func TestFindDependencyCycle(t *testing.T) {
+	tests := []struct {
+		name    string
+		bundles []fleet.Bundle
+		want    []string
+	}{
+		{
+			name: "no cycle",
+			bundles: []fleet.Bundle{
+				dependentBundle("app", nil, fleet.BundleRef{Name: "db"}),
+				dependentBundle("db", nil, fleet.BundleRef{Name: "crds"}),
+				dependentBundle("crds", nil),
+			},
+		},
+		{
+			name: "cycle by name",
+			bundles: []fleet.Bundle{
+				dependentBundle("app", nil, fleet.BundleRef{Name: "db"}),
+				dependentBundle("db", nil, fleet.BundleRef{Name: "app"}),
+			},
+			want: []string{"app", "db", "app"},
+		},
+		{
+			name: "cycle by selector",
+			bundles: []fleet.Bundle{
+				dependentBundle("app", map[string]string{"tier": "frontend"},
+					fleet.BundleRef{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "backend"}}}),
+				dependentBundle("db", map[string]string{"tier": "backend"},
+					fleet.BundleRef{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "frontend"}}}),
+			},
+			want: []string{"app", "db", "app"},
+		},
+		{
+			name: "selector matching the bundle itself",
+			bundles: []fleet.Bundle{
+				dependentBundle("app", map[string]string{"tier": "backend"},
+					fleet.BundleRef{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "backend"}}}),
+			},
+			want: []string{"app", "app"},
+		},
+		{
+			name: "cycle not involving the bundle",
+			bundles: []fleet.Bundle{
+				dependentBundle("app", nil, fleet.BundleRef{Name: "db"}),
+				dependentBundle("db", nil, fleet.BundleRef{Name: "cache"}),
+				dependentBundle("cache", nil, fleet.BundleRef{Name: "db"}),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FindDependencyCycle(&tt.bundles[0], tt.bundles)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected cycle %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func dependencyTarget(ns string) *Target {
+	return &Target{Cluster: &fleet.Cluster{Status: fleet.ClusterStatus{Namespace: ns}}}
+}
+
+func dependencyDeployment(ns string, ready corev1.ConditionStatus) fleet.BundleDeployment {
+	return fleet.BundleDeployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "db",
+			Namespace: ns,
+			Labels: map[string]string{
+				fleet.BundleLabel:          "db",
+				fleet.BundleNamespaceLabel: "fleet-local",
+			},
+		},
+		Status: fleet.BundleDeploymentStatus{
+			Conditions: []genericcondition.GenericCondition{
+				{Type: string(fleet.BundleDeploymentConditionReady), Status: ready},
+			},
+		},
+	}
+}
+
+func TestHoldForDependencies(t *testing.T) {
+	bundle := dependentBundle("app", nil, fleet.BundleRef{Name: "db"})
+	deps, err := Dependencies(&bundle)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	missing, notReady, ready := dependencyTarget("cluster-missing"), dependencyTarget("cluster-not-ready"), dependencyTarget("cluster-ready")
+	HoldForDependencies([]*Target{missing, notReady, ready}, deps, []fleet.BundleDeployment{
+		dependencyDeployment("cluster-not-ready", corev1.ConditionFalse),
+		dependencyDeployment("cluster-ready", corev1.ConditionTrue),
+	})
+
+	if len(missing.PendingDependencies) != 1 {
+		t.Errorf("expected a missing dependency to be pending, got %v", missing.PendingDependencies)
+	}
+	if !reflect.DeepEqual(notReady.PendingDependencies, []string{"db"}) {
+		t.Errorf("expected dependency db to be pending, got %v", notReady.PendingDependencies)
+	}
+	if len(ready.PendingDependencies) != 0 {
+		t.Errorf("expected no pending dependencies, got %v", ready.PendingDependencies)
+	}
+}
+
+func TestHoldForCrossClusterDependencies(t *testing.T) {
+	bundle := dependentBundle("app", nil, fleet.BundleRef{Name: "db", ClusterName: "hub"})
+	deps, err := Dependencies(&bundle)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !deps[0].CrossCluster() {
+		t.Fatalf("expected a cross-cluster dependency")
+	}
+
+	hub := &fleet.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "hub", Namespace: "fleet-local"},
+		Status:     fleet.ClusterStatus{Namespace: "cluster-hub"},
+	}
+	edge := &fleet.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "edge", Namespace: "fleet-local"},
+		Status:     fleet.ClusterStatus{Namespace: "cluster-edge"},
+	}
+	if deps[0].MatchesCluster(edge) || !deps[0].MatchesCluster(hub) {
+		t.Fatalf("expected the dependency to be scoped to the hub cluster")
+	}
+
+	edgeTarget := dependencyTarget("cluster-edge")
+	HoldForDependencies([]*Target{edgeTarget}, deps, nil)
+	if !reflect.DeepEqual(edgeTarget.PendingDependencies, []string{
+		"fleet.cattle.io/bundle-name=db,fleet.cattle.io/bundle-namespace=fleet-local on cluster hub: no cluster found",
+	}) {
+		t.Errorf("expected dependency without cluster to be pending, got %v", edgeTarget.PendingDependencies)
+	}
+
+	deps[0].Clusters = []*fleet.Cluster{hub}
+	// a ready bundledeployment on the edge cluster itself does not count
+	HoldForDependencies([]*Target{edgeTarget}, deps, []fleet.BundleDeployment{
+		dependencyDeployment("cluster-edge", corev1.ConditionTrue),
+		dependencyDeployment("cluster-hub", corev1.ConditionFalse),
+	})
+	if !reflect.DeepEqual(edgeTarget.PendingDependencies, []string{"db on cluster hub"}) {
+		t.Errorf("expected dependency db on cluster hub to be pending, got %v", edgeTarget.PendingDependencies)
+	}
+
+	HoldForDependencies([]*Target{edgeTarget}, deps, []fleet.BundleDeployment{
+		dependencyDeployment("cluster-hub", corev1.ConditionTrue),
+	})
+	if len(edgeTarget.PendingDependencies) != 0 {
+		t.Errorf("expected no pending dependencies, got %v", edgeTarget.PendingDependencies)
+	}
+}
@@ -10,7 +10,7 @@ type BundleMatch struct {
 	matcher *matcher
 }
 
-type findCriteriaMatch func(targetMatch targetMatch, clusterName, clusterGroup string, clusterGroupLabels, clusterLabels map[string]string) bool
+type findCriteriaMatch func(targetMatch targetMatch, clusterName, clusterGroup string, clusterGroupLabels, clusterLabels map[string]string, facts *fleet.ClusterFacts) bool
 
 func New(bundle *fleet.Bundle) (*BundleMatch, error) {
 	bm := &BundleMatch{
@@ -33,8 +33,8 @@ func (a *BundleMatch) MatchForTarget(name string) *fleet.BundleTarget {
 // It checks for restrictions, which means that just targets included in the GitRepo can be returned. TargetCustomizations
 // described in the fleet.yaml will be ignored.
 // All GitRepo targets are added as TargetRestrictions, which acts as a whitelist.
-func (a *BundleMatch) Match(clusterName string, clusterGroups map[string]map[string]string, clusterLabels map[string]string) *fleet.BundleTarget {
-	if m := a.matcher.match(clusterName, clusterLabels, clusterGroups, a.matcher.criteriaWithRestrictions); m != nil {
+func (a *BundleMatch) Match(clusterName string, clusterGroups map[string]map[string]string, clusterLabels map[string]string, facts *fleet.ClusterFacts) *fleet.BundleTarget {
+	if m := a.matcher.match(clusterName, clusterLabels, clusterGroups, facts, a.matcher.criteriaWithRestrictions); m != nil {
 		return m
 	}
 
@@ -43,8 +43,8 @@ func (a *BundleMatch) Match(clusterName string, clusterGroups map[string]map[str
 
 // MatchTargetCustomizations returns the first BundleTarget that matches the target criteria. Targets are evaluated in order.
 // It doesn't check for restrictions, which means TargetCustomizations described in the fleet.yaml are considered.
-func (a *BundleMatch) MatchTargetCustomizations(clusterName string, clusterGroups map[string]map[string]string, clusterLabels map[string]string) *fleet.BundleTarget {
-	if m := a.matcher.match(clusterName, clusterLabels, clusterGroups, criteriaWithoutRestrictions); m != nil {
+func (a *BundleMatch) MatchTargetCustomizations(clusterName string, clusterGroups map[string]map[string]string, clusterLabels map[string]string, facts *fleet.ClusterFacts) *fleet.BundleTarget {
+	if m := a.matcher.match(clusterName, clusterLabels, clusterGroups, facts, criteriaWithoutRestrictions); m != nil {
 		return m
 	}
 
@@ -69,6 +69,9 @@ func (a *BundleMatch) initMatcher() error {
 		if err != nil {
 			return err
 		}
+		if err := clusterMatcher.WithFacts(target.ClusterFactSelector); err != nil {
+			return err
+		}
 		t := targetMatch{
 			bundleTarget: &a.bundle.Spec.Targets[i],
 			criteria:     clusterMatcher,
@@ -82,6 +85,9 @@ func (a *BundleMatch) initMatcher() error {
 		if err != nil {
 			return err
 		}
+		if err := clusterMatcher.WithFacts(target.ClusterFactSelector); err != nil {
+			return err
+		}
 		m.restrictions = append(m.restrictions, clusterMatcher)
 	}
 
@@ -89,14 +95,14 @@ func (a *BundleMatch) initMatcher() error {
 	return nil
 }
 
-func (m *matcher) isRestricted(clusterName, clusterGroup string, clusterGroupLabels, clusterLabels map[string]string) bool {
+func (m *matcher) isRestricted(clusterName, clusterGroup string, clusterGroupLabels, clusterLabels map[string]string, facts *fleet.ClusterFacts) bool {
 	// There are no restrictions. That means this Bundle was not created by a GitRepo, and there are no targetCustomizations
 	if len(m.restrictions) == 0 {
 		return false
 	}
 
 	for _, restriction := range m.restrictions {
-		if restriction.Match(clusterName, clusterGroup, clusterGroupLabels, clusterLabels) {
+		if restriction.MatchFacts(clusterName, clusterGroup, clusterGroupLabels, clusterLabels, facts) {
 			return false
 		}
 	}
@@ -107,9 +113,9 @@ func (m *matcher) isRestricted(clusterName, clusterGroup string, clusterGroupLab
 
 // checks if criteria is matched just if the target is inside the targetRestrictions. This is used for Targets defined
 // in the GitRepo, since these targets are also added as targetRestrictions.
-func (m *matcher) criteriaWithRestrictions(targetMatch targetMatch, clusterName, clusterGroup string, clusterGroupLabels, clusterLabels map[string]string) bool {
-	if !m.isRestricted(clusterName, clusterGroup, clusterGroupLabels, clusterLabels) &&
-		targetMatch.criteria.Match(clusterName, clusterGroup, clusterGroupLabels, clusterLabels) {
+func (m *matcher) criteriaWithRestrictions(targetMatch targetMatch, clusterName, clusterGroup string, clusterGroupLabels, clusterLabels map[string]string, facts *fleet.ClusterFacts) bool {
+	if !m.isRestricted(clusterName, clusterGroup, clusterGroupLabels, clusterLabels, facts) &&
+		targetMatch.criteria.MatchFacts(clusterName, clusterGroup, clusterGroupLabels, clusterLabels, facts) {
 		return true
 	}
 
@@ -117,20 +123,20 @@ func (m *matcher) criteriaWithRestrictions(targetMatch targetMatch, clusterName,
 }
 
 // Checks targetMatch's criteria for a match on the specified cluster name, group and labels, without checking if target is inside the targetRestrictions. This is used for TargetCustomizations.
-func criteriaWithoutRestrictions(targetMatch targetMatch, clusterName, clusterGroup string, clusterGroupLabels, clusterLabels map[string]string) bool {
-	return targetMatch.criteria.Match(clusterName, clusterGroup, clusterGroupLabels, clusterLabels)
+func criteriaWithoutRestrictions(targetMatch targetMatch, clusterName, clusterGroup string, clusterGroupLabels, clusterLabels map[string]string, facts *fleet.ClusterFacts) bool {
+	return targetMatch.criteria.MatchFacts(clusterName, clusterGroup, clusterGroupLabels, clusterLabels, facts)
 }
 
-// match returns the first BundleTarget, from the matcher's target matches, which matches the specified cluster name, groups and labels, using matching logic implemented via findCriteriaMatch.
-func (m *matcher) match(clusterName string, clusterLabels map[string]string, clusterGroups map[string]map[string]string, findCriteriaMatch findCriteriaMatch) *fleet.BundleTarget {
+// match returns the first BundleTarget, from the matcher's target matches, which matches the specified cluster name, groups, labels and facts, using matching logic implemented via findCriteriaMatch.
+func (m *matcher) match(clusterName string, clusterLabels map[string]string, clusterGroups map[string]map[string]string, facts *fleet.ClusterFacts, findCriteriaMatch findCriteriaMatch) *fleet.BundleTarget {
 	for _, targetMatch := range m.matches {
 		if len(clusterGroups) == 0 {
-			if findCriteriaMatch(targetMatch, clusterName, "", nil, clusterLabels) {
+			if findCriteriaMatch(targetMatch, clusterName, "", nil, clusterLabels, facts) {
 				return targetMatch.bundleTarget
 			}
 		} else {
 			for clusterGroup, clusterGroupLabels := range clusterGroups {
-				if findCriteriaMatch(targetMatch, clusterName, clusterGroup, clusterGroupLabels, clusterLabels) {
+				if findCriteriaMatch(targetMatch, clusterName, clusterGroup, clusterGroupLabels, clusterLabels, facts) {
 					return targetMatch.bundleTarget
 				}
 			}
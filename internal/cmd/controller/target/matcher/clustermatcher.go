@@ -1,6 +1,8 @@
 package matcher
 
 import (
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 )
@@ -9,6 +11,7 @@ type criteria func(clusterName, clusterGroup string, clusterGroupLabels, cluster
 
 type ClusterMatcher struct {
 	criteria []criteria
+	facts    *factMatcher
 }
 
 func toSelector(labels *metav1.LabelSelector) (labels.Selector, error) {
@@ -53,8 +56,28 @@ func NewClusterMatcher(clusterName, clusterGroup string, clusterGroupSelector *m
 	return t, nil
 }
 
+// WithFacts adds a selector on the facts, which agents report for their
+// clusters, to the matcher.
+func (t *ClusterMatcher) WithFacts(selector *fleet.ClusterFactSelector) error {
+	if selector == nil {
+		return nil
+	}
+	facts, err := newFactMatcher(selector)
+	if err != nil {
+		return err
+	}
+	t.facts = facts
+	return nil
+}
+
 func (t *ClusterMatcher) Match(clusterName, clusterGroup string, clusterGroupLabels, clusterLabels map[string]string) bool {
-	if len(t.criteria) == 0 {
+	return t.MatchFacts(clusterName, clusterGroup, clusterGroupLabels, clusterLabels, nil)
+}
+
+// MatchFacts is like Match, but also matches the facts of the cluster, if
+// the matcher has a fact selector.
+func (t *ClusterMatcher) MatchFacts(clusterName, clusterGroup string, clusterGroupLabels, clusterLabels map[string]string, facts *fleet.ClusterFacts) bool {
+	if len(t.criteria) == 0 && t.facts == nil {
 		return false
 	}
 	for _, criteria := range t.criteria {
@@ -62,5 +85,5 @@ func (t *ClusterMatcher) Match(clusterName, clusterGroup string, clusterGroupLab
 			return false
 		}
 	}
-	return true
+	return t.facts == nil || t.facts.match(facts)
 }
@@ -0,0 +1,69 @@
+package matcher
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/Masterminds/semver/v3"
+
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+)
+
+// factMatcher matches the facts, which agents report for their clusters.
+type factMatcher struct {
+	selector          *fleet.ClusterFactSelector
+	kubernetesVersion *semver.Constraints
+}
+
+func newFactMatcher(selector *fleet.ClusterFactSelector) (*factMatcher, error) {
+	m := &factMatcher{selector: selector}
+	if selector.KubernetesVersion != "" {
+		c, err := semver.NewConstraint(selector.KubernetesVersion)
+		if err != nil {
+			return nil, fmt.Errorf("invalid kubernetes version constraint %q: %w", selector.KubernetesVersion, err)
+		}
+		m.kubernetesVersion = c
+	}
+	return m, nil
+}
+
+func (m *factMatcher) match(facts *fleet.ClusterFacts) bool {
+	if facts == nil {
+		return false
+	}
+	sel := m.selector
+
+	if m.kubernetesVersion != nil {
+		v, err := semver.NewVersion(facts.KubernetesVersion)
+		if err != nil {
+			return false
+		}
+		// distributions use pre-release parts for their builds, e.g.
+		// "v1.29.3-eks-adc7111", which would not satisfy ">= 1.29"
+		release, err := semver.NewVersion(fmt.Sprintf("%d.%d.%d", v.Major(), v.Minor(), v.Patch()))
+		if err != nil || !m.kubernetesVersion.Check(release) {
+			return false
+		}
+	}
+
+	if facts.NodeCount < sel.MinNodeCount {
+		return false
+	}
+	if len(sel.CloudProviders) > 0 && !slices.Contains(sel.CloudProviders, facts.CloudProvider) {
+		return false
+	}
+
+	return containsAll(facts.CRDGroups, sel.CRDGroups) &&
+		containsAll(facts.StorageClasses, sel.StorageClasses) &&
+		containsAll(facts.Architectures, sel.Architectures) &&
+		containsAll(facts.OperatingSystems, sel.OperatingSystems)
+}
+
+func containsAll(values, required []string) bool {
+	for _, r := range required {
+		if !slices.Contains(values, r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,108 @@
+package matcher
+
+import (
+	"testing"
+
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMatchFacts(t *testing.T) {
+	facts := &fleet.ClusterFacts{
+		KubernetesVersion: "v1.29.3-eks-adc7111",
+		NodeCount:         3,
+		Architectures:     []string{"amd64", "arm64"},
+		CloudProvider:     "aws",
+		CRDGroups:         []string{"cert-manager.io", "monitoring.coreos.com"},
+		StorageClasses:    []string{"gp3"},
+	}
+
+	tests := map[string]struct {
+		selector fleet.ClusterFactSelector
+		expected bool
+	}{
+		"version and crd groups": {
+			selector: fleet.ClusterFactSelector{KubernetesVersion: ">= 1.29", CRDGroups: []string{"cert-manager.io"}},
+			expected: true,
+		},
+		"version too old": {
+			selector: fleet.ClusterFactSelector{KubernetesVersion: ">= 1.30"},
+		},
+		"missing crd group": {
+			selector: fleet.ClusterFactSelector{CRDGroups: []string{"cert-manager.io", "gateway.networking.k8s.io"}},
+		},
+		"cloud provider": {
+			selector: fleet.ClusterFactSelector{CloudProviders: []string{"gce", "aws"}},
+			expected: true,
+		},
+		"other cloud provider": {
+			selector: fleet.ClusterFactSelector{CloudProviders: []string{"azure"}},
+		},
+		"architectures and storage classes": {
+			selector: fleet.ClusterFactSelector{Architectures: []string{"arm64"}, StorageClasses: []string{"gp3"}},
+			expected: true,
+		},
+		"too few nodes": {
+			selector: fleet.ClusterFactSelector{MinNodeCount: 5},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			m, err := newFactMatcher(&test.selector)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := m.match(facts); got != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, got)
+			}
+		})
+	}
+
+	m, err := newFactMatcher(&fleet.ClusterFactSelector{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.match(nil) {
+		t.Error("expected clusters without facts not to match")
+	}
+
+	if _, err := newFactMatcher(&fleet.ClusterFactSelector{KubernetesVersion: "latest"}); err == nil {
+		t.Error("expected error for invalid version constraint")
+	}
+}
+
+func TestBundleMatchFacts(t *testing.T) {
+	bundle := &fleet.Bundle{}
+	bundle.Spec.Targets = []fleet.BundleTarget{
+		{
+			Name:                "cert-manager",
+			ClusterSelector:     &metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}},
+			ClusterFactSelector: &fleet.ClusterFactSelector{CRDGroups: []string{"cert-manager.io"}},
+		},
+		{
+			Name:                "new-clusters",
+			ClusterFactSelector: &fleet.ClusterFactSelector{KubernetesVersion: ">= 1.29"},
+		},
+	}
+	bm, err := New(bundle)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	labels := map[string]string{"env": "prod"}
+	facts := &fleet.ClusterFacts{KubernetesVersion: "v1.30.0", CRDGroups: []string{"cert-manager.io"}}
+	if m := bm.Match("a", nil, labels, facts); m == nil || m.Name != "cert-manager" {
+		t.Errorf("expected first target to match, got %v", m)
+	}
+
+	facts.CRDGroups = nil
+	if m := bm.Match("a", nil, labels, facts); m == nil || m.Name != "new-clusters" {
+		t.Errorf("expected target with a fact selector only to match, got %v", m)
+	}
+
+	if m := bm.Match("a", nil, labels, nil); m != nil {
+		t.Errorf("expected no match for a cluster without facts, got %v", m.Name)
+	}
+}
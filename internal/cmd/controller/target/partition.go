@@ -1,33 +1,53 @@
 package target
 
 import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
 	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// analysisRetryInterval is the interval at which pending analyses are re-run.
+const analysisRetryInterval = 30 * time.Second
+
 type partition struct {
 	Status  fleet.PartitionStatus
 	Targets []*Target
+	// Definition is the partition definition from the rollout strategy, nil for automatic partitions.
+	Definition *fleet.Partition
 }
 
 // UpdatePartitions recomputes status, including partitions, from data in allTargets.
 // It creates Deployments in allTargets if they are missing.
 // It updates Deployments in allTargets if they are out of sync (DeploymentID != StagedDeploymentID).
-func UpdatePartitions(status *fleet.BundleStatus, allTargets []*Target) (err error) {
+// Partitions with a pause or an analysis hold back the rollout of the following partitions,
+// analyzer runs their analysis. The gates of the partitions continue from their previous
+// status, e.g. a passed analysis is not run again. The returned duration is non-zero, if the rollout waits for a
+// pause or a pending analysis and should be re-checked after that duration.
+// Deployments of partitions and targets, which require an approval, are only updated if
+// approvals contains an approval for the bundle's generation.
+func UpdatePartitions(ctx context.Context, status *fleet.BundleStatus, previous []fleet.PartitionStatus, allTargets []*Target, analyzer Analyzer, approvals []fleet.Approval) (requeueAfter time.Duration, err error) {
 	partitions, err := partitions(allTargets)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
+	status.PartitionStatus = nil
+
 	status.UnavailablePartitions = 0
 	status.MaxUnavailablePartitions, err = maxUnavailablePartitions(partitions, allTargets)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	for _, partition := range partitions {
-		for _, target := range partition.Targets {
+	for i := range partitions {
+		p := &partitions[i]
+		for _, target := range p.Targets {
 			// for a new bundledeployment, only stage the first maxNew (50) targets
 			if target.Deployment == nil && status.NewlyCreated < status.MaxNew {
 				status.NewlyCreated++
@@ -47,25 +67,135 @@ func UpdatePartitions(status *fleet.BundleStatus, allTargets []*Target) (err err
 			}
 		}
 
-		for _, currentTarget := range partition.Targets {
+		partitionApproved, unapproved := approvePartition(p, approvals)
+		for _, currentTarget := range p.Targets {
+			if !partitionApproved || unapproved[currentTarget] {
+				continue
+			}
 			// NOTE this will propagate the staged, merged options to the current deployment
-			updateDeploymentFromStaged(currentTarget, status, &partition.Status)
+			updateDeploymentFromStaged(currentTarget, status, &p.Status)
 		}
 
-		if updatePartitionStatus(&partition.Status, partition.Targets) {
+		if updatePartitionStatus(&p.Status, p.Targets) {
 			status.UnavailablePartitions++
 		}
 
+		// a partition waiting for its approval holds back the following partitions
+		if !partitionApproved {
+			break
+		}
+
 		if status.UnavailablePartitions > status.MaxUnavailablePartitions {
 			break
 		}
+
+		// the last partition does not hold back any other partition
+		if i == len(partitions)-1 {
+			break
+		}
+
+		blocked, wait := gatePartition(ctx, analyzer, p, previousPartitionStatus(previous, i, p.Status.Name), p.Status.Unavailable, time.Now())
+		if blocked {
+			requeueAfter = wait
+			break
+		}
 	}
 
 	for _, partition := range partitions {
 		status.PartitionStatus = append(status.PartitionStatus, partition.Status)
 	}
 
-	return nil
+	return requeueAfter, nil
+}
+
+// previousPartitionStatus returns the status of the partition at index i from
+// the previous reconcile, if the partition has the same name.
+func previousPartitionStatus(previous []fleet.PartitionStatus, i int, name string) *fleet.PartitionStatus {
+	if i >= len(previous) || previous[i].Name != name {
+		return nil
+	}
+	return &previous[i]
+}
+
+// gatePartition updates the gate fields of the partition's status from the
+// previous status and the partition's pause and analysis. It returns true, if
+// the rollout must not continue with the next partitions yet, and the
+// duration after which the gate should be re-checked. A zero duration means
+// the gate is only re-checked when the bundledeployments or the bundle change.
+func gatePartition(ctx context.Context, analyzer Analyzer, p *partition, previous *fleet.PartitionStatus, unavailable int, now time.Time) (bool, time.Duration) {
+	if p.Definition == nil || (p.Definition.PauseAfter == nil && p.Definition.Analysis == nil) {
+		return false, 0
+	}
+
+	allUpToDate := true
+	for _, target := range p.Targets {
+		if !upToDate(target) {
+			allUpToDate = false
+			break
+		}
+	}
+
+	// a halted or passed analysis is kept until the partition's deployments change
+	if previous != nil && allUpToDate {
+		p.Status.Halted = previous.Halted
+		p.Status.AnalysisPassed = previous.AnalysisPassed
+		if previous.Halted {
+			p.Status.Message = previous.Message
+			// halted rollouts are not re-checked, they wait for a bundle update
+			return true, 0
+		}
+	}
+
+	// a failed job keeps its cluster from becoming ready, halt early
+	if allUpToDate && p.Definition.Analysis != nil && p.Definition.Analysis.Job != nil {
+		if msg, failed := failedJob(p.Definition.Analysis.Job, p.Targets); failed {
+			p.Status.Halted = true
+			p.Status.Message = "rollout halted, analysis failed: " + msg
+			return true, 0
+		}
+	}
+
+	// unlike maxUnavailable, gates require all clusters of the partition to be ready
+	if !allUpToDate || unavailable > 0 {
+		p.Status.Message = "waiting for all clusters of the partition to be up-to-date and ready"
+		// bundledeployment status changes trigger a new reconcile
+		return true, 0
+	}
+
+	p.Status.ReadySince = &metav1.Time{Time: now}
+	if previous != nil && previous.ReadySince != nil {
+		p.Status.ReadySince = previous.ReadySince
+	}
+
+	if p.Definition.PauseAfter != nil {
+		until := p.Status.ReadySince.Add(p.Definition.PauseAfter.Duration)
+		if now.Before(until) {
+			p.Status.Message = fmt.Sprintf("paused until %s", until.UTC().Format(time.RFC3339))
+			return true, until.Sub(now)
+		}
+	}
+
+	if p.Definition.Analysis == nil || p.Status.AnalysisPassed {
+		return false, 0
+	}
+
+	if analyzer == nil {
+		p.Status.Message = "analysis is not supported"
+		return true, analysisRetryInterval
+	}
+
+	switch phase, msg := analyzer.Analyze(ctx, p.Definition.Analysis, p.Targets); phase {
+	case AnalysisPassed:
+		p.Status.AnalysisPassed = true
+		return false, 0
+	case AnalysisFailed:
+		p.Status.Halted = true
+		p.Status.Message = "rollout halted, analysis failed: " + msg
+		return true, 0
+	default:
+		p.Status.Message = "waiting for analysis: " + msg
+		return true, analysisRetryInterval
+	}
 }
 
 // maxUnavailablePartitions returns the maximum number of unavailable partitions given the targets and partitions (pure function)
@@ -80,6 +210,10 @@ func updateDeploymentFromStaged(t *Target, bundleStatus *fleet.BundleStatus, par
 	if t.Deployment != nil &&
 		// Not Paused
 		!t.IsPaused() &&
+		// Dependencies are ready
+		len(t.PendingDependencies) == 0 &&
+		// Not in a deny window
+		t.DenySchedule == "" &&
 		// Has been staged
 		t.Deployment.Spec.StagedDeploymentID != "" &&
 		// Is out of sync
@@ -98,3 +232,114 @@ func updateDeploymentFromStaged(t *Target, bundleStatus *fleet.BundleStatus, par
 		t.Deployment.Spec.Options = t.Deployment.Spec.StagedOptions
 	}
 }
+
+// approvePartition records the approvals of the gates of the partition and
+// of its targets in the partition's status. It returns false, if the
+// partition requires an approval, which is missing, and the targets, whose
+// own gates are missing an approval. Gates only need an approval, if a
+// deployment behind them is out of sync, i.e. would be updated.
+func approvePartition(p *partition, approvals []fleet.Approval) (bool, map[*Target]bool) {
+	if len(p.Targets) == 0 {
+		return true, nil
+	}
+	bundle := p.Targets[0].Bundle
+
+	approved := map[string]bool{}
+	pending := map[string]bool{}
+	check := func(gate string, outOfSync bool) bool {
+		if ok, seen := approved[gate]; seen {
+			return ok || !outOfSync
+		}
+		approval := findApproval(approvals, bundle, gate)
+		if approval != nil {
+			p.Status.Approvals = append(p.Status.Approvals, fleet.ApprovalRecord{
+				Gate:             gate,
+				Name:             approval.Name,
+				ClaimedApprover:  approval.Spec.ClaimedApprover,
+				ApprovedAt:       approval.CreationTimestamp.DeepCopy(),
+				BundleGeneration: approval.Spec.BundleGeneration,
+			})
+		}
+		approved[gate] = approval != nil
+		return approval != nil || !outOfSync
+	}
+
+	partitionApproved := true
+	if p.Definition != nil && p.Definition.RequireApproval {
+		outOfSync := false
+		for _, t := range p.Targets {
+			if !inSync(t) {
+				outOfSync = true
+				break
+			}
+		}
+		if !check(p.Status.Name, outOfSync) {
+			partitionApproved = false
+			pending[p.Status.Name] = true
+		}
+	}
+
+	var unapproved map[*Target]bool
+	for _, t := range p.Targets {
+		for _, gate := range t.ApprovalGates {
+			if !check(gate, !inSync(t)) {
+				if unapproved == nil {
+					unapproved = map[*Target]bool{}
+				}
+				unapproved[t] = true
+				pending[gate] = true
+			}
+		}
+	}
+
+	if len(pending) > 0 {
+		gates := make([]string, 0, len(pending))
+		for gate := range pending {
+			gates = append(gates, gate)
+		}
+		sort.Strings(gates)
+		p.Status.Message = fmt.Sprintf("waiting for approval of %s for bundle generation %d", strings.Join(gates, ", "), bundle.Generation)
+	}
+
+	return partitionApproved, unapproved
+}
+
+// findApproval returns the oldest approval of the gate for the bundle's
+// current generation.
+func findApproval(approvals []fleet.Approval, bundle *fleet.Bundle, gate string) *fleet.Approval {
+	var found *fleet.Approval
+	for i, a := range approvals {
+		if a.Namespace != bundle.Namespace ||
+			a.Spec.BundleName != bundle.Name ||
+			a.Spec.Gate != gate ||
+			a.Spec.BundleGeneration != bundle.Generation {
+			continue
+		}
+		if found == nil || a.CreationTimestamp.Before(&found.CreationTimestamp) {
+			found = &approvals[i]
+		}
+	}
+	return found
+}
+
+// inSync returns true if the target's deployment does not need to be
+// updated to its staged deployment.
+func inSync(t *Target) bool {
+	return t.Deployment == nil || t.Deployment.Spec.DeploymentID == t.Deployment.Spec.StagedDeploymentID
+}
+
+// RequireApproval returns true if a partition or a target of the bundle
+// requires an approval.
+func RequireApproval(targets []*Target) bool {
+	for _, p := range getRollout(targets).Partitions {
+		if p.RequireApproval {
+			return true
+		}
+	}
+	for _, t := range targets {
+		if len(t.ApprovalGates) > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,369 @@
+package target
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+	"github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1/summary"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type fakeAnalyzer struct {
+	phase AnalysisPhase
+	msg   string
+	calls int
+}
+
+func (f *fakeAnalyzer) Analyze(context.Context, *fleet.PartitionAnalysis, []*Target) (AnalysisPhase, string) {
+	f.calls++
+	return f.phase, f.msg
+}
+
+// canaryTargets returns a target in a canary partition and a target in a prod
+// partition. The canary cluster already deployed "v2", the prod cluster still
+// runs "v1".
+func canaryTargets(canary fleet.Partition, canaryReady bool) []*Target {
+	canary.Name = "canary"
+	canary.ClusterSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"env": "canary"}}
+	bundle := &fleet.Bundle{
+		ObjectMeta: metav1.ObjectMeta{Name: "app"},
+		Spec: fleet.BundleSpec{
+			RolloutStrategy: &fleet.RolloutStrategy{
+				Partitions: []fleet.Partition{
+					canary,
+					{
+						Name:            "prod",
+						ClusterSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}},
+					},
+				},
+			},
+		},
+	}
+
+	newTarget := func(env, deployed string, ready bool) *Target {
+		return &Target{
+			Bundle: bundle,
+			Cluster: &fleet.Cluster{
+				ObjectMeta: metav1.ObjectMeta{Name: env, Namespace: "fleet-default", Labels: map[string]string{"env": env}},
+			},
+			Deployment: &fleet.BundleDeployment{
+				Spec:   fleet.BundleDeploymentSpec{DeploymentID: deployed, StagedDeploymentID: deployed},
+				Status: fleet.BundleDeploymentStatus{AppliedDeploymentID: deployed, Ready: ready},
+			},
+			DeploymentID: "v2",
+		}
+	}
+
+	return []*Target{newTarget("canary", "v2", canaryReady), newTarget("prod", "v1", true)}
+}
+
+func TestUpdatePartitionsGates(t *testing.T) {
+	hour := &metav1.Duration{Duration: time.Hour}
+	analysis := &fleet.PartitionAnalysis{Prometheus: &fleet.PrometheusAnalysis{Address: "http://prometheus", Query: "up"}}
+	twoHoursAgo := &metav1.Time{Time: time.Now().Add(-2 * time.Hour)}
+
+	tests := []struct {
+		name        string
+		canary      fleet.Partition
+		canaryReady bool
+		previous    *fleet.PartitionStatus
+		analyzer    *fakeAnalyzer
+
+		wantAdvanced bool
+		wantRequeue  bool
+		wantHalted   bool
+		wantPassed   bool
+		wantCalls    int
+		wantMessage  string
+	}{
+		{
+			name:         "no gate",
+			canary:       fleet.Partition{},
+			canaryReady:  false,
+			wantAdvanced: true,
+		},
+		{
+			name:        "waits for canary to be ready",
+			canary:      fleet.Partition{PauseAfter: hour},
+			canaryReady: false,
+			wantMessage: "waiting for all clusters",
+		},
+		{
+			name:        "pauses after canary is ready",
+			canary:      fleet.Partition{PauseAfter: hour},
+			canaryReady: true,
+			wantRequeue: true,
+			wantMessage: "paused until",
+		},
+		{
+			name:         "continues after pause",
+			canary:       fleet.Partition{PauseAfter: hour},
+			canaryReady:  true,
+			previous:     &fleet.PartitionStatus{Name: "canary", ReadySince: twoHoursAgo},
+			wantAdvanced: true,
+		},
+		{
+			name:        "pending analysis is retried",
+			canary:      fleet.Partition{Analysis: analysis},
+			canaryReady: true,
+			analyzer:    &fakeAnalyzer{phase: AnalysisPending, msg: "connection refused"},
+			wantRequeue: true,
+			wantCalls:   1,
+			wantMessage: "waiting for analysis: connection refused",
+		},
+		{
+			name:         "passed analysis",
+			canary:       fleet.Partition{Analysis: analysis},
+			canaryReady:  true,
+			analyzer:     &fakeAnalyzer{phase: AnalysisPassed},
+			wantAdvanced: true,
+			wantPassed:   true,
+			wantCalls:    1,
+		},
+		{
+			name:         "analysis is not re-run after it passed",
+			canary:       fleet.Partition{Analysis: analysis},
+			canaryReady:  true,
+			previous:     &fleet.PartitionStatus{Name: "canary", AnalysisPassed: true},
+			analyzer:     &fakeAnalyzer{phase: AnalysisFailed},
+			wantAdvanced: true,
+			wantPassed:   true,
+		},
+		{
+			name:        "failed analysis halts the rollout",
+			canary:      fleet.Partition{Analysis: analysis},
+			canaryReady: true,
+			analyzer:    &fakeAnalyzer{phase: AnalysisFailed, msg: "error rate too high"},
+			wantHalted:  true,
+			wantCalls:   1,
+			wantMessage: "rollout halted, analysis failed: error rate too high",
+		},
+		{
+			name:        "halted rollout is not re-checked",
+			canary:      fleet.Partition{Analysis: analysis},
+			canaryReady: true,
+			previous:    &fleet.PartitionStatus{Name: "canary", Halted: true, Message: "rollout halted"},
+			analyzer:    &fakeAnalyzer{phase: AnalysisPassed},
+			wantHalted:  true,
+			wantMessage: "rollout halted",
+		},
+		{
+			name:         "previous status of a renamed partition is ignored",
+			canary:       fleet.Partition{Analysis: analysis},
+			canaryReady:  true,
+			previous:     &fleet.PartitionStatus{Name: "other", Halted: true},
+			analyzer:     &fakeAnalyzer{phase: AnalysisPassed},
+			wantAdvanced: true,
+			wantPassed:   true,
+			wantCalls:    1,
+		},
+		{
+			name:        "analysis without analyzer",
+			canary:      fleet.Partition{Analysis: analysis},
+			canaryReady: true,
+			wantRequeue: true,
+			wantMessage: "analysis is not supported",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			targets := canaryTargets(tt.canary, tt.canaryReady)
+			status := &fleet.BundleStatus{MaxUnavailable: 2}
+			var previous []fleet.PartitionStatus
+			if tt.previous != nil {
+				previous = []fleet.PartitionStatus{*tt.previous}
+			}
+
+			var analyzer Analyzer
+			if tt.analyzer != nil {
+				analyzer = tt.analyzer
+			}
+			requeueAfter, err := UpdatePartitions(context.TODO(), status, previous, targets, analyzer, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			prod := targets[1].Deployment.Spec
+			if advanced := prod.DeploymentID == "v2"; advanced != tt.wantAdvanced {
+				t.Errorf("expected prod deployment advanced to be %v, got deployment ID %q", tt.wantAdvanced, prod.DeploymentID)
+			}
+			if (requeueAfter > 0) != tt.wantRequeue {
+				t.Errorf("expected requeue to be %v, got %s", tt.wantRequeue, requeueAfter)
+			}
+
+			if len(status.PartitionStatus) != 2 {
+				t.Fatalf("expected 2 partitions, got %+v", status.PartitionStatus)
+			}
+			canary := status.PartitionStatus[0]
+			if canary.Halted != tt.wantHalted {
+				t.Errorf("expected halted to be %v, got %+v", tt.wantHalted, canary)
+			}
+			if canary.AnalysisPassed != tt.wantPassed {
+				t.Errorf("expected analysis passed to be %v, got %+v", tt.wantPassed, canary)
+			}
+			if !strings.HasPrefix(canary.Message, tt.wantMessage) || (tt.wantMessage == "" && canary.Message != "") {
+				t.Errorf("expected message to start with %q, got %q", tt.wantMessage, canary.Message)
+			}
+			if tt.analyzer != nil && tt.analyzer.calls != tt.wantCalls {
+				t.Errorf("expected %d analyzer calls, got %d", tt.wantCalls, tt.analyzer.calls)
+			}
+		})
+	}
+}
+
+func TestUpdatePartitionsPauseKeepsReadySince(t *testing.T) {
+	readySince := &metav1.Time{Time: time.Now().Add(-30 * time.Minute)}
+	targets := canaryTargets(fleet.Partition{PauseAfter: &metav1.Duration{Duration: time.Hour}}, true)
+	status := &fleet.BundleStatus{MaxUnavailable: 2}
+	previous := []fleet.PartitionStatus{{Name: "canary", ReadySince: readySince}}
+
+	requeueAfter, err := UpdatePartitions(context.TODO(), status, previous, targets, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !status.PartitionStatus[0].ReadySince.Equal(readySince) {
+		t.Errorf("expected ready since to be kept, got %v", status.PartitionStatus[0].ReadySince)
+	}
+	if requeueAfter <= 0 || requeueAfter > 30*time.Minute {
+		t.Errorf("expected requeue after the remaining 30 minutes, got %s", requeueAfter)
+	}
+}
+
+func TestUpdatePartitionsPublishesUnavailable(t *testing.T) {
+	targets := canaryTargets(fleet.Partition{}, true)
+	status := &fleet.BundleStatus{MaxUnavailable: 2}
+
+	if _, err := UpdatePartitions(context.TODO(), status, nil, targets, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// the prod target is updated and not yet applied
+	prod := status.PartitionStatus[1]
+	if id := targets[1].Deployment.Spec.DeploymentID; id != "v2" {
+		t.Fatalf("expected prod deployment to be advanced, got %q", id)
+	}
+	if prod.Unavailable != 1 {
+		t.Errorf("expected one unavailable target in the prod partition, got %+v", prod)
+	}
+}
+
+func TestUpdatePartitionsFailedJobHalts(t *testing.T) {
+	targets := canaryTargets(fleet.Partition{Analysis: &fleet.PartitionAnalysis{Job: &fleet.JobAnalysis{Name: "smoke-test"}}}, false)
+	targets[0].Deployment.Status.NonReadyStatus = []fleet.NonReadyStatus{{
+		Kind:      "Job",
+		Name:      "smoke-test",
+		Namespace: "default",
+		Summary:   summary.Summary{State: "failed", Error: true, Message: []string{"BackoffLimitExceeded"}},
+	}}
+	status := &fleet.BundleStatus{MaxUnavailable: 2}
+
+	if _, err := UpdatePartitions(context.TODO(), status, nil, targets, NewAnalyzer(nil), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	canary := status.PartitionStatus[0]
+	if !canary.Halted || !strings.Contains(canary.Message, "job default/smoke-test failed on cluster fleet-default/canary") {
+		t.Errorf("expected the rollout to be halted by the failed job, got %+v", canary)
+	}
+	if id := targets[1].Deployment.Spec.DeploymentID; id != "v1" {
+		t.Errorf("expected prod deployment not to be advanced, got %q", id)
+	}
+}
+
+func TestUpdatePartitionsApproval(t *testing.T) {
+	approval := func(gate string, generation int64) fleet.Approval {
+		return fleet.Approval{
+			ObjectMeta: metav1.ObjectMeta{Name: gate + "-approval", Namespace: "fleet-default"},
+			Spec:       fleet.ApprovalSpec{BundleName: "app", Gate: gate, BundleGeneration: generation, ClaimedApprover: "alice"},
+		}
+	}
+
+	tests := []struct {
+		name           string
+		canaryApproval bool
+		prodApproval   bool
+		targetGate     string
+		approvals      []fleet.Approval
+		wantAdvanced   bool
+		wantMessage    string
+		wantApprovedBy string
+	}{
+		{
+			name:         "without gates",
+			wantAdvanced: true,
+		},
+		{
+			name:         "waits for partition approval",
+			prodApproval: true,
+			wantMessage:  "waiting for approval of prod for bundle generation 3",
+		},
+		{
+			name:         "approval of a previous generation",
+			prodApproval: true,
+			approvals:    []fleet.Approval{approval("prod", 2)},
+			wantMessage:  "waiting for approval of prod for bundle generation 3",
+		},
+		{
+			name:           "approved partition",
+			prodApproval:   true,
+			approvals:      []fleet.Approval{approval("prod", 3)},
+			wantAdvanced:   true,
+			wantApprovedBy: "alice",
+		},
+		{
+			name:           "up-to-date partition needs no approval",
+			canaryApproval: true,
+			wantAdvanced:   true,
+		},
+		{
+			name:        "waits for target approval",
+			targetGate:  "production",
+			wantMessage: "waiting for approval of production for bundle generation 3",
+		},
+		{
+			name:           "approved target",
+			targetGate:     "production",
+			approvals:      []fleet.Approval{approval("production", 3)},
+			wantAdvanced:   true,
+			wantApprovedBy: "alice",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			targets := canaryTargets(fleet.Partition{RequireApproval: tt.canaryApproval}, true)
+			bundle := targets[1].Bundle
+			bundle.Namespace = "fleet-default"
+			bundle.Generation = 3
+			bundle.Spec.RolloutStrategy.Partitions[1].RequireApproval = tt.prodApproval
+			if tt.targetGate != "" {
+				targets[1].ApprovalGates = []string{tt.targetGate}
+			}
+			status := &fleet.BundleStatus{MaxUnavailable: 2}
+
+			if _, err := UpdatePartitions(context.TODO(), status, nil, targets, nil, tt.approvals); err != nil {
+				t.Fatal(err)
+			}
+
+			if advanced := targets[1].Deployment.Spec.DeploymentID == "v2"; advanced != tt.wantAdvanced {
+				t.Errorf("expected prod deployment advanced to be %v, got %q", tt.wantAdvanced, targets[1].Deployment.Spec.DeploymentID)
+			}
+			prod := status.PartitionStatus[1]
+			if prod.Message != tt.wantMessage {
+				t.Errorf("expected message %q, got %q", tt.wantMessage, prod.Message)
+			}
+			if tt.wantApprovedBy == "" {
+				if len(prod.Approvals) != 0 {
+					t.Errorf("expected no approvals, got %+v", prod.Approvals)
+				}
+			} else if len(prod.Approvals) != 1 || prod.Approvals[0].ClaimedApprover != tt.wantApprovedBy || prod.Approvals[0].BundleGeneration != 3 {
+				t.Errorf("expected approval by %s to be recorded, got %+v", tt.wantApprovedBy, prod.Approvals)
+			}
+		})
+	}
+}
@@ -32,7 +32,7 @@ func (m *Manager) BundlesForCluster(ctx context.Context, cluster *fleet.Cluster)
 			return nil, nil, err
 		}
 
-		match := bm.Match(cluster.Name, ClusterGroupsToLabelMap(cgs), cluster.Labels)
+		match := bm.Match(cluster.Name, ClusterGroupsToLabelMap(cgs), cluster.Labels, cluster.Status.Facts)
 		if match != nil {
 			bundlesToRefresh = append(bundlesToRefresh, bundle)
 		} else {
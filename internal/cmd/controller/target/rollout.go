@@ -42,7 +42,8 @@ func manualPartition(rollout *fleet.RolloutStrategy, targets []*Target) ([]parti
 		partitions []partition
 	)
 
-	for _, partitionDef := range rollout.Partitions {
+	for i := range rollout.Partitions {
+		partitionDef := &rollout.Partitions[i]
 		matcher, err := matcher.NewClusterMatcher(partitionDef.ClusterName, partitionDef.ClusterGroup, partitionDef.ClusterGroupSelector, partitionDef.ClusterSelector)
 		if err != nil {
 			return nil, err
@@ -67,6 +68,7 @@ func manualPartition(rollout *fleet.RolloutStrategy, targets []*Target) ([]parti
 		if err != nil {
 			return nil, err
 		}
+		partitions[len(partitions)-1].Definition = partitionDef
 	}
 
 	return partitions, nil
@@ -15,6 +15,7 @@ import (
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 var (
@@ -47,6 +48,17 @@ type Target struct {
 	Bundle        *fleet.Bundle
 	Options       fleet.BundleDeploymentOptions
 	DeploymentID  string
+	// TargetName is the name of the bundle target, whose options are used.
+	TargetName string
+	// ApprovalGates are the names of the bundle targets, which matched the
+	// cluster and require an approval.
+	ApprovalGates []string
+	// PendingDependencies are the dependencies of the bundle, which are
+	// not ready on the cluster yet.
+	PendingDependencies []string
+	// DenySchedule is the name of the Deny schedule, whose active deny
+	// window holds back the rollout to the cluster.
+	DenySchedule string
 }
 
 // BundleDeployment returns a new BundleDeployment, it discards annotations, status, etc.
@@ -112,6 +124,11 @@ func (t *Target) BundleDeploymentLabels(clusterNamespace string, clusterName str
 	labels[fleet.ClusterNamespaceLabel] = clusterNamespace
 	labels[fleet.ClusterLabel] = clusterName
 
+	// target names are free-form, only valid label values are recorded
+	if t.TargetName != "" && len(validation.IsValidLabelValue(t.TargetName)) == 0 {
+		labels[fleet.BundleTargetLabel] = t.TargetName
+	}
+
 	return labels
 }
 
@@ -6,6 +6,7 @@ import (
 
 	"github.com/pkg/errors"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/yaml"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
@@ -560,3 +561,57 @@ func TestDisablePreProcessFlagMissing(t *testing.T) {
 	}
 
 }
+
+const bundleYamlWithTopLevelArgs = `namespace: default
+jsonnet:
+  topLevelArgs:
+    cluster: "${ .ClusterName }"
+    label: '${ index .ClusterLabels "testLabel" }'
+    value: "${ .ClusterValues.someKey }"
+cue:
+  topLevelArgs:
+    cluster: "${ .ClusterName }"
+    version: "${ .ClusterFacts.kubernetesVersion }"
+`
+
+func TestPreprocessTopLevelArgs(t *testing.T) {
+	cluster, bundle, err := getClusterAndBundle(bundleYamlWithTopLevelArgs)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	jsonnetArgs := bundle.Jsonnet.TopLevelArgs
+	cluster.Status.Facts = &v1alpha1.ClusterFacts{KubernetesVersion: "v1.30.2"}
+
+	err = preprocessTopLevelArgs(bundle, cluster)
+	if err != nil {
+		t.Fatalf("error during cluster processing %v", err)
+	}
+
+	args := bundle.Jsonnet.TopLevelArgs.Data
+	if args["cluster"] != "test-cluster" || args["label"] != "test-label-value" || args["value"] != "someValue" {
+		t.Errorf("unexpected jsonnet top-level arguments %v", args)
+	}
+	if bundle.CUE.TopLevelArgs.Data["cluster"] != "test-cluster" || bundle.CUE.TopLevelArgs.Data["version"] != "v1.30.2" {
+		t.Errorf("unexpected cue top-level arguments %v", bundle.CUE.TopLevelArgs.Data)
+	}
+	if jsonnetArgs.Data["cluster"] != "${ .ClusterName }" {
+		t.Errorf("expected the bundle's options to be unchanged, got %v", jsonnetArgs.Data)
+	}
+}
+
+func TestBundleDeploymentLabelsTargetName(t *testing.T) {
+	target := &Target{
+		Bundle:     &v1alpha1.Bundle{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "fleet-default"}},
+		TargetName: "canary",
+	}
+	labels := target.BundleDeploymentLabels("cluster-ns", "cluster")
+	if labels[v1alpha1.BundleTargetLabel] != "canary" {
+		t.Errorf("expected target label, got %v", labels)
+	}
+
+	target.TargetName = "not a label value"
+	labels = target.BundleDeploymentLabels("cluster-ns", "cluster")
+	if _, ok := labels[v1alpha1.BundleTargetLabel]; ok {
+		t.Errorf("expected no target label for an invalid label value, got %v", labels)
+	}
+}
@@ -56,6 +56,10 @@ const (
 	// RepoNameIndex is the name of the index for the gitrepo name in bundles
 	RepoNameIndex = "metadata.labels." + fleet.RepoLabel
 
+	// BundleDependsOnIndex is the name of the index for bundles by the
+	// bundles they depend on
+	BundleDependsOnIndex = "spec.dependsOn"
+
 	// ImageScanGitRepoIndex is the name of the index for the gitrepo name in imagescans
 	ImageScanGitRepoIndex = "spec.gitrepoName"
 )
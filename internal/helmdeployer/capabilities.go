@@ -46,7 +46,7 @@ func getCapabilities(ctx context.Context, c *action.Configuration) (*common.Capa
 	// We trap that error here and print a warning. But since the discovery client continues
 	// building the API object, it is correctly populated with all valid APIs.
 	// See https://github.com/kubernetes/kubernetes/issues/72051#issuecomment-521157642
-	apiVersions, err := getVersionSet(dc)
+	apiVersions, err := GetVersionSet(dc)
 	if err != nil {
 		if discovery.IsGroupDiscoveryFailedError(err) {
 			logger := log.FromContext(ctx).WithName("helm-capabilities")
@@ -67,10 +67,10 @@ func getCapabilities(ctx context.Context, c *action.Configuration) (*common.Capa
 	return c.Capabilities, nil
 }
 
-// getVersionSet retrieves the set of available Kubernetes API versions and resources
+// GetVersionSet retrieves the set of available Kubernetes API versions and resources
 // from the discovery client. It tolerates GroupDiscoveryFailedErrors which occur when
 // some API groups are unavailable.
-func getVersionSet(client discovery.ServerResourcesInterface) (common.VersionSet, error) {
+func GetVersionSet(client discovery.ServerResourcesInterface) (common.VersionSet, error) {
 	groups, resources, err := client.ServerGroupsAndResources()
 	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
 		return common.DefaultVersionSet, errors.New("could not get apiVersions from Kubernetes")
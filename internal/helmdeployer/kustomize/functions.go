@@ -0,0 +1,132 @@
+package kustomize
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/rancher/fleet/internal/cmd/agent/deployer/data/convert"
+
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	kyaml "sigs.k8s.io/kustomize/kyaml/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+// Function is a KRM function, which is built into Fleet and runs in-process.
+// It returns the filter to apply to the resources, configured by the
+// transformer's config object.
+type Function func(config *kyaml.RNode) (kio.Filter, error)
+
+var (
+	functionsMu sync.RWMutex
+	functions   = map[string]Function{}
+)
+
+// RegisterFunction makes a KRM function available as a transformer, if
+// alpha plugins are enabled. Transformer configs with the given apiVersion
+// and kind are passed to the function.
+func RegisterFunction(apiVersion, kind string, fn Function) {
+	functionsMu.Lock()
+	defer functionsMu.Unlock()
+	functions[apiVersion+"/"+kind] = fn
+}
+
+func lookupFunction(apiVersion, kind string) (Function, bool) {
+	functionsMu.RLock()
+	defer functionsMu.RUnlock()
+	fn, ok := functions[apiVersion+"/"+kind]
+	return fn, ok
+}
+
+// extractFunctions removes the transformers, which are KRM functions, from the
+// kustomization file in dir and returns their filters. Kustomize can only
+// run KRM functions as containers or executables, so the filters are applied
+// to the result of the build instead, in the order of the transformers.
+// Builtin transformers are left to kustomize.
+func extractFunctions(f filesys.FileSystem, dir string) ([]kio.Filter, error) {
+	file := filepath.Join(dir, KustomizeYAML)
+	fileBytes, err := f.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	data := map[string]interface{}{}
+	if err := yaml.Unmarshal(fileBytes, &data); err != nil {
+		return nil, err
+	}
+
+	var (
+		filters      []kio.Filter
+		transformers []string
+	)
+	for _, t := range convert.ToStringSlice(data["transformers"]) {
+		configs, err := transformerConfigs(f, dir, t)
+		if err != nil {
+			return nil, err
+		}
+		var tFilters []kio.Filter
+		for _, config := range configs {
+			apiVersion, kind := config.GetApiVersion(), config.GetKind()
+			if apiVersion == konfig.BuiltinPluginApiVersion {
+				continue
+			}
+			fn, ok := lookupFunction(apiVersion, kind)
+			if !ok {
+				return nil, fmt.Errorf("transformer %s %s is not a KRM function built into fleet, functions cannot run as containers or executables", apiVersion, kind)
+			}
+			filter, err := fn(config)
+			if err != nil {
+				return nil, fmt.Errorf("failed to configure transformer %s %s: %w", apiVersion, kind, err)
+			}
+			tFilters = append(tFilters, filter)
+		}
+		if len(tFilters) == 0 {
+			transformers = append(transformers, t)
+			continue
+		}
+		if len(tFilters) != len(configs) {
+			return nil, fmt.Errorf("transformer %s mixes builtin transformers and KRM functions", t)
+		}
+		filters = append(filters, tFilters...)
+	}
+
+	if len(filters) == 0 {
+		return nil, nil
+	}
+	if len(transformers) == 0 {
+		delete(data, "transformers")
+	} else {
+		data["transformers"] = transformers
+	}
+	fileBytes, err = yaml.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return filters, f.WriteFile(file, fileBytes)
+}
+
+// transformerConfigs returns the config objects of a transformer entry,
+// which is either a file name or an inline object.
+func transformerConfigs(f filesys.FileSystem, dir, transformer string) ([]*kyaml.RNode, error) {
+	data := transformer
+	if !strings.Contains(transformer, "\n") {
+		path := filepath.Join(dir, transformer)
+		if !f.Exists(path) || f.IsDir(path) {
+			// a kustomization directory or a remote target, which
+			// kustomize handles
+			return nil, nil
+		}
+		b, err := f.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		data = string(b)
+	}
+	nodes, err := kio.FromBytes([]byte(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse transformer %s: %w", transformer, err)
+	}
+	return nodes, nil
+}
@@ -0,0 +1,284 @@
+package kustomize
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/chart/common"
+	"helm.sh/helm/v4/pkg/chart/loader/archive"
+	chartv2 "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/chart/v2/loader"
+	kubefake "helm.sh/helm/v4/pkg/kube/fake"
+	releasev1 "helm.sh/helm/v4/pkg/release/v1"
+	"helm.sh/helm/v4/pkg/storage"
+	"helm.sh/helm/v4/pkg/storage/driver"
+
+	"github.com/rancher/fleet/internal/cmd/agent/deployer/data/convert"
+
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/yaml"
+)
+
+// defaultReleaseName is the release name used by "helm template".
+const defaultReleaseName = "release-name"
+
+// inflateHelmCharts renders the helmCharts of all kustomization files into
+// resource files and removes helmCharts from the kustomization files.
+// Kustomize would run the helm binary instead, which needs the charts on disk
+// and downloads missing charts. Here, the charts are rendered in-process and
+// have to be part of the bundle.
+func inflateHelmCharts(f filesys.FileSystem, restrictions types.LoadRestrictions) error {
+	var files []string
+	err := f.Walk(".", func(path string, info fs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && isKustomization(path) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if err := inflateKustomizationCharts(f, file, restrictions); err != nil {
+			return fmt.Errorf("failed to inflate helm charts of %s: %w", file, err)
+		}
+	}
+	return nil
+}
+
+func isKustomization(path string) bool {
+	switch filepath.Base(path) {
+	case "kustomization.yaml", "kustomization.yml", "Kustomization":
+		return true
+	}
+	return false
+}
+
+func inflateKustomizationCharts(f filesys.FileSystem, file string, restrictions types.LoadRestrictions) error {
+	fileBytes, err := f.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	data := map[string]interface{}{}
+	if err := yaml.Unmarshal(fileBytes, &data); err != nil {
+		return err
+	}
+	if _, ok := data["helmCharts"]; !ok {
+		return nil
+	}
+
+	kust := types.Kustomization{}
+	if err := yaml.Unmarshal(fileBytes, &kust); err != nil {
+		return err
+	}
+	chartHome := types.HelmDefaultHome
+	if kust.HelmGlobals != nil && kust.HelmGlobals.ChartHome != "" {
+		chartHome = kust.HelmGlobals.ChartHome
+	}
+
+	dir := filepath.Dir(file)
+	var resources []string
+	for i, chart := range kust.HelmCharts {
+		manifests, err := renderChart(f, dir, chartHome, chart, restrictions)
+		if err != nil {
+			return fmt.Errorf("chart %s: %w", chart.Name, err)
+		}
+		name := fmt.Sprintf("fleet-helmchart-%d-%s.yaml", i, chart.Name)
+		if err := f.WriteFile(filepath.Join(dir, name), manifests); err != nil {
+			return err
+		}
+		resources = append(resources, name)
+	}
+
+	delete(data, "helmCharts")
+	delete(data, "helmGlobals")
+	data["resources"] = append(resources, convert.ToStringSlice(data["resources"])...)
+	fileBytes, err = yaml.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return f.WriteFile(file, fileBytes)
+}
+
+// renderChart renders a chart like "helm template" and returns the manifests.
+func renderChart(f filesys.FileSystem, dir, chartHome string, chart types.HelmChart, restrictions types.LoadRestrictions) ([]byte, error) {
+	if chart.Name == "" {
+		return nil, fmt.Errorf("chart name cannot be empty")
+	}
+
+	home := chartHome
+	if !filepath.IsAbs(home) {
+		home = filepath.Join(dir, home)
+	}
+	if chart.Version != "" && chart.Repo != "" {
+		home = filepath.Join(home, fmt.Sprintf("%s-%s", chart.Name, chart.Version))
+	}
+	chartDir := filepath.Join(home, chart.Name)
+	if err := checkRestrictions(dir, chartDir, restrictions); err != nil {
+		return nil, err
+	}
+	if !f.IsDir(chartDir) {
+		return nil, fmt.Errorf("chart not found at %s, charts are not downloaded and must be part of the bundle", chartDir)
+	}
+
+	ch, err := loadChart(f, chartDir)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := chartValues(f, dir, chart, restrictions)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &action.Configuration{
+		KubeClient: &kubefake.PrintingKubeClient{Out: io.Discard},
+		Releases:   storage.Init(driver.NewMemory()),
+	}
+	cfg.SetLogger(nil)
+	cfg.Capabilities = common.DefaultCapabilities.Copy()
+	if chart.KubeVersion != "" {
+		kubeVersion, err := common.ParseKubeVersion(chart.KubeVersion)
+		if err != nil {
+			return nil, fmt.Errorf("invalid kubeVersion: %w", err)
+		}
+		cfg.Capabilities.KubeVersion = *kubeVersion
+	}
+
+	install := action.NewInstall(cfg)
+	install.DryRunStrategy = action.DryRunClient
+	install.Replace = true
+	install.ReleaseName = chart.ReleaseName
+	if install.ReleaseName == "" {
+		install.ReleaseName = defaultReleaseName
+	}
+	install.Namespace = chart.Namespace
+	install.IncludeCRDs = chart.IncludeCRDs
+	install.APIVersions = chart.ApiVersions
+	if chart.KubeVersion != "" {
+		install.KubeVersion = &cfg.Capabilities.KubeVersion
+	}
+
+	rel, err := install.Run(ch, values)
+	if err != nil {
+		return nil, err
+	}
+	release, ok := rel.(*releasev1.Release)
+	if !ok {
+		return nil, fmt.Errorf("unexpected release type: %T", rel)
+	}
+
+	var out bytes.Buffer
+	out.WriteString(release.Manifest)
+	if !chart.SkipHooks {
+		for _, hook := range release.Hooks {
+			if chart.SkipTests && isTestHook(hook) {
+				continue
+			}
+			fmt.Fprintf(&out, "\n---\n# Source: %s\n%s\n", hook.Path, hook.Manifest)
+		}
+	}
+	return out.Bytes(), nil
+}
+
+func isTestHook(hook *releasev1.Hook) bool {
+	for _, event := range hook.Events {
+		if event == releasev1.HookTest {
+			return true
+		}
+	}
+	return false
+}
+
+// loadChart loads the chart's files from the filesystem.
+func loadChart(f filesys.FileSystem, chartDir string) (*chartv2.Chart, error) {
+	var files []*archive.BufferedFile
+	err := f.Walk(chartDir, func(path string, info fs.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		data, err := f.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		name, err := filepath.Rel(chartDir, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, &archive.BufferedFile{Name: filepath.ToSlash(name), Data: data})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return loader.LoadFiles(files)
+}
+
+// chartValues returns the values of the chart, following kustomize's
+// valuesMerge semantics for valuesInline.
+func chartValues(f filesys.FileSystem, dir string, chart types.HelmChart, restrictions types.LoadRestrictions) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	if chart.ValuesFile != "" && chart.ValuesMerge != "replace" {
+		v, err := readValues(f, dir, chart.ValuesFile, restrictions)
+		if err != nil {
+			return nil, err
+		}
+		values = v
+	}
+
+	switch chart.ValuesMerge {
+	case "", "override", "replace":
+		values = loader.MergeMaps(values, chart.ValuesInline)
+	case "merge":
+		values = loader.MergeMaps(chart.ValuesInline, values)
+	default:
+		return nil, fmt.Errorf("valuesMerge must be one of override, merge or replace")
+	}
+
+	for _, file := range chart.AdditionalValuesFiles {
+		v, err := readValues(f, dir, file, restrictions)
+		if err != nil {
+			return nil, err
+		}
+		values = loader.MergeMaps(values, v)
+	}
+	return values, nil
+}
+
+func readValues(f filesys.FileSystem, dir, file string, restrictions types.LoadRestrictions) (map[string]interface{}, error) {
+	path := file
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	if err := checkRestrictions(dir, path, restrictions); err != nil {
+		return nil, err
+	}
+	data, err := f.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read values file: %w", err)
+	}
+	return loader.LoadValues(bytes.NewReader(data))
+}
+
+// checkRestrictions returns an error if the load restrictions forbid
+// kustomization files in dir to load path.
+func checkRestrictions(dir, path string, restrictions types.LoadRestrictions) error {
+	if restrictions != types.LoadRestrictionsRootOnly {
+		return nil
+	}
+	rel, err := filepath.Rel(dir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, "../") {
+		return fmt.Errorf("security; file '%s' is not in or below '%s'", path, dir)
+	}
+	return nil
+}
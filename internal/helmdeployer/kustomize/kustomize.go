@@ -6,12 +6,14 @@ import (
 	"github.com/rancher/fleet/internal/cmd/agent/deployer/data/convert"
 	"github.com/rancher/fleet/internal/content"
 	"github.com/rancher/fleet/internal/manifest"
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/kustomize/api/krusty"
 	"sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/kio"
 	"sigs.k8s.io/yaml"
 )
 
@@ -20,7 +22,11 @@ const (
 	ManifestsYAML = "fleet-manifests.yaml"
 )
 
-func Process(m *manifest.Manifest, content []byte, dir string) ([]runtime.Object, bool, error) {
+// Process builds the kustomization in the options' dir, with the rendered
+// manifests as an additional resource. It returns false, if there is no
+// kustomization file.
+func Process(m *manifest.Manifest, content []byte, opts fleet.KustomizeOptions) ([]runtime.Object, bool, error) {
+	dir := opts.Dir
 	if dir == "" {
 		dir = "."
 	}
@@ -41,7 +47,26 @@ func Process(m *manifest.Manifest, content []byte, dir string) ([]runtime.Object
 		}
 	}
 
-	objs, err := kustomize(fs, dir)
+	restrictions := types.LoadRestrictionsRootOnly
+	if opts.LoadRestrictions == fleet.KustomizeLoadRestrictionsNone {
+		restrictions = types.LoadRestrictionsNone
+	}
+
+	if opts.EnableHelm {
+		if err := inflateHelmCharts(fs, restrictions); err != nil {
+			return nil, false, err
+		}
+	}
+
+	var functions []kio.Filter
+	if opts.EnableAlphaPlugins {
+		functions, err = extractFunctions(fs, dir)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	objs, err := kustomize(fs, dir, restrictions, functions)
 	return objs, true, err
 }
 
@@ -99,16 +124,21 @@ func toFilesystem(m *manifest.Manifest, dir string, manifestsContent []byte) (fi
 	return f, err
 }
 
-func kustomize(fs filesys.FileSystem, dir string) (result []runtime.Object, err error) {
+func kustomize(fs filesys.FileSystem, dir string, restrictions types.LoadRestrictions, functions []kio.Filter) (result []runtime.Object, err error) {
 	pcfg := types.DisabledPluginConfig()
 	kust := krusty.MakeKustomizer(&krusty.Options{
-		LoadRestrictions: types.LoadRestrictionsRootOnly,
+		LoadRestrictions: restrictions,
 		PluginConfig:     pcfg,
 	})
 	resMap, err := kust.Run(fs, dir)
 	if err != nil {
 		return nil, err
 	}
+	for _, fn := range functions {
+		if err := resMap.ApplyFilter(fn); err != nil {
+			return nil, err
+		}
+	}
 	for _, m := range resMap.Resources() {
 		mm, err := m.Map()
 		if err != nil {
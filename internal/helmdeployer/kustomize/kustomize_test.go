@@ -0,0 +1,234 @@
+package kustomize
+
+import (
+	"maps"
+	"strings"
+	"testing"
+
+	"github.com/rancher/fleet/internal/manifest"
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	kyaml "sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func newManifest(files map[string]string) *manifest.Manifest {
+	m := &manifest.Manifest{}
+	for name, content := range files {
+		m.Resources = append(m.Resources, fleet.BundleResource{Name: name, Content: content})
+	}
+	return m
+}
+
+func names(objs []runtime.Object) []string {
+	var result []string
+	for _, obj := range objs {
+		u := obj.(*unstructured.Unstructured)
+		result = append(result, u.GetKind()+"/"+u.GetName())
+	}
+	return result
+}
+
+var chart = map[string]string{
+	"app/charts/web/Chart.yaml":  "apiVersion: v2\nname: web\nversion: 0.1.0\n",
+	"app/charts/web/values.yaml": "replicas: 1\ncolor: blue\n",
+	"app/charts/web/templates/cm.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ .Release.Name }}-config
+data:
+  replicas: "{{ .Values.replicas }}"
+  color: {{ .Values.color }}
+`,
+	"app/charts/web/templates/test.yaml": `apiVersion: v1
+kind: Pod
+metadata:
+  name: {{ .Release.Name }}-test
+  annotations:
+    helm.sh/hook: test
+spec:
+  containers: []
+`,
+	"app/kustomization.yaml": `helmCharts:
+- name: web
+  releaseName: web
+  skipTests: true
+  valuesInline:
+    replicas: 3
+resources:
+- secret.yaml
+`,
+	"app/secret.yaml": "apiVersion: v1\nkind: Secret\nmetadata:\n  name: web\n",
+}
+
+func TestProcessHelmCharts(t *testing.T) {
+	objs, processed, err := Process(newManifest(chart), nil, fleet.KustomizeOptions{Dir: "app", EnableHelm: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !processed {
+		t.Fatal("expected the kustomization to be processed")
+	}
+	if got := strings.Join(names(objs), ","); got != "ConfigMap/web-config,Secret/web" {
+		t.Fatalf("unexpected objects %s", got)
+	}
+	data := objs[0].(*unstructured.Unstructured).Object["data"].(map[string]interface{})
+	if data["replicas"] != "3" || data["color"] != "blue" {
+		t.Errorf("expected inline values to override the chart's values, got %v", data)
+	}
+
+	if _, _, err := Process(newManifest(chart), nil, fleet.KustomizeOptions{Dir: "app"}); err == nil {
+		t.Error("expected error for helmCharts, if helm is not enabled")
+	}
+
+	files := map[string]string{
+		"app/kustomization.yaml": "helmCharts:\n- name: missing\n  repo: https://charts.example.com\n  version: 1.0.0\n",
+	}
+	_, _, err = Process(newManifest(files), nil, fleet.KustomizeOptions{Dir: "app", EnableHelm: true})
+	if err == nil || !strings.Contains(err.Error(), "must be part of the bundle") {
+		t.Errorf("expected error for missing chart, got %v", err)
+	}
+}
+
+func TestProcessLoadRestrictions(t *testing.T) {
+	files := map[string]string{
+		"base/cm.yaml":                     "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: base\n",
+		"overlays/prod/kustomization.yaml": "resources:\n- ../../base/cm.yaml\n",
+	}
+
+	_, _, err := Process(newManifest(files), nil, fleet.KustomizeOptions{Dir: "overlays/prod"})
+	if err == nil {
+		t.Fatal("expected error when loading a file outside of the kustomization's directory")
+	}
+
+	objs, _, err := Process(newManifest(files), nil, fleet.KustomizeOptions{
+		Dir:              "overlays/prod",
+		LoadRestrictions: fleet.KustomizeLoadRestrictionsNone,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := strings.Join(names(objs), ","); got != "ConfigMap/base" {
+		t.Errorf("unexpected objects %s", got)
+	}
+}
+
+// registerFunction registers a KRM function for the test and restores the
+// registered functions afterwards.
+func registerFunction(t *testing.T, apiVersion, kind string, fn Function) {
+	t.Helper()
+	functionsMu.Lock()
+	registered := maps.Clone(functions)
+	functionsMu.Unlock()
+	t.Cleanup(func() {
+		functionsMu.Lock()
+		defer functionsMu.Unlock()
+		functions = registered
+	})
+	RegisterFunction(apiVersion, kind, fn)
+}
+
+func TestProcessFunctions(t *testing.T) {
+	registerFunction(t, "fn.example.com/v1", "SetColor", func(config *kyaml.RNode) (kio.Filter, error) {
+		color, err := config.GetString("color")
+		if err != nil {
+			return nil, err
+		}
+		return kio.FilterFunc(func(nodes []*kyaml.RNode) ([]*kyaml.RNode, error) {
+			for _, n := range nodes {
+				if err := n.PipeE(kyaml.SetLabel("color", color)); err != nil {
+					return nil, err
+				}
+			}
+			return nodes, nil
+		}), nil
+	})
+
+	files := map[string]string{
+		"cm.yaml":    "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm\n",
+		"color.yaml": "apiVersion: fn.example.com/v1\nkind: SetColor\nmetadata:\n  name: color\ncolor: green\n",
+		"kustomization.yaml": `resources:
+- cm.yaml
+transformers:
+- color.yaml
+- |
+  apiVersion: builtin
+  kind: NamespaceTransformer
+  metadata:
+    name: ns
+    namespace: apps
+`,
+	}
+
+	objs, _, err := Process(newManifest(files), nil, fleet.KustomizeOptions{EnableAlphaPlugins: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	u := objs[0].(*unstructured.Unstructured)
+	if u.GetLabels()["color"] != "green" || u.GetNamespace() != "apps" {
+		t.Errorf("expected function and builtin transformer to be applied, got %v", u.Object)
+	}
+
+	if _, _, err := Process(newManifest(files), nil, fleet.KustomizeOptions{}); err == nil {
+		t.Error("expected error for KRM function, if alpha plugins are not enabled")
+	}
+
+	files["color.yaml"] = "apiVersion: fn.example.com/v1\nkind: Unknown\nmetadata:\n  name: color\n"
+	_, _, err = Process(newManifest(files), nil, fleet.KustomizeOptions{EnableAlphaPlugins: true})
+	if err == nil || !strings.Contains(err.Error(), "not a KRM function built into fleet") {
+		t.Errorf("expected error for unknown function, got %v", err)
+	}
+}
+
+func TestProcessApplySetters(t *testing.T) {
+	files := map[string]string{
+		"deployment.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  replicas: 1 # kpt-set: ${replicas}
+  template:
+    spec:
+      containers:
+      - name: app
+        image: nginx:1.0 # kpt-set: ${image}:${tag}
+        env:
+        - name: LEVEL
+          value: info # kpt-set: ${level}
+`,
+		"kustomization.yaml": `resources:
+- deployment.yaml
+transformers:
+- |
+  apiVersion: fn.kpt.dev/v1alpha1
+  kind: ApplySetters
+  metadata:
+    name: setters
+  setters:
+    replicas: "3"
+    image: registry.example.com/nginx
+    tag: "1.1"
+`,
+	}
+
+	objs, _, err := Process(newManifest(files), nil, fleet.KustomizeOptions{EnableAlphaPlugins: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	u := objs[0].(*unstructured.Unstructured)
+	if replicas, _, _ := unstructured.NestedFieldNoCopy(u.Object, "spec", "replicas"); replicas != 3 {
+		t.Errorf("expected 3 replicas, got %v", u.Object["spec"])
+	}
+	containers, _, _ := unstructured.NestedSlice(u.Object, "spec", "template", "spec", "containers")
+	container := containers[0].(map[string]interface{})
+	if container["image"] != "registry.example.com/nginx:1.1" {
+		t.Errorf("expected image to be set, got %v", container["image"])
+	}
+	// the level setter is missing
+	if env := container["env"].([]interface{})[0].(map[string]interface{}); env["value"] != "info" {
+		t.Errorf("expected env value to be unchanged, got %v", env["value"])
+	}
+}
@@ -0,0 +1,84 @@
+package kustomize
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	kyaml "sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const (
+	// applySettersAPIVersion and applySettersKind identify the config of
+	// kpt's apply-setters function.
+	applySettersAPIVersion = "fn.kpt.dev/v1alpha1"
+	applySettersKind       = "ApplySetters"
+
+	// setterCommentPrefix starts the line comments of fields, whose values
+	// are set by apply-setters, e.g. "# kpt-set: ${image}:${tag}".
+	setterCommentPrefix = "kpt-set:"
+)
+
+// setterRef matches the references to setters in a setter comment.
+var setterRef = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+func init() {
+	RegisterFunction(applySettersAPIVersion, applySettersKind, applySetters)
+}
+
+// applySetters returns the filter of kpt's apply-setters function. It sets
+// the scalar fields with a setter comment to the comment's pattern, with the
+// references replaced by the values of the setters in the config. Fields
+// referencing setters missing from the config are left unchanged.
+func applySetters(config *kyaml.RNode) (kio.Filter, error) {
+	setters := map[string]string{}
+	if field := config.Field("setters"); field != nil {
+		err := field.Value.VisitFields(func(n *kyaml.MapNode) error {
+			if n.Value.YNode().Kind != kyaml.ScalarNode {
+				return fmt.Errorf("value of setter %s is not a scalar", n.Key.YNode().Value)
+			}
+			setters[n.Key.YNode().Value] = n.Value.YNode().Value
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return kio.FilterFunc(func(nodes []*kyaml.RNode) ([]*kyaml.RNode, error) {
+		for _, n := range nodes {
+			setFields(n.YNode(), setters)
+		}
+		return nodes, nil
+	}), nil
+}
+
+func setFields(node *kyaml.Node, setters map[string]string) {
+	if node.Kind == kyaml.ScalarNode {
+		comment := strings.TrimSpace(strings.TrimPrefix(node.LineComment, "#"))
+		if pattern, ok := strings.CutPrefix(comment, setterCommentPrefix); ok {
+			if value, ok := substituteSetters(strings.TrimSpace(pattern), setters); ok && value != node.Value {
+				// the tag is resolved from the new value
+				node.Value, node.Tag = value, ""
+			}
+		}
+	}
+	for _, c := range node.Content {
+		setFields(c, setters)
+	}
+}
+
+// substituteSetters replaces the setter references in the pattern. It
+// returns false, if a setter is missing.
+func substituteSetters(pattern string, setters map[string]string) (string, bool) {
+	missing := false
+	value := setterRef.ReplaceAllStringFunc(pattern, func(ref string) string {
+		v, ok := setters[setterRef.FindStringSubmatch(ref)[1]]
+		if !ok {
+			missing = true
+		}
+		return v
+	})
+	return value, !missing
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/rancher/fleet/internal/cmd/agent/deployer/desiredset"
 	"github.com/rancher/fleet/internal/helmdeployer/kustomize"
+	"github.com/rancher/fleet/internal/helmdeployer/postrender"
 	"github.com/rancher/fleet/internal/helmdeployer/rawyaml"
 	"github.com/rancher/fleet/internal/manifest"
 	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
@@ -57,7 +58,7 @@ func (p *postRender) Run(renderedManifests *bytes.Buffer) (modifiedManifests *by
 		}
 	}
 	if kustomizable {
-		newObjs, processed, err := kustomize.Process(p.manifest, data, p.opts.Kustomize.Dir)
+		newObjs, processed, err := kustomize.Process(p.manifest, data, *p.opts.Kustomize)
 		if err != nil {
 			return nil, err
 		}
@@ -72,6 +73,13 @@ func (p *postRender) Run(renderedManifests *bytes.Buffer) (modifiedManifests *by
 	}
 	objs = append(objs, yamlObjs...)
 
+	if len(p.opts.PostRender) > 0 {
+		objs, err = postrender.Apply(objs, p.opts.PostRender, p.mapper)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	setID := desiredset.GetSetID(p.bundleID, p.labelPrefix, p.labelSuffix)
 	labels, annotations, err := desiredset.GetLabelsAndAnnotations(setID)
 	if err != nil {
@@ -0,0 +1,207 @@
+// Package postrender applies the post-render pipeline of a bundle to the
+// rendered resources.
+package postrender
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	"github.com/rancher/wrangler/v3/pkg/patch"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+)
+
+// Apply runs the steps in order on the objects and returns the transformed
+// objects. The mapper is used to skip cluster scoped resources when
+// rewriting namespaces, if it is nil only resources with a namespace are
+// rewritten.
+func Apply(objs []runtime.Object, steps []fleet.PostRenderStep, mapper meta.RESTMapper) ([]runtime.Object, error) {
+	for i, step := range steps {
+		s, err := newStep(step)
+		if err != nil {
+			return nil, fmt.Errorf("post-render step %d: %w", i, err)
+		}
+		for j, obj := range objs {
+			u, ok := obj.(*unstructured.Unstructured)
+			if !ok {
+				return nil, fmt.Errorf("post-render step %d: unexpected object type %T", i, obj)
+			}
+			if !s.matches(u) {
+				continue
+			}
+			u, err = s.apply(u, mapper)
+			if err != nil {
+				return nil, fmt.Errorf("post-render step %d: %s %s: %w", i, u.GetKind(), u.GetName(), err)
+			}
+			objs[j] = u
+		}
+	}
+	return objs, nil
+}
+
+type step struct {
+	fleet.PostRenderStep
+	selector labels.Selector
+	patch    []byte
+}
+
+func newStep(s fleet.PostRenderStep) (*step, error) {
+	result := &step{PostRenderStep: s, selector: labels.Everything()}
+	if s.Selector != nil && s.Selector.LabelSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(s.Selector.LabelSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid label selector: %w", err)
+		}
+		result.selector = selector
+	}
+	if s.Patch != "" {
+		data, err := yaml.YAMLToJSON([]byte(s.Patch))
+		if err != nil {
+			return nil, fmt.Errorf("invalid patch: %w", err)
+		}
+		result.patch = data
+	}
+	return result, nil
+}
+
+func (s *step) matches(u *unstructured.Unstructured) bool {
+	if s.Selector == nil {
+		return true
+	}
+	sel := s.Selector
+	return (sel.Kind == "" || sel.Kind == u.GetKind()) &&
+		(sel.APIVersion == "" || sel.APIVersion == u.GetAPIVersion()) &&
+		(sel.Namespace == "" || sel.Namespace == u.GetNamespace()) &&
+		(sel.Name == "" || sel.Name == u.GetName()) &&
+		s.selector.Matches(labels.Set(u.GetLabels()))
+}
+
+func (s *step) apply(u *unstructured.Unstructured, mapper meta.RESTMapper) (*unstructured.Unstructured, error) {
+	if s.patch != nil {
+		original, err := json.Marshal(u.Object)
+		if err != nil {
+			return u, err
+		}
+		patched, err := patch.Apply(original, s.patch)
+		if err != nil {
+			return u, fmt.Errorf("failed to apply patch: %w", err)
+		}
+		result := &unstructured.Unstructured{}
+		if err := result.UnmarshalJSON(patched); err != nil {
+			return u, err
+		}
+		u = result
+	}
+
+	if len(s.Images) > 0 {
+		rewriteContainers(u.Object, s.Images)
+	}
+
+	if len(s.Labels) > 0 {
+		l := u.GetLabels()
+		if l == nil {
+			l = map[string]string{}
+		}
+		maps.Copy(l, s.Labels)
+		u.SetLabels(l)
+	}
+
+	if len(s.Annotations) > 0 {
+		a := u.GetAnnotations()
+		if a == nil {
+			a = map[string]string{}
+		}
+		maps.Copy(a, s.Annotations)
+		u.SetAnnotations(a)
+	}
+
+	if s.Namespace != "" {
+		namespaced, err := isNamespaced(u, mapper)
+		if err != nil {
+			return u, err
+		}
+		if namespaced {
+			u.SetNamespace(s.Namespace)
+		}
+	}
+
+	return u, nil
+}
+
+func isNamespaced(u *unstructured.Unstructured, mapper meta.RESTMapper) (bool, error) {
+	if mapper == nil {
+		return u.GetNamespace() != "", nil
+	}
+	gvk := u.GroupVersionKind()
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return false, err
+	}
+	return mapping.Scope.Name() != meta.RESTScopeNameRoot, nil
+}
+
+// containerFields are the fields of pod specs, which contain containers.
+var containerFields = []string{"containers", "initContainers", "ephemeralContainers"}
+
+// rewriteContainers rewrites the images of all containers in the object. Pod
+// specs are found at any depth, so this works for pods, workloads and
+// custom resources with pod templates.
+func rewriteContainers(value interface{}, rewrites []fleet.ImageRegistryRewrite) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, field := range v {
+			if slices.Contains(containerFields, k) {
+				if containers, ok := field.([]interface{}); ok {
+					for _, c := range containers {
+						if container, ok := c.(map[string]interface{}); ok {
+							if image, ok := container["image"].(string); ok {
+								container["image"] = rewriteImage(image, rewrites)
+							}
+						}
+					}
+				}
+			}
+			rewriteContainers(field, rewrites)
+		}
+	case []interface{}:
+		for _, item := range v {
+			rewriteContainers(item, rewrites)
+		}
+	}
+}
+
+// rewriteImage replaces the registry of the image with the first matching
+// rewrite.
+func rewriteImage(image string, rewrites []fleet.ImageRegistryRewrite) string {
+	normalized := normalizeImage(image)
+	for _, r := range rewrites {
+		from := strings.TrimSuffix(r.From, "/")
+		if rest, ok := strings.CutPrefix(normalized, from+"/"); ok {
+			return strings.TrimSuffix(r.To, "/") + "/" + rest
+		}
+	}
+	return image
+}
+
+// normalizeImage adds the implicit docker.io registry and library
+// repository to the image.
+func normalizeImage(image string) string {
+	domain, _, found := strings.Cut(image, "/")
+	if found && (strings.ContainsAny(domain, ".:") || domain == "localhost") {
+		return image
+	}
+	if !found {
+		image = "library/" + image
+	}
+	return "docker.io/" + image
+}
@@ -0,0 +1,209 @@
+package postrender
+
+import (
+	"bytes"
+	"testing"
+
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	"github.com/rancher/wrangler/v3/pkg/yaml"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const manifests = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: apps
+  labels:
+    app: web
+spec:
+  template:
+    spec:
+      initContainers:
+      - name: init
+        image: busybox
+      containers:
+      - name: web
+        image: ghcr.io/org/web:1.0
+      - name: sidecar
+        image: docker.io/envoyproxy/envoy:v1
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+  namespace: apps
+data:
+  color: blue
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: reader
+`
+
+func objects(t *testing.T) []runtime.Object {
+	t.Helper()
+	objs, err := yaml.ToObjects(bytes.NewBufferString(manifests))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return objs
+}
+
+func get(t *testing.T, objs []runtime.Object, kind string) *unstructured.Unstructured {
+	t.Helper()
+	for _, obj := range objs {
+		if u := obj.(*unstructured.Unstructured); u.GetKind() == kind {
+			return u
+		}
+	}
+	t.Fatalf("%s not found", kind)
+	return nil
+}
+
+func containers(t *testing.T, u *unstructured.Unstructured, field string) []interface{} {
+	t.Helper()
+	c, _, err := unstructured.NestedSlice(u.Object, "spec", "template", "spec", field)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func image(c interface{}) string {
+	return c.(map[string]interface{})["image"].(string)
+}
+
+func TestApplyPatches(t *testing.T) {
+	objs, err := Apply(objects(t), []fleet.PostRenderStep{
+		{
+			Selector: &fleet.PostRenderSelector{Kind: "Deployment"},
+			Patch: `spec:
+  template:
+    spec:
+      containers:
+      - name: web
+        resources:
+          limits:
+            memory: 128Mi
+`,
+		},
+		{
+			Selector: &fleet.PostRenderSelector{Kind: "ConfigMap", Name: "config"},
+			Patch:    `[{"op": "replace", "path": "/data/color", "value": "green"}]`,
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	c := containers(t, get(t, objs, "Deployment"), "containers")
+	if len(c) != 2 || image(c[0]) != "ghcr.io/org/web:1.0" {
+		t.Fatalf("expected the strategic merge patch to merge containers by name, got %v", c)
+	}
+	if _, ok := c[0].(map[string]interface{})["resources"]; !ok {
+		t.Errorf("expected resources to be added to the container, got %v", c[0])
+	}
+
+	if color, _, _ := unstructured.NestedString(get(t, objs, "ConfigMap").Object, "data", "color"); color != "green" {
+		t.Errorf("expected the JSON patch to replace the color, got %s", color)
+	}
+
+	_, err = Apply(objects(t), []fleet.PostRenderStep{
+		{Patch: `[{"op": "test", "path": "/kind", "value": "Secret"}]`},
+	}, nil)
+	if err == nil {
+		t.Error("expected error for a patch, which does not apply")
+	}
+}
+
+func TestApplyImages(t *testing.T) {
+	objs, err := Apply(objects(t), []fleet.PostRenderStep{{
+		Images: []fleet.ImageRegistryRewrite{
+			{From: "ghcr.io/org", To: "registry.example.com/org-mirror"},
+			{From: "docker.io", To: "registry.example.com/hub/"},
+		},
+	}}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	d := get(t, objs, "Deployment")
+	c := containers(t, d, "containers")
+	if image(c[0]) != "registry.example.com/org-mirror/web:1.0" {
+		t.Errorf("unexpected image %s", image(c[0]))
+	}
+	if image(c[1]) != "registry.example.com/hub/envoyproxy/envoy:v1" {
+		t.Errorf("unexpected image %s", image(c[1]))
+	}
+	if init := containers(t, d, "initContainers"); image(init[0]) != "registry.example.com/hub/library/busybox" {
+		t.Errorf("unexpected init container image %s", image(init[0]))
+	}
+}
+
+func TestApplyMetadata(t *testing.T) {
+	objs, err := Apply(objects(t), []fleet.PostRenderStep{
+		{
+			Selector: &fleet.PostRenderSelector{
+				LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			},
+			Labels:      map[string]string{"team": "web"},
+			Annotations: map[string]string{"owner": "web-team"},
+		},
+		{
+			Selector: &fleet.PostRenderSelector{Kind: "ClusterRole"},
+			Labels:   map[string]string{"scope": "cluster"},
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	d := get(t, objs, "Deployment")
+	if d.GetLabels()["team"] != "web" || d.GetLabels()["app"] != "web" || d.GetAnnotations()["owner"] != "web-team" {
+		t.Errorf("unexpected metadata of deployment %v %v", d.GetLabels(), d.GetAnnotations())
+	}
+	if cm := get(t, objs, "ConfigMap"); len(cm.GetLabels()) != 0 {
+		t.Errorf("expected config map not to be selected, got %v", cm.GetLabels())
+	}
+	if cr := get(t, objs, "ClusterRole"); cr.GetLabels()["scope"] != "cluster" {
+		t.Errorf("unexpected labels of cluster role %v", cr.GetLabels())
+	}
+}
+
+func TestApplyNamespace(t *testing.T) {
+	steps := []fleet.PostRenderStep{{
+		Selector:  &fleet.PostRenderSelector{Namespace: "apps"},
+		Namespace: "web",
+	}}
+	objs, err := Apply(objects(t), steps, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ns := get(t, objs, "ConfigMap").GetNamespace(); ns != "web" {
+		t.Errorf("expected namespace to be rewritten, got %s", ns)
+	}
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRole"}, meta.RESTScopeRoot)
+
+	objs, err = Apply(objects(t), []fleet.PostRenderStep{{Namespace: "web"}}, mapper)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ns := get(t, objs, "Deployment").GetNamespace(); ns != "web" {
+		t.Errorf("expected namespace to be rewritten, got %s", ns)
+	}
+	if ns := get(t, objs, "ClusterRole").GetNamespace(); ns != "" {
+		t.Errorf("expected cluster scoped resource to be skipped, got %s", ns)
+	}
+}
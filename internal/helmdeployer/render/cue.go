@@ -0,0 +1,73 @@
+package render
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/load"
+
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+)
+
+const (
+	// cueRoot is the virtual directory the bundle's files are loaded from.
+	cueRoot = "/fleet-bundle"
+	// cueArgsField is the field of the package the top-level arguments
+	// are unified with.
+	cueArgsField = "args"
+)
+
+// evaluateCUE evaluates the CUE package of the bundle and returns its
+// value. Files are loaded from the bundle only, the package must be concrete
+// after the top-level arguments are filled in. Without an expression, the
+// arguments are not part of the returned value.
+func evaluateCUE(files map[string][]byte, opts fleet.CUEOptions) (interface{}, error) {
+	overlay := map[string]load.Source{}
+	for name, data := range files {
+		if strings.HasSuffix(name, ".cue") {
+			overlay[path.Join(cueRoot, name)] = load.FromBytes(data)
+		}
+	}
+
+	instances := load.Instances([]string{"."}, &load.Config{
+		Dir:     path.Join(cueRoot, opts.Dir),
+		Overlay: overlay,
+	})
+	if len(instances) != 1 {
+		return nil, fmt.Errorf("expected one CUE package, found %d", len(instances))
+	}
+	if err := instances[0].Err; err != nil {
+		return nil, err
+	}
+
+	ctx := cuecontext.New()
+	v := ctx.BuildInstance(instances[0])
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+
+	if opts.TopLevelArgs != nil && len(opts.TopLevelArgs.Data) > 0 {
+		v = v.FillPath(cue.ParsePath(cueArgsField), opts.TopLevelArgs.Data)
+	}
+	if opts.Expression != "" {
+		v = v.LookupPath(cue.ParsePath(opts.Expression))
+		if !v.Exists() {
+			return nil, fmt.Errorf("expression %s not found", opts.Expression)
+		}
+	}
+	if err := v.Validate(cue.Concrete(true)); err != nil {
+		return nil, err
+	}
+
+	var value interface{}
+	if err := v.Decode(&value); err != nil {
+		return nil, err
+	}
+	if m, ok := value.(map[string]interface{}); ok && opts.Expression == "" {
+		delete(m, cueArgsField)
+	}
+	return value, nil
+}
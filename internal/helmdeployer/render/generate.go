@@ -0,0 +1,122 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/rancher/fleet/internal/content"
+	"github.com/rancher/fleet/internal/helmdeployer/rawyaml"
+	"github.com/rancher/fleet/internal/manifest"
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	"sigs.k8s.io/yaml"
+)
+
+const generatedYAML = "generated.yaml"
+
+// generate evaluates the Jsonnet file or CUE package of the manifest and
+// returns a manifest, which contains the generated resources as raw YAML.
+func generate(m *manifest.Manifest, options fleet.BundleDeploymentOptions) (*manifest.Manifest, error) {
+	files := map[string][]byte{}
+	for _, resource := range m.Resources {
+		data, err := content.Decode(resource.Content, resource.Encoding)
+		if err != nil {
+			return nil, err
+		}
+		files[resource.Name] = data
+	}
+
+	var (
+		value interface{}
+		err   error
+	)
+	switch {
+	case options.Jsonnet != nil:
+		value, err = evaluateJsonnet(files, *options.Jsonnet)
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate jsonnet: %w", err)
+		}
+	case options.CUE != nil:
+		value, err = evaluateCUE(files, *options.CUE)
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate cue: %w", err)
+		}
+	}
+
+	out, err := toYAML(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &manifest.Manifest{
+		Resources: []fleet.BundleResource{{
+			Name:    rawyaml.YAMLPrefix + generatedYAML,
+			Content: string(out),
+		}},
+		Commit: m.Commit,
+	}, nil
+}
+
+// toYAML converts the value of an evaluation into a multi document YAML
+// stream. The value can be a single object, a list of objects or an object,
+// whose fields contain objects or lists of objects, at any depth.
+func toYAML(value interface{}) ([]byte, error) {
+	objs, err := objects(value, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	for _, obj := range objs {
+		b, err := yaml.Marshal(obj)
+		if err != nil {
+			return nil, err
+		}
+		out.WriteString("---\n")
+		out.Write(b)
+	}
+	return out.Bytes(), nil
+}
+
+func objects(value interface{}, path string) ([]map[string]interface{}, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case []interface{}:
+		var result []map[string]interface{}
+		for i, item := range v {
+			objs, err := objects(item, fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, objs...)
+		}
+		return result, nil
+	case map[string]interface{}:
+		if _, ok := v["kind"]; ok {
+			if _, ok := v["apiVersion"]; ok {
+				return []map[string]interface{}{v}, nil
+			}
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		var result []map[string]interface{}
+		for _, k := range keys {
+			objs, err := objects(v[k], path+"."+k)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, objs...)
+		}
+		return result, nil
+	default:
+		if path == "" {
+			path = "."
+		}
+		return nil, fmt.Errorf("value at %s is not a kubernetes object or a list of objects", path)
+	}
+}
@@ -0,0 +1,120 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v4/pkg/chart/v2/loader"
+
+	"github.com/rancher/fleet/internal/helmdeployer/rawyaml"
+	"github.com/rancher/fleet/internal/manifest"
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newManifest(files map[string]string) *manifest.Manifest {
+	m := &manifest.Manifest{}
+	for name, content := range files {
+		m.Resources = append(m.Resources, fleet.BundleResource{Name: name, Content: content})
+	}
+	return m
+}
+
+func render(t *testing.T, files map[string]string, options fleet.BundleDeploymentOptions) []*unstructured.Unstructured {
+	t.Helper()
+	tar, err := HelmChart("fleet-default/app", newManifest(files), options)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	chart, err := loader.LoadArchive(tar)
+	if err != nil {
+		t.Fatalf("failed to load chart: %v", err)
+	}
+	objs, err := rawyaml.ToObjects(chart)
+	if err != nil {
+		t.Fatalf("failed to read objects: %v", err)
+	}
+	var result []*unstructured.Unstructured
+	for _, obj := range objs {
+		result = append(result, obj.(*unstructured.Unstructured))
+	}
+	return result
+}
+
+func TestHelmChartJsonnet(t *testing.T) {
+	files := map[string]string{
+		"lib/labels.libsonnet": `{ app: "web" }`,
+		"app/util.libsonnet":   `{ configMap(name, data):: { apiVersion: "v1", kind: "ConfigMap", metadata: { name: name }, data: data } }`,
+		"app/main.jsonnet": `local labels = import "labels.libsonnet";
+local util = import "util.libsonnet";
+function(env, replicas=1) {
+  config: util.configMap("config", { env: env }),
+  deployments: [
+    { apiVersion: "apps/v1", kind: "Deployment", metadata: { name: "web", labels: labels }, spec: { replicas: replicas } },
+  ],
+}
+`,
+		"fleet.yaml": "jsonnet: {}\n",
+	}
+	args := &fleet.GenericMap{Data: map[string]interface{}{"env": "prod", "replicas": 3}}
+	options := fleet.BundleDeploymentOptions{}
+	options.Jsonnet = &fleet.JsonnetOptions{File: "app/main.jsonnet", LibraryPaths: []string{"lib"}, TopLevelArgs: args}
+
+	objs := render(t, files, options)
+	if len(objs) != 2 {
+		t.Fatalf("expected 2 objects, got %d", len(objs))
+	}
+	if objs[0].GetKind() != "ConfigMap" || objs[0].Object["data"].(map[string]interface{})["env"] != "prod" {
+		t.Errorf("unexpected config map %v", objs[0].Object)
+	}
+	if objs[1].GetLabels()["app"] != "web" || objs[1].Object["spec"].(map[string]interface{})["replicas"] != int64(3) {
+		t.Errorf("unexpected deployment %v", objs[1].Object)
+	}
+
+	options.Jsonnet = &fleet.JsonnetOptions{File: "app/main.jsonnet", TopLevelArgs: args}
+	if _, err := HelmChart("app", newManifest(files), options); err == nil || !strings.Contains(err.Error(), "couldn't find labels.libsonnet") {
+		t.Errorf("expected error for missing import, got %v", err)
+	}
+}
+
+func TestHelmChartCUE(t *testing.T) {
+	files := map[string]string{
+		"app/main.cue": `package app
+
+args: {
+	env:      string
+	replicas: int | *1
+}
+
+objects: config: {
+	apiVersion: "v1"
+	kind:       "ConfigMap"
+	metadata: name: "config-\(args.env)"
+	data: replicas: "\(args.replicas)"
+}
+`,
+		"app/ignored.yaml": "apiVersion: v1\nkind: Secret\nmetadata:\n  name: ignored\n",
+	}
+	args := &fleet.GenericMap{Data: map[string]interface{}{"env": "prod"}}
+	options := fleet.BundleDeploymentOptions{}
+	options.CUE = &fleet.CUEOptions{Dir: "app", TopLevelArgs: args}
+
+	objs := render(t, files, options)
+	if len(objs) != 1 || objs[0].GetName() != "config-prod" {
+		t.Fatalf("unexpected objects %v", objs)
+	}
+	if objs[0].Object["data"].(map[string]interface{})["replicas"] != "1" {
+		t.Errorf("expected default for replicas, got %v", objs[0].Object)
+	}
+
+	options.CUE.Expression = "objects.config"
+	if objs := render(t, files, options); len(objs) != 1 || objs[0].GetName() != "config-prod" {
+		t.Errorf("unexpected objects for expression %v", objs)
+	}
+
+	options.CUE = &fleet.CUEOptions{Dir: "app"}
+	if _, err := HelmChart("app", newManifest(files), options); err == nil {
+		t.Error("expected error for missing arguments")
+	}
+}
@@ -19,14 +19,26 @@ import (
 	"sigs.k8s.io/yaml"
 )
 
-// HelmChart applies overlays to "manifest"-style gitrepos and transforms the
-// manifest into a helm chart tgz
+// HelmChart applies overlays to "manifest"-style gitrepos, evaluates Jsonnet
+// and CUE bundles and transforms the manifest into a helm chart tgz
 func HelmChart(name string, m *manifest.Manifest, options fleet.BundleDeploymentOptions) (io.Reader, error) {
 	var (
 		style = bundlereader.DetermineStyle(m, options)
 		err   error
 	)
 
+	if style.IsJsonnet() || style.IsCUE() {
+		m, err = generate(m, options)
+		if err != nil {
+			return nil, err
+		}
+		m, err = addChartYAML(name, m, m)
+		if err != nil {
+			return nil, err
+		}
+		return m.ToTarGZ()
+	}
+
 	if style.IsRawYAML() {
 		var overlays []string
 		if options.YAML != nil {
@@ -0,0 +1,83 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+
+	"github.com/google/go-jsonnet"
+
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+)
+
+const defaultJsonnetFile = "main.jsonnet"
+
+// evaluateJsonnet evaluates the Jsonnet file of the bundle and returns its
+// value. Imports are resolved from the bundle's files only.
+func evaluateJsonnet(files map[string][]byte, opts fleet.JsonnetOptions) (interface{}, error) {
+	file := opts.File
+	if file == "" {
+		file = defaultJsonnetFile
+	}
+
+	vm := jsonnet.MakeVM()
+	vm.Importer(&bundleImporter{
+		files:    files,
+		paths:    opts.LibraryPaths,
+		contents: map[string]jsonnet.Contents{},
+	})
+
+	if opts.TopLevelArgs != nil {
+		keys := make([]string, 0, len(opts.TopLevelArgs.Data))
+		for k := range opts.TopLevelArgs.Data {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			// JSON is valid Jsonnet, so arguments keep their type
+			code, err := json.Marshal(opts.TopLevelArgs.Data[k])
+			if err != nil {
+				return nil, fmt.Errorf("invalid top-level argument %s: %w", k, err)
+			}
+			vm.TLACode(k, string(code))
+		}
+	}
+
+	out, err := vm.EvaluateFile(path.Clean(file))
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	if err := json.Unmarshal([]byte(out), &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// bundleImporter imports files from the bundle. Paths are relative to the
+// importing file, then to the library paths.
+type bundleImporter struct {
+	files    map[string][]byte
+	paths    []string
+	contents map[string]jsonnet.Contents
+}
+
+func (i *bundleImporter) Import(importedFrom, importedPath string) (jsonnet.Contents, string, error) {
+	candidates := []string{path.Join(path.Dir(importedFrom), importedPath)}
+	for _, p := range i.paths {
+		candidates = append(candidates, path.Join(p, importedPath))
+	}
+
+	for _, name := range candidates {
+		if c, ok := i.contents[name]; ok {
+			return c, name, nil
+		}
+		if data, ok := i.files[name]; ok {
+			c := jsonnet.MakeContentsRaw(data)
+			i.contents[name] = c
+			return c, name, nil
+		}
+	}
+	return jsonnet.Contents{}, "", fmt.Errorf("couldn't find %s in the bundle", importedPath)
+}
@@ -22,7 +22,78 @@ var (
 )
 
 // Template runs helm template and returns the resources as a list of objects, without applying them.
-func Template(ctx context.Context, bundleID string, manifest *manifest.Manifest, options fleet.BundleDeploymentOptions, kubeVersionString string) (*releasev1.Release, error) {
+// The capabilities are usually created by Capabilities, from the facts a cluster reported.
+func Template(ctx context.Context, bundleID string, manifest *manifest.Manifest, options fleet.BundleDeploymentOptions, capabilities *common.Capabilities) (*releasev1.Release, error) {
+	return newTemplateHelm(capabilities).Deploy(ctx, bundleID, manifest, options)
+}
+
+// Capabilities returns the capabilities to render charts with offline, as
+// they would be on a cluster with the given facts. The kube version
+// overrides the version from the facts, if not empty. Without facts, helm's
+// default API versions and Kubernetes version v1.25.0 are used.
+func Capabilities(kubeVersionString string, facts *fleet.ClusterFacts) (*common.Capabilities, error) {
+	if kubeVersionString == "" && facts != nil {
+		kubeVersionString = facts.KubernetesVersion
+	}
+	if kubeVersionString == "" {
+		kubeVersionString = defaultKubernetesVersion
+	}
+	kubeVersion, err := semver.NewVersion(kubeVersionString)
+	if err != nil {
+		return nil, fmt.Errorf("invalid kubeVersion: %s", kubeVersionString)
+	}
+
+	capabilities := common.DefaultCapabilities.Copy()
+	capabilities.KubeVersion = common.KubeVersion{
+		Version: kubeVersion.String(),
+		Major:   fmt.Sprint(kubeVersion.Major()),
+		Minor:   fmt.Sprint(kubeVersion.Minor()),
+	}
+	if facts != nil && len(facts.APIVersions) > 0 {
+		capabilities.APIVersions = common.VersionSet(append([]string{}, facts.APIVersions...))
+	}
+	return capabilities, nil
+}
+
+// Template renders the manifest with the namespaces and labels Deploy
+// would use, but without installing it. The capabilities are taken from
+// the cluster, if available.
+func (h *Helm) Template(ctx context.Context, bundleID string, manifest *manifest.Manifest, options fleet.BundleDeploymentOptions) (*Resources, error) {
+	facts := &fleet.ClusterFacts{}
+	if h.getter != nil {
+		if dc, err := h.getter.ToDiscoveryClient(); err == nil {
+			if v, err := dc.ServerVersion(); err == nil {
+				facts.KubernetesVersion = v.GitVersion
+			}
+			if apiVersions, err := GetVersionSet(dc); err == nil {
+				facts.APIVersions = apiVersions
+			}
+		}
+	}
+
+	capabilities, err := Capabilities("", facts)
+	if err != nil {
+		return nil, err
+	}
+	t := newTemplateHelm(capabilities)
+	t.agentNamespace = h.agentNamespace
+	t.defaultNamespace = h.defaultNamespace
+	t.labelPrefix = h.labelPrefix
+	t.labelSuffix = h.labelSuffix
+
+	release, err := t.Deploy(ctx, bundleID, manifest, options)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := &Resources{DefaultNamespace: release.Namespace}
+	resources.Objects, err = ReleaseToObjects(release)
+	return resources, err
+}
+
+// newTemplateHelm returns a helm deployer, which renders releases into
+// memory instead of installing them.
+func newTemplateHelm(capabilities *common.Capabilities) *Helm {
 	h := &Helm{
 		globalCfg:    &action.Configuration{},
 		useGlobalCfg: true,
@@ -33,24 +104,11 @@ func Template(ctx context.Context, bundleID string, manifest *manifest.Manifest,
 	mem.SetNamespace("default")
 	// Template operations use a discard logger since they don't interact with a real cluster
 	mem.SetLogger(nil) // nil sets discard handler in Helm v4
-	kubeVersionToUse := defaultKubernetesVersion
-	if kubeVersionString != "" {
-		kubeVersionToUse = kubeVersionString
-	}
-	kubeVersion, err := semver.NewVersion(kubeVersionToUse)
-	if err != nil {
-		return nil, fmt.Errorf("invalid kubeVersion: %s", kubeVersionToUse)
-	}
-	h.globalCfg.Capabilities = common.DefaultCapabilities.Copy()
-	h.globalCfg.Capabilities.KubeVersion = common.KubeVersion{
-		Version: kubeVersion.String(),
-		Major:   fmt.Sprint(kubeVersion.Major()),
-		Minor:   fmt.Sprint(kubeVersion.Minor()),
-	}
+	h.globalCfg.Capabilities = capabilities
 	h.globalCfg.KubeClient = &kubefake.PrintingKubeClient{Out: io.Discard}
 	h.globalCfg.Releases = storage.Init(mem)
 	// Template operations don't need logging since they're just rendering
 	h.globalCfg.SetLogger(nil) // nil sets discard handler in Helm v4
 
-	return h.Deploy(ctx, bundleID, manifest, options)
+	return h
 }
@@ -0,0 +1,94 @@
+package helmdeployer
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/rancher/fleet/internal/manifest"
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+)
+
+func TestCapabilities(t *testing.T) {
+	capabilities, err := Capabilities("", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if capabilities.KubeVersion.Version != "1.25.0" || !capabilities.APIVersions.Has("apps/v1") {
+		t.Errorf("expected default capabilities, got %+v", capabilities)
+	}
+
+	facts := &fleet.ClusterFacts{
+		KubernetesVersion: "v1.30.2+k3s1",
+		APIVersions:       []string{"v1", "cert-manager.io/v1", "cert-manager.io/v1/Certificate"},
+	}
+	capabilities, err = Capabilities("", facts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if capabilities.KubeVersion.Major != "1" || capabilities.KubeVersion.Minor != "30" {
+		t.Errorf("expected kube version of the cluster, got %+v", capabilities.KubeVersion)
+	}
+	if !capabilities.APIVersions.Has("cert-manager.io/v1/Certificate") || capabilities.APIVersions.Has("apps/v1") {
+		t.Errorf("expected api versions of the cluster, got %v", capabilities.APIVersions)
+	}
+
+	capabilities, err = Capabilities("v1.28.0", facts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if capabilities.KubeVersion.Minor != "28" || !capabilities.APIVersions.Has("cert-manager.io/v1") {
+		t.Errorf("expected kube version to be overridden, got %+v", capabilities)
+	}
+
+	if _, err := Capabilities("latest", nil); err == nil {
+		t.Error("expected error for invalid kube version")
+	}
+}
+
+func TestTemplateCapabilities(t *testing.T) {
+	m := manifest.New([]fleet.BundleResource{
+		{Name: "Chart.yaml", Content: "apiVersion: v2\nname: app\nversion: 0.1.0\n"},
+		{Name: "templates/cm.yaml", Content: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app
+data:
+  minor: "{{ .Capabilities.KubeVersion.Minor }}"
+  certManager: "{{ .Capabilities.APIVersions.Has "cert-manager.io/v1/Certificate" }}"
+`},
+	})
+
+	tests := map[string]struct {
+		facts    *fleet.ClusterFacts
+		expected []string
+	}{
+		"defaults": {
+			expected: []string{`minor: "25"`, `certManager: "false"`},
+		},
+		"cluster facts": {
+			facts: &fleet.ClusterFacts{
+				KubernetesVersion: "v1.31.1",
+				APIVersions:       []string{"v1", "cert-manager.io/v1", "cert-manager.io/v1/Certificate"},
+			},
+			expected: []string{`minor: "31"`, `certManager: "true"`},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			capabilities, err := Capabilities("", tt.facts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rel, err := Template(context.TODO(), "app", m, fleet.BundleDeploymentOptions{}, capabilities)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, s := range tt.expected {
+				if !strings.Contains(rel.Manifest, s) {
+					t.Errorf("expected manifest to contain %q, got:\n%s", s, rel.Manifest)
+				}
+			}
+		})
+	}
+}
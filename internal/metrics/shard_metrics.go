@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	shardResources = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricPrefix,
+			Subsystem: "shard",
+			Name:      "resources",
+			Help:      "The count of resources by kind, which are processed by a shard. The default shard has an empty shard_id.",
+		},
+		[]string{"shard_id", "kind"},
+	)
+	shardLive = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricPrefix,
+			Subsystem: "shard",
+			Name:      "live",
+			Help:      "Whether the shard coordinator assigns resources to a shard.",
+		},
+		[]string{"shard_id"},
+	)
+)
+
+func init() {
+	objMetrics = append(objMetrics, shardResources, shardLive)
+}
+
+// SetShardLoad replaces the shard metrics with the live shards and the
+// count of resources by shard ID and kind.
+func SetShardLoad(live []string, load map[string]map[string]int) {
+	shardLive.Reset()
+	for _, id := range live {
+		shardLive.WithLabelValues(id).Set(1)
+	}
+	shardResources.Reset()
+	for id, kinds := range load {
+		for kind, n := range kinds {
+			shardResources.WithLabelValues(id, kind).Set(float64(n))
+		}
+	}
+}
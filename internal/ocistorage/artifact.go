@@ -0,0 +1,75 @@
+package ocistorage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rancher/fleet/internal/bundlereader"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content/file"
+)
+
+const (
+	// ociScheme is the scheme of OCI artifact URLs, e.g. in an OCIRepo.
+	ociScheme  = "oci://"
+	defaultTag = "latest"
+)
+
+// ResolveArtifact returns the tag and the digest of the artifact in the OCI
+// repository opts.Reference. If semverRange is set, the highest tag in the
+// range is resolved, otherwise the given tag, which defaults to "latest".
+func (o *OCIWrapper) ResolveArtifact(ctx context.Context, opts OCIOpts, tag, semverRange string) (string, string, error) {
+	repo, err := newOCIRepository("", artifactOpts(opts))
+	if err != nil {
+		return "", "", err
+	}
+
+	if semverRange != "" {
+		tag, err = bundlereader.GetOCITag(ctx, repo, semverRange)
+		if err != nil {
+			return "", "", err
+		}
+		if tag == "" {
+			return "", "", fmt.Errorf("no tag found in %s matching %q", repo.Reference, semverRange)
+		}
+	}
+	if tag == "" {
+		tag = defaultTag
+	}
+
+	desc, err := repo.Resolve(ctx, tag)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to resolve tag %q of %s: %w", tag, repo.Reference, err)
+	}
+	return tag, desc.Digest.String(), nil
+}
+
+// PullArtifact pulls the artifact identified by ref, a tag or digest, from
+// the OCI repository opts.Reference and writes its files to dir. Like `oras
+// pull`, layers are written to files named after their title annotation and
+// layers of pushed directories are unpacked.
+func (o *OCIWrapper) PullArtifact(ctx context.Context, opts OCIOpts, ref, dir string) error {
+	repo, err := newOCIRepository("", artifactOpts(opts))
+	if err != nil {
+		return err
+	}
+
+	store, err := file.New(dir)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	if _, err := o.oci.Copy(ctx, repo, ref, store, ref, oras.DefaultCopyOptions); err != nil {
+		return fmt.Errorf("failed to pull %s from %s: %w", ref, repo.Reference, err)
+	}
+	return nil
+}
+
+// artifactOpts strips the scheme from the reference, as OCI artifact URLs
+// are prefixed with "oci://".
+func artifactOpts(opts OCIOpts) OCIOpts {
+	opts.Reference = strings.TrimPrefix(opts.Reference, ociScheme)
+	return opts
+}
@@ -0,0 +1,98 @@
+package ocistorage
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/registry"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content/file"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OCI artifacts", func() {
+	var (
+		server *httptest.Server
+		opts   OCIOpts
+		oci    *OCIWrapper
+	)
+
+	// push packs the directory "manifests" with a config map containing
+	// data, like `oras push <ref>:<tag> manifests/`.
+	push := func(tag, data string) {
+		src := GinkgoT().TempDir()
+		dir := filepath.Join(src, "manifests", "app")
+		Expect(os.MkdirAll(dir, 0o755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "cm.yaml"), []byte(data), 0o600)).To(Succeed())
+
+		store, err := file.New(src)
+		Expect(err).ToNot(HaveOccurred())
+		defer store.Close()
+
+		ctx := context.Background()
+		layer, err := store.Add(ctx, "manifests", "", filepath.Join(src, "manifests"))
+		Expect(err).ToNot(HaveOccurred())
+		manifest, err := oras.PackManifest(ctx, store, oras.PackManifestVersion1_1, "application/vnd.test.manifests", oras.PackManifestOptions{
+			Layers: []ocispec.Descriptor{layer},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(store.Tag(ctx, manifest, tag)).To(Succeed())
+
+		repo, err := newOCIRepository("", artifactOpts(opts))
+		Expect(err).ToNot(HaveOccurred())
+		_, err = oras.Copy(ctx, store, tag, repo, tag, oras.DefaultCopyOptions)
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	BeforeEach(func() {
+		server = httptest.NewServer(registry.New())
+		DeferCleanup(server.Close)
+
+		opts = OCIOpts{
+			Reference: "oci://" + strings.TrimPrefix(server.URL, "http://") + "/fleet/manifests",
+			BasicHTTP: true,
+		}
+		oci = NewOCIWrapper()
+
+		push("1.0.0", "v1.0.0")
+		push("1.1.0", "v1.1.0")
+		push("2.0.0", "v2.0.0")
+	})
+
+	It("resolves the highest tag in a semver range", func() {
+		tag, digest, err := oci.ResolveArtifact(context.Background(), opts, "", "1.x")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(tag).To(Equal("1.1.0"))
+		Expect(digest).To(HavePrefix("sha256:"))
+
+		_, other, err := oci.ResolveArtifact(context.Background(), opts, "2.0.0", "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(other).ToNot(Equal(digest))
+	})
+
+	It("returns an error if no tag matches", func() {
+		_, _, err := oci.ResolveArtifact(context.Background(), opts, "", ">= 3.0.0")
+		Expect(err).To(HaveOccurred())
+
+		_, _, err = oci.ResolveArtifact(context.Background(), opts, "", "")
+		Expect(err).To(MatchError(ContainSubstring(`failed to resolve tag "latest"`)))
+	})
+
+	It("pulls and unpacks the artifact", func() {
+		_, digest, err := oci.ResolveArtifact(context.Background(), opts, "1.0.0", "")
+		Expect(err).ToNot(HaveOccurred())
+
+		dir := GinkgoT().TempDir()
+		Expect(oci.PullArtifact(context.Background(), opts, digest, dir)).To(Succeed())
+
+		data, err := os.ReadFile(filepath.Join(dir, "manifests", "app", "cm.yaml"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(Equal("v1.0.0"))
+	})
+})
@@ -16,6 +16,7 @@ import (
 	"github.com/opencontainers/go-digest"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/rancher/fleet/internal/manifest"
+	"github.com/rancher/fleet/internal/ociverify"
 	"oras.land/oras-go/v2"
 	"oras.land/oras-go/v2/content"
 	"oras.land/oras-go/v2/content/memory"
@@ -39,6 +40,10 @@ type OCIOpts struct {
 	AgentPassword   string
 	BasicHTTP       bool
 	InsecureSkipTLS bool
+	// VerificationProvider and VerificationKeys enable the verification of
+	// the signatures of pulled manifests, see ociverify.New.
+	VerificationProvider string
+	VerificationKeys     []byte
 }
 
 type OrasOps interface {
@@ -205,6 +210,17 @@ func (o *OCIWrapper) pullFile(ctx context.Context, opts OCIOpts, id string) ([]b
 		return nil, err
 	}
 
+	// the signatures are stored next to the manifest in the remote repository
+	if len(opts.VerificationKeys) > 0 {
+		verifier, err := ociverify.New(opts.VerificationProvider, opts.VerificationKeys)
+		if err != nil {
+			return nil, err
+		}
+		if err := verifier.Verify(ctx, repo, rootDesc); err != nil {
+			return nil, err
+		}
+	}
+
 	// fetch the root node of the manifest
 	rootData, err := getDataFromDescriptor(ctx, s, rootDesc)
 	if err != nil {
@@ -20,6 +20,11 @@ const (
 	OCISecretReference     = "reference"
 	OCISecretBasicHTTP     = "basicHTTP"
 	OCISecretInsecure      = "insecure"
+	// OCISecretVerificationKeys holds PEM encoded public keys (cosign) or
+	// root certificates (notation). If set, the agent only deploys
+	// manifests, which are signed by a trusted key.
+	OCISecretVerificationKeys     = "verificationKeys"
+	OCISecretVerificationProvider = "verificationProvider"
 )
 
 // ReadOptsFromSecret reads the secret identified by the given NamespacedName and
@@ -78,6 +83,13 @@ func ReadOptsFromSecret(ctx context.Context, c client.Reader, ns client.ObjectKe
 		return OCIOpts{}, err
 	}
 
+	opts.VerificationProvider, err = getStringValueFromSecret(secret.Data, OCISecretVerificationProvider, false)
+	if err != nil {
+		return OCIOpts{}, err
+	}
+
+	opts.VerificationKeys = secret.Data[OCISecretVerificationKeys]
+
 	return opts, nil
 }
 
@@ -57,6 +57,9 @@ var _ = Describe("OCIOpts loaded from secret", func() {
 				OCISecretReference:     []byte("reference"),
 				OCISecretBasicHTTP:     []byte("true"),
 				OCISecretInsecure:      []byte("true"),
+
+				OCISecretVerificationProvider: []byte("notation"),
+				OCISecretVerificationKeys:     []byte("certificates"),
 			}
 			secretType = fleet.SecretTypeOCIStorage
 			secretGetErrorMessage = ""
@@ -73,6 +76,8 @@ var _ = Describe("OCIOpts loaded from secret", func() {
 			Expect(opts.AgentPassword).To(Equal(string(secretData[OCISecretAgentPassword])))
 			Expect(opts.BasicHTTP).To(BeTrue())
 			Expect(opts.InsecureSkipTLS).To(BeTrue())
+			Expect(opts.VerificationProvider).To(Equal("notation"))
+			Expect(opts.VerificationKeys).To(Equal([]byte("certificates")))
 		})
 	})
 
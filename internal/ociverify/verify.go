@@ -0,0 +1,657 @@
+// Package ociverify verifies the signatures of OCI artifacts, which were
+// signed with cosign or notation, against a set of trusted keys.
+package ociverify
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry"
+)
+
+const (
+	// ProviderCosign verifies signatures created with "cosign sign --key".
+	// The signatures are looked up in the "sha256-<digest>.sig" tag of the
+	// repository and, in the sigstore bundle format, in the referrers of the
+	// artifact.
+	ProviderCosign = "cosign"
+	// ProviderNotation verifies JWS signatures created with "notation sign".
+	// The signatures are stored as referrers of the artifact.
+	ProviderNotation = "notation"
+
+	cosignSignatureAnnotation = "dev.cosignproject.cosign/signature"
+	cosignSignatureTagSuffix  = ".sig"
+	sigstoreBundleType        = "application/vnd.dev.sigstore.bundle.v0.3+json"
+	inTotoPayloadType         = "application/vnd.in-toto+json"
+
+	notationArtifactType = "application/vnd.cncf.notary.signature"
+	notationJWSMediaType = "application/jose+json"
+	notationPayloadType  = "application/vnd.cncf.notary.payload.v1+json"
+
+	notationSigningScheme = "io.cncf.notary.signingScheme"
+	notationSigningTime   = "io.cncf.notary.signingTime"
+	notationExpiry        = "io.cncf.notary.expiry"
+	notationSchemeX509    = "notary.x509"
+
+	notationIdentityPrefix = "x509.subject:"
+)
+
+// VerificationError is returned, if an artifact has no signature from a
+// trusted key.
+type VerificationError struct {
+	Digest string
+	Err    error
+}
+
+func (e *VerificationError) Error() string {
+	return fmt.Sprintf("artifact %s failed signature verification: %v", e.Digest, e.Err)
+}
+
+func (e *VerificationError) Unwrap() error {
+	return e.Err
+}
+
+// Verifier verifies the signatures of OCI artifacts.
+type Verifier struct {
+	provider string
+	// keys are the trusted public keys for cosign
+	keys []crypto.PublicKey
+	// roots are the trusted root certificates for notation
+	roots *x509.CertPool
+	// identities are the trusted subjects of notation signing certificates
+	identities []map[string]string
+	// now returns the current time
+	now func() time.Time
+}
+
+// New returns a verifier for the provider, which defaults to cosign. The
+// data contains PEM encoded public keys for cosign, or PEM encoded root
+// certificates for notation.
+func New(provider string, data ...[]byte) (*Verifier, error) {
+	if provider == "" {
+		provider = ProviderCosign
+	}
+	v := &Verifier{provider: provider, now: time.Now}
+
+	var wantType string
+	switch provider {
+	case ProviderCosign:
+		wantType = "PUBLIC KEY"
+	case ProviderNotation:
+		wantType = "CERTIFICATE"
+		v.roots = x509.NewCertPool()
+	default:
+		return nil, fmt.Errorf("unsupported signature verification provider %q", provider)
+	}
+
+	found := 0
+	for _, d := range data {
+		for rest := d; ; {
+			var block *pem.Block
+			block, rest = pem.Decode(rest)
+			if block == nil {
+				break
+			}
+			if block.Type != wantType {
+				continue
+			}
+			switch provider {
+			case ProviderCosign:
+				key, err := x509.ParsePKIXPublicKey(block.Bytes)
+				if err != nil {
+					return nil, fmt.Errorf("failed to parse public key: %w", err)
+				}
+				v.keys = append(v.keys, key)
+			case ProviderNotation:
+				cert, err := x509.ParseCertificate(block.Bytes)
+				if err != nil {
+					return nil, fmt.Errorf("failed to parse certificate: %w", err)
+				}
+				v.roots.AddCert(cert)
+			}
+			found++
+		}
+	}
+	if found == 0 {
+		return nil, fmt.Errorf("no PEM encoded %s found to verify %s signatures", strings.ToLower(wantType), provider)
+	}
+	return v, nil
+}
+
+// TrustIdentities restricts notation signatures to signing certificates
+// with one of the given subjects. Identities are written as in notation trust
+// policies, e.g. "x509.subject: C=US, O=example, CN=signer". A certificate
+// matches, if its subject contains all attributes of the identity.
+func (v *Verifier) TrustIdentities(identities ...string) error {
+	if len(identities) > 0 && v.provider != ProviderNotation {
+		return fmt.Errorf("trusted identities are not supported for %s signatures", v.provider)
+	}
+	for _, identity := range identities {
+		dn, ok := strings.CutPrefix(identity, notationIdentityPrefix)
+		if !ok {
+			return fmt.Errorf("trusted identity %q must start with %q", identity, notationIdentityPrefix)
+		}
+		attrs, err := parseDN(dn)
+		if err != nil {
+			return fmt.Errorf("trusted identity %q: %w", identity, err)
+		}
+		v.identities = append(v.identities, attrs)
+	}
+	return nil
+}
+
+// Verify checks that the artifact described by desc was signed by a trusted
+// key. The signatures are looked up in target, which is usually the remote
+// repository of the artifact.
+// A *VerificationError is returned if no valid signature is found. Other
+// errors are returned as is, e.g. if the registry is not reachable.
+func (v *Verifier) Verify(ctx context.Context, target oras.ReadOnlyGraphTarget, desc ocispec.Descriptor) error {
+	var err error
+	switch v.provider {
+	case ProviderNotation:
+		err = v.verifyNotation(ctx, target, desc)
+	default:
+		err = v.verifyCosign(ctx, target, desc)
+	}
+
+	var f verificationFailure
+	if errors.As(err, &f) {
+		return &VerificationError{Digest: desc.Digest.String(), Err: err}
+	}
+	return err
+}
+
+// verificationFailure distinguishes a missing or invalid signature from
+// errors while fetching the signatures.
+type verificationFailure struct {
+	msg string
+}
+
+func (f verificationFailure) Error() string {
+	return f.msg
+}
+
+func failure(format string, args ...interface{}) error {
+	return verificationFailure{msg: fmt.Sprintf(format, args...)}
+}
+
+func (v *Verifier) verifyCosign(ctx context.Context, target oras.ReadOnlyGraphTarget, desc ocispec.Descriptor) error {
+	err := v.verifyCosignTag(ctx, target, desc)
+	var f verificationFailure
+	if err == nil || !errors.As(err, &f) {
+		return err
+	}
+	// fall back to signatures in the sigstore bundle format
+	bundleErr := v.verifyCosignBundles(ctx, target, desc)
+	if bundleErr == nil || !errors.As(bundleErr, &f) || errors.Is(err, errNoCosignSignature) {
+		return bundleErr
+	}
+	return err
+}
+
+var errNoCosignSignature = failure("no cosign signature found")
+
+// verifyCosignTag verifies the signatures in the signature tag of the
+// artifact, as stored by cosign by default.
+func (v *Verifier) verifyCosignTag(ctx context.Context, target oras.ReadOnlyGraphTarget, desc ocispec.Descriptor) error {
+	tag := strings.Replace(desc.Digest.String(), ":", "-", 1) + cosignSignatureTagSuffix
+	sigDesc, err := target.Resolve(ctx, tag)
+	if errors.Is(err, errdef.ErrNotFound) {
+		return errNoCosignSignature
+	} else if err != nil {
+		return fmt.Errorf("failed to resolve cosign signature %s: %w", tag, err)
+	}
+
+	var m ocispec.Manifest
+	if err := fetchJSON(ctx, target, sigDesc, &m); err != nil {
+		return err
+	}
+
+	var lastErr error = errNoCosignSignature
+	for _, layer := range m.Layers {
+		sig, ok := layer.Annotations[cosignSignatureAnnotation]
+		if !ok {
+			continue
+		}
+		payload, err := content.FetchAll(ctx, target, layer)
+		if err != nil {
+			return fmt.Errorf("failed to fetch cosign signature payload: %w", err)
+		}
+		if lastErr = v.verifyCosignPayload(desc, payload, sig); lastErr == nil {
+			return nil
+		}
+	}
+	return lastErr
+}
+
+// verifyCosignBundles verifies the signatures in the sigstore bundles, which
+// refer to the artifact, as stored by "cosign sign --new-bundle-format". The
+// transparency log entries of the bundles are not verified, as the keys are
+// trusted directly.
+func (v *Verifier) verifyCosignBundles(ctx context.Context, target oras.ReadOnlyGraphTarget, desc ocispec.Descriptor) error {
+	referrers, err := registry.Referrers(ctx, target, desc, sigstoreBundleType)
+	if err != nil {
+		return fmt.Errorf("failed to list cosign signatures: %w", err)
+	}
+
+	var lastErr error = errNoCosignSignature
+	for _, r := range referrers {
+		var m ocispec.Manifest
+		if err := fetchJSON(ctx, target, r, &m); err != nil {
+			return err
+		}
+		if len(m.Layers) != 1 || m.Layers[0].MediaType != sigstoreBundleType {
+			lastErr = failure("cosign signature %s is not a sigstore bundle", r.Digest)
+			continue
+		}
+		bundle, err := content.FetchAll(ctx, target, m.Layers[0])
+		if err != nil {
+			return fmt.Errorf("failed to fetch cosign signature: %w", err)
+		}
+		if lastErr = v.verifySigstoreBundle(ctx, target, desc, bundle); lastErr == nil {
+			return nil
+		}
+	}
+	return lastErr
+}
+
+// verifySigstoreBundle verifies a sigstore bundle, which either signs the
+// artifact directly or contains an in-toto statement about the artifact in a
+// DSSE envelope. See
+// https://github.com/sigstore/protobuf-specs/blob/main/protos/sigstore_bundle.proto
+func (v *Verifier) verifySigstoreBundle(ctx context.Context, target content.Fetcher, desc ocispec.Descriptor, data []byte) error {
+	var bundle struct {
+		MessageSignature *struct {
+			MessageDigest struct {
+				Algorithm string `json:"algorithm"`
+				Digest    []byte `json:"digest"`
+			} `json:"messageDigest"`
+			Signature []byte `json:"signature"`
+		} `json:"messageSignature"`
+		DSSEEnvelope *struct {
+			Payload     []byte `json:"payload"`
+			PayloadType string `json:"payloadType"`
+			Signatures  []struct {
+				Sig []byte `json:"sig"`
+			} `json:"signatures"`
+		} `json:"dsseEnvelope"`
+	}
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return failure("failed to parse cosign signature bundle: %v", err)
+	}
+
+	switch {
+	case bundle.MessageSignature != nil:
+		sig := bundle.MessageSignature
+		if sig.MessageDigest.Algorithm != "SHA2_256" || "sha256:"+hex.EncodeToString(sig.MessageDigest.Digest) != desc.Digest.String() {
+			return failure("cosign signature is for a different digest")
+		}
+		// the artifact itself is signed
+		manifest, err := content.FetchAll(ctx, target, desc)
+		if err != nil {
+			return fmt.Errorf("failed to fetch signed artifact: %w", err)
+		}
+		if !v.trustedKeySigned(manifest, sig.Signature) {
+			return failure("cosign signature is not from a trusted key")
+		}
+		return nil
+	case bundle.DSSEEnvelope != nil:
+		env := bundle.DSSEEnvelope
+		if env.PayloadType != inTotoPayloadType {
+			return failure("cosign signature has unsupported payload type %q", env.PayloadType)
+		}
+		pae := fmt.Appendf(nil, "DSSEv1 %d %s %d %s", len(env.PayloadType), env.PayloadType, len(env.Payload), env.Payload)
+		trusted := false
+		for _, sig := range env.Signatures {
+			if v.trustedKeySigned(pae, sig.Sig) {
+				trusted = true
+				break
+			}
+		}
+		if !trusted {
+			return failure("cosign signature is not from a trusted key")
+		}
+		var statement struct {
+			Subject []struct {
+				Digest map[string]string `json:"digest"`
+			} `json:"subject"`
+		}
+		if err := json.Unmarshal(env.Payload, &statement); err != nil {
+			return failure("failed to parse cosign signature statement: %v", err)
+		}
+		for _, subject := range statement.Subject {
+			if "sha256:"+subject.Digest["sha256"] == desc.Digest.String() {
+				return nil
+			}
+		}
+		return failure("cosign signature statement is not about digest %q", desc.Digest)
+	default:
+		return failure("cosign signature bundle has no signature")
+	}
+}
+
+// trustedKeySigned returns true, if sig is a signature of message by one of
+// the trusted keys.
+func (v *Verifier) trustedKeySigned(message, sig []byte) bool {
+	for _, key := range v.keys {
+		if verifySignature(key, crypto.SHA256, message, sig) == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *Verifier) verifyCosignPayload(desc ocispec.Descriptor, payload []byte, encodedSig string) error {
+	sig, err := base64.StdEncoding.DecodeString(encodedSig)
+	if err != nil {
+		return failure("failed to decode cosign signature: %v", err)
+	}
+	if !v.trustedKeySigned(payload, sig) {
+		return failure("cosign signature is not from a trusted key")
+	}
+
+	var simpleSigning struct {
+		Critical struct {
+			Image struct {
+				DockerManifestDigest string `json:"docker-manifest-digest"`
+			} `json:"image"`
+		} `json:"critical"`
+	}
+	if err := json.Unmarshal(payload, &simpleSigning); err != nil {
+		return failure("failed to parse cosign signature payload: %v", err)
+	}
+	if signed := simpleSigning.Critical.Image.DockerManifestDigest; signed != desc.Digest.String() {
+		return failure("cosign signature is for digest %q", signed)
+	}
+	return nil
+}
+
+func (v *Verifier) verifyNotation(ctx context.Context, target oras.ReadOnlyGraphTarget, desc ocispec.Descriptor) error {
+	referrers, err := registry.Referrers(ctx, target, desc, notationArtifactType)
+	if err != nil {
+		return fmt.Errorf("failed to list notation signatures: %w", err)
+	}
+
+	var lastErr error = failure("no notation signature found")
+	for _, r := range referrers {
+		var m ocispec.Manifest
+		if err := fetchJSON(ctx, target, r, &m); err != nil {
+			return err
+		}
+		if len(m.Layers) != 1 {
+			lastErr = failure("notation signature has %d layers, expected 1", len(m.Layers))
+			continue
+		}
+		if m.Layers[0].MediaType != notationJWSMediaType {
+			lastErr = failure("notation signature envelope %q is not supported", m.Layers[0].MediaType)
+			continue
+		}
+		envelope, err := content.FetchAll(ctx, target, m.Layers[0])
+		if err != nil {
+			return fmt.Errorf("failed to fetch notation signature: %w", err)
+		}
+		if lastErr = v.verifyNotationJWS(desc, envelope); lastErr == nil {
+			return nil
+		}
+	}
+	return lastErr
+}
+
+// verifyNotationJWS verifies a notation signature in the JWS JSON
+// serialization. See
+// https://github.com/notaryproject/specifications/blob/main/specs/signature-envelope-jws.md
+func (v *Verifier) verifyNotationJWS(desc ocispec.Descriptor, envelope []byte) error {
+	var jws struct {
+		Payload   string `json:"payload"`
+		Protected string `json:"protected"`
+		Header    struct {
+			CertificateChain [][]byte `json:"x5c"`
+		} `json:"header"`
+		Signature string `json:"signature"`
+	}
+	if err := json.Unmarshal(envelope, &jws); err != nil {
+		return failure("failed to parse notation signature: %v", err)
+	}
+
+	protected, err := base64.RawURLEncoding.DecodeString(jws.Protected)
+	if err != nil {
+		return failure("failed to decode notation signature header: %v", err)
+	}
+	var header struct {
+		Algorithm     string     `json:"alg"`
+		ContentType   string     `json:"cty"`
+		Critical      []string   `json:"crit"`
+		SigningScheme string     `json:"io.cncf.notary.signingScheme"`
+		SigningTime   *time.Time `json:"io.cncf.notary.signingTime"`
+		Expiry        *time.Time `json:"io.cncf.notary.expiry"`
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(protected, &header); err != nil {
+		return failure("failed to parse notation signature header: %v", err)
+	}
+	if err := json.Unmarshal(protected, &fields); err != nil {
+		return failure("failed to parse notation signature header: %v", err)
+	}
+	if header.ContentType != notationPayloadType {
+		return failure("notation signature has unsupported content type %q", header.ContentType)
+	}
+	// critical headers, which are not understood, must be rejected
+	for _, crit := range header.Critical {
+		if crit != notationSigningScheme && crit != notationExpiry {
+			return failure("notation signature has unsupported critical header %q", crit)
+		}
+		if _, ok := fields[crit]; !ok {
+			return failure("notation signature lacks critical header %q", crit)
+		}
+	}
+	if !slices.Contains(header.Critical, notationSigningScheme) {
+		return failure("notation signature does not mark %q as critical", notationSigningScheme)
+	}
+	if header.Expiry != nil && !slices.Contains(header.Critical, notationExpiry) {
+		return failure("notation signature does not mark %q as critical", notationExpiry)
+	}
+	if header.SigningScheme != notationSchemeX509 {
+		return failure("notation signing scheme %q is not supported", header.SigningScheme)
+	}
+	if header.SigningTime == nil {
+		return failure("notation signature has no signing time")
+	}
+	now := v.now()
+	if header.SigningTime.After(now) {
+		return failure("notation signature signing time %s is in the future", header.SigningTime.Format(time.RFC3339))
+	}
+	if header.Expiry != nil && !now.Before(*header.Expiry) {
+		return failure("notation signature expired at %s", header.Expiry.Format(time.RFC3339))
+	}
+
+	if len(jws.Header.CertificateChain) == 0 {
+		return failure("notation signature has no certificate chain")
+	}
+	var certs []*x509.Certificate
+	for _, der := range jws.Header.CertificateChain {
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return failure("failed to parse notation signature certificate: %v", err)
+		}
+		certs = append(certs, cert)
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	// the chain must have been valid when the artifact was signed
+	if _, err := certs[0].Verify(x509.VerifyOptions{
+		Roots:         v.roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		CurrentTime:   *header.SigningTime,
+	}); err != nil {
+		return failure("notation signature certificate is not trusted: %v", err)
+	}
+	if !v.trustedIdentity(certs[0]) {
+		return failure("notation signature certificate subject %q is not a trusted identity", certs[0].Subject)
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(jws.Signature)
+	if err != nil {
+		return failure("failed to decode notation signature: %v", err)
+	}
+	if err := verifyJWSSignature(certs[0].PublicKey, header.Algorithm, []byte(jws.Protected+"."+jws.Payload), sig); err != nil {
+		return failure("invalid notation signature: %v", err)
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(jws.Payload)
+	if err != nil {
+		return failure("failed to decode notation signature payload: %v", err)
+	}
+	var p struct {
+		TargetArtifact ocispec.Descriptor `json:"targetArtifact"`
+	}
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return failure("failed to parse notation signature payload: %v", err)
+	}
+	if p.TargetArtifact.Digest != desc.Digest {
+		return failure("notation signature is for digest %q", p.TargetArtifact.Digest)
+	}
+	return nil
+}
+
+// trustedIdentity returns true, if no identities are configured or if the
+// subject of cert matches one of them.
+func (v *Verifier) trustedIdentity(cert *x509.Certificate) bool {
+	if len(v.identities) == 0 {
+		return true
+	}
+	subject, err := parseDN(cert.Subject.String())
+	if err != nil {
+		return false
+	}
+	for _, identity := range v.identities {
+		matches := true
+		for k, val := range identity {
+			if subject[k] != val {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return true
+		}
+	}
+	return false
+}
+
+// parseDN parses a distinguished name like "C=US, O=example" into its
+// attributes. Escaped separators and multi-valued attributes are not
+// supported.
+func parseDN(dn string) (map[string]string, error) {
+	attrs := map[string]string{}
+	for _, rdn := range strings.Split(dn, ",") {
+		k, val, ok := strings.Cut(rdn, "=")
+		k, val = strings.ToUpper(strings.TrimSpace(k)), strings.TrimSpace(val)
+		if !ok || k == "" || val == "" || strings.ContainsAny(val, `+\`) {
+			return nil, fmt.Errorf("invalid distinguished name %q", dn)
+		}
+		if _, dup := attrs[k]; dup {
+			return nil, fmt.Errorf("duplicate attribute %s in distinguished name %q", k, dn)
+		}
+		attrs[k] = val
+	}
+	return attrs, nil
+}
+
+func verifyJWSSignature(key crypto.PublicKey, alg string, message, sig []byte) error {
+	var hash crypto.Hash
+	switch alg {
+	case "PS256", "ES256":
+		hash = crypto.SHA256
+	case "PS384", "ES384":
+		hash = crypto.SHA384
+	case "PS512", "ES512":
+		hash = crypto.SHA512
+	default:
+		return fmt.Errorf("unsupported algorithm %q", alg)
+	}
+	h := hash.New()
+	h.Write(message)
+	digest := h.Sum(nil)
+
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		if !strings.HasPrefix(alg, "PS") {
+			return fmt.Errorf("algorithm %q does not match rsa key", alg)
+		}
+		return rsa.VerifyPSS(k, hash, digest, sig, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+	case *ecdsa.PublicKey:
+		if !strings.HasPrefix(alg, "ES") {
+			return fmt.Errorf("algorithm %q does not match ecdsa key", alg)
+		}
+		// JWS ecdsa signatures are the concatenation of r and s
+		size := (k.Curve.Params().BitSize + 7) / 8
+		if len(sig) != 2*size {
+			return errors.New("invalid ecdsa signature length")
+		}
+		r := new(big.Int).SetBytes(sig[:size])
+		s := new(big.Int).SetBytes(sig[size:])
+		if !ecdsa.Verify(k, digest, r, s) {
+			return errors.New("ecdsa signature does not match")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported key type %T", key)
+	}
+}
+
+// verifySignature verifies a signature, as created by cosign for the
+// given key type.
+func verifySignature(key crypto.PublicKey, hash crypto.Hash, message, sig []byte) error {
+	switch k := key.(type) {
+	case ed25519.PublicKey:
+		if !ed25519.Verify(k, message, sig) {
+			return errors.New("ed25519 signature does not match")
+		}
+		return nil
+	case *ecdsa.PublicKey:
+		h := hash.New()
+		h.Write(message)
+		if !ecdsa.VerifyASN1(k, h.Sum(nil), sig) {
+			return errors.New("ecdsa signature does not match")
+		}
+		return nil
+	case *rsa.PublicKey:
+		h := hash.New()
+		h.Write(message)
+		return rsa.VerifyPKCS1v15(k, hash, h.Sum(nil), sig)
+	default:
+		return fmt.Errorf("unsupported key type %T", key)
+	}
+}
+
+func fetchJSON(ctx context.Context, target content.Fetcher, desc ocispec.Descriptor, v interface{}) error {
+	data, err := content.FetchAll(ctx, target, desc)
+	if err != nil {
+		return fmt.Errorf("failed to fetch signature manifest %s: %w", desc.Digest, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse signature manifest %s: %w", desc.Digest, err)
+	}
+	return nil
+}
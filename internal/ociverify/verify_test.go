@@ -0,0 +1,535 @@
+package ociverify
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+// push stores data in the store and returns its descriptor.
+func push(t *testing.T, store *memory.Store, mediaType string, data []byte, annotations map[string]string) ocispec.Descriptor {
+	t.Helper()
+	desc := ocispec.Descriptor{
+		MediaType:   mediaType,
+		Digest:      digest.FromBytes(data),
+		Size:        int64(len(data)),
+		Annotations: annotations,
+	}
+	if err := store.Push(context.TODO(), desc, bytes.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+	return desc
+}
+
+// pushManifest stores an image manifest with the given layers.
+func pushManifest(t *testing.T, store *memory.Store, artifactType string, subject *ocispec.Descriptor, layers ...ocispec.Descriptor) ocispec.Descriptor {
+	t.Helper()
+	m := ocispec.Manifest{
+		Versioned:    specs.Versioned{SchemaVersion: 2},
+		MediaType:    ocispec.MediaTypeImageManifest,
+		ArtifactType: artifactType,
+		Config:       ocispec.DescriptorEmptyJSON,
+		Layers:       layers,
+		Subject:      subject,
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := store.Exists(context.TODO(), ocispec.DescriptorEmptyJSON); !ok {
+		push(t, store, ocispec.MediaTypeEmptyJSON, ocispec.DescriptorEmptyJSON.Data, nil)
+	}
+	return push(t, store, ocispec.MediaTypeImageManifest, data, nil)
+}
+
+// newArtifact creates a store with an artifact, which can be signed.
+func newArtifact(t *testing.T) (*memory.Store, ocispec.Descriptor) {
+	t.Helper()
+	store := memory.New()
+	layer := push(t, store, "application/fleet.file", []byte("manifest"), nil)
+	return store, pushManifest(t, store, "application/fleet.manifest", nil, layer)
+}
+
+func newECDSAKey(t *testing.T) (*ecdsa.PrivateKey, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+// cosignSign stores a signature in the format of "cosign sign --key".
+func cosignSign(t *testing.T, store *memory.Store, desc ocispec.Descriptor, key *ecdsa.PrivateKey, signedDigest string) {
+	t.Helper()
+	payload := []byte(`{"critical":{"identity":{"docker-reference":"registry.example.com/bundle"},"image":{"docker-manifest-digest":"` +
+		signedDigest + `"},"type":"cosign container image signature"},"optional":null}`)
+	h := sha256.Sum256(payload)
+	sig, err := ecdsa.SignASN1(rand.Reader, key, h[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	layer := push(t, store, "application/vnd.dev.cosign.simplesigning.v1+json", payload, map[string]string{
+		cosignSignatureAnnotation: base64.StdEncoding.EncodeToString(sig),
+	})
+	sigDesc := pushManifest(t, store, "", nil, layer)
+	tag := strings.Replace(desc.Digest.String(), ":", "-", 1) + cosignSignatureTagSuffix
+	if err := store.Tag(context.TODO(), sigDesc, tag); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// cosignSignBundle stores a sigstore bundle in the format of "cosign sign
+// --new-bundle-format", which signs an in-toto statement about subject.
+func cosignSignBundle(t *testing.T, store *memory.Store, desc ocispec.Descriptor, key *ecdsa.PrivateKey, subject digest.Digest) {
+	t.Helper()
+	statement, _ := json.Marshal(map[string]interface{}{
+		"_type":         "https://in-toto.io/Statement/v1",
+		"subject":       []map[string]interface{}{{"digest": map[string]string{subject.Algorithm().String(): subject.Encoded()}}},
+		"predicateType": "https://sigstore.dev/cosign/sign/v1",
+		"predicate":     map[string]interface{}{},
+	})
+	pae := fmt.Appendf(nil, "DSSEv1 %d %s %d %s", len(inTotoPayloadType), inTotoPayloadType, len(statement), statement)
+	h := sha256.Sum256(pae)
+	sig, err := ecdsa.SignASN1(rand.Reader, key, h[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	pushBundle(t, store, desc, map[string]interface{}{
+		"dsseEnvelope": map[string]interface{}{
+			"payload":     statement,
+			"payloadType": inTotoPayloadType,
+			"signatures":  []map[string]interface{}{{"sig": sig}},
+		},
+	})
+}
+
+// cosignSignBundleMessage stores a sigstore bundle, which signs the manifest
+// of the artifact directly.
+func cosignSignBundleMessage(t *testing.T, store *memory.Store, desc ocispec.Descriptor, key *ecdsa.PrivateKey) {
+	t.Helper()
+	manifest, err := content.FetchAll(context.TODO(), store, desc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := sha256.Sum256(manifest)
+	sig, err := ecdsa.SignASN1(rand.Reader, key, h[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	pushBundle(t, store, desc, map[string]interface{}{
+		"messageSignature": map[string]interface{}{
+			"messageDigest": map[string]interface{}{"algorithm": "SHA2_256", "digest": h[:]},
+			"signature":     sig,
+		},
+	})
+}
+
+func pushBundle(t *testing.T, store *memory.Store, desc ocispec.Descriptor, bundle map[string]interface{}) {
+	t.Helper()
+	bundle["mediaType"] = sigstoreBundleType
+	data, _ := json.Marshal(bundle)
+	layer := push(t, store, sigstoreBundleType, data, nil)
+	pushManifest(t, store, sigstoreBundleType, &desc, layer)
+}
+
+func TestVerifyCosign(t *testing.T) {
+	key, pub := newECDSAKey(t)
+	otherKey, otherPub := newECDSAKey(t)
+
+	tests := map[string]struct {
+		sign    func(*testing.T, *memory.Store, ocispec.Descriptor)
+		keys    []byte
+		wantErr string
+	}{
+		"signed": {
+			sign: func(t *testing.T, s *memory.Store, desc ocispec.Descriptor) {
+				cosignSign(t, s, desc, key, desc.Digest.String())
+			},
+			keys: pub,
+		},
+		"signed with one of multiple keys": {
+			sign: func(t *testing.T, s *memory.Store, desc ocispec.Descriptor) {
+				cosignSign(t, s, desc, key, desc.Digest.String())
+			},
+			keys: append(append([]byte{}, otherPub...), pub...),
+		},
+		"unsigned": {
+			sign:    func(*testing.T, *memory.Store, ocispec.Descriptor) {},
+			keys:    pub,
+			wantErr: "no cosign signature found",
+		},
+		"untrusted key": {
+			sign: func(t *testing.T, s *memory.Store, desc ocispec.Descriptor) {
+				cosignSign(t, s, desc, otherKey, desc.Digest.String())
+			},
+			keys:    pub,
+			wantErr: "not from a trusted key",
+		},
+		"signature for other digest": {
+			sign: func(t *testing.T, s *memory.Store, desc ocispec.Descriptor) {
+				cosignSign(t, s, desc, key, digest.FromString("other").String())
+			},
+			keys:    pub,
+			wantErr: "cosign signature is for digest",
+		},
+		"signed in a sigstore bundle": {
+			sign: func(t *testing.T, s *memory.Store, desc ocispec.Descriptor) {
+				cosignSignBundle(t, s, desc, key, desc.Digest)
+			},
+			keys: pub,
+		},
+		"signed message in a sigstore bundle": {
+			sign: func(t *testing.T, s *memory.Store, desc ocispec.Descriptor) {
+				cosignSignBundleMessage(t, s, desc, key)
+			},
+			keys: pub,
+		},
+		"sigstore bundle from untrusted key": {
+			sign: func(t *testing.T, s *memory.Store, desc ocispec.Descriptor) {
+				cosignSignBundle(t, s, desc, otherKey, desc.Digest)
+			},
+			keys:    pub,
+			wantErr: "not from a trusted key",
+		},
+		"sigstore bundle for other digest": {
+			sign: func(t *testing.T, s *memory.Store, desc ocispec.Descriptor) {
+				cosignSignBundle(t, s, desc, key, digest.FromString("other"))
+			},
+			keys:    pub,
+			wantErr: "statement is not about digest",
+		},
+		"untrusted signature tag and trusted sigstore bundle": {
+			sign: func(t *testing.T, s *memory.Store, desc ocispec.Descriptor) {
+				cosignSign(t, s, desc, otherKey, desc.Digest.String())
+				cosignSignBundle(t, s, desc, key, desc.Digest)
+			},
+			keys: pub,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			store, desc := newArtifact(t)
+			tt.sign(t, store, desc)
+
+			v, err := New(ProviderCosign, tt.keys)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = v.Verify(context.TODO(), store, desc)
+			checkVerificationError(t, err, desc, tt.wantErr)
+		})
+	}
+}
+
+func checkVerificationError(t *testing.T, err error, desc ocispec.Descriptor, wantErr string) {
+	t.Helper()
+	if wantErr == "" {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return
+	}
+	var verr *VerificationError
+	if !errors.As(err, &verr) || !strings.Contains(err.Error(), wantErr) {
+		t.Fatalf("expected verification error containing %q, got %v", wantErr, err)
+	}
+	if verr.Digest != desc.Digest.String() {
+		t.Errorf("expected digest %s in error, got %s", desc.Digest, verr.Digest)
+	}
+}
+
+func newCertificate(t *testing.T, cn string, parent *x509.Certificate, parentKey crypto.Signer, usage []x509.ExtKeyUsage) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           usage,
+		BasicConstraintsValid: true,
+		IsCA:                  parent == nil,
+	}
+	if parent == nil {
+		parent, parentKey = tmpl, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, key.Public(), parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
+// notationSign stores a JWS signature in the format of "notation sign".
+// The header entries replace those of the protected header, nil values
+// remove them.
+func notationSign(t *testing.T, store *memory.Store, desc ocispec.Descriptor, header map[string]interface{}, key *ecdsa.PrivateKey, chain ...*x509.Certificate) {
+	t.Helper()
+	b64 := base64.RawURLEncoding.EncodeToString
+	h := map[string]interface{}{
+		"alg":                          "ES256",
+		"cty":                          notationPayloadType,
+		"crit":                         []string{"io.cncf.notary.signingScheme"},
+		"io.cncf.notary.signingScheme": "notary.x509",
+		"io.cncf.notary.signingTime":   time.Now().Format(time.RFC3339),
+	}
+	for k, v := range header {
+		if v == nil {
+			delete(h, k)
+			continue
+		}
+		h[k] = v
+	}
+	protected, _ := json.Marshal(h)
+	payload, _ := json.Marshal(map[string]interface{}{"targetArtifact": desc})
+	signingInput := b64(protected) + "." + b64(payload)
+	sum := sha256.Sum256([]byte(signingInput))
+	r, s, err := ecdsa.Sign(rand.Reader, key, sum[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+
+	var x5c [][]byte
+	for _, c := range chain {
+		x5c = append(x5c, c.Raw)
+	}
+	envelope, _ := json.Marshal(map[string]interface{}{
+		"payload":   b64(payload),
+		"protected": b64(protected),
+		"header":    map[string]interface{}{"x5c": x5c},
+		"signature": b64(sig),
+	})
+	layer := push(t, store, notationJWSMediaType, envelope, nil)
+	pushManifest(t, store, notationArtifactType, &desc, layer)
+}
+
+func TestVerifyNotation(t *testing.T) {
+	codeSigning := []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning}
+	root, rootKey := newCertificate(t, "root", nil, nil, nil)
+	leaf, leafKey := newCertificate(t, "leaf", root, rootKey, codeSigning)
+	serverLeaf, serverLeafKey := newCertificate(t, "server", root, rootKey, []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth})
+	otherRoot, otherRootKey := newCertificate(t, "other", nil, nil, nil)
+	otherLeaf, otherLeafKey := newCertificate(t, "leaf", otherRoot, otherRootKey, codeSigning)
+
+	rootPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: root.Raw})
+
+	critical := func(extra ...string) []string {
+		return append([]string{"io.cncf.notary.signingScheme"}, extra...)
+	}
+	future := time.Now().Add(time.Hour).Format(time.RFC3339)
+	past := time.Now().Add(-time.Minute).Format(time.RFC3339)
+
+	tests := map[string]struct {
+		sign       func(*testing.T, *memory.Store, ocispec.Descriptor)
+		identities []string
+		// after shifts the time of verification
+		after   time.Duration
+		wantErr string
+	}{
+		"signed": {
+			sign: func(t *testing.T, s *memory.Store, desc ocispec.Descriptor) {
+				notationSign(t, s, desc, nil, leafKey, leaf, root)
+			},
+		},
+		"unsigned": {
+			sign:    func(*testing.T, *memory.Store, ocispec.Descriptor) {},
+			wantErr: "no notation signature found",
+		},
+		"untrusted root": {
+			sign: func(t *testing.T, s *memory.Store, desc ocispec.Descriptor) {
+				notationSign(t, s, desc, nil, otherLeafKey, otherLeaf, otherRoot)
+			},
+			wantErr: "certificate is not trusted",
+		},
+		"certificate not for code signing": {
+			sign: func(t *testing.T, s *memory.Store, desc ocispec.Descriptor) {
+				notationSign(t, s, desc, nil, serverLeafKey, serverLeaf, root)
+			},
+			wantErr: "certificate is not trusted",
+		},
+		"key does not match certificate": {
+			sign: func(t *testing.T, s *memory.Store, desc ocispec.Descriptor) {
+				notationSign(t, s, desc, nil, otherLeafKey, leaf, root)
+			},
+			wantErr: "invalid notation signature",
+		},
+		"not expired": {
+			sign: func(t *testing.T, s *memory.Store, desc ocispec.Descriptor) {
+				notationSign(t, s, desc, map[string]interface{}{"crit": critical(notationExpiry), notationExpiry: future}, leafKey, leaf, root)
+			},
+		},
+		"expired": {
+			sign: func(t *testing.T, s *memory.Store, desc ocispec.Descriptor) {
+				notationSign(t, s, desc, map[string]interface{}{"crit": critical(notationExpiry), notationExpiry: past}, leafKey, leaf, root)
+			},
+			wantErr: "notation signature expired",
+		},
+		"expiry not marked as critical": {
+			sign: func(t *testing.T, s *memory.Store, desc ocispec.Descriptor) {
+				notationSign(t, s, desc, map[string]interface{}{notationExpiry: future}, leafKey, leaf, root)
+			},
+			wantErr: "does not mark \"io.cncf.notary.expiry\" as critical",
+		},
+		"unknown critical header": {
+			sign: func(t *testing.T, s *memory.Store, desc ocispec.Descriptor) {
+				notationSign(t, s, desc, map[string]interface{}{"crit": critical("io.cncf.notary.verificationPlugin"), "io.cncf.notary.verificationPlugin": "plugin"}, leafKey, leaf, root)
+			},
+			wantErr: "unsupported critical header",
+		},
+		"missing critical header": {
+			sign: func(t *testing.T, s *memory.Store, desc ocispec.Descriptor) {
+				notationSign(t, s, desc, map[string]interface{}{"crit": critical(notationExpiry)}, leafKey, leaf, root)
+			},
+			wantErr: "lacks critical header",
+		},
+		"signing scheme not marked as critical": {
+			sign: func(t *testing.T, s *memory.Store, desc ocispec.Descriptor) {
+				notationSign(t, s, desc, map[string]interface{}{"crit": nil}, leafKey, leaf, root)
+			},
+			wantErr: "does not mark \"io.cncf.notary.signingScheme\" as critical",
+		},
+		"unsupported signing scheme": {
+			sign: func(t *testing.T, s *memory.Store, desc ocispec.Descriptor) {
+				notationSign(t, s, desc, map[string]interface{}{notationSigningScheme: "notary.x509.signingAuthority"}, leafKey, leaf, root)
+			},
+			wantErr: "signing scheme \"notary.x509.signingAuthority\" is not supported",
+		},
+		"missing signing time": {
+			sign: func(t *testing.T, s *memory.Store, desc ocispec.Descriptor) {
+				notationSign(t, s, desc, map[string]interface{}{notationSigningTime: nil}, leafKey, leaf, root)
+			},
+			wantErr: "has no signing time",
+		},
+		"signing time in the future": {
+			sign: func(t *testing.T, s *memory.Store, desc ocispec.Descriptor) {
+				notationSign(t, s, desc, map[string]interface{}{notationSigningTime: future}, leafKey, leaf, root)
+			},
+			wantErr: "is in the future",
+		},
+		"certificate expired after signing": {
+			sign: func(t *testing.T, s *memory.Store, desc ocispec.Descriptor) {
+				notationSign(t, s, desc, nil, leafKey, leaf, root)
+			},
+			after: 2 * time.Hour,
+		},
+		"signed before the certificate was valid": {
+			sign: func(t *testing.T, s *memory.Store, desc ocispec.Descriptor) {
+				notationSign(t, s, desc, map[string]interface{}{notationSigningTime: time.Now().Add(-2 * time.Hour).Format(time.RFC3339)}, leafKey, leaf, root)
+			},
+			wantErr: "certificate is not trusted",
+		},
+		"trusted identity": {
+			sign: func(t *testing.T, s *memory.Store, desc ocispec.Descriptor) {
+				notationSign(t, s, desc, nil, leafKey, leaf, root)
+			},
+			identities: []string{"x509.subject: CN=other", "x509.subject: CN=leaf"},
+		},
+		"untrusted identity": {
+			sign: func(t *testing.T, s *memory.Store, desc ocispec.Descriptor) {
+				notationSign(t, s, desc, nil, leafKey, leaf, root)
+			},
+			identities: []string{"x509.subject: CN=leaf, O=example"},
+			wantErr:    "is not a trusted identity",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			store, desc := newArtifact(t)
+			tt.sign(t, store, desc)
+
+			v, err := New(ProviderNotation, rootPEM)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := v.TrustIdentities(tt.identities...); err != nil {
+				t.Fatal(err)
+			}
+			v.now = func() time.Time { return time.Now().Add(tt.after) }
+			err = v.Verify(context.TODO(), store, desc)
+			checkVerificationError(t, err, desc, tt.wantErr)
+		})
+	}
+}
+
+func TestTrustIdentities(t *testing.T) {
+	_, pub := newECDSAKey(t)
+	root, _ := newCertificate(t, "root", nil, nil, nil)
+	rootPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: root.Raw})
+
+	cosign, err := New(ProviderCosign, pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cosign.TrustIdentities("x509.subject: CN=signer"); err == nil {
+		t.Errorf("expected error for identities of cosign signatures")
+	}
+
+	notation, err := New(ProviderNotation, rootPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, identity := range []string{"CN=signer", "x509.subject: CN", "x509.subject: CN=a, CN=b", "x509.subject: CN=a+O=b"} {
+		if err := notation.TrustIdentities(identity); err == nil {
+			t.Errorf("expected error for identity %q", identity)
+		}
+	}
+	if err := notation.TrustIdentities("x509.subject: C=US, O=example, CN=signer"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestNew(t *testing.T) {
+	_, pub := newECDSAKey(t)
+
+	if _, err := New("", pub); err != nil {
+		t.Errorf("expected cosign to be the default provider, got %v", err)
+	}
+	if _, err := New("gpg", pub); err == nil {
+		t.Errorf("expected error for unsupported provider")
+	}
+	if _, err := New(ProviderNotation, pub); err == nil {
+		t.Errorf("expected error without certificates")
+	}
+	if _, err := New(ProviderCosign, []byte("not a key")); err == nil {
+		t.Errorf("expected error without keys")
+	}
+}
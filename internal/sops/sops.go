@@ -0,0 +1,222 @@
+// Package sops decrypts YAML files, which were encrypted with SOPS for age or
+// PGP recipients.
+//
+// Files are parsed and decrypted by upstream SOPS, like its decrypt package
+// does. Instead of reading the private keys from the environment of the
+// process, the data key is decrypted in-process with the keys passed to
+// Decrypt. Comments are dropped from the decrypted output.
+package sops
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"filippo.io/age"
+	"github.com/ProtonMail/go-crypto/openpgp"
+	pgparmor "github.com/ProtonMail/go-crypto/openpgp/armor"
+	sopsapi "github.com/getsops/sops/v3"
+	"github.com/getsops/sops/v3/aes"
+	sopsage "github.com/getsops/sops/v3/age"
+	"github.com/getsops/sops/v3/cmd/sops/common"
+	"github.com/getsops/sops/v3/cmd/sops/formats"
+	"github.com/getsops/sops/v3/config"
+	"github.com/getsops/sops/v3/keyservice"
+	"github.com/getsops/sops/v3/pgp"
+	"go.yaml.in/yaml/v3"
+	"google.golang.org/grpc"
+)
+
+const (
+	// metadataKey is the top level key, which holds the SOPS metadata.
+	metadataKey = "sops"
+
+	// AgeKeySuffix is the suffix of secret keys, which contain age
+	// identities.
+	AgeKeySuffix = ".agekey"
+	// PGPKeySuffix is the suffix of secret keys, which contain armored PGP
+	// private keys.
+	PGPKeySuffix = ".asc"
+)
+
+// ErrNoKey is returned if none of the keys can decrypt the data key.
+var ErrNoKey = errors.New("none of the keys can decrypt the sops data key")
+
+// Keys holds the private keys used to decrypt SOPS files.
+type Keys struct {
+	AgeIdentities []age.Identity
+	PGPKeys       openpgp.EntityList
+}
+
+// ParseKeys parses the keys from the data of a secret. Keys ending in
+// ".agekey" contain age identities, keys ending in ".asc" contain armored PGP
+// private keys. Other keys are ignored.
+func ParseKeys(data map[string][]byte) (*Keys, error) {
+	keys := &Keys{}
+	for name, value := range data {
+		switch filepath.Ext(name) {
+		case AgeKeySuffix:
+			ids, err := age.ParseIdentities(bytes.NewReader(value))
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse age identities in %q: %w", name, err)
+			}
+			keys.AgeIdentities = append(keys.AgeIdentities, ids...)
+		case PGPKeySuffix:
+			entities, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(value))
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse pgp keys in %q: %w", name, err)
+			}
+			keys.PGPKeys = append(keys.PGPKeys, entities...)
+		}
+	}
+	return keys, nil
+}
+
+// IsEncrypted returns true if data is a YAML file with SOPS metadata. It
+// does not validate the metadata, so files with invalid metadata are
+// rejected by Recipients and Decrypt instead of being treated as cleartext.
+func IsEncrypted(data []byte) bool {
+	if !bytes.Contains(data, []byte(metadataKey+":")) {
+		return false
+	}
+	f := struct {
+		Metadata *struct {
+			MAC string `yaml:"mac"`
+		} `yaml:"sops"`
+	}{}
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return false
+	}
+	return f.Metadata != nil && f.Metadata.MAC != ""
+}
+
+// Recipients returns the age recipients and PGP fingerprints the data key of
+// the SOPS encrypted data is encrypted for.
+func Recipients(data []byte) ([]string, error) {
+	tree, err := load(data)
+	if err != nil {
+		return nil, err
+	}
+	var recipients []string
+	for _, group := range tree.Metadata.KeyGroups {
+		for _, k := range group {
+			switch k := k.(type) {
+			case *sopsage.MasterKey:
+				recipients = append(recipients, k.Recipient)
+			case *pgp.MasterKey:
+				recipients = append(recipients, k.Fingerprint)
+			}
+		}
+	}
+	return recipients, nil
+}
+
+// Decrypt decrypts the SOPS encrypted YAML data with the keys and verifies
+// its MAC. Files with multiple key groups are decrypted, if the keys can
+// decrypt the parts of the data key of enough groups. The result does not
+// contain the SOPS metadata.
+func Decrypt(data []byte, keys *Keys) ([]byte, error) {
+	tree, err := load(data)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := tree.Metadata.GetDataKeyWithKeyServices([]keyservice.KeyServiceClient{keyService{keys: keys}}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrNoKey, err)
+	}
+
+	cipher := aes.NewCipher()
+	mac, err := tree.Decrypt(key, cipher)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt sops file: %w", err)
+	}
+	originalMAC, err := cipher.Decrypt(tree.Metadata.MessageAuthenticationCode, key, tree.Metadata.LastModified.Format(time.RFC3339))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt sops mac: %w", err)
+	}
+	if originalMAC != mac {
+		return nil, errors.New("failed to verify sops mac, the file was modified")
+	}
+
+	return store().EmitPlainFile(tree.Branches)
+}
+
+func store() common.Store {
+	return common.StoreForFormat(formats.Yaml, config.NewStoresConfig())
+}
+
+// load parses the SOPS encrypted YAML data. It returns an error if the data
+// has no SOPS metadata.
+func load(data []byte) (*sopsapi.Tree, error) {
+	tree, err := store().LoadEncryptedFile(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse sops file: %w", err)
+	}
+	if tree.Metadata.MessageAuthenticationCode == "" {
+		return nil, errors.New("sops metadata not found")
+	}
+	return &tree, nil
+}
+
+// keyService decrypts the data key with the keys, instead of the keys in the
+// environment of the process like the local key service of SOPS.
+type keyService struct {
+	keys *Keys
+}
+
+var _ keyservice.KeyServiceClient = keyService{}
+
+func (s keyService) Encrypt(context.Context, *keyservice.EncryptRequest, ...grpc.CallOption) (*keyservice.EncryptResponse, error) {
+	return nil, errors.New("encrypting sops data keys is not supported")
+}
+
+func (s keyService) Decrypt(_ context.Context, req *keyservice.DecryptRequest, _ ...grpc.CallOption) (*keyservice.DecryptResponse, error) {
+	var (
+		plaintext []byte
+		err       error
+	)
+	switch k := req.GetKey().GetKeyType().(type) {
+	case *keyservice.Key_AgeKey:
+		plaintext, err = s.decryptAge(req.GetCiphertext())
+	case *keyservice.Key_PgpKey:
+		plaintext, err = s.decryptPGP(req.GetCiphertext())
+	default:
+		err = fmt.Errorf("key type %T is not supported", k)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &keyservice.DecryptResponse{Plaintext: plaintext}, nil
+}
+
+func (s keyService) decryptAge(ciphertext []byte) ([]byte, error) {
+	// age master keys without identities fall back to the keys in the
+	// environment
+	if len(s.keys.AgeIdentities) == 0 {
+		return nil, errors.New("no age identities")
+	}
+	key := &sopsage.MasterKey{EncryptedKey: string(ciphertext)}
+	sopsage.ParsedIdentities(s.keys.AgeIdentities).ApplyToMasterKey(key)
+	return key.Decrypt()
+}
+
+func (s keyService) decryptPGP(ciphertext []byte) ([]byte, error) {
+	if len(s.keys.PGPKeys) == 0 {
+		return nil, errors.New("no pgp keys")
+	}
+	block, err := pgparmor.Decode(strings.NewReader(string(ciphertext)))
+	if err != nil {
+		return nil, err
+	}
+	md, err := openpgp.ReadMessage(block.Body, s.keys.PGPKeys, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(md.UnverifiedBody)
+}
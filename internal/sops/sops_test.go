@@ -0,0 +1,142 @@
+package sops
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"go.yaml.in/yaml/v3"
+)
+
+// The files in testdata were encrypted with sops 3.9.4 from secret.yaml.
+func readFile(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func testKeys(t *testing.T) *Keys {
+	t.Helper()
+	keys, err := ParseKeys(map[string][]byte{
+		"age.agekey": readFile(t, "age.agekey"),
+		"pgp.asc":    readFile(t, "pgp.asc"),
+		"README":     []byte("ignored"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys.AgeIdentities) != 1 || len(keys.PGPKeys) != 1 {
+		t.Fatalf("expected one age and one pgp key, got %+v", keys)
+	}
+	return keys
+}
+
+func documents(t *testing.T, data []byte) []interface{} {
+	t.Helper()
+	var docs []interface{}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc interface{}
+		if err := dec.Decode(&doc); err != nil {
+			break
+		}
+		docs = append(docs, doc)
+	}
+	return docs
+}
+
+func TestDecrypt(t *testing.T) {
+	keys := testKeys(t)
+	expected := documents(t, readFile(t, "secret.yaml"))
+
+	for _, name := range []string{"age.yaml", "pgp.yaml", "maconly.yaml", "keygroups.yaml"} {
+		t.Run(name, func(t *testing.T) {
+			data := readFile(t, name)
+			if !IsEncrypted(data) {
+				t.Fatalf("expected %s to be encrypted", name)
+			}
+
+			out, err := Decrypt(data, keys)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Contains(string(out), "ENC[") || strings.Contains(string(out), "sops:") {
+				t.Errorf("expected decrypted output without sops data, got\n%s", out)
+			}
+			if actual := documents(t, out); !reflect.DeepEqual(expected, actual) {
+				t.Errorf("expected %v, got %v", expected, actual)
+			}
+		})
+	}
+}
+
+func TestDecryptErrors(t *testing.T) {
+	data := readFile(t, "age.yaml")
+
+	id, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Decrypt(data, &Keys{AgeIdentities: []age.Identity{id}}); !errors.Is(err, ErrNoKey) {
+		t.Errorf("expected error for unknown key, got %v", err)
+	}
+	if _, err := Decrypt(data, &Keys{}); !errors.Is(err, ErrNoKey) {
+		t.Errorf("expected error without keys, got %v", err)
+	}
+
+	// both key groups are needed to recover the data key
+	keys := testKeys(t)
+	keys.PGPKeys = nil
+	if _, err := Decrypt(readFile(t, "keygroups.yaml"), keys); !errors.Is(err, ErrNoKey) {
+		t.Errorf("expected error for missing key group, got %v", err)
+	}
+
+	// unencrypted values are protected by the mac
+	modified := bytes.Replace(data, []byte("name: db"), []byte("name: dc"), 1)
+	if _, err := Decrypt(modified, testKeys(t)); err == nil || !strings.Contains(err.Error(), "failed to verify sops mac") {
+		t.Errorf("expected mac error for modified file, got %v", err)
+	}
+
+	if _, err := Decrypt(readFile(t, "secret.yaml"), testKeys(t)); err == nil {
+		t.Errorf("expected error for unencrypted file")
+	}
+}
+
+func TestIsEncrypted(t *testing.T) {
+	for name, data := range map[string]string{
+		"plain":         string(readFile(t, "secret.yaml")),
+		"invalid yaml":  "{{ .Values.foo }}",
+		"sops key only": "sops: true",
+		"no mac":        "sops:\n  version: 3.9.4",
+	} {
+		if IsEncrypted([]byte(data)) {
+			t.Errorf("expected %s not to be encrypted", name)
+		}
+	}
+}
+
+func TestRecipients(t *testing.T) {
+	recipients, err := Recipients(readFile(t, "pgp.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(recipients, []string{"BEF3E3FB03DEA0AC46A6179918AD2E3306EDADC9"}) {
+		t.Errorf("unexpected recipients %v", recipients)
+	}
+
+	recipients, err = Recipients(readFile(t, "keygroups.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(recipients, []string{"age1fyejjxc9nhysxzvanel3grgms0xhd2h57ugmqj2h7hjnjy35z5jq8g3ngf", "BEF3E3FB03DEA0AC46A6179918AD2E3306EDADC9"}) {
+		t.Errorf("unexpected recipients %v", recipients)
+	}
+}
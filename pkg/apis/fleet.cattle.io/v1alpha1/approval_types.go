@@ -0,0 +1,72 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func init() {
+	InternalSchemeBuilder.Register(&Approval{}, &ApprovalList{})
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+// +kubebuilder:printcolumn:name="Bundle",type=string,JSONPath=`.spec.bundleName`
+// +kubebuilder:printcolumn:name="Gate",type=string,JSONPath=`.spec.gate`
+// +kubebuilder:printcolumn:name="Generation",type=integer,JSONPath=`.spec.bundleGeneration`
+// +kubebuilder:printcolumn:name="Claimed-Approver",type=string,JSONPath=`.spec.claimedApprover`
+
+// Approval approves the rollout of a bundle past a gate, i.e. a partition
+// or a bundle target, which requires an approval. The bundle's staged
+// deployments are only promoted to the clusters behind the gate, once an
+// approval for the bundle's current generation exists. Anyone allowed to
+// create approvals in the bundle's namespace can approve its rollout.
+type Approval struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ApprovalSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ApprovalList contains a list of Approval
+type ApprovalList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Approval `json:"items"`
+}
+
+type ApprovalSpec struct {
+	// BundleName is the name of the approved bundle, in the namespace of
+	// the approval.
+	BundleName string `json:"bundleName"`
+	// Gate is the name of the approved partition or bundle target.
+	Gate string `json:"gate"`
+	// BundleGeneration is the approved generation of the bundle. Any
+	// change to the bundle requires a new approval.
+	BundleGeneration int64 `json:"bundleGeneration"`
+	// ClaimedApprover is the name of the approver, as claimed by the
+	// creator of the approval. It is not verified and only recorded in the
+	// bundle's partition status for reference.
+	// +nullable
+	ClaimedApprover string `json:"claimedApprover,omitempty"`
+}
+
+// ApprovalRecord records the approval of a gate in a partition status.
+type ApprovalRecord struct {
+	// Gate is the name of the approved partition or bundle target.
+	Gate string `json:"gate"`
+	// Name is the name of the approval resource.
+	// +nullable
+	Name string `json:"name,omitempty"`
+	// ClaimedApprover is the unverified name of the approver, copied from
+	// the approval.
+	// +nullable
+	ClaimedApprover string `json:"claimedApprover,omitempty"`
+	// ApprovedAt is the time the approval was created.
+	// +nullable
+	ApprovedAt *metav1.Time `json:"approvedAt,omitempty"`
+	// BundleGeneration is the approved generation of the bundle.
+	BundleGeneration int64 `json:"bundleGeneration,omitempty"`
+}
@@ -40,6 +40,10 @@ const (
 	// of etcd.
 	SecretTypeOCIStorage = "fleet.cattle.io/bundle-oci-storage/v1alpha1"
 
+	// SecretTypeBundleRollback is the secret type used to store the
+	// deployment IDs and options of a known-good deployment of a bundle.
+	SecretTypeBundleRollback = "fleet.cattle.io/bundle-rollback/v1alpha1"
+
 	// InternalSecretLabel is a label added to any secret created by Fleet to propagate Bundle or
 	// BundleDeployment secrets storing credential details for OCI storage or HelmOps.
 	InternalSecretLabel = "fleet.cattle.io/bundle-internal-secret"
@@ -146,6 +150,16 @@ type BundleRef struct {
 	// Selector matching bundle's labels.
 	// +nullable
 	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+	// ClusterName scopes the dependency to the named cluster in the
+	// bundle's namespace. The dependency must be ready on that cluster,
+	// instead of on the cluster the bundle is deployed to.
+	// +nullable
+	ClusterName string `json:"clusterName,omitempty"`
+	// ClusterSelector scopes the dependency to the clusters in the bundle's
+	// namespace matching the selector. The dependency must be ready on all
+	// of them, instead of on the cluster the bundle is deployed to.
+	// +nullable
+	ClusterSelector *metav1.LabelSelector `json:"clusterSelector,omitempty"`
 }
 
 // BundleResource represents the content of a single resource from the bundle, like a YAML manifest.
@@ -191,6 +205,34 @@ type RolloutStrategy struct {
 	// autoPartitionSize.
 	// +nullable
 	Partitions []Partition `json:"partitions,omitempty"`
+	// AutoRollback rolls back the clusters to the last known-good
+	// deployment of the bundle, if too many clusters fail to deploy the
+	// current version.
+	// +nullable
+	AutoRollback *AutoRollback `json:"autoRollback,omitempty"`
+}
+
+// AutoRollback defines when a bundle is rolled back. A known-good deployment
+// is a version of the bundle, which was ready on all targeted clusters.
+// Bundles, whose contents are stored in an OCI registry or which are
+// deployed by a HelmOp, are not rolled back.
+type AutoRollback struct {
+	// A number or percentage of clusters that can fail to deploy the
+	// current version of the bundle, before the bundle is rolled back. A
+	// cluster fails, if its deployment is in the ErrApplied state, or is
+	// not ready within the progress deadline.
+	// default: 0
+	// +nullable
+	MaxFailures *intstr.IntOrString `json:"maxFailures,omitempty"`
+	// ProgressDeadline is the duration after a new version of the bundle
+	// was first rolled out, after which not ready clusters count as failed.
+	// default: 10m
+	// +nullable
+	ProgressDeadline *metav1.Duration `json:"progressDeadline,omitempty"`
+	// HistoryLimit is the number of known-good deployments to keep.
+	// default: 3
+	// +kubebuilder:validation:Minimum=1
+	HistoryLimit int `json:"historyLimit,omitempty"`
 }
 
 // Partition defines a separate rollout strategy for a set of clusters.
@@ -211,6 +253,54 @@ type Partition struct {
 	// Selector matching cluster group labels to include in this partition
 	// +nullable
 	ClusterGroupSelector *metav1.LabelSelector `json:"clusterGroupSelector,omitempty"`
+	// PauseAfter is the duration to wait, once all clusters of this partition
+	// are up-to-date and available, before the rollout continues with the
+	// next partition.
+	// +nullable
+	PauseAfter *metav1.Duration `json:"pauseAfter,omitempty"`
+	// Analysis defines checks which must pass, once all clusters of this
+	// partition are up-to-date and available and the pause is over, before
+	// the rollout continues with the next partition. A failing check halts
+	// the rollout until the bundle is updated.
+	// +nullable
+	Analysis *PartitionAnalysis `json:"analysis,omitempty"`
+	// RequireApproval holds back the rollout to the clusters of this
+	// partition and of all following partitions, until an Approval for
+	// the partition's name and the bundle's current generation exists.
+	RequireApproval bool `json:"requireApproval,omitempty"`
+}
+
+// PartitionAnalysis defines the checks run between partitions of a rollout.
+// If several checks are configured, all of them must pass.
+type PartitionAnalysis struct {
+	// Prometheus runs a query against a Prometheus API.
+	// +nullable
+	Prometheus *PrometheusAnalysis `json:"prometheus,omitempty"`
+	// Job references a Job, deployed by the bundle, which must complete
+	// successfully on each cluster of the partition.
+	// +nullable
+	Job *JobAnalysis `json:"job,omitempty"`
+}
+
+// PrometheusAnalysis is a check based on a Prometheus query. The check passes
+// if the query returns at least one sample and all samples are non-zero.
+// Comparison queries, e.g. "rate(errors[5m]) < 0.01", return no samples if
+// the comparison is false.
+type PrometheusAnalysis struct {
+	// Address is the URL of the Prometheus server, e.g. "http://prometheus.monitoring:9090".
+	Address string `json:"address"`
+	// Query is the PromQL query to evaluate.
+	Query string `json:"query"`
+}
+
+// JobAnalysis is a check based on a Job, which is part of the bundle's
+// resources.
+type JobAnalysis struct {
+	// Name of the Job.
+	Name string `json:"name"`
+	// Namespace of the Job, defaults to the namespace the bundle is deployed to.
+	// +nullable
+	Namespace string `json:"namespace,omitempty"`
 }
 
 // BundleTargetRestriction is used internally by Fleet and should not be modified.
@@ -227,6 +317,42 @@ type BundleTargetRestriction struct {
 	ClusterGroup string `json:"clusterGroup,omitempty"`
 	// +nullable
 	ClusterGroupSelector *metav1.LabelSelector `json:"clusterGroupSelector,omitempty"`
+	// +nullable
+	ClusterFactSelector *ClusterFactSelector `json:"clusterFactSelector,omitempty"`
+}
+
+// ClusterFactSelector selects clusters by the facts their agents report.
+// All fields, which are set, must match. Clusters, which have not reported
+// facts yet, do not match.
+type ClusterFactSelector struct {
+	// KubernetesVersion is a semver constraint for the Kubernetes version,
+	// e.g. ">= 1.29". Pre-release parts of the version are ignored.
+	// +optional
+	KubernetesVersion string `json:"kubernetesVersion,omitempty"`
+	// CRDGroups are API groups, which installed custom resource
+	// definitions must provide, e.g. "cert-manager.io".
+	// +nullable
+	// +optional
+	CRDGroups []string `json:"crdGroups,omitempty"`
+	// StorageClasses are the names of storage classes, which must exist.
+	// +nullable
+	// +optional
+	StorageClasses []string `json:"storageClasses,omitempty"`
+	// Architectures are CPU architectures, which nodes must provide.
+	// +nullable
+	// +optional
+	Architectures []string `json:"architectures,omitempty"`
+	// OperatingSystems are operating systems, which nodes must provide.
+	// +nullable
+	// +optional
+	OperatingSystems []string `json:"operatingSystems,omitempty"`
+	// CloudProviders are the accepted cloud providers.
+	// +nullable
+	// +optional
+	CloudProviders []string `json:"cloudProviders,omitempty"`
+	// MinNodeCount is the minimum number of nodes.
+	// +optional
+	MinNodeCount int `json:"minNodeCount,omitempty"`
 }
 
 // BundleTarget declares clusters to deploy to. Fleet will merge the
@@ -253,8 +379,16 @@ type BundleTarget struct {
 	// ClusterGroupSelector is a selector to match cluster groups.
 	// +nullable
 	ClusterGroupSelector *metav1.LabelSelector `json:"clusterGroupSelector,omitempty"`
+	// ClusterFactSelector further refines the selection by the facts,
+	// which the agents report for their clusters.
+	// +nullable
+	ClusterFactSelector *ClusterFactSelector `json:"clusterFactSelector,omitempty"`
 	// DoNotDeploy if set to true, will not deploy to this target.
 	DoNotDeploy bool `json:"doNotDeploy,omitempty"`
+	// RequireApproval holds back the rollout to the clusters matched by
+	// this target, until an Approval for the target's name and the
+	// bundle's current generation exists.
+	RequireApproval bool `json:"requireApproval,omitempty"`
 	// NamespaceLabels are labels that will be appended to the namespace created by Fleet.
 	// +nullable
 	NamespaceLabels map[string]string `json:"namespaceLabels,omitempty"`
@@ -328,6 +462,18 @@ const (
 	// indicates that its resources are ready and the dependencies are
 	// fulfilled.
 	BundleConditionReady = "Ready"
+	// BundleConditionRolledBack indicates that the current version of the
+	// bundle failed and the clusters were rolled back to a known-good
+	// deployment.
+	BundleConditionRolledBack = "RolledBack"
+	// BundleConditionDependencies is false, if the dependencies of the
+	// bundle form a cycle or are not ready on some clusters yet. The
+	// deployments on these clusters are held back.
+	BundleConditionDependencies = "Dependencies"
+	// BundleConditionDenyWindows is false, if a Deny schedule targeting the
+	// bundle is invalid. All deployments are held back, until the schedule
+	// is fixed.
+	BundleConditionDenyWindows = "DenyWindows"
 	// BundleDeploymentConditionReady is the condition that displays for
 	// status in general and it is used for the readiness of resources.
 	BundleDeploymentConditionReady = "Ready"
@@ -338,6 +484,9 @@ const (
 	// succeeded.
 	BundleDeploymentConditionDeployed  = "Deployed"
 	BundleDeploymentConditionMonitored = "Monitored"
+	// BundleDeploymentConditionVerified is false, if the signature of the
+	// bundle deployment's OCI artifact could not be verified.
+	BundleDeploymentConditionVerified = "Verified"
 )
 
 type BundleStatus struct {
@@ -390,6 +539,52 @@ type BundleStatus struct {
 	ObservedGeneration int64 `json:"observedGeneration"`
 	// ResourcesSHA256Sum corresponds to the JSON serialization of the .Spec.Resources field
 	ResourcesSHA256Sum string `json:"resourcesSha256Sum,omitempty"`
+	// Rollback contains the known-good deployments and the state of the
+	// automatic rollback. Only set if auto rollback is enabled.
+	// +nullable
+	Rollback *RollbackStatus `json:"rollback,omitempty"`
+	// DenyWindow is the active deny window of a Deny schedule, which holds
+	// back the rollout of the bundle to at least one of its clusters.
+	// +nullable
+	DenyWindow *DenyWindow `json:"denyWindow,omitempty"`
+}
+
+// RollbackStatus tracks the rollout of the current version of a bundle and
+// the known-good deployments it can be rolled back to.
+type RollbackStatus struct {
+	// ManifestID is the manifest ID of the current version of the bundle.
+	// +nullable
+	ManifestID string `json:"manifestID,omitempty"`
+	// DeploymentID identifies the current version of the bundle by its
+	// manifest ID and a hash of the options of the bundle and its targets.
+	// +nullable
+	DeploymentID string `json:"deploymentID,omitempty"`
+	// StartedAt is the time at which the rollout of the current version
+	// was first observed.
+	// +nullable
+	StartedAt metav1.Time `json:"startedAt,omitempty"`
+	// History lists known-good deployments, newest first.
+	// +nullable
+	History []RollbackHistory `json:"history,omitempty"`
+	// RolledBackTo is the deployment ID of the known-good deployment the
+	// clusters were rolled back to, while the current version is failing.
+	// +nullable
+	RolledBackTo string `json:"rolledBackTo,omitempty"`
+}
+
+// RollbackHistory is a known-good deployment of a bundle.
+type RollbackHistory struct {
+	// ManifestID is the ID of the Content resource of the deployment.
+	ManifestID string `json:"manifestID"`
+	// DeploymentID identifies the version of the bundle, see
+	// RollbackStatus.DeploymentID.
+	DeploymentID string `json:"deploymentID"`
+	// SecretName is the name of the secret, which holds the deployment IDs
+	// and options of the clusters.
+	SecretName string `json:"secretName"`
+	// ReadyAt is the time the deployment was found ready on all clusters.
+	// +nullable
+	ReadyAt metav1.Time `json:"readyAt,omitempty"`
 }
 
 // ResourceKey lists resources, which will likely be deployed.
@@ -434,6 +629,24 @@ type PartitionStatus struct {
 	Unavailable int `json:"unavailable,omitempty"`
 	// Summary is a summary state for the partition, calculated over its non-ready resources.
 	Summary BundleSummary `json:"summary,omitempty"`
+	// ReadySince is the time at which all clusters of the partition were
+	// found up-to-date and available. It is only set for partitions with a
+	// pause or an analysis.
+	// +nullable
+	ReadySince *metav1.Time `json:"readySince,omitempty"`
+	// AnalysisPassed is true if the analysis of the partition passed for
+	// the current deployments.
+	AnalysisPassed bool `json:"analysisPassed,omitempty"`
+	// Halted is true if the analysis of the partition failed. The rollout
+	// does not continue with the next partitions, until the bundle is updated.
+	Halted bool `json:"halted,omitempty"`
+	// Message explains why the rollout waits for, or is halted by, the partition.
+	// +nullable
+	Message string `json:"message,omitempty"`
+	// Approvals records who approved the gates of the partition and its
+	// targets for the current generation of the bundle, and when.
+	// +nullable
+	Approvals []ApprovalRecord `json:"approvals,omitempty"`
 }
 
 type BundleHelmOptions struct {
@@ -28,6 +28,13 @@ const (
 
 	BundleDeploymentOwnershipLabel = "fleet.cattle.io/bundledeployment"
 	ContentNameLabel               = "fleet.cattle.io/content-name"
+	// BundleTargetLabel is the name of the bundle target, whose options
+	// the bundledeployment uses.
+	BundleTargetLabel = "fleet.cattle.io/bundle-target"
+	// ContentRollbackLabelPrefix prefixes labels on contents, which are
+	// kept as known-good deployments of a bundle, followed by a hash of the
+	// bundle's namespace and name.
+	ContentRollbackLabelPrefix = "fleet.cattle.io/rollback-"
 )
 
 const IgnoreOp = "ignore"
@@ -121,6 +128,12 @@ type BundleDeploymentOptions struct {
 	// namespace.
 	DownstreamResources []DownstreamResource `json:"downstreamResources,omitempty"`
 
+	// PostRender is a pipeline of transformations, which the agent applies
+	// in order to the rendered resources, after Helm, kustomize and
+	// overlays, for every bundle style.
+	// +nullable
+	PostRender []PostRenderStep `json:"postRender,omitempty"`
+
 	// Overwrites indicates which resources, if any, come from this bundle and overwrite another existing bundle.
 	// This flag is set internally by Fleet, and should not be altered by users.
 	Overwrites []OverwrittenResource `json:"overwrites,omitempty"`
@@ -137,6 +150,52 @@ type GitOpsBundleDeploymentOptions struct {
 	// kustomization.yaml file.
 	// +nullable
 	Kustomize *KustomizeOptions `json:"kustomize,omitempty"`
+
+	// Jsonnet options, if set the manifests are generated by evaluating a
+	// Jsonnet file of the bundle.
+	// +nullable
+	Jsonnet *JsonnetOptions `json:"jsonnet,omitempty"`
+
+	// CUE options, if set the manifests are generated by evaluating a CUE
+	// package of the bundle.
+	// +nullable
+	CUE *CUEOptions `json:"cue,omitempty"`
+}
+
+// JsonnetOptions for a deployment.
+type JsonnetOptions struct {
+	// File is the path of the Jsonnet file to evaluate, relative to the
+	// bundle's root. Defaults to main.jsonnet.
+	// +optional
+	File string `json:"file,omitempty"`
+	// LibraryPaths are directories, relative to the bundle's root, which
+	// are searched for imported files.
+	// +nullable
+	LibraryPaths []string `json:"libraryPaths,omitempty"`
+	// TopLevelArgs are passed to the top-level function of the Jsonnet
+	// file. String values can use the same templating as Helm values, e.g.
+	// "${ .ClusterLabels.env }", and are rendered per target cluster.
+	// +nullable
+	// +kubebuilder:validation:XPreserveUnknownFields
+	TopLevelArgs *GenericMap `json:"topLevelArgs,omitempty"`
+}
+
+// CUEOptions for a deployment.
+type CUEOptions struct {
+	// Dir is the directory of the CUE package, relative to the bundle's
+	// root. Defaults to the bundle's root.
+	// +optional
+	Dir string `json:"dir,omitempty"`
+	// Expression selects the value which contains the resources, e.g.
+	// "objects". Defaults to the whole package.
+	// +optional
+	Expression string `json:"expression,omitempty"`
+	// TopLevelArgs are unified with the "args" field of the CUE package.
+	// String values can use the same templating as Helm values, e.g.
+	// "${ .ClusterLabels.env }", and are rendered per target cluster.
+	// +nullable
+	// +kubebuilder:validation:XPreserveUnknownFields
+	TopLevelArgs *GenericMap `json:"topLevelArgs,omitempty"`
 }
 
 type DiffOptions struct {
@@ -145,6 +204,66 @@ type DiffOptions struct {
 	ComparePatches []ComparePatch `json:"comparePatches,omitempty"`
 }
 
+// PostRenderStep transforms the rendered resources matched by its selector.
+// If a step sets several transformations, they are applied in the order of
+// the fields: patch, images, labels, annotations and namespace.
+type PostRenderStep struct {
+	// Selector matches the resources to transform. If empty, all
+	// resources are transformed.
+	// +nullable
+	Selector *PostRenderSelector `json:"selector,omitempty"`
+	// Patch is a JSON6902 patch, given as a list of operations, or a
+	// strategic merge patch, given as a partial resource, in YAML or JSON.
+	// +optional
+	Patch string `json:"patch,omitempty"`
+	// Images rewrites the registries of container images.
+	// +nullable
+	Images []ImageRegistryRewrite `json:"images,omitempty"`
+	// Labels are added to the resources, existing labels are overwritten.
+	// +nullable
+	Labels map[string]string `json:"labels,omitempty"`
+	// Annotations are added to the resources, existing annotations are
+	// overwritten.
+	// +nullable
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// Namespace replaces the namespace of namespaced resources.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// PostRenderSelector matches rendered resources. Empty fields match any
+// value.
+type PostRenderSelector struct {
+	// Kind is the kind of the resources to match.
+	// +optional
+	Kind string `json:"kind,omitempty"`
+	// APIVersion is the apiVersion of the resources to match.
+	// +optional
+	APIVersion string `json:"apiVersion,omitempty"`
+	// Namespace is the namespace of the resources to match.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	// Name is the name of the resources to match.
+	// +optional
+	Name string `json:"name,omitempty"`
+	// LabelSelector matches the labels of the resources.
+	// +nullable
+	LabelSelector *metav1.LabelSelector `json:"labelSelector,omitempty"`
+}
+
+// ImageRegistryRewrite replaces the registry of container images.
+type ImageRegistryRewrite struct {
+	// From is the registry to replace, optionally with a path, e.g.
+	// "docker.io" or "ghcr.io/org". Images without a registry are from
+	// docker.io.
+	// +kubebuilder:validation:MinLength=1
+	From string `json:"from"`
+	// To is the registry to use instead, optionally with a path, e.g.
+	// "registry.example.com/mirror".
+	// +kubebuilder:validation:MinLength=1
+	To string `json:"to"`
+}
+
 // ComparePatch matches a resource and removes fields from the check for modifications.
 type ComparePatch struct {
 	// Kind is the kind of the resource to match.
@@ -200,8 +319,39 @@ type KustomizeOptions struct {
 	// a kustomization.yaml file.
 	// +nullable
 	Dir string `json:"dir,omitempty"`
+	// EnableHelm enables the inflation of Helm charts listed in the
+	// helmCharts field of kustomization files. Charts are rendered
+	// in-process and are not downloaded, they must be part of the bundle,
+	// below the chartHome directory of the kustomization.
+	// +optional
+	EnableHelm bool `json:"enableHelm,omitempty"`
+	// LoadRestrictions restricts which files kustomization files can load.
+	// "RootOnly", the default, restricts them to their own directory,
+	// "None" allows loading any file of the bundle.
+	// +kubebuilder:validation:Enum=RootOnly;None
+	// +optional
+	LoadRestrictions KustomizeLoadRestrictions `json:"loadRestrictions,omitempty"`
+	// EnableAlphaPlugins enables KRM function transformers. Only functions
+	// built into Fleet are supported, they run in-process and never as
+	// containers or executables. Built in is kpt's apply-setters function,
+	// configured by transformers of kind ApplySetters in apiVersion
+	// fn.kpt.dev/v1alpha1.
+	// +optional
+	EnableAlphaPlugins bool `json:"enableAlphaPlugins,omitempty"`
 }
 
+// KustomizeLoadRestrictions restricts which files kustomize can load.
+type KustomizeLoadRestrictions string
+
+const (
+	// KustomizeLoadRestrictionsRootOnly restricts kustomization files to
+	// load files from their own directory and below.
+	KustomizeLoadRestrictionsRootOnly KustomizeLoadRestrictions = "RootOnly"
+	// KustomizeLoadRestrictionsNone allows kustomization files to load
+	// any file of the bundle.
+	KustomizeLoadRestrictionsNone KustomizeLoadRestrictions = "None"
+)
+
 // HelmOptions for the deployment. For Helm-based bundles, all options can be
 // used, otherwise some options are ignored. For example ReleaseName works with
 // all bundle types.
@@ -278,6 +428,35 @@ type HelmOptions struct {
 
 	// DisableDependencyUpdate allows skipping chart dependencies update
 	DisableDependencyUpdate bool `json:"disableDependencyUpdate,omitempty"`
+
+	// Verification enables the verification of the signature of an OCI
+	// chart. The chart is not deployed, if it is not signed by a trusted key.
+	// +nullable
+	Verification *OCIVerification `json:"verification,omitempty"`
+}
+
+// OCIVerification configures the verification of the signatures of OCI
+// artifacts.
+type OCIVerification struct {
+	// Provider is the tool which signed the artifact, either "cosign" or
+	// "notation". Defaults to "cosign".
+	// +kubebuilder:validation:Enum=cosign;notation
+	// +optional
+	Provider string `json:"provider,omitempty"`
+
+	// SecretName is the name of a secret in the namespace of the GitRepo or
+	// HelmOp, which holds the trusted keys. For cosign, the secret contains
+	// PEM encoded public keys. For notation, it contains PEM encoded root
+	// certificates.
+	// +kubebuilder:validation:MinLength=1
+	SecretName string `json:"secretName"`
+
+	// TrustedIdentities restricts notation signatures to signing
+	// certificates with one of the given subjects, as in notation trust
+	// policies, e.g. "x509.subject: C=US, O=example, CN=signer". Signing
+	// certificates of any subject are trusted, if empty.
+	// +optional
+	TrustedIdentities []string `json:"trustedIdentities,omitempty"`
 }
 
 // GitOpsHelmOptions contains Helm options which only make sense for GitOps.
@@ -418,6 +597,52 @@ type BundleDeploymentStatus struct {
 	Resources []BundleDeploymentResource `json:"resources,omitempty"`
 	// ResourceCounts contains the number of resources in each state.
 	ResourceCounts ResourceCounts `json:"resourceCounts,omitempty"`
+	// Plan lists the changes deploying the staged deployment would make.
+	// It is only computed while the bundle deployment is paused.
+	// +nullable
+	Plan *BundleDeploymentPlan `json:"plan,omitempty"`
+}
+
+// BundleDeploymentPlan is the result of a dry run of the staged deployment
+// against the resources in the downstream cluster.
+type BundleDeploymentPlan struct {
+	// StagedDeploymentID is the deployment ID the plan was computed for.
+	// +nullable
+	StagedDeploymentID string `json:"stagedDeploymentID,omitempty"`
+	// Create is the number of resources, which would be created.
+	Create int `json:"create,omitempty"`
+	// Update is the number of resources, which would be updated.
+	Update int `json:"update,omitempty"`
+	// Delete is the number of resources, which would be deleted.
+	Delete int `json:"delete,omitempty"`
+	// Resources lists the resources, which would be changed. The list is
+	// truncated to keep the status small.
+	// +nullable
+	Resources []PlannedResource `json:"resources,omitempty"`
+	// IncompleteState is true if the list of resources was truncated.
+	IncompleteState bool `json:"incompleteState,omitempty"`
+	// Error is set if the plan could not be computed.
+	// +nullable
+	Error string `json:"error,omitempty"`
+}
+
+// PlannedResource is a resource, which would be changed by deploying the
+// staged deployment.
+type PlannedResource struct {
+	// Action is one of "create", "update" or "delete".
+	Action string `json:"action"`
+	// +nullable
+	Kind string `json:"kind,omitempty"`
+	// +nullable
+	APIVersion string `json:"apiVersion,omitempty"`
+	// +nullable
+	Namespace string `json:"namespace,omitempty"`
+	// +nullable
+	Name string `json:"name,omitempty"`
+}
+
+func (in PlannedResource) String() string {
+	return in.Action + " " + name(in.APIVersion, in.Kind, in.Namespace, in.Name)
 }
 
 type BundleDeploymentDisplay struct {
@@ -478,6 +703,27 @@ type ModifiedStatus struct {
 	Delete bool `json:"delete,omitempty"`
 	// +nullable
 	Patch string `json:"patch,omitempty"`
+	// Changes lists the modified fields of the resource, if drift
+	// reporting is enabled. The list is truncated to keep the status
+	// small.
+	// +nullable
+	Changes []FieldChange `json:"changes,omitempty"`
+}
+
+// FieldChange is a field of a resource, whose live value differs from the
+// desired value.
+type FieldChange struct {
+	// Path is the JSON pointer of the field, e.g. "/spec/replicas". It can
+	// be used in the jsonPointers of a comparePatch.
+	Path string `json:"path"`
+	// Live is the JSON encoded value in the cluster. It is empty if the
+	// field is missing.
+	// +nullable
+	Live string `json:"live,omitempty"`
+	// Desired is the JSON encoded value of the bundle. It is empty if the
+	// field should not exist.
+	// +nullable
+	Desired string `json:"desired,omitempty"`
 }
 
 func (in ModifiedStatus) String() string {
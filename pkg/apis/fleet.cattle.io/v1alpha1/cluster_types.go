@@ -3,6 +3,7 @@ package v1alpha1
 import (
 	"github.com/rancher/wrangler/v3/pkg/genericcondition"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -244,6 +245,12 @@ type ClusterStatus struct {
 	Display ClusterDisplay `json:"display,omitempty"`
 	// AgentStatus contains information about the agent.
 	Agent AgentStatus `json:"agent,omitempty"`
+	// Facts about the downstream cluster, which the agent collects
+	// periodically. Targets can select clusters by their facts and
+	// templates can read them.
+	// +nullable
+	// +optional
+	Facts *ClusterFacts `json:"facts,omitempty"`
 
 	// GarbageCollectionInterval determines how often agents clean up obsolete Helm releases.
 	GarbageCollectionInterval *metav1.Duration `json:"garbageCollectionInterval,omitempty"`
@@ -261,6 +268,56 @@ type ClusterStatus struct {
 	ActiveSchedule bool `json:"activeSchedule,omitempty"`
 }
 
+// ClusterFacts are facts about a downstream cluster, reported by its agent.
+type ClusterFacts struct {
+	// KubernetesVersion is the version of the cluster's API server, e.g.
+	// "v1.30.2+k3s1".
+	// +optional
+	KubernetesVersion string `json:"kubernetesVersion,omitempty"`
+	// APIVersions are the API versions served by the cluster, as
+	// "group/version" and "group/version/kind". They are used for
+	// .Capabilities.APIVersions, when rendering charts outside of the
+	// cluster.
+	// +nullable
+	// +optional
+	APIVersions []string `json:"apiVersions,omitempty"`
+	// NodeCount is the number of nodes.
+	// +optional
+	NodeCount int `json:"nodeCount,omitempty"`
+	// Architectures are the distinct CPU architectures of the nodes, e.g.
+	// "amd64".
+	// +nullable
+	// +optional
+	Architectures []string `json:"architectures,omitempty"`
+	// OperatingSystems are the distinct operating systems of the nodes,
+	// e.g. "linux".
+	// +nullable
+	// +optional
+	OperatingSystems []string `json:"operatingSystems,omitempty"`
+	// AllocatableCPU is the sum of the allocatable CPU of all nodes.
+	// +optional
+	AllocatableCPU resource.Quantity `json:"allocatableCPU,omitempty"`
+	// AllocatableMemory is the sum of the allocatable memory of all nodes.
+	// +optional
+	AllocatableMemory resource.Quantity `json:"allocatableMemory,omitempty"`
+	// CloudProvider is the provider of most nodes, taken from the scheme of
+	// their providerID, e.g. "aws", "gce" or "azure".
+	// +optional
+	CloudProvider string `json:"cloudProvider,omitempty"`
+	// CRDGroups are the distinct API groups of the installed custom
+	// resource definitions, e.g. "cert-manager.io".
+	// +nullable
+	// +optional
+	CRDGroups []string `json:"crdGroups,omitempty"`
+	// StorageClasses are the names of the storage classes.
+	// +nullable
+	// +optional
+	StorageClasses []string `json:"storageClasses,omitempty"`
+	// DefaultStorageClass is the name of the default storage class.
+	// +optional
+	DefaultStorageClass string `json:"defaultStorageClass,omitempty"`
+}
+
 type ClusterDisplay struct {
 	// ReadyBundles is a string in the form "%d/%d", that describes the
 	// number of bundles that are ready vs. the number of bundles desired
@@ -16,6 +16,9 @@ const (
 	CreatedByUserIDLabel = "fleet.cattle.io/created-by-user-id"
 
 	GitRepoAcceptedCondition = "Accepted"
+	// GitRepoVerifiedCondition is false, if the signature of the cloned
+	// commit could not be verified with the trusted keys.
+	GitRepoVerifiedCondition = "Verified"
 )
 
 // +genclient
@@ -144,6 +147,21 @@ type GitRepoSpec struct {
 	// Bundles defines the paths of bundles to be read.
 	// This drives the fleet resource scanner that simply loads the specified folders
 	Bundles []BundlePath `json:"bundles,omitempty"`
+
+	// Verification configures the verification of commit signatures. If
+	// set, bundles are only created from commits signed by a trusted key.
+	// +nullable
+	Verification *GitVerification `json:"verification,omitempty"`
+}
+
+// GitVerification configures the keys, which are trusted to sign commits.
+type GitVerification struct {
+	// SecretName is the name of a secret in the GitRepo's namespace, which
+	// holds the trusted keys. Keys ending in ".asc" contain armored GPG
+	// public keys, keys ending in ".pub" contain SSH public keys in
+	// authorized_keys format.
+	// +kubebuilder:validation:MinLength=1
+	SecretName string `json:"secretName"`
 }
 
 type BundlePath struct {
@@ -171,6 +189,9 @@ type GitTarget struct {
 	// ClusterGroupSelector is a label selector to select cluster groups.
 	// +nullable
 	ClusterGroupSelector *metav1.LabelSelector `json:"clusterGroupSelector,omitempty"`
+	// ClusterFactSelector selects clusters by the facts their agents report.
+	// +nullable
+	ClusterFactSelector *ClusterFactSelector `json:"clusterFactSelector,omitempty"`
 }
 
 type GitRepoStatus struct {
@@ -196,6 +217,11 @@ type GitRepoStatus struct {
 	LastSyncedImageScanTime metav1.Time `json:"lastSyncedImageScanTime,omitempty"`
 	// LastPollingTime is the last time the polling check was triggered
 	LastPollingTime metav1.Time `json:"lastPollingTriggered,omitempty"`
+	// ImageScanPullRequestURL is the URL of the pull request, which was
+	// last opened or updated to write back scanned images. It is cleared
+	// once the branch contains the scanned images, e.g. after a merge.
+	// +optional
+	ImageScanPullRequestURL string `json:"imageScanPullRequestURL,omitempty"`
 }
 
 type GitRepoDisplay struct {
@@ -223,6 +249,58 @@ type CommitSpec struct {
 	// into which will be interpolated the details of the change made.
 	// +optional
 	MessageTemplate string `json:"messageTemplate,omitempty"`
+	// Mode controls how commits are written back to the git repository.
+	// "push" pushes the commit to the GitRepo's branch. "pullRequest"
+	// pushes the commit to a separate branch and opens, or updates, a
+	// pull request against the GitRepo's branch.
+	// default: push
+	// +kubebuilder:validation:Enum=push;pullRequest
+	// +optional
+	Mode CommitMode `json:"mode,omitempty"`
+	// PullRequest configures the pull request, if mode is "pullRequest".
+	// +nullable
+	// +optional
+	PullRequest *PullRequestSpec `json:"pullRequest,omitempty"`
+}
+
+type CommitMode string
+
+const (
+	// CommitModePush pushes commits directly to the GitRepo's branch.
+	CommitModePush CommitMode = "push"
+	// CommitModePullRequest pushes commits to a separate branch and
+	// opens a pull request against the GitRepo's branch.
+	CommitModePullRequest CommitMode = "pullRequest"
+)
+
+// PullRequestSpec specifies how to open pull requests, or merge requests, on
+// a git hosting service.
+type PullRequestSpec struct {
+	// Provider is the git hosting service of the repository, one of
+	// "github", "gitlab" or "gitea". If empty, it is detected from the
+	// repository's host name.
+	// +kubebuilder:validation:Enum=github;gitlab;gitea
+	// +optional
+	Provider string `json:"provider,omitempty"`
+	// APIURL is the base URL of the provider's API, e.g.
+	// "https://github.example.com/api/v3" for GitHub Enterprise. If empty,
+	// it is derived from the repository URL.
+	// +optional
+	APIURL string `json:"apiURL,omitempty"`
+	// Branch is the branch commits are pushed to. The branch is
+	// overwritten on each write back. If empty, a branch name is generated
+	// from the GitRepo's name.
+	// +optional
+	Branch string `json:"branch,omitempty"`
+	// Title of the pull request. If empty, the first line of the commit
+	// message is used.
+	// +optional
+	Title string `json:"title,omitempty"`
+	// SecretName is the name of a secret, containing an API token in the
+	// "token" key, or the keys of a GitHub App. If empty, the password of
+	// the GitRepo's client secret is used as a token.
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
 }
 
 type CorrectDrift struct {
@@ -232,4 +310,8 @@ type CorrectDrift struct {
 	Force bool `json:"force,omitempty"`
 	// KeepFailHistory keeps track of failed rollbacks in the helm history.
 	KeepFailHistory bool `json:"keepFailHistory,omitempty"`
+	// Report records the changed fields of modified resources, with their
+	// live and desired values, in the status of the bundle deployment.
+	// It can be used without enabling drift correction.
+	Report bool `json:"report,omitempty"`
 }
@@ -44,8 +44,30 @@ type GitRepoRestriction struct {
 	// be set.
 	// +nullable
 	AllowedTargetNamespaces []string `json:"allowedTargetNamespaces,omitempty"`
+
+	// DisallowedKustomizeFeatures is a list of kustomize features, which
+	// bundles in the namespace must not enable, e.g. in their fleet.yaml.
+	// Unlike the other restrictions, it also applies to bundles, which were
+	// not created from GitRepos.
+	// +nullable
+	DisallowedKustomizeFeatures []KustomizeFeature `json:"disallowedKustomizeFeatures,omitempty"`
 }
 
+// KustomizeFeature is a kustomize feature, which can be enabled in fleet.yaml.
+// +kubebuilder:validation:Enum=HelmCharts;LoadRestrictionsNone;AlphaPlugins
+type KustomizeFeature string
+
+const (
+	// KustomizeFeatureHelmCharts is the inflation of Helm charts, enabled by kustomize.enableHelm.
+	KustomizeFeatureHelmCharts KustomizeFeature = "HelmCharts"
+	// KustomizeFeatureLoadRestrictionsNone is loading files from outside
+	// the kustomization's directory, enabled by kustomize.loadRestrictions.
+	KustomizeFeatureLoadRestrictionsNone KustomizeFeature = "LoadRestrictionsNone"
+	// KustomizeFeatureAlphaPlugins is running KRM function transformers,
+	// enabled by kustomize.enableAlphaPlugins.
+	KustomizeFeatureAlphaPlugins KustomizeFeature = "AlphaPlugins"
+)
+
 // +kubebuilder:object:root=true
 
 // GitRepoRestrictionList contains a list of GitRepoRestriction
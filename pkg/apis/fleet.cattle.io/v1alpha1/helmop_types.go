@@ -68,6 +68,76 @@ type HelmOpSpec struct {
 
 	// InsecureSkipTLSverify will use insecure HTTPS to clone the helm app resource.
 	InsecureSkipTLSverify bool `json:"insecureSkipTLSVerify,omitempty"`
+
+	// VersionPolicy controls which of the versions, matching the version
+	// constraint, polling adopts. Without a policy, polling adopts the
+	// latest matching version as soon as it is available.
+	// +nullable
+	// +optional
+	VersionPolicy *HelmOpVersionPolicy `json:"versionPolicy,omitempty"`
+
+	// VersionRings stage new chart versions through the targets. The
+	// first ring gets a new version right away. Every following ring gets
+	// it, after the bundle deployments of the previous ring have been
+	// ready on it for the previous ring's ReadyFor duration. Targets, which
+	// are not part of any ring, get new versions right away.
+	// +optional
+	VersionRings []HelmOpVersionRing `json:"versionRings,omitempty"`
+}
+
+// HelmOpVersionRing is a group of targets, which is updated to a new chart
+// version in one step.
+type HelmOpVersionRing struct {
+	// Name of the ring.
+	Name string `json:"name"`
+
+	// Targets are the names of the entries in targets, which belong to
+	// the ring. Unnamed targets are named target000, target001, etc.
+	Targets []string `json:"targets"`
+
+	// ReadyFor is how long the ring must have been ready on a version,
+	// before the next ring is updated to it.
+	// +nullable
+	// +optional
+	ReadyFor *metav1.Duration `json:"readyFor,omitempty"`
+}
+
+// HelmOpVersionPolicy controls the promotion of new chart versions, which
+// are found by polling.
+type HelmOpVersionPolicy struct {
+	// Policy selects the latest version, like the policy of an ImageScan.
+	// The semver range further restricts the version constraint of the
+	// chart. Defaults to semver ordering.
+	// +optional
+	Policy ImagePolicyChoice `json:"policy,omitempty"`
+
+	// AllowedUpdates limits semver updates relative to the current
+	// version. "patch" only adopts versions with the same major and minor
+	// version, "minor" only versions with the same major version. Polling
+	// never downgrades the current version.
+	// +kubebuilder:validation:Enum=patch;minor;major
+	// +optional
+	AllowedUpdates string `json:"allowedUpdates,omitempty"`
+
+	// Exclude is a regular expression. Matching versions are never
+	// adopted, e.g. "-(alpha|beta|rc)" excludes these pre-releases.
+	// +optional
+	Exclude string `json:"exclude,omitempty"`
+
+	// SoakTime is how long a version must have existed, before it is
+	// adopted. For helm repositories, the creation time from the index is
+	// used. OCI registries do not record it, so the time polling first saw
+	// the version is used instead. Tags, which existed before a version was
+	// adopted, count as soaked.
+	// +nullable
+	// +optional
+	SoakTime *metav1.Duration `json:"soakTime,omitempty"`
+
+	// HistoryLimit is the number of resolved versions kept in the status.
+	// Defaults to 10.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	HistoryLimit int `json:"historyLimit,omitempty"`
 }
 
 type HelmOpStatus struct {
@@ -80,4 +150,42 @@ type HelmOpStatus struct {
 	// When using * or empty version in the spec we get the latest version from
 	// the helm repository when possible
 	Version string `json:"version,omitempty"`
+
+	// VersionHistory lists the last resolved versions, newest first. A
+	// version from the history can be set in the spec to pin it.
+	// +optional
+	VersionHistory []HelmOpVersion `json:"versionHistory,omitempty"`
+
+	// PendingVersions are versions, which the version policy allows, but
+	// which have not existed for its soak time yet. At most the latest 10
+	// pending versions are kept.
+	// +optional
+	PendingVersions []HelmOpVersion `json:"pendingVersions,omitempty"`
+
+	// VersionRings are the chart versions of the version rings. Rings
+	// after the first are rendered as helm version overrides of their
+	// targets.
+	// +optional
+	VersionRings []HelmOpVersionRingStatus `json:"versionRings,omitempty"`
+}
+
+// HelmOpVersionRingStatus is the effective chart version of a version ring.
+type HelmOpVersionRingStatus struct {
+	Name string `json:"name"`
+
+	// Version is the chart version deployed to the ring's targets.
+	Version string `json:"version,omitempty"`
+
+	// ReadySince is the time all bundle deployments of the ring became
+	// ready on its version. It is unset, while the ring is not ready.
+	// +nullable
+	// +optional
+	ReadySince *metav1.Time `json:"readySince,omitempty"`
+}
+
+// HelmOpVersion is a chart version and when it was resolved, or first seen
+// for pending versions.
+type HelmOpVersion struct {
+	Version string      `json:"version"`
+	Time    metav1.Time `json:"time,omitempty"`
 }
@@ -0,0 +1,184 @@
+package v1alpha1
+
+import (
+	"github.com/rancher/wrangler/v3/pkg/genericcondition"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func init() {
+	InternalSchemeBuilder.Register(&Notifier{}, &NotifierList{})
+}
+
+const (
+	// NotifierResourceBundle selects bundles as the source of notifications.
+	NotifierResourceBundle = "Bundle"
+	// NotifierResourceGitRepo selects gitrepos as the source of notifications.
+	NotifierResourceGitRepo = "GitRepo"
+	// NotifierResourceCluster selects clusters as the source of notifications.
+	NotifierResourceCluster = "Cluster"
+
+	// NotifierConditionReady is false, if the notifier failed to send
+	// notifications.
+	NotifierConditionReady = "Ready"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Sent",type=integer,JSONPath=`.status.sent`
+// +kubebuilder:printcolumn:name="Last-Sent",type=string,JSONPath=`.status.lastSentTime`
+
+// Notifier sends notifications to a sink, whenever the state of a bundle,
+// gitrepo or cluster in its namespace changes.
+type Notifier struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NotifierSpec   `json:"spec,omitempty"`
+	Status NotifierStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// NotifierList contains a list of Notifier
+type NotifierList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Notifier `json:"items"`
+}
+
+type NotifierSpec struct {
+	// Resources is the list of resource kinds to watch for state
+	// transitions. Defaults to all supported kinds.
+	// +nullable
+	Resources []NotifierResource `json:"resources,omitempty"`
+	// Selector is a label selector to select the watched resources.
+	// Defaults to all resources in the namespace.
+	// +nullable
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+	// States is the list of states, transitions into which are notified,
+	// e.g. "ErrApplied" or "Ready". Defaults to all states.
+	// +nullable
+	States []string `json:"states,omitempty"`
+	// Template is a Go template for the message. It is rendered with the
+	// fields Kind, Namespace, Name, State, PreviousState, Message and
+	// Time.
+	// +nullable
+	Template string `json:"template,omitempty"`
+	// Sink is the destination of the notifications.
+	Sink NotifierSink `json:"sink"`
+	// DedupeWindow is the duration in which repeated transitions of a
+	// resource into the same state are only notified once. Defaults to
+	// 10m.
+	// +nullable
+	DedupeWindow *metav1.Duration `json:"dedupeWindow,omitempty"`
+	// RateLimit limits the number of notifications sent by this notifier.
+	// Notifications exceeding the limit are dropped.
+	// +nullable
+	RateLimit *NotifierRateLimit `json:"rateLimit,omitempty"`
+	// Suspend stops sending notifications, transitions are still tracked.
+	Suspend bool `json:"suspend,omitempty"`
+}
+
+// NotifierResource is the kind of a resource, whose state transitions are
+// notified.
+// +kubebuilder:validation:Enum=Bundle;GitRepo;Cluster
+type NotifierResource string
+
+// NotifierSink configures the destination of notifications. Exactly one of
+// the sinks must be set.
+type NotifierSink struct {
+	// Webhook posts the notification as JSON to an HTTP endpoint.
+	// +nullable
+	Webhook *WebhookSink `json:"webhook,omitempty"`
+	// Slack posts the message to a Slack-compatible incoming webhook.
+	// +nullable
+	Slack *SlackSink `json:"slack,omitempty"`
+	// SMTP sends the message as an email.
+	// +nullable
+	SMTP *SMTPSink `json:"smtp,omitempty"`
+	// Event creates a Kubernetes event.
+	// +nullable
+	Event *EventSink `json:"event,omitempty"`
+}
+
+type WebhookSink struct {
+	// URL of the endpoint.
+	// +nullable
+	URL string `json:"url,omitempty"`
+	// SecretName is the name of a secret in the notifier's namespace. The
+	// key "url" overrides the URL and the key "token" is sent as a bearer
+	// token.
+	// +nullable
+	SecretName string `json:"secretName,omitempty"`
+}
+
+type SlackSink struct {
+	// URL of the incoming webhook.
+	// +nullable
+	URL string `json:"url,omitempty"`
+	// SecretName is the name of a secret in the notifier's namespace. The
+	// key "url" overrides the URL.
+	// +nullable
+	SecretName string `json:"secretName,omitempty"`
+	// Channel overrides the default channel of the incoming webhook.
+	// +nullable
+	Channel string `json:"channel,omitempty"`
+	// Username overrides the default username of the incoming webhook.
+	// +nullable
+	Username string `json:"username,omitempty"`
+}
+
+type SMTPSink struct {
+	// Host of the SMTP server.
+	Host string `json:"host"`
+	// Port of the SMTP server, defaults to 587.
+	// +nullable
+	Port int `json:"port,omitempty"`
+	// From is the sender address.
+	From string `json:"from"`
+	// To is the list of recipient addresses.
+	To []string `json:"to"`
+	// SecretName is the name of a secret in the notifier's namespace with
+	// the keys "username" and "password", which are used to authenticate.
+	// +nullable
+	SecretName string `json:"secretName,omitempty"`
+}
+
+type EventSink struct {
+	// Namespace the events are created in, defaults to the notifier's
+	// namespace.
+	// +nullable
+	Namespace string `json:"namespace,omitempty"`
+}
+
+type NotifierRateLimit struct {
+	// Limit is the maximum number of notifications per period.
+	// +kubebuilder:validation:Minimum=1
+	Limit int `json:"limit"`
+	// Period defaults to 1m.
+	// +nullable
+	Period *metav1.Duration `json:"period,omitempty"`
+}
+
+type NotifierStatus struct {
+	// Conditions is a list of Wrangler conditions that describe the state
+	// of the resource.
+	Conditions []genericcondition.GenericCondition `json:"conditions,omitempty"`
+	// Sent is the number of notifications sent.
+	// +optional
+	Sent int64 `json:"sent"`
+	// Deduplicated is the number of notifications suppressed, because the
+	// resource was in the same state within the dedupe window.
+	// +optional
+	Deduplicated int64 `json:"deduplicated"`
+	// RateLimited is the number of notifications dropped, because the
+	// rate limit was exceeded.
+	// +optional
+	RateLimited int64 `json:"rateLimited"`
+	// LastSentTime is the time the last notification was sent.
+	// +nullable
+	LastSentTime *metav1.Time `json:"lastSentTime,omitempty"`
+}
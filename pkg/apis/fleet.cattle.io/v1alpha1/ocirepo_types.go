@@ -0,0 +1,137 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func init() {
+	InternalSchemeBuilder.Register(&OCIRepo{}, &OCIRepoList{})
+}
+
+const (
+	// OCIRepoLabel is the label of bundles created from an OCIRepo.
+	OCIRepoLabel = "fleet.cattle.io/ocirepo-name"
+
+	OCIRepoAcceptedCondition = "Accepted"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:categories=fleet,path=ocirepos
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="URL",type=string,JSONPath=`.spec.url`
+// +kubebuilder:printcolumn:name="Tag",type=string,JSONPath=`.status.tag`
+// +kubebuilder:printcolumn:name="BundleDeployments-Ready",type=string,JSONPath=`.status.display.readyBundleDeployments`
+// +kubebuilder:printcolumn:name="Status",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].message`
+
+// OCIRepo describes an OCI artifact that is watched by Fleet.
+// The artifact contains a tree of bundle directories, like a git repository,
+// which is deployed to target clusters. Bundles are created from the local
+// files of the artifact only, helm charts must be part of the artifact and
+// are not downloaded.
+type OCIRepo struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   OCIRepoSpec   `json:"spec,omitempty"`
+	Status OCIRepoStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// OCIRepoList contains a list of OCIRepo
+type OCIRepoList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []OCIRepo `json:"items"`
+}
+
+type OCIRepoSpec struct {
+	// URL is the OCI repository of the artifact, e.g.
+	// oci://ghcr.io/org/manifests.
+	// +kubebuilder:validation:Pattern=`^oci://`
+	URL string `json:"url"`
+
+	// Tag of the artifact to pull. Defaults to "latest".
+	// +nullable
+	Tag string `json:"tag,omitempty"`
+
+	// Semver is a semantic version range. If set, the artifact with the
+	// highest tag in the range is pulled, instead of Tag.
+	// +nullable
+	Semver string `json:"semver,omitempty"`
+
+	// ClientSecretName is the name of the client secret to be used to
+	// connect to the registry. It is expected the secret be of type
+	// "kubernetes.io/basic-auth".
+	// +nullable
+	ClientSecretName string `json:"clientSecretName,omitempty"`
+
+	// InsecureSkipTLSverify will use insecure HTTPS to pull the artifact.
+	InsecureSkipTLSverify bool `json:"insecureSkipTLSVerify,omitempty"`
+
+	// BasicHTTP uses plain HTTP to pull the artifact.
+	BasicHTTP bool `json:"basicHTTP,omitempty"`
+
+	// PollingInterval is how often to check the registry for new
+	// artifacts. Defaults to 5m.
+	// +nullable
+	PollingInterval *metav1.Duration `json:"pollingInterval,omitempty"`
+
+	// Paths is the directories relative to the artifact root that contain resources to be applied.
+	// Path globbing is supported, for example ["charts/*"] will match all folders as a subdirectory of charts/
+	// If empty, "/" is the default.
+	// +nullable
+	Paths []string `json:"paths,omitempty"`
+
+	// Bundles defines the paths of bundles to be read.
+	// This drives the fleet resource scanner that simply loads the specified folders
+	Bundles []BundlePath `json:"bundles,omitempty"`
+
+	// Ensure that all resources are created in this namespace
+	// Any cluster scoped resource will be rejected if this is set
+	// Additionally this namespace will be created on demand
+	// +nullable
+	TargetNamespace string `json:"targetNamespace,omitempty"`
+
+	// This artifact will not be actively deployed and will be
+	// partially downloaded, waiting for a manual deployment.
+	Paused bool `json:"paused,omitempty"`
+
+	// ServiceAccount used in the downstream cluster for deployment.
+	// +nullable
+	ServiceAccount string `json:"serviceAccount,omitempty"`
+
+	// Targets is a list of targets this artifact will deploy to.
+	Targets []GitTarget `json:"targets,omitempty"`
+
+	// Increment this number to force a redeployment of contents from the artifact.
+	ForceSyncGeneration int64 `json:"forceSyncGeneration,omitempty"`
+
+	// KeepResources specifies if the resources created must be kept after deleting the OCIRepo.
+	KeepResources bool `json:"keepResources,omitempty"`
+
+	// DeleteNamespace specifies if the namespace created must be deleted after deleting the OCIRepo.
+	DeleteNamespace bool `json:"deleteNamespace,omitempty"`
+
+	// CorrectDrift specifies how drift correction should work.
+	CorrectDrift *CorrectDrift `json:"correctDrift,omitempty"`
+}
+
+type OCIRepoStatus struct {
+	StatusBase `json:",inline"`
+	// ObservedGeneration is the current generation of the resource in the cluster. It is copied from k8s
+	// metadata.Generation. The value is incremented for all changes, except for changes to .metadata or .status.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration"`
+	// Tag is the tag of the artifact the bundles were created from.
+	// +optional
+	Tag string `json:"tag,omitempty"`
+	// Digest is the digest of the artifact the bundles were created from.
+	// +optional
+	Digest string `json:"digest,omitempty"`
+	// LastPollingTime is the last time the registry was polled.
+	// +optional
+	LastPollingTime metav1.Time `json:"lastPollingTriggered,omitempty"`
+}
@@ -36,14 +36,52 @@ type ScheduleList struct {
 	Items           []Schedule `json:"items"`
 }
 
+// ScheduleType defines whether a schedule allows or denies deployments while it is active.
+type ScheduleType string
+
+const (
+	// AllowSchedule only lets the targeted clusters deploy while the schedule is active.
+	AllowSchedule ScheduleType = "Allow"
+	// DenySchedule holds back rollouts of the targeted bundles to the
+	// targeted clusters while the schedule is active.
+	DenySchedule ScheduleType = "Deny"
+)
+
+// ScheduleConditionCalendar is false, if events of the calendar of a Deny
+// schedule are skipped, because their recurrence is not supported.
+const ScheduleConditionCalendar = "Calendar"
+
 type ScheduleSpec struct {
 	Schedule string          `json:"schedule,omitempty"`
 	Duration metav1.Duration `json:"duration,omitempty"`
 	Location string          `json:"location,omitempty"`
+	// Type is either Allow (default) or Deny. An Allow schedule only lets
+	// the targeted clusters deploy while it is active. A Deny schedule is a
+	// freeze period: while it is active, new deployments of the targeted
+	// bundles are not rolled out to the targeted clusters. Deny schedules
+	// target all clusters in their namespace, if no cluster targets are
+	// given.
+	// +kubebuilder:validation:Enum=Allow;Deny
+	// +optional
+	Type ScheduleType `json:"type,omitempty"`
+	// Calendar refers to an iCal file in a config map. Each event of the
+	// calendar is an additional deny window, e.g. a holiday. Only used by
+	// Deny schedules, which may leave Schedule empty if a calendar is given.
+	// +nullable
+	Calendar *ScheduleCalendar `json:"calendar,omitempty"`
 	// Targets is a list of resources affected by this schedule
 	Targets ScheduleTargets `json:"targets,omitempty"`
 }
 
+// ScheduleCalendar refers to an iCal file in a config map in the schedule's namespace.
+type ScheduleCalendar struct {
+	// ConfigMapName is the name of the config map.
+	ConfigMapName string `json:"configMapName,omitempty"`
+	// Key of the iCal file in the config map. Defaults to "calendar.ics".
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
 type ScheduleStatus struct {
 	// Active is set to true when the Schedule is actively running
 	Active bool `json:"active,omitempty"`
@@ -58,6 +96,38 @@ type ScheduleStatus struct {
 
 type ScheduleTargets struct {
 	Clusters []ScheduleTarget `json:"clusters,omitempty"`
+	// Bundles is a list of bundles affected by a Deny schedule. If empty,
+	// all bundles in the schedule's namespace are affected.
+	Bundles []ScheduleBundleTarget `json:"bundles,omitempty"`
+}
+
+// ScheduleBundleTarget represents a bundle (or group of bundles) affected by a Deny schedule
+type ScheduleBundleTarget struct {
+	// BundleName is the name of a bundle.
+	// +nullable
+	BundleName string `json:"bundleName,omitempty"`
+	// BundleSelector is a label selector to select bundles.
+	// +nullable
+	BundleSelector *metav1.LabelSelector `json:"bundleSelector,omitempty"`
+	// GitRepoName is the name of a GitRepo, whose bundles are selected.
+	// +nullable
+	GitRepoName string `json:"gitRepoName,omitempty"`
+	// GitRepoSelector is a label selector to select GitRepos, whose bundles are selected.
+	// +nullable
+	GitRepoSelector *metav1.LabelSelector `json:"gitRepoSelector,omitempty"`
+}
+
+// DenyWindow is an active deny window of a Deny schedule.
+type DenyWindow struct {
+	// Schedule is the name of the Deny schedule.
+	Schedule string `json:"schedule,omitempty"`
+	// Event is the summary of the calendar event the window comes from, if any.
+	// +nullable
+	Event string `json:"event,omitempty"`
+	// Start is the time the window started.
+	Start metav1.Time `json:"start,omitempty"`
+	// End is the time the window ends and rollouts continue.
+	End metav1.Time `json:"end,omitempty"`
 }
 
 // ScheduleTarget represents a resource (or group of resources) affected by a Schedule
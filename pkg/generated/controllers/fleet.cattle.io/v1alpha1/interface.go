@@ -31,6 +31,7 @@ func init() {
 }
 
 type Interface interface {
+	Approval() ApprovalController
 	Bundle() BundleController
 	BundleDeployment() BundleDeploymentController
 	BundleNamespaceMapping() BundleNamespaceMappingController
@@ -43,6 +44,8 @@ type Interface interface {
 	GitRepoRestriction() GitRepoRestrictionController
 	HelmOp() HelmOpController
 	ImageScan() ImageScanController
+	Notifier() NotifierController
+	OCIRepo() OCIRepoController
 	Schedule() ScheduleController
 }
 
@@ -56,6 +59,10 @@ type version struct {
 	controllerFactory controller.SharedControllerFactory
 }
 
+func (v *version) Approval() ApprovalController {
+	return generic.NewController[*v1alpha1.Approval, *v1alpha1.ApprovalList](schema.GroupVersionKind{Group: "fleet.cattle.io", Version: "v1alpha1", Kind: "Approval"}, "approvals", true, v.controllerFactory)
+}
+
 func (v *version) Bundle() BundleController {
 	return generic.NewController[*v1alpha1.Bundle, *v1alpha1.BundleList](schema.GroupVersionKind{Group: "fleet.cattle.io", Version: "v1alpha1", Kind: "Bundle"}, "bundles", true, v.controllerFactory)
 }
@@ -104,6 +111,14 @@ func (v *version) ImageScan() ImageScanController {
 	return generic.NewController[*v1alpha1.ImageScan, *v1alpha1.ImageScanList](schema.GroupVersionKind{Group: "fleet.cattle.io", Version: "v1alpha1", Kind: "ImageScan"}, "imagescans", true, v.controllerFactory)
 }
 
+func (v *version) Notifier() NotifierController {
+	return generic.NewController[*v1alpha1.Notifier, *v1alpha1.NotifierList](schema.GroupVersionKind{Group: "fleet.cattle.io", Version: "v1alpha1", Kind: "Notifier"}, "notifiers", true, v.controllerFactory)
+}
+
+func (v *version) OCIRepo() OCIRepoController {
+	return generic.NewController[*v1alpha1.OCIRepo, *v1alpha1.OCIRepoList](schema.GroupVersionKind{Group: "fleet.cattle.io", Version: "v1alpha1", Kind: "OCIRepo"}, "ocirepos", true, v.controllerFactory)
+}
+
 func (v *version) Schedule() ScheduleController {
 	return generic.NewController[*v1alpha1.Schedule, *v1alpha1.ScheduleList](schema.GroupVersionKind{Group: "fleet.cattle.io", Version: "v1alpha1", Kind: "Schedule"}, "schedules", true, v.controllerFactory)
 }
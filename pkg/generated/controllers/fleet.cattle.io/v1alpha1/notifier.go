@@ -0,0 +1,208 @@
+/*
+Copyright (c) 2020 - 2025 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by main. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	v1alpha1 "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+	"github.com/rancher/wrangler/v3/pkg/apply"
+	"github.com/rancher/wrangler/v3/pkg/condition"
+	"github.com/rancher/wrangler/v3/pkg/generic"
+	"github.com/rancher/wrangler/v3/pkg/kv"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// NotifierController interface for managing Notifier resources.
+type NotifierController interface {
+	generic.ControllerInterface[*v1alpha1.Notifier, *v1alpha1.NotifierList]
+}
+
+// NotifierClient interface for managing Notifier resources in Kubernetes.
+type NotifierClient interface {
+	generic.ClientInterface[*v1alpha1.Notifier, *v1alpha1.NotifierList]
+}
+
+// NotifierCache interface for retrieving Notifier resources in memory.
+type NotifierCache interface {
+	generic.CacheInterface[*v1alpha1.Notifier]
+}
+
+// NotifierStatusHandler is executed for every added or modified Notifier. Should return the new status to be updated
+type NotifierStatusHandler func(obj *v1alpha1.Notifier, status v1alpha1.NotifierStatus) (v1alpha1.NotifierStatus, error)
+
+// NotifierGeneratingHandler is the top-level handler that is executed for every Notifier event. It extends NotifierStatusHandler by a returning a slice of child objects to be passed to apply.Apply
+type NotifierGeneratingHandler func(obj *v1alpha1.Notifier, status v1alpha1.NotifierStatus) ([]runtime.Object, v1alpha1.NotifierStatus, error)
+
+// RegisterNotifierStatusHandler configures a NotifierController to execute a NotifierStatusHandler for every events observed.
+// If a non-empty condition is provided, it will be updated in the status conditions for every handler execution
+func RegisterNotifierStatusHandler(ctx context.Context, controller NotifierController, condition condition.Cond, name string, handler NotifierStatusHandler) {
+	statusHandler := &notifierStatusHandler{
+		client:    controller,
+		condition: condition,
+		handler:   handler,
+	}
+	controller.AddGenericHandler(ctx, name, generic.FromObjectHandlerToHandler(statusHandler.sync))
+}
+
+// RegisterNotifierGeneratingHandler configures a NotifierController to execute a NotifierGeneratingHandler for every events observed, passing the returned objects to the provided apply.Apply.
+// If a non-empty condition is provided, it will be updated in the status conditions for every handler execution
+func RegisterNotifierGeneratingHandler(ctx context.Context, controller NotifierController, apply apply.Apply,
+	condition condition.Cond, name string, handler NotifierGeneratingHandler, opts *generic.GeneratingHandlerOptions) {
+	statusHandler := &notifierGeneratingHandler{
+		NotifierGeneratingHandler: handler,
+		apply:                     apply,
+		name:                      name,
+		gvk:                       controller.GroupVersionKind(),
+	}
+	if opts != nil {
+		statusHandler.opts = *opts
+	}
+	controller.OnChange(ctx, name, statusHandler.Remove)
+	RegisterNotifierStatusHandler(ctx, controller, condition, name, statusHandler.Handle)
+}
+
+type notifierStatusHandler struct {
+	client    NotifierClient
+	condition condition.Cond
+	handler   NotifierStatusHandler
+}
+
+// sync is executed on every resource addition or modification. Executes the configured handlers and sends the updated status to the Kubernetes API
+func (a *notifierStatusHandler) sync(key string, obj *v1alpha1.Notifier) (*v1alpha1.Notifier, error) {
+	if obj == nil {
+		return obj, nil
+	}
+
+	origStatus := obj.Status.DeepCopy()
+	obj = obj.DeepCopy()
+	newStatus, err := a.handler(obj, obj.Status)
+	if err != nil {
+		// Revert to old status on error
+		newStatus = *origStatus.DeepCopy()
+	}
+
+	if a.condition != "" {
+		if errors.IsConflict(err) {
+			a.condition.SetError(&newStatus, "", nil)
+		} else {
+			a.condition.SetError(&newStatus, "", err)
+		}
+	}
+	if !equality.Semantic.DeepEqual(origStatus, &newStatus) {
+		if a.condition != "" {
+			// Since status has changed, update the lastUpdatedTime
+			a.condition.LastUpdated(&newStatus, time.Now().UTC().Format(time.RFC3339))
+		}
+
+		var newErr error
+		obj.Status = newStatus
+		newObj, newErr := a.client.UpdateStatus(obj)
+		if err == nil {
+			err = newErr
+		}
+		if newErr == nil {
+			obj = newObj
+		}
+	}
+	return obj, err
+}
+
+type notifierGeneratingHandler struct {
+	NotifierGeneratingHandler
+	apply apply.Apply
+	opts  generic.GeneratingHandlerOptions
+	gvk   schema.GroupVersionKind
+	name  string
+	seen  sync.Map
+}
+
+// Remove handles the observed deletion of a resource, cascade deleting every associated resource previously applied
+func (a *notifierGeneratingHandler) Remove(key string, obj *v1alpha1.Notifier) (*v1alpha1.Notifier, error) {
+	if obj != nil {
+		return obj, nil
+	}
+
+	obj = &v1alpha1.Notifier{}
+	obj.Namespace, obj.Name = kv.RSplit(key, "/")
+	obj.SetGroupVersionKind(a.gvk)
+
+	if a.opts.UniqueApplyForResourceVersion {
+		a.seen.Delete(key)
+	}
+
+	return nil, generic.ConfigureApplyForObject(a.apply, obj, &a.opts).
+		WithOwner(obj).
+		WithSetID(a.name).
+		ApplyObjects()
+}
+
+// Handle executes the configured NotifierGeneratingHandler and pass the resulting objects to apply.Apply, finally returning the new status of the resource
+func (a *notifierGeneratingHandler) Handle(obj *v1alpha1.Notifier, status v1alpha1.NotifierStatus) (v1alpha1.NotifierStatus, error) {
+	if !obj.DeletionTimestamp.IsZero() {
+		return status, nil
+	}
+
+	objs, newStatus, err := a.NotifierGeneratingHandler(obj, status)
+	if err != nil {
+		return newStatus, err
+	}
+	if !a.isNewResourceVersion(obj) {
+		return newStatus, nil
+	}
+
+	err = generic.ConfigureApplyForObject(a.apply, obj, &a.opts).
+		WithOwner(obj).
+		WithSetID(a.name).
+		ApplyObjects(objs...)
+	if err != nil {
+		return newStatus, err
+	}
+	a.storeResourceVersion(obj)
+	return newStatus, nil
+}
+
+// isNewResourceVersion detects if a specific resource version was already successfully processed.
+// Only used if UniqueApplyForResourceVersion is set in generic.GeneratingHandlerOptions
+func (a *notifierGeneratingHandler) isNewResourceVersion(obj *v1alpha1.Notifier) bool {
+	if !a.opts.UniqueApplyForResourceVersion {
+		return true
+	}
+
+	// Apply once per resource version
+	key := obj.Namespace + "/" + obj.Name
+	previous, ok := a.seen.Load(key)
+	return !ok || previous != obj.ResourceVersion
+}
+
+// storeResourceVersion keeps track of the latest resource version of an object for which Apply was executed
+// Only used if UniqueApplyForResourceVersion is set in generic.GeneratingHandlerOptions
+func (a *notifierGeneratingHandler) storeResourceVersion(obj *v1alpha1.Notifier) {
+	if !a.opts.UniqueApplyForResourceVersion {
+		return
+	}
+
+	key := obj.Namespace + "/" + obj.Name
+	a.seen.Store(key, obj.ResourceVersion)
+}
@@ -0,0 +1,59 @@
+package sharding
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"sort"
+	"strconv"
+)
+
+// ringReplicas is the number of points per shard on the ring. More points
+// spread the keys more evenly across shards.
+const ringReplicas = 100
+
+// Ring assigns keys to shards by consistent hashing. Adding or removing a
+// shard only moves the keys from or to that shard.
+type Ring struct {
+	points []uint64
+	shards map[uint64]string
+}
+
+// NewRing returns a ring for the given shard IDs. Empty and duplicate IDs
+// are ignored.
+func NewRing(shardIDs ...string) *Ring {
+	r := &Ring{shards: map[uint64]string{}}
+	for _, id := range shardIDs {
+		if id == "" {
+			continue
+		}
+		for i := 0; i < ringReplicas; i++ {
+			p := hash(id + "#" + strconv.Itoa(i))
+			if _, ok := r.shards[p]; ok {
+				continue
+			}
+			r.shards[p] = id
+			r.points = append(r.points, p)
+		}
+	}
+	sort.Slice(r.points, func(i, j int) bool { return r.points[i] < r.points[j] })
+	return r
+}
+
+// Get returns the shard ID for key, or an empty string if the ring has no
+// shards.
+func (r *Ring) Get(key string) string {
+	if len(r.points) == 0 {
+		return ""
+	}
+	h := hash(key)
+	i := sort.Search(len(r.points), func(i int) bool { return r.points[i] >= h })
+	if i == len(r.points) {
+		i = 0
+	}
+	return r.shards[r.points[i]]
+}
+
+func hash(s string) uint64 {
+	sum := sha256.Sum256([]byte(s))
+	return binary.BigEndian.Uint64(sum[:8])
+}
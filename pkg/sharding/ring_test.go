@@ -0,0 +1,54 @@
+package sharding
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestRing(t *testing.T) {
+	if got := NewRing().Get("key"); got != "" {
+		t.Errorf("expected no shard from an empty ring, got %q", got)
+	}
+	if got := NewRing("", "shard0").Get("key"); got != "shard0" {
+		t.Errorf("expected the only shard, got %q", got)
+	}
+
+	keys := make([]string, 3000)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("fleet-default/repo-%d", i)
+	}
+
+	ring := NewRing("shard0", "shard1", "shard2")
+	before := map[string]string{}
+	counts := map[string]int{}
+	for _, k := range keys {
+		before[k] = ring.Get(k)
+		counts[before[k]]++
+	}
+	for id, n := range counts {
+		// each shard should get roughly a third of the keys
+		if n < len(keys)/5 {
+			t.Errorf("expected keys to be spread evenly, shard %s got %d of %d", id, n, len(keys))
+		}
+	}
+
+	// the order of the shard IDs does not matter
+	reordered := NewRing("shard2", "shard0", "shard1", "shard0")
+	for _, k := range keys {
+		if got := reordered.Get(k); got != before[k] {
+			t.Fatalf("expected %s to be assigned to %s, got %s", k, before[k], got)
+		}
+	}
+
+	// removing a shard only moves the keys of that shard
+	reduced := NewRing("shard0", "shard2")
+	for _, k := range keys {
+		got := reduced.Get(k)
+		if before[k] != "shard1" && got != before[k] {
+			t.Errorf("expected %s to stay on %s, got %s", k, before[k], got)
+		}
+		if got == "shard1" {
+			t.Errorf("expected %s to be moved from removed shard", k)
+		}
+	}
+}
@@ -14,6 +14,10 @@ const (
 	ShardingRefLabel string = "fleet.cattle.io/shard-ref"
 	// ShardingDefaultLabel is the label key which is set to true on the controller handling unlabeled resources
 	ShardingDefaultLabel string = "fleet.cattle.io/shard-default"
+	// ShardingAssignedAnnotation is set to true on resources, which were
+	// labeled by the shard coordinator. Only these are moved to another shard,
+	// if their shard disappears.
+	ShardingAssignedAnnotation string = "fleet.cattle.io/shard-assigned"
 )
 
 // ShouldProcess returns true if the given object should be processed by the shard
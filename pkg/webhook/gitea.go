@@ -0,0 +1,95 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Gitea and Forgejo send the same payloads, Forgejo prefixes its headers
+// with X-Forgejo instead of X-Gitea.
+const (
+	giteaEventHeader       = "X-Gitea-Event"
+	giteaSignatureHeader   = "X-Gitea-Signature"
+	forgejoEventHeader     = "X-Forgejo-Event"
+	forgejoSignatureHeader = "X-Forgejo-Signature"
+
+	giteaPushEvent = "push"
+)
+
+var (
+	ErrGiteaInvalidHTTPMethod      = errors.New("invalid HTTP Method")
+	ErrGiteaMissingEventHeader     = errors.New("missing X-Gitea-Event or X-Forgejo-Event Header")
+	ErrGiteaMissingSignatureHeader = errors.New("missing X-Gitea-Signature or X-Forgejo-Signature Header")
+	ErrGiteaHMACVerificationFailed = errors.New("HMAC verification failed")
+	ErrGiteaParsingPayload         = errors.New("error parsing payload")
+)
+
+// GiteaPushPayload is the subset of the Gitea and Forgejo push event payload
+// needed to update gitrepos.
+type GiteaPushPayload struct {
+	Ref        string          `json:"ref"`
+	After      string          `json:"after"`
+	Repository GiteaRepository `json:"repository"`
+}
+
+type GiteaRepository struct {
+	HTMLURL  string `json:"html_url"`
+	CloneURL string `json:"clone_url"`
+	SSHURL   string `json:"ssh_url"`
+}
+
+// parseGitea parses Gitea and Forgejo push events. If a secret is given, the
+// HMAC-SHA256 signature of the payload is verified. Other events are ignored.
+func parseGitea(r *http.Request, secret *corev1.Secret) (interface{}, error) {
+	if r.Method != http.MethodPost {
+		return nil, ErrGiteaInvalidHTTPMethod
+	}
+
+	event, signature := r.Header.Get(forgejoEventHeader), r.Header.Get(forgejoSignatureHeader)
+	if event == "" {
+		event, signature = r.Header.Get(giteaEventHeader), r.Header.Get(giteaSignatureHeader)
+	}
+	if event == "" {
+		return nil, ErrGiteaMissingEventHeader
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading payload: %w", err)
+	}
+
+	if secret != nil {
+		value, err := getValue(secret, giteaKey)
+		if err != nil {
+			return nil, err
+		}
+		if signature == "" {
+			return nil, ErrGiteaMissingSignatureHeader
+		}
+		mac := hmac.New(sha256.New, []byte(value))
+		_, _ = mac.Write(body)
+		expected := hex.EncodeToString(mac.Sum(nil))
+		if !hmac.Equal([]byte(signature), []byte(expected)) {
+			return nil, ErrGiteaHMACVerificationFailed
+		}
+	}
+
+	if event != giteaPushEvent {
+		return nil, nil
+	}
+
+	var payload GiteaPushPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrGiteaParsingPayload, err)
+	}
+
+	return payload, nil
+}
@@ -19,12 +19,17 @@ const (
 	bitbucketKey       = "bitbucket"
 	bitbucketServerKey = "bitbucket-server"
 	gogsKey            = "gogs"
+	giteaKey           = "gitea"
+	refreshTokenKey    = "refresh-token"
 	azureUsername      = "azure-username"
 	azurePassword      = "azure-password"
 )
 
 func parseWebhook(r *http.Request, secret *corev1.Secret) (interface{}, error) {
 	switch {
+	// Gitea and Forgejo need to be checked before Gogs and Github since they carry Gogs and Github headers as well
+	case r.Header.Get(forgejoEventHeader) != "" || r.Header.Get(giteaEventHeader) != "":
+		return parseGitea(r, secret)
 	// Gogs needs to be checked before Github since it carries both Gogs and (incompatible) Github headers
 	case r.Header.Get("X-Gogs-Event") != "":
 		return parseGogs(r, secret)
@@ -171,6 +171,161 @@ func TestParseGogs(t *testing.T) {
 	}
 }
 
+func TestParseGitea(t *testing.T) {
+	body := []byte(`{
+		"ref": "refs/heads/main",
+		"after": "af69d162de5a276abc86e0686b2b44033cd3f442",
+		"repository": {
+			"html_url": "https://gitea.example.com/example/repo",
+			"clone_url": "https://gitea.example.com/example/repo.git",
+			"ssh_url": "git@gitea.example.com:example/repo.git"
+		}
+	}`)
+	payload := GiteaPushPayload{
+		Ref:   "refs/heads/main",
+		After: "af69d162de5a276abc86e0686b2b44033cd3f442",
+		Repository: GiteaRepository{
+			HTMLURL:  "https://gitea.example.com/example/repo",
+			CloneURL: "https://gitea.example.com/example/repo.git",
+			SSHURL:   "git@gitea.example.com:example/repo.git",
+		},
+	}
+
+	tests := map[string]struct {
+		secretData map[string][]byte
+		method     string
+		headers    map[string]string
+		wantErr    bool
+		wantErrMsg string
+		wantEvent  interface{}
+	}{
+		"valid-gitea-push-event-no-secret": {
+			headers: map[string]string{
+				"X-Gitea-Event": "push",
+			},
+			wantEvent: payload,
+		},
+		"valid-gitea-push-event-with-secret": {
+			secretData: map[string][]byte{
+				giteaKey: []byte("giteasecret"),
+			},
+			headers: map[string]string{
+				"X-Gitea-Event":     "push",
+				"X-Gitea-Signature": "53e5f09c785894fe85051b5e6961c29e628b6cf80beb51364d99e31843d3d297",
+			},
+			wantEvent: payload,
+		},
+		"valid-forgejo-push-event-with-secret": {
+			secretData: map[string][]byte{
+				giteaKey: []byte("giteasecret"),
+			},
+			headers: map[string]string{
+				"X-Forgejo-Event":     "push",
+				"X-Forgejo-Signature": "53e5f09c785894fe85051b5e6961c29e628b6cf80beb51364d99e31843d3d297",
+			},
+			wantEvent: payload,
+		},
+		"invalid-gitea-push-event-with-secret": {
+			secretData: map[string][]byte{
+				giteaKey: []byte("giteasecret"),
+			},
+			headers: map[string]string{
+				"X-Gitea-Event":     "push",
+				"X-Gitea-Signature": "wrongsignature",
+			},
+			wantErr:    true,
+			wantErrMsg: "HMAC verification failed",
+		},
+		"missing-gitea-signature": {
+			secretData: map[string][]byte{
+				giteaKey: []byte("giteasecret"),
+			},
+			headers: map[string]string{
+				"X-Gitea-Event": "push",
+			},
+			wantErr:    true,
+			wantErrMsg: "missing X-Gitea-Signature or X-Forgejo-Signature Header",
+		},
+		"missing-gitea-secret": {
+			secretData: map[string][]byte{
+				"wrongkey": []byte("giteasecret"),
+			},
+			headers: map[string]string{
+				"X-Gitea-Event":     "push",
+				"X-Gitea-Signature": "53e5f09c785894fe85051b5e6961c29e628b6cf80beb51364d99e31843d3d297",
+			},
+			wantErr:    true,
+			wantErrMsg: "secret key \"gitea\" not found in secret \"test-secret\"",
+		},
+		"ignored-gitea-event": {
+			headers: map[string]string{
+				"X-Gitea-Event": "issues",
+			},
+			wantEvent: nil,
+		},
+		"no-gitea-event": {
+			headers:    map[string]string{},
+			wantErr:    true,
+			wantErrMsg: "missing X-Gitea-Event or X-Forgejo-Event Header",
+		},
+		"invalid-method": {
+			method: http.MethodGet,
+			headers: map[string]string{
+				"X-Gitea-Event": "push",
+			},
+			wantErr:    true,
+			wantErrMsg: "invalid HTTP Method",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var secret *corev1.Secret
+			if tt.secretData != nil {
+				secret = &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-secret",
+						Namespace: "test-ns",
+					},
+					Data: tt.secretData,
+				}
+			}
+
+			method := tt.method
+			if method == "" {
+				method = http.MethodPost
+			}
+			req, err := http.NewRequestWithContext(context.Background(), method, "/", bytes.NewReader(body))
+			if err != nil {
+				t.Fatalf("Failed to create HTTP request: %v", err)
+			}
+
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+
+			got, err := parseGitea(req, secret)
+
+			if tt.wantErr {
+				assert.Error(t, err, tt.wantErrMsg)
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("parseGitea() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantEvent == nil {
+				if got != nil {
+					t.Fatalf("parseGitea() = %v, want %v", got, tt.wantEvent)
+				}
+			} else {
+				assert.DeepEqual(t, got, tt.wantEvent)
+			}
+		})
+	}
+}
+
 func TestParseGithub(t *testing.T) {
 	utilruntime.Must(corev1.AddToScheme(scheme.Scheme))
 
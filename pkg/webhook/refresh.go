@@ -0,0 +1,121 @@
+package webhook
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	fleet "github.com/rancher/fleet/pkg/apis/fleet.cattle.io/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+var (
+	ErrRefreshInvalidHTTPMethod       = errors.New("invalid HTTP Method")
+	ErrRefreshBadRequest              = errors.New("bad refresh request")
+	ErrRefreshTokenVerificationFailed = errors.New("refresh token verification failed")
+	ErrRefreshForbidden               = errors.New("refresh token is not valid for all gitrepos of the repo")
+
+	// commits are referenced by their full SHA-1 or SHA-256 hash
+	commitRegexp = regexp.MustCompile(`^([0-9a-fA-F]{40}|[0-9a-fA-F]{64})$`)
+)
+
+// ServeRefresh handles generic refresh calls, e.g. from CI systems, which
+// are not sent by a git provider:
+//
+//	POST /refresh?repo=<url>&commit=<sha>[&branch=<branch>]
+//
+// If no branch is given, all gitrepos of the repo are updated. The request
+// must carry a refresh token as a bearer token. The token is authenticated
+// against the webhook secrets of all gitrepos, before the request is
+// processed any further. The token must be contained in the webhook secrets
+// of all matching gitrepos, otherwise none is updated. Refresh calls are
+// rejected for gitrepos without a webhook secret.
+func (w *Webhook) ServeRefresh(rw http.ResponseWriter, r *http.Request) {
+	token, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if err := w.authenticateRefresh(r.Context(), token); err != nil {
+		w.logAndReturn(rw, err)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		w.logAndReturn(rw, ErrRefreshInvalidHTTPMethod)
+		return
+	}
+
+	query := r.URL.Query()
+	repo, commit, branch := query.Get("repo"), query.Get("commit"), query.Get("branch")
+	if repo == "" || commit == "" {
+		w.logAndReturn(rw, fmt.Errorf("%w: repo and commit parameters are required", ErrRefreshBadRequest))
+		return
+	}
+	if u, err := url.Parse(repo); err != nil || strings.Trim(u.Path, "/") == "" {
+		w.logAndReturn(rw, fmt.Errorf("%w: repo %q is not a repository URL", ErrRefreshBadRequest, repo))
+		return
+	}
+	if !commitRegexp.MatchString(commit) {
+		w.logAndReturn(rw, fmt.Errorf("%w: commit %q is not a full commit hash", ErrRefreshBadRequest, commit))
+		return
+	}
+
+	w.log.V(1).Info("Refresh request", "repo", repo, "commit", commit, "branch", branch)
+
+	err := w.updateGitRepos(r.Context(), commit, branch, true, []string{repo}, func(secret *corev1.Secret) error {
+		if err := verifyRefreshToken(secret, token); err != nil {
+			return fmt.Errorf("%w: %w", ErrRefreshForbidden, err)
+		}
+		return nil
+	})
+	if err != nil {
+		w.logAndReturn(rw, err)
+		return
+	}
+
+	rw.WriteHeader(http.StatusOK)
+	_, _ = rw.Write([]byte("succeeded"))
+}
+
+// authenticateRefresh checks the token matches the refresh token of the
+// webhook secret of at least one gitrepo.
+func (w *Webhook) authenticateRefresh(ctx context.Context, token string) error {
+	if token == "" {
+		return fmt.Errorf("%w: no bearer token", ErrRefreshTokenVerificationFailed)
+	}
+
+	var gitRepoList fleet.GitRepoList
+	if err := w.client.List(ctx, &gitRepoList); err != nil {
+		return err
+	}
+	for _, gitrepo := range gitRepoList.Items {
+		secret, err := w.getSecret(ctx, gitrepo)
+		if err != nil {
+			// a missing secret of one gitrepo does not fail the others
+			continue
+		}
+		if verifyRefreshToken(secret, token) == nil {
+			return nil
+		}
+	}
+	return ErrRefreshTokenVerificationFailed
+}
+
+// verifyRefreshToken checks the token matches the refresh token stored in
+// the secret.
+func verifyRefreshToken(secret *corev1.Secret, token string) error {
+	if secret == nil {
+		return fmt.Errorf("%w: no webhook secret defined", ErrRefreshTokenVerificationFailed)
+	}
+	value, err := getValue(secret, refreshTokenKey)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrRefreshTokenVerificationFailed, err)
+	}
+	if token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(value)) != 1 {
+		return ErrRefreshTokenVerificationFailed
+	}
+	return nil
+}
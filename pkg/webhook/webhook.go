@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"net/url"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
 
@@ -93,28 +94,66 @@ func (w *Webhook) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 
 	revision, branch, _, repoURLs := parsePayload(payload)
 
-	var gitRepoList fleet.GitRepoList
-	err = w.client.List(ctx, &gitRepoList, &client.ListOptions{LabelSelector: labels.Everything()})
+	err = w.updateGitRepos(ctx, revision, branch, false, repoURLs, func(secret *corev1.Secret) error {
+		if secret == nil {
+			return nil
+		}
+		// At this point we know that a secret is defined and exists.
+		// Parse the request again (this time with secret)
+		// We need to parse twice because in the first parsing we didn't
+		// know the gitrepo associated with the webhook payload.
+		// The first parsing is used to get the gitrepo and, if a secret is
+		// defined in the gitrepo, it takes precedence over the global one.
+		r.Body = io.NopCloser(bytes.NewBuffer(body))
+		_, err := parseWebhook(r, secret)
+		return err
+	})
 	if err != nil {
 		w.logAndReturn(rw, err)
 		return
 	}
 
+	rw.WriteHeader(http.StatusOK)
+	_, _ = rw.Write([]byte("succeeded"))
+}
+
+// updateGitRepos sets the webhook commit of all gitrepos, which track the
+// branch of one of the repo URLs. If anyBranch is set and branch is empty,
+// the branch of the gitrepos is not checked. Before any gitrepo is updated,
+// verify is called with the webhook secret of each of them, which is nil if
+// no secret is defined. If verify fails for one gitrepo, none is updated.
+func (w *Webhook) updateGitRepos(
+	ctx context.Context,
+	revision, branch string,
+	anyBranch bool,
+	repoURLs []string,
+	verify func(secret *corev1.Secret) error,
+) error {
+	var gitRepoList fleet.GitRepoList
+	err := w.client.List(ctx, &gitRepoList, &client.ListOptions{LabelSelector: labels.Everything()})
+	if err != nil {
+		return err
+	}
+
+	var matches []types.NamespacedName
 	for _, repo := range repoURLs {
 		u, err := url.Parse(repo)
 		if err != nil {
-			w.logAndReturn(rw, err)
-			return
+			return err
 		}
 
-		path := strings.Replace(u.EscapedPath()[1:], "/_git/", "(/_git)?/", 1)
+		// URLs without a repository path cannot match a gitrepo
+		path := strings.TrimPrefix(u.EscapedPath(), "/")
+		if path == "" {
+			continue
+		}
+		path = strings.Replace(path, "/_git/", "(/_git)?/", 1)
 
 		regexpStr := `(?i)(http://|https://|\w+@|ssh://(\w+@)?|git@(ssh\.)?)` + u.Hostname() +
 			"(:[0-9]+|)[:/](v\\d/)?" + path + "(\\.git)?"
 		repoRegexp, err := regexp.Compile(regexpStr)
 		if err != nil {
-			w.logAndReturn(rw, err)
-			return
+			return err
 		}
 		for _, gitrepo := range gitRepoList.Items {
 			if gitrepo.Spec.Revision != "" {
@@ -125,66 +164,51 @@ func (w *Webhook) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 				continue
 			}
 
-			if gitrepo.Spec.Branch != "" {
+			if gitrepo.Spec.Branch != "" && (branch != "" || !anyBranch) {
 				// we check if the branch from webhook matches gitrepo's branch
 				if branch == "" || branch != gitrepo.Spec.Branch {
 					continue
 				}
 			}
 
-			if gitrepo.Status.WebhookCommit != revision && revision != "" {
-				// before updating the gitrepo check if a secret was
-				// defined and, if so, verify that it is correct
-				secret, err := w.getSecret(ctx, gitrepo)
-				if err != nil {
-					w.logAndReturn(rw, err)
-					return
-				}
-				if secret != nil {
-					// At this point we know that a secret is defined and exists.
-					// Parse the request again (this time with secret)
-					// We need to parse twice because in the first parsing we didn't
-					// know the gitrepo associated with the webhook payload.
-					// The first parsing is used to get the gitrepo and, if a secret is
-					// defined in the gitrepo, it takes precedence over the global one.
-					r.Body = io.NopCloser(bytes.NewBuffer(body))
-					_, err = parseWebhook(r, secret)
-					if err != nil {
-						w.logAndReturn(rw, err)
-						return
-					}
-				}
+			key := types.NamespacedName{Name: gitrepo.Name, Namespace: gitrepo.Namespace}
+			if gitrepo.Status.WebhookCommit == revision || revision == "" || slices.Contains(matches, key) {
+				continue
+			}
 
-				var gitRepoFromCluster fleet.GitRepo
-				err = w.client.Get(
-					ctx,
-					types.NamespacedName{
-						Name:      gitrepo.Name,
-						Namespace: gitrepo.Namespace,
-					}, &gitRepoFromCluster,
-				)
-				if err != nil {
-					w.logAndReturn(rw, err)
-					return
-				}
-				orig := gitRepoFromCluster.DeepCopy()
-				gitRepoFromCluster.Status.WebhookCommit = revision
-				// if PollingInterval is not set and webhook is configured, set it to 1 hour
-				if gitRepoFromCluster.Spec.PollingInterval == nil {
-					gitRepoFromCluster.Spec.PollingInterval = &metav1.Duration{
-						Duration: webhookDefaultSyncInterval * time.Second,
-					}
-				}
-				p := client.MergeFrom(orig)
-				if err := w.client.Status().Patch(ctx, &gitRepoFromCluster, p); err != nil {
-					w.logAndReturn(rw, err)
-					return
-				}
+			// before updating any gitrepo check if a secret was
+			// defined and, if so, verify that it is correct
+			secret, err := w.getSecret(ctx, gitrepo)
+			if err != nil {
+				return err
+			}
+			if err := verify(secret); err != nil {
+				return err
 			}
+			matches = append(matches, key)
 		}
 	}
-	rw.WriteHeader(http.StatusOK)
-	_, _ = rw.Write([]byte("succeeded"))
+
+	for _, key := range matches {
+		var gitRepoFromCluster fleet.GitRepo
+		if err := w.client.Get(ctx, key, &gitRepoFromCluster); err != nil {
+			return err
+		}
+		orig := gitRepoFromCluster.DeepCopy()
+		gitRepoFromCluster.Status.WebhookCommit = revision
+		// if PollingInterval is not set and webhook is configured, set it to 1 hour
+		if gitRepoFromCluster.Spec.PollingInterval == nil {
+			gitRepoFromCluster.Spec.PollingInterval = &metav1.Duration{
+				Duration: webhookDefaultSyncInterval * time.Second,
+			}
+		}
+		p := client.MergeFrom(orig)
+		if err := w.client.Status().Patch(ctx, &gitRepoFromCluster, p); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 func HandleHooks(ctx context.Context, namespace string, client client.Client, clientCache cache.Cache) (http.Handler, error) {
@@ -195,6 +219,7 @@ func HandleHooks(ctx context.Context, namespace string, client client.Client, cl
 	}
 	root.UseEncodedPath()
 	root.Handle("/", webhook)
+	root.HandleFunc("/refresh", webhook.ServeRefresh)
 
 	return root, nil
 }
@@ -235,8 +260,13 @@ func getErrorCodeFromErr(err error) int {
 	// secret check, or basic credentials or token verification
 	// depending on the provider
 	switch {
+	case errors.Is(err, ErrRefreshForbidden):
+		return http.StatusForbidden
 	case
 		errors.Is(err, gogs.ErrHMACVerificationFailed),
+		errors.Is(err, ErrGiteaHMACVerificationFailed),
+		errors.Is(err, ErrGiteaMissingSignatureHeader),
+		errors.Is(err, ErrRefreshTokenVerificationFailed),
 		errors.Is(err, github.ErrHMACVerificationFailed),
 		errors.Is(err, gitlab.ErrGitLabTokenVerificationFailed),
 		errors.Is(err, bitbucket.ErrUUIDVerificationFailed),
@@ -246,6 +276,8 @@ func getErrorCodeFromErr(err error) int {
 		return http.StatusUnauthorized
 	case
 		errors.Is(err, gogs.ErrInvalidHTTPMethod),
+		errors.Is(err, ErrGiteaInvalidHTTPMethod),
+		errors.Is(err, ErrRefreshInvalidHTTPMethod),
 		errors.Is(err, github.ErrInvalidHTTPMethod),
 		errors.Is(err, gitlab.ErrInvalidHTTPMethod),
 		errors.Is(err, bitbucket.ErrInvalidHTTPMethod),
@@ -253,6 +285,8 @@ func getErrorCodeFromErr(err error) int {
 		errors.Is(err, azuredevops.ErrInvalidHTTPMethod):
 
 		return http.StatusMethodNotAllowed
+	case errors.Is(err, ErrRefreshBadRequest):
+		return http.StatusBadRequest
 	}
 	return http.StatusInternalServerError
 }
@@ -317,6 +351,10 @@ func parsePayload(payload interface{}) (revision, branch, tag string, repoURLs [
 			branch, tag = getBranchTagFromRef(change.ReferenceID)
 			break
 		}
+	case GiteaPushPayload:
+		repoURLs = append(repoURLs, t.Repository.HTMLURL)
+		branch, tag = getBranchTagFromRef(t.Ref)
+		revision = t.After
 	case gogsclient.PushPayload:
 		repoURLs = append(repoURLs, t.Repo.HTMLURL)
 		branch, tag = getBranchTagFromRef(t.Ref)
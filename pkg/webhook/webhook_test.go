@@ -355,6 +355,22 @@ func TestAuthErrorCodes(t *testing.T) {
 			err:               gogs.ErrInvalidHTTPMethod,
 			expectedErrorCode: http.StatusMethodNotAllowed,
 		},
+		"gitea-verification": {
+			err:               ErrGiteaHMACVerificationFailed,
+			expectedErrorCode: http.StatusUnauthorized,
+		},
+		"gitea-no-verification": {
+			err:               ErrGiteaInvalidHTTPMethod,
+			expectedErrorCode: http.StatusMethodNotAllowed,
+		},
+		"refresh-verification": {
+			err:               fmt.Errorf("%w: no webhook secret defined", ErrRefreshTokenVerificationFailed),
+			expectedErrorCode: http.StatusUnauthorized,
+		},
+		"refresh-bad-request": {
+			err:               ErrRefreshBadRequest,
+			expectedErrorCode: http.StatusBadRequest,
+		},
 		"github-verification": {
 			err:               github.ErrHMACVerificationFailed,
 			expectedErrorCode: http.StatusUnauthorized,
@@ -782,3 +798,300 @@ func TestErrorReadingRequest(t *testing.T) {
 		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusInternalServerError)
 	}
 }
+
+func TestGiteaSecretAndCommitUpdated(t *testing.T) {
+	expectedCommit := "af69d162de5a276abc86e0686b2b44033cd3f442"
+	tests := map[string]struct {
+		eventHeader     string
+		signatureHeader string
+		secretInRequest string
+		expectedResCode int
+		expectedCommit  string
+	}{
+		"gitea-secret-ok": {
+			eventHeader:     "X-Gitea-Event",
+			signatureHeader: "X-Gitea-Signature",
+			secretInRequest: "supersecretvalue",
+			expectedResCode: http.StatusOK,
+			expectedCommit:  expectedCommit,
+		},
+		"forgejo-secret-ok": {
+			eventHeader:     "X-Forgejo-Event",
+			signatureHeader: "X-Forgejo-Signature",
+			secretInRequest: "supersecretvalue",
+			expectedResCode: http.StatusOK,
+			expectedCommit:  expectedCommit,
+		},
+		"gitea-secret-wrong": {
+			eventHeader:     "X-Gitea-Event",
+			signatureHeader: "X-Gitea-Signature",
+			secretInRequest: "bad-secret",
+			expectedResCode: http.StatusUnauthorized,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			gitRepo := &v1alpha1.GitRepo{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+				Spec: v1alpha1.GitRepoSpec{
+					Repo:   "https://gitea.example.com/example/repo.git",
+					Branch: "main",
+				},
+			}
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: webhookSecretName, Namespace: "default"},
+				Data:       map[string][]byte{giteaKey: []byte("supersecretvalue")},
+			}
+
+			sch := scheme.Scheme
+			utilruntime.Must(v1alpha1.AddToScheme(sch))
+			client := cfake.NewClientBuilder().WithScheme(sch).
+				WithRuntimeObjects(gitRepo, secret).WithStatusSubresource(gitRepo).Build()
+			w := &Webhook{client: client, namespace: "default"}
+
+			jsonBody := []byte(fmt.Sprintf(`
+			{
+			  "ref":"refs/heads/main",
+			  "after":"%s",
+			  "repository":{
+				"html_url":"https://gitea.example.com/example/repo",
+				"clone_url":"https://gitea.example.com/example/repo.git"
+			  }
+			}`, expectedCommit))
+
+			req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, "/", bytes.NewReader(jsonBody))
+			if err != nil {
+				t.Fatalf("Failed to create HTTP request: %v", err)
+			}
+			// Gitea and Forgejo send Gogs and GitHub headers as well
+			req.Header.Set(tt.eventHeader, "push")
+			req.Header.Set("X-Gogs-Event", "push")
+			req.Header.Set("X-GitHub-Event", "push")
+
+			mac256 := hmac.New(sha256.New, []byte(tt.secretInRequest))
+			_, _ = mac256.Write(jsonBody)
+			req.Header.Set(tt.signatureHeader, hex.EncodeToString(mac256.Sum(nil)))
+
+			rr := httptest.NewRecorder()
+			w.ServeHTTP(rr, req)
+
+			if status := rr.Code; status != tt.expectedResCode {
+				t.Errorf("handler returned wrong status code: got %v want %v", status, tt.expectedResCode)
+			}
+
+			updated := &v1alpha1.GitRepo{}
+			if err := client.Get(context.Background(), types.NamespacedName{Name: "test", Namespace: "default"}, updated); err != nil {
+				t.Fatal(err)
+			}
+			if updated.Status.WebhookCommit != tt.expectedCommit {
+				t.Errorf("expecting gitrepo webhook commit %q, got %q", tt.expectedCommit, updated.Status.WebhookCommit)
+			}
+		})
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	expectedCommit := "af69d162de5a276abc86e0686b2b44033cd3f442"
+	tests := map[string]struct {
+		method     string
+		query      string
+		token      string
+		secretData map[string][]byte
+		// otherGitRepo adds a gitrepo of the same repo, whose webhook
+		// secret has another refresh token
+		otherGitRepo    bool
+		expectedResCode int
+		expectedCommit  string
+	}{
+		"token-ok": {
+			method:          http.MethodPost,
+			query:           "repo=https://github.com/example/repo&commit=" + expectedCommit,
+			token:           "refreshtoken",
+			secretData:      map[string][]byte{refreshTokenKey: []byte("refreshtoken")},
+			expectedResCode: http.StatusOK,
+			expectedCommit:  expectedCommit,
+		},
+		"token-ok-ssh-url": {
+			method:          http.MethodPost,
+			query:           "repo=ssh://git@github.com/example/repo.git&branch=main&commit=" + expectedCommit,
+			token:           "refreshtoken",
+			secretData:      map[string][]byte{refreshTokenKey: []byte("refreshtoken")},
+			expectedResCode: http.StatusOK,
+			expectedCommit:  expectedCommit,
+		},
+		"other-branch": {
+			method:          http.MethodPost,
+			query:           "repo=https://github.com/example/repo&branch=dev&commit=" + expectedCommit,
+			token:           "refreshtoken",
+			secretData:      map[string][]byte{refreshTokenKey: []byte("refreshtoken")},
+			expectedResCode: http.StatusOK,
+		},
+		"token-wrong": {
+			method:          http.MethodPost,
+			query:           "repo=https://github.com/example/repo&commit=" + expectedCommit,
+			token:           "bad-token",
+			secretData:      map[string][]byte{refreshTokenKey: []byte("refreshtoken")},
+			expectedResCode: http.StatusUnauthorized,
+		},
+		"token-missing": {
+			method:          http.MethodPost,
+			query:           "repo=https://github.com/example/repo&commit=" + expectedCommit,
+			secretData:      map[string][]byte{refreshTokenKey: []byte("refreshtoken")},
+			expectedResCode: http.StatusUnauthorized,
+		},
+		"no-refresh-token-in-secret": {
+			method:          http.MethodPost,
+			query:           "repo=https://github.com/example/repo&commit=" + expectedCommit,
+			token:           "refreshtoken",
+			secretData:      map[string][]byte{githubKey: []byte("refreshtoken")},
+			expectedResCode: http.StatusUnauthorized,
+		},
+		"no-secret": {
+			method:          http.MethodPost,
+			query:           "repo=https://github.com/example/repo&commit=" + expectedCommit,
+			token:           "refreshtoken",
+			expectedResCode: http.StatusUnauthorized,
+		},
+		"missing-commit": {
+			method:          http.MethodPost,
+			query:           "repo=https://github.com/example/repo",
+			token:           "refreshtoken",
+			secretData:      map[string][]byte{refreshTokenKey: []byte("refreshtoken")},
+			expectedResCode: http.StatusBadRequest,
+		},
+		"invalid-commit": {
+			method:          http.MethodPost,
+			query:           "repo=https://github.com/example/repo&commit=main",
+			token:           "refreshtoken",
+			secretData:      map[string][]byte{refreshTokenKey: []byte("refreshtoken")},
+			expectedResCode: http.StatusBadRequest,
+		},
+		"invalid-method": {
+			method:          http.MethodGet,
+			query:           "repo=https://github.com/example/repo&commit=" + expectedCommit,
+			token:           "refreshtoken",
+			secretData:      map[string][]byte{refreshTokenKey: []byte("refreshtoken")},
+			expectedResCode: http.StatusMethodNotAllowed,
+		},
+		"invalid-method-token-missing": {
+			method:          http.MethodGet,
+			query:           "repo=https://github.com/example/repo&commit=" + expectedCommit,
+			secretData:      map[string][]byte{refreshTokenKey: []byte("refreshtoken")},
+			expectedResCode: http.StatusUnauthorized,
+		},
+		"other-repo-token-wrong": {
+			method:          http.MethodPost,
+			query:           "repo=https://github.com/example/other&commit=" + expectedCommit,
+			token:           "bad-token",
+			secretData:      map[string][]byte{refreshTokenKey: []byte("refreshtoken")},
+			expectedResCode: http.StatusUnauthorized,
+		},
+		"repo-without-path": {
+			method:          http.MethodPost,
+			query:           "repo=https://github.com/&commit=" + expectedCommit,
+			token:           "refreshtoken",
+			secretData:      map[string][]byte{refreshTokenKey: []byte("refreshtoken")},
+			expectedResCode: http.StatusBadRequest,
+		},
+		"token-of-other-gitrepo": {
+			method:          http.MethodPost,
+			query:           "repo=https://github.com/example/repo&commit=" + expectedCommit,
+			token:           "refreshtoken",
+			secretData:      map[string][]byte{refreshTokenKey: []byte("refreshtoken")},
+			otherGitRepo:    true,
+			expectedResCode: http.StatusForbidden,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			gitRepo := &v1alpha1.GitRepo{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+				Spec: v1alpha1.GitRepoSpec{
+					Repo:   "git@github.com:example/repo.git",
+					Branch: "main",
+				},
+			}
+			objs := []runtime.Object{gitRepo}
+			if tt.otherGitRepo {
+				objs = append(objs,
+					// listed after the gitrepo with a valid token
+					&v1alpha1.GitRepo{
+						ObjectMeta: metav1.ObjectMeta{Name: "test-other", Namespace: "default"},
+						Spec: v1alpha1.GitRepoSpec{
+							Repo:          "https://github.com/example/repo",
+							WebhookSecret: "other-secret",
+						},
+					},
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{Name: "other-secret", Namespace: "default"},
+						Data:       map[string][]byte{refreshTokenKey: []byte("othertoken")},
+					},
+				)
+			}
+			if tt.secretData != nil {
+				objs = append(objs, &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: webhookSecretName, Namespace: "default"},
+					Data:       tt.secretData,
+				})
+			}
+
+			sch := scheme.Scheme
+			utilruntime.Must(v1alpha1.AddToScheme(sch))
+			client := cfake.NewClientBuilder().WithScheme(sch).
+				WithRuntimeObjects(objs...).WithStatusSubresource(gitRepo).Build()
+
+			handler, err := HandleHooks(context.Background(), "default", client, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req, err := http.NewRequestWithContext(context.Background(), tt.method, "/refresh?"+tt.query, nil)
+			if err != nil {
+				t.Fatalf("Failed to create HTTP request: %v", err)
+			}
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if status := rr.Code; status != tt.expectedResCode {
+				t.Errorf("handler returned wrong status code: got %v want %v: %s", status, tt.expectedResCode, rr.Body)
+			}
+
+			updated := &v1alpha1.GitRepo{}
+			if err := client.Get(context.Background(), types.NamespacedName{Name: "test", Namespace: "default"}, updated); err != nil {
+				t.Fatal(err)
+			}
+			if updated.Status.WebhookCommit != tt.expectedCommit {
+				t.Errorf("expecting gitrepo webhook commit %q, got %q", tt.expectedCommit, updated.Status.WebhookCommit)
+			}
+		})
+	}
+}
+
+func TestUpdateGitReposWithoutPath(t *testing.T) {
+	gitRepo := &v1alpha1.GitRepo{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec:       v1alpha1.GitRepoSpec{Repo: "https://github.com/example/repo"},
+	}
+	sch := scheme.Scheme
+	utilruntime.Must(v1alpha1.AddToScheme(sch))
+	client := cfake.NewClientBuilder().WithScheme(sch).WithRuntimeObjects(gitRepo).Build()
+
+	w, err := New("default", client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, repo := range []string{"https://github.com", "https://github.com/"} {
+		if err := w.updateGitRepos(context.Background(), "af69d162de5a276abc86e0686b2b44033cd3f442", "", true, []string{repo}, func(*corev1.Secret) error {
+			t.Errorf("expected %q not to match a gitrepo", repo)
+			return nil
+		}); err != nil {
+			t.Errorf("expected no error for %q, got %v", repo, err)
+		}
+	}
+}